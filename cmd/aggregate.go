@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var aggregateCmd = &cobra.Command{
+	Use:   "aggregate [pattern...]",
+	Short: "Print summary statistics pooled across several evaluation files",
+	Long: `Pool the results of every evaluation file matching the given glob patterns
+and print one set of summary statistics for all of them, as summary does for a
+single file.
+
+Patterns without a path separator are matched in the evals directory (evals/
+unless --evals-dir or HTR_EVALS_DIR is set). Quote patterns so the shell does
+not expand them. Only YAML and JSON eval files, compressed or not, are read.
+
+Every row counts once, so a file with more rows weighs more in the averages
+than a smaller one. With --group-by provider or --group-by model, the rows are
+pooled per provider or per model instead, and one summary is printed for each.
+
+Examples:
+  htr aggregate 'openai_*'
+  htr aggregate '*' --group-by model
+  htr aggregate 'runs/prompt-v2/*.yaml' --weighted`,
+	RunE: runAggregate,
+	Args: cobra.MinimumNArgs(1),
+}
+
+var (
+	aggregateGroupBy  string
+	aggregateWeighted bool
+)
+
+// evalGroup is the pooled results of the eval files sharing a group key.
+type evalGroup struct {
+	Name    string
+	Files   []string
+	Results []EvalResult
+}
+
+func init() {
+	RootCmd.AddCommand(aggregateCmd)
+
+	aggregateCmd.Flags().StringVar(&aggregateGroupBy, "group-by", "", "Pool results per provider or model instead of across all files (provider, model)")
+	aggregateCmd.Flags().BoolVar(&aggregateWeighted, "weighted", false, "Also print word accuracy and word error rate over the whole corpus, weighting each document by its length")
+}
+
+func runAggregate(cmd *cobra.Command, args []string) error {
+	if aggregateGroupBy != "" && aggregateGroupBy != "provider" && aggregateGroupBy != "model" {
+		return fmt.Errorf("invalid --group-by %q: must be provider or model", aggregateGroupBy)
+	}
+
+	files, err := matchEvalFiles(evalsDir, args)
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("no evaluation files match %s", strings.Join(args, " "))
+	}
+
+	groups, err := aggregateEvalFiles(files, aggregateGroupBy)
+	if err != nil {
+		return err
+	}
+	for i, group := range groups {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("=== AGGREGATE SUMMARY ===\n")
+		if aggregateGroupBy != "" {
+			fmt.Printf("%s: %s\n", strings.ToUpper(aggregateGroupBy[:1])+aggregateGroupBy[1:], group.Name)
+		}
+		fmt.Printf("Files: %d\n", len(group.Files))
+		for _, file := range group.Files {
+			fmt.Printf("  %s\n", filepath.Base(file))
+		}
+		fmt.Printf("Total Images Evaluated: %d\n", len(group.Results))
+		printSummaryStats(group.Results, aggregateWeighted)
+	}
+	return nil
+}
+
+// matchEvalFiles returns the eval files matching any of patterns, sorted and
+// without duplicates. Patterns without a path separator are matched in
+// evalsDir, as resolveEvalFile looks up bare names.
+func matchEvalFiles(evalsDir string, patterns []string) ([]string, error) {
+	var files []string
+	for _, pattern := range patterns {
+		if !strings.Contains(pattern, string(filepath.Separator)) {
+			pattern = filepath.Join(evalsDir, pattern)
+		}
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+		for _, match := range matches {
+			if isEvalFile(match) {
+				files = append(files, match)
+			}
+		}
+	}
+	slices.Sort(files)
+	return slices.Compact(files), nil
+}
+
+// isEvalFile reports whether path has one of the eval file extensions.
+func isEvalFile(path string) bool {
+	for _, ext := range evalFileExtensions {
+		if strings.HasSuffix(path, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// aggregateEvalFiles loads files and pools their results, all together when
+// groupBy is empty and otherwise per the provider or model in each file's
+// config. Groups are sorted by name.
+func aggregateEvalFiles(files []string, groupBy string) ([]evalGroup, error) {
+	var groups []evalGroup
+	index := make(map[string]int)
+	for _, file := range files {
+		summary, err := loadEvalSummary(file)
+		if err != nil {
+			return nil, err
+		}
+		var name string
+		switch groupBy {
+		case "provider":
+			name = summary.Config.Provider
+		case "model":
+			name = summary.Config.Model
+		}
+		i, ok := index[name]
+		if !ok {
+			i = len(groups)
+			index[name] = i
+			groups = append(groups, evalGroup{Name: name})
+		}
+		groups[i].Files = append(groups[i].Files, file)
+		groups[i].Results = append(groups[i].Results, summary.Results...)
+	}
+	slices.SortFunc(groups, func(a, b evalGroup) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return groups, nil
+}
@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	htrmetrics "github.com/lehigh-university-libraries/htr/pkg/metrics"
+)
+
+func writeAggregateFixtures(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	summaries := map[string]EvalSummary{
+		"openai_a.yaml": {
+			Config:  EvalConfig{Provider: "openai", Model: "a"},
+			Results: []EvalResult{{Identifier: "1.jpg", WordAccuracy: 1}, {Identifier: "2.jpg", WordAccuracy: 0.5}},
+		},
+		"openai_b.json": {
+			Config:  EvalConfig{Provider: "openai", Model: "b"},
+			Results: []EvalResult{{Identifier: "1.jpg", WordAccuracy: 0}},
+		},
+		"claude_a.yaml.gz": {
+			Config:  EvalConfig{Provider: "claude", Model: "a"},
+			Results: []EvalResult{{Identifier: "1.jpg", WordAccuracy: 0.5}},
+		},
+	}
+	for name, summary := range summaries {
+		if err := saveEvalResults(summary, filepath.Join(dir, name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, "openai_c.yaml.partial"), []byte("config: {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestMatchEvalFiles(t *testing.T) {
+	dir := writeAggregateFixtures(t)
+
+	got, err := matchEvalFiles(dir, []string{"openai_*", "*_a.*", filepath.Join(dir, "openai_b.json")})
+	if err != nil {
+		t.Fatalf("matchEvalFiles() error = %v", err)
+	}
+	want := []string{
+		filepath.Join(dir, "claude_a.yaml.gz"),
+		filepath.Join(dir, "openai_a.yaml"),
+		filepath.Join(dir, "openai_b.json"),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("matchEvalFiles() = %v, want %v", got, want)
+	}
+
+	if _, err := matchEvalFiles(dir, []string{"["}); err == nil {
+		t.Error("matchEvalFiles() with a malformed pattern: expected error")
+	}
+}
+
+func TestAggregateEvalFiles(t *testing.T) {
+	dir := writeAggregateFixtures(t)
+	files, err := matchEvalFiles(dir, []string{"*"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type pooled struct {
+		files, results int
+		wordAccuracy   float64
+	}
+	tests := []struct {
+		groupBy string
+		want    map[string]pooled
+	}{
+		{groupBy: "", want: map[string]pooled{"": {3, 4, 0.5}}},
+		{groupBy: "provider", want: map[string]pooled{"claude": {1, 1, 0.5}, "openai": {2, 3, 0.5}}},
+		{groupBy: "model", want: map[string]pooled{"a": {2, 3, 2.0 / 3}, "b": {1, 1, 0}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.groupBy, func(t *testing.T) {
+			groups, err := aggregateEvalFiles(files, tt.groupBy)
+			if err != nil {
+				t.Fatalf("aggregateEvalFiles() error = %v", err)
+			}
+			if len(groups) != len(tt.want) {
+				t.Fatalf("aggregateEvalFiles() returned %d groups, want %d", len(groups), len(tt.want))
+			}
+			for i, group := range groups {
+				if i > 0 && groups[i-1].Name >= group.Name {
+					t.Errorf("groups not sorted by name: %q before %q", groups[i-1].Name, group.Name)
+				}
+				want, ok := tt.want[group.Name]
+				if !ok {
+					t.Errorf("unexpected group %q", group.Name)
+					continue
+				}
+				if len(group.Files) != want.files {
+					t.Errorf("group %q has %d files, want %d", group.Name, len(group.Files), want.files)
+				}
+				if len(group.Results) != want.results {
+					t.Errorf("group %q has %d results, want %d", group.Name, len(group.Results), want.results)
+				}
+				accuracies := make([]float64, len(group.Results))
+				for j, result := range group.Results {
+					accuracies[j] = result.WordAccuracy
+				}
+				if mean := htrmetrics.Summarize(accuracies).Mean; math.Abs(mean-want.wordAccuracy) > 1e-9 {
+					t.Errorf("group %q mean word accuracy = %v, want %v", group.Name, mean, want.wordAccuracy)
+				}
+			}
+		})
+	}
+}
+
+func TestRunAggregateErrors(t *testing.T) {
+	originalEvalsDir := evalsDir
+	originalGroupBy := aggregateGroupBy
+	t.Cleanup(func() {
+		evalsDir = originalEvalsDir
+		aggregateGroupBy = originalGroupBy
+	})
+	evalsDir = writeAggregateFixtures(t)
+
+	aggregateGroupBy = "prompt"
+	if err := runAggregate(aggregateCmd, []string{"*"}); err == nil {
+		t.Error("runAggregate() with --group-by prompt: expected error")
+	}
+
+	aggregateGroupBy = ""
+	if err := runAggregate(aggregateCmd, []string{"missing_*"}); err == nil {
+		t.Error("runAggregate() with no matching files: expected error")
+	}
+	if err := runAggregate(aggregateCmd, []string{"*"}); err != nil {
+		t.Errorf("runAggregate() error = %v", err)
+	}
+}
@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/lehigh-university-libraries/htr/pkg/providers"
+)
+
+const defaultCacheDir = ".htr-cache"
+
+// cachedResponse is the on-disk form of one provider response.
+type cachedResponse struct {
+	Text  string              `json:"text"`
+	Usage providers.UsageInfo `json:"usage"`
+}
+
+// responseCacheKey hashes every input that determines a provider response.
+// Fields are length-prefixed so adjacent values cannot run together. The
+// max tokens limit, system prompt, prompt suffix, OpenAI image detail, and
+// Ollama context window are only included when set, so keys from runs
+// without them stay valid.
+func responseCacheKey(config EvalConfig, imageBase64 string) string {
+	hash := sha256.New()
+	fields := []string{
+		config.Provider,
+		config.Model,
+		config.Prompt,
+		strconv.FormatFloat(config.Temperature, 'g', -1, 64),
+		config.MaxResolution,
+		imageBase64,
+	}
+	if config.MaxTokens > 0 {
+		fields = append(fields, "max_tokens="+strconv.Itoa(config.MaxTokens))
+	}
+	if config.SystemPrompt != "" {
+		fields = append(fields, "system_prompt="+config.SystemPrompt)
+	}
+	if config.PromptSuffix != "" {
+		fields = append(fields, "prompt_suffix="+config.PromptSuffix)
+	}
+	if config.OpenAIDetail != "" {
+		fields = append(fields, "openai_detail="+config.OpenAIDetail)
+	}
+	if config.OllamaNumCtx > 0 {
+		fields = append(fields, "num_ctx="+strconv.Itoa(config.OllamaNumCtx))
+	}
+	for _, field := range fields {
+		hash.Write([]byte(strconv.Itoa(len(field)) + ":" + field))
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func responseCachePath(cacheDir, key string) string {
+	return filepath.Join(cacheDir, key[:2], key+".json")
+}
+
+// loadCachedResponse returns the cached response for key. A missing entry is
+// reported as ok == false rather than an error.
+func loadCachedResponse(cacheDir, key string) (cachedResponse, bool, error) {
+	data, err := os.ReadFile(responseCachePath(cacheDir, key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return cachedResponse{}, false, nil
+	}
+	if err != nil {
+		return cachedResponse{}, false, err
+	}
+	var cached cachedResponse
+	if err := json.Unmarshal(data, &cached); err != nil {
+		return cachedResponse{}, false, err
+	}
+	return cached, true, nil
+}
+
+func saveCachedResponse(cacheDir, key string, response cachedResponse) error {
+	path := responseCachePath(cacheDir, key)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(response)
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
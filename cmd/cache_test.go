@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/lehigh-university-libraries/htr/pkg/providers"
+)
+
+func TestResponseCacheKey(t *testing.T) {
+	base := EvalConfig{Provider: "openai", Model: "gpt-4o", Prompt: "Transcribe", Temperature: 0.2}
+	baseKey := responseCacheKey(base, "aW1hZ2U=")
+
+	if got := responseCacheKey(base, "aW1hZ2U="); got != baseKey {
+		t.Fatalf("key is not stable: %s != %s", got, baseKey)
+	}
+	// Settings that do not affect the response must not change the key.
+	unrelated := base
+	unrelated.Timestamp = "2026-01-01_00-00-00"
+	unrelated.CSVPath = "other.csv"
+	unrelated.MaxRetries = 5
+	if got := responseCacheKey(unrelated, "aW1hZ2U="); got != baseKey {
+		t.Errorf("unrelated settings changed the key")
+	}
+
+	tests := []struct {
+		name   string
+		mutate func(*EvalConfig)
+		image  string
+	}{
+		{"provider", func(c *EvalConfig) { c.Provider = "azure" }, "aW1hZ2U="},
+		{"model", func(c *EvalConfig) { c.Model = "gpt-4o-mini" }, "aW1hZ2U="},
+		{"prompt", func(c *EvalConfig) { c.Prompt = "Transcribe!" }, "aW1hZ2U="},
+		{"temperature", func(c *EvalConfig) { c.Temperature = 0.3 }, "aW1hZ2U="},
+		{"resolution", func(c *EvalConfig) { c.MaxResolution = "MEDIA_RESOLUTION_LOW" }, "aW1hZ2U="},
+		{"max tokens", func(c *EvalConfig) { c.MaxTokens = 8192 }, "aW1hZ2U="},
+		{"system prompt", func(c *EvalConfig) { c.SystemPrompt = "You are a paleographer." }, "aW1hZ2U="},
+		{"prompt suffix", func(c *EvalConfig) { c.PromptSuffix = "This is Secretary hand." }, "aW1hZ2U="},
+		{"ollama context window", func(c *EvalConfig) { c.OllamaNumCtx = 8192 }, "aW1hZ2U="},
+		{"openai detail", func(c *EvalConfig) { c.OpenAIDetail = "low" }, "aW1hZ2U="},
+		{"image", func(*EvalConfig) {}, "b3RoZXI="},
+		{"field boundary", func(c *EvalConfig) { c.Model, c.Prompt = "gpt-4oT", "ranscribe" }, "aW1hZ2U="},
+	}
+	for _, tt := range tests {
+		config := base
+		tt.mutate(&config)
+		if responseCacheKey(config, tt.image) == baseKey {
+			t.Errorf("changing %s did not change the key", tt.name)
+		}
+	}
+}
+
+func TestExtractTextWithProviderCache(t *testing.T) {
+	stub := &stubEvalProvider{responses: map[string]string{"page.jpg": "cached text"}}
+	useStubEvalProvider(t, stub)
+	config := EvalConfig{Provider: "stub", Model: "m", Prompt: "p", CacheDir: t.TempDir()}
+
+	text, usage, err := extractTextWithProvider(context.Background(), config, "page.jpg", "aW1hZ2U=")
+	if err != nil || text != "cached text" || usage.InputTokens != 10 {
+		t.Fatalf("miss: text = %q, usage = %+v, err = %v", text, usage, err)
+	}
+
+	stub.responses["page.jpg"] = "fresh text"
+	stub.truncated = map[string]bool{"page.jpg": true}
+	text, usage, err = extractTextWithProvider(context.Background(), config, "page.jpg", "aW1hZ2U=")
+	if err != nil || text != "cached text" || usage != (providers.UsageInfo{InputTokens: 10, OutputTokens: 5}) {
+		t.Fatalf("hit: text = %q, usage = %+v, err = %v", text, usage, err)
+	}
+	if len(stub.calls) != 1 {
+		t.Fatalf("provider called %d times, want 1", len(stub.calls))
+	}
+
+	config.Prompt = "another prompt"
+	if text, _, _ := extractTextWithProvider(context.Background(), config, "page.jpg", "aW1hZ2U="); text != "fresh text" || len(stub.calls) != 2 {
+		t.Fatalf("changed prompt: text = %q after %d calls, want a fresh call", text, len(stub.calls))
+	}
+
+	config.CacheDir = ""
+	if _, _, _ = extractTextWithProvider(context.Background(), config, "page.jpg", "aW1hZ2U="); len(stub.calls) != 3 {
+		t.Fatalf("disabled cache still served a response")
+	}
+}
@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"cmp"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"slices"
+	"strconv"
+
+	htrmetrics "github.com/lehigh-university-libraries/htr/pkg/metrics"
+	"github.com/spf13/cobra"
+)
+
+var confusionCmd = &cobra.Command{
+	Use:   "confusion [eval-file...]",
+	Short: "Show the characters a model most often misreads",
+	Long: `Tally character substitutions across one or more evaluation files.
+
+Each row's ground truth is aligned character by character with the stored provider
+response, and every substituted pair (for example "ſ" read as "f") is counted. The
+most frequent confusions across all rows are printed, sorted by count.
+
+Ground truth is normalized with each file's saved --ignore, --single-line, and
+--ignore-case settings, so the alignment matches the one behind the accuracy metrics.
+Rows whose transcript can no longer be read are skipped.
+
+Examples:
+  htr confusion gpt-4o
+  htr confusion gpt-4o claude-sonnet-4-5 --top 50
+  htr confusion gpt-4o --output confusions.csv`,
+	RunE: runConfusion,
+	Args: cobra.MinimumNArgs(1),
+}
+
+var (
+	confusionTop        int
+	confusionOutputPath string
+)
+
+// confusionPair is a ground-truth character and the character transcribed in its place.
+type confusionPair struct {
+	Truth       rune
+	Transcribed rune
+}
+
+// confusionCount is a confusionPair with the number of times it occurred.
+type confusionCount struct {
+	confusionPair
+	Count int
+}
+
+func init() {
+	RootCmd.AddCommand(confusionCmd)
+
+	confusionCmd.Flags().IntVarP(&confusionTop, "top", "n", 20, "Number of most frequent confusions to print (0 prints all)")
+	confusionCmd.Flags().StringVarP(&confusionOutputPath, "output", "o", "", "Also write every confusion to this CSV file")
+}
+
+func runConfusion(cmd *cobra.Command, args []string) error {
+	counts := make(map[confusionPair]int)
+	rows := 0
+	for _, arg := range args {
+		evalFile := resolveEvalFile(evalsDir, arg)
+		summary, err := loadEvalSummary(evalFile)
+		if err != nil {
+			return err
+		}
+
+		ignoreRegex, err := compileIgnoreRegex(summary.Config.IgnoreRegex)
+		if err != nil {
+			return fmt.Errorf("%s: %w", evalFile, err)
+		}
+		options := htrmetrics.Options{
+			IgnorePatterns: summary.Config.IgnorePatterns,
+			IgnoreRegex:    ignoreRegex,
+			SingleLine:     summary.Config.SingleLine,
+			IgnoreCase:     summary.Config.IgnoreCase,
+		}
+		for _, result := range summary.Results {
+			if result.TranscriptPath == "" {
+				continue
+			}
+			groundTruth, err := readTextFile(context.Background(), result.TranscriptPath)
+			if err != nil {
+				slog.Warn("Skipping row with unreadable transcript", "file", evalFile, "identifier", result.Identifier, "err", err)
+				continue
+			}
+			tallyConfusions(counts, groundTruth, result.ProviderResponse, options)
+			rows++
+		}
+	}
+
+	confusions := sortedConfusions(counts)
+	fmt.Printf("Analyzed %d rows from %d evaluation files; found %d distinct substitutions\n\n", rows, len(args), len(confusions))
+	printConfusions(os.Stdout, confusions, confusionTop)
+
+	if confusionOutputPath != "" {
+		file, err := os.Create(confusionOutputPath)
+		if err != nil {
+			return fmt.Errorf("failed to create CSV: %w", err)
+		}
+		defer file.Close()
+
+		if err := writeConfusionCSV(file, confusions); err != nil {
+			return fmt.Errorf("failed to write CSV: %w", err)
+		}
+		fmt.Printf("\nConfusions written to %s\n", confusionOutputPath)
+	}
+	return nil
+}
+
+// tallyConfusions adds the character substitutions between groundTruth and
+// response to counts after applying options.
+func tallyConfusions(counts map[confusionPair]int, groundTruth, response string, options htrmetrics.Options) {
+	groundTruth, response, _ = htrmetrics.Normalize(groundTruth, response, options)
+	truthRunes, responseRunes := []rune(groundTruth), []rune(response)
+	for _, step := range htrmetrics.AlignCharacters(groundTruth, response) {
+		if step.Op == htrmetrics.EditSubstitute {
+			counts[confusionPair{Truth: truthRunes[step.Original], Transcribed: responseRunes[step.Transcribed]}]++
+		}
+	}
+}
+
+// sortedConfusions orders counts from most to least frequent, breaking ties
+// by ground-truth and then transcribed character.
+func sortedConfusions(counts map[confusionPair]int) []confusionCount {
+	confusions := make([]confusionCount, 0, len(counts))
+	for pair, count := range counts {
+		confusions = append(confusions, confusionCount{confusionPair: pair, Count: count})
+	}
+	slices.SortFunc(confusions, func(a, b confusionCount) int {
+		return cmp.Or(
+			cmp.Compare(b.Count, a.Count),
+			cmp.Compare(a.Truth, b.Truth),
+			cmp.Compare(a.Transcribed, b.Transcribed),
+		)
+	})
+	return confusions
+}
+
+// printConfusions writes the top confusions as an aligned table. Characters
+// are quoted so whitespace and control characters stay visible.
+func printConfusions(w io.Writer, confusions []confusionCount, top int) {
+	if top > 0 && len(confusions) > top {
+		confusions = confusions[:top]
+	}
+	fmt.Fprintf(w, "%-6s %-12s %-12s %s\n", "Rank", "GroundTruth", "Transcribed", "Count")
+	for index, confusion := range confusions {
+		fmt.Fprintf(w, "%-6d %-12s %-12s %d\n", index+1, strconv.QuoteRune(confusion.Truth), strconv.QuoteRune(confusion.Transcribed), confusion.Count)
+	}
+}
+
+// writeConfusionCSV writes every confusion with its raw characters.
+func writeConfusionCSV(w io.Writer, confusions []confusionCount) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"ground_truth", "transcribed", "count"}); err != nil {
+		return err
+	}
+	for _, confusion := range confusions {
+		record := []string{string(confusion.Truth), string(confusion.Transcribed), strconv.Itoa(confusion.Count)}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
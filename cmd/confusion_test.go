@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	htrmetrics "github.com/lehigh-university-libraries/htr/pkg/metrics"
+)
+
+func TestTallyConfusions(t *testing.T) {
+	tests := []struct {
+		name    string
+		rows    [][2]string
+		options htrmetrics.Options
+		want    map[confusionPair]int
+	}{
+		{
+			name: "long s and minims across rows",
+			rows: [][2]string{
+				{"ſome houſe", "fome houfe"},
+				{"minute", "ninute"},
+				{"unum", "unnm"},
+			},
+			want: map[confusionPair]int{
+				{Truth: 'ſ', Transcribed: 'f'}: 2,
+				{Truth: 'm', Transcribed: 'n'}: 1,
+				{Truth: 'u', Transcribed: 'n'}: 1,
+			},
+		},
+		{
+			name: "insertions and deletions are not confusions",
+			rows: [][2]string{{"cat", "cart"}, {"dogs", "dog"}},
+			want: map[confusionPair]int{},
+		},
+		{
+			name:    "ignore case hides case-only differences",
+			rows:    [][2]string{{"Salt", "salt"}},
+			options: htrmetrics.Options{IgnoreCase: true},
+			want:    map[confusionPair]int{},
+		},
+		{
+			name:    "ignore patterns skip unknown characters",
+			rows:    [][2]string{{"d|te", "dote"}},
+			options: htrmetrics.Options{IgnorePatterns: []string{"|"}},
+			want:    map[confusionPair]int{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			counts := make(map[confusionPair]int)
+			for _, row := range tt.rows {
+				tallyConfusions(counts, row[0], row[1], tt.options)
+			}
+			if !reflect.DeepEqual(counts, tt.want) {
+				t.Errorf("tallyConfusions() = %v, want %v", counts, tt.want)
+			}
+		})
+	}
+}
+
+func TestSortedConfusions(t *testing.T) {
+	counts := map[confusionPair]int{
+		{Truth: 'u', Transcribed: 'n'}: 2,
+		{Truth: 'ſ', Transcribed: 'f'}: 5,
+		{Truth: 'e', Transcribed: 'c'}: 2,
+	}
+
+	got := sortedConfusions(counts)
+	want := []confusionCount{
+		{confusionPair: confusionPair{Truth: 'ſ', Transcribed: 'f'}, Count: 5},
+		{confusionPair: confusionPair{Truth: 'e', Transcribed: 'c'}, Count: 2},
+		{confusionPair: confusionPair{Truth: 'u', Transcribed: 'n'}, Count: 2},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("sortedConfusions() = %v, want %v", got, want)
+	}
+
+	var table strings.Builder
+	printConfusions(&table, got, 2)
+	lines := strings.Split(strings.TrimSpace(table.String()), "\n")
+	if len(lines) != 3 || !strings.Contains(lines[1], "'ſ'") || !strings.HasSuffix(lines[1], "5") {
+		t.Errorf("printConfusions() top 2 =\n%s", table.String())
+	}
+
+	var csvOut strings.Builder
+	if err := writeConfusionCSV(&csvOut, got); err != nil {
+		t.Fatalf("writeConfusionCSV() error = %v", err)
+	}
+	wantCSV := "ground_truth,transcribed,count\nſ,f,5\ne,c,2\nu,n,2\n"
+	if csvOut.String() != wantCSV {
+		t.Errorf("writeConfusionCSV() = %q, want %q", csvOut.String(), wantCSV)
+	}
+}
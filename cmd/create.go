@@ -5,12 +5,19 @@ import (
 	"log/slog"
 	"os"
 
+	"github.com/lehigh-university-libraries/htr/internal/utils"
 	"github.com/lehigh-university-libraries/htr/pkg/azure"
+	"github.com/lehigh-university-libraries/htr/pkg/azureopenai"
+	"github.com/lehigh-university-libraries/htr/pkg/bedrock"
 	"github.com/lehigh-university-libraries/htr/pkg/claude"
 	"github.com/lehigh-university-libraries/htr/pkg/gemini"
 	"github.com/lehigh-university-libraries/htr/pkg/hocr"
+	"github.com/lehigh-university-libraries/htr/pkg/huggingface"
+	"github.com/lehigh-university-libraries/htr/pkg/kraken"
+	"github.com/lehigh-university-libraries/htr/pkg/mistral"
 	"github.com/lehigh-university-libraries/htr/pkg/ollama"
 	"github.com/lehigh-university-libraries/htr/pkg/openai"
+	"github.com/lehigh-university-libraries/htr/pkg/openaicompat"
 	"github.com/lehigh-university-libraries/htr/pkg/providers"
 	"github.com/spf13/cobra"
 )
@@ -22,26 +29,54 @@ var createCmd = &cobra.Command{
 
 This command processes an image to detect word boundaries using image processing techniques,
 creates a stitched image with hOCR markup overlays, and then uses a Language Model to
-transcribe the text content, producing a complete hOCR XML output.`,
+transcribe the text content, producing a complete hOCR XML output.
+
+Intermediate images are written to a per-run directory under --temp-dir (or $TMPDIR)
+and removed when the command finishes.`,
 	RunE: runCreate,
 }
 
 var (
-	imagePath   string
-	provider    string
-	model       string
-	outputPath  string
-	temperature float64
+	imagePath    string
+	provider     string
+	model        string
+	outputPath   string
+	outputFormat string
+	temperature  float64
+	tempDir      string
+	concurrency  int
+	maxTokens    int
+	systemPrompt string
+	maxDimension int
+	page         int
+
+	deskew          bool
+	grayscale       bool
+	contrastStretch string
+	threshold       string
+	readingOrder    string
 )
 
 func init() {
 	RootCmd.AddCommand(createCmd)
 
 	createCmd.Flags().StringVar(&imagePath, "image", "", "Path to input image file (required)")
-	createCmd.Flags().StringVar(&provider, "provider", "ollama", "Provider to use: openai, azure, claude, gemini, ollama")
+	createCmd.Flags().IntVar(&page, "page", 1, "Page of a TIFF or PDF input to rasterize and transcribe, numbered from 1")
+	createCmd.Flags().StringVar(&provider, "provider", "ollama", "Provider to use: openai, azure, azure-openai, claude, gemini, ollama, mistral, openai-compat, huggingface, kraken, bedrock")
 	createCmd.Flags().StringVar(&model, "model", "", "Model to use (uses provider default if not specified)")
-	createCmd.Flags().StringVarP(&outputPath, "output", "o", "", "Output path for hOCR XML file (prints to stdout if not specified)")
+	createCmd.Flags().StringVarP(&outputPath, "output", "o", "", "Output path for the result file (prints to stdout if not specified)")
+	createCmd.Flags().StringVar(&outputFormat, "output-format", "hocr", "Output format: hocr or text")
 	createCmd.Flags().Float64VarP(&temperature, "temperature", "t", 0.0, "Temperature for LLM")
+	createCmd.Flags().StringVar(&tempDir, "temp-dir", "", "Parent directory for temporary word images (defaults to $TMPDIR or the system temp directory)")
+	createCmd.Flags().StringVar(&systemPrompt, "system-prompt", "", "System prompt to send with each line transcription request (optional)")
+	createCmd.Flags().IntVar(&maxTokens, "max-tokens", 0, "Maximum response tokens for Claude and OpenAI-style providers (Claude defaults to 4096)")
+	createCmd.Flags().IntVar(&maxDimension, "max-dimension", 0, "Downscale line images whose longest side exceeds this many pixels before upload (0 sends them as-is)")
+	createCmd.Flags().BoolVar(&deskew, "deskew", hocr.DefaultPreprocessing.Deskew, "Straighten slightly rotated scans before word detection")
+	createCmd.Flags().BoolVar(&grayscale, "grayscale", hocr.DefaultPreprocessing.Grayscale, "Convert to grayscale before word detection (--grayscale=false to skip)")
+	createCmd.Flags().StringVar(&contrastStretch, "contrast-stretch", hocr.DefaultPreprocessing.ContrastStretch, "Black and white points clipped before word detection, as BLACKxWHITE (empty to skip)")
+	createCmd.Flags().StringVar(&threshold, "threshold", hocr.DefaultPreprocessing.Threshold, "Level at which the image is binarized for word detection (empty to skip)")
+	createCmd.Flags().StringVar(&readingOrder, "reading-order", string(hocr.ReadingOrderSingle), "Order of detected lines: single (top to bottom) or columns (each column top to bottom, left column first)")
+	createCmd.Flags().IntVar(&concurrency, "concurrency", 4, "Maximum number of lines to transcribe in parallel")
 
 	err := createCmd.MarkFlagRequired("image")
 	if err != nil {
@@ -51,20 +86,74 @@ func init() {
 }
 
 func runCreate(cmd *cobra.Command, args []string) error {
-	// Validate input file exists
-	if _, err := os.Stat(imagePath); os.IsNotExist(err) {
-		return fmt.Errorf("input image file does not exist: %s", imagePath)
-	}
-
 	// Initialize provider registry
 	registry := providers.NewRegistry()
 	registry.Register(openai.New())
 	registry.Register(azure.New())
+	registry.Register(azureopenai.New())
 	registry.Register(claude.New())
 	registry.Register(gemini.New())
 	registry.Register(ollama.New())
+	registry.Register(mistral.New())
+	registry.Register(openaicompat.New())
+	registry.Register(huggingface.New())
+	registry.Register(kraken.New())
+	registry.Register(bedrock.New())
+
+	// Check the provider before touching any files
+	if err := validateProvider(registry, provider); err != nil {
+		return err
+	}
+
+	if outputFormat != "hocr" && outputFormat != "text" {
+		return fmt.Errorf("unsupported output format: %s (use hocr or text)", outputFormat)
+	}
+
+	// Validate input file exists
+	if _, err := os.Stat(imagePath); os.IsNotExist(err) {
+		return fmt.Errorf("input image file does not exist: %s", imagePath)
+	}
+
+	if maxTokens < 0 {
+		return fmt.Errorf("--max-tokens cannot be negative")
+	}
+
+	if maxDimension < 0 {
+		return fmt.Errorf("--max-dimension cannot be negative")
+	}
+
+	if concurrency < 1 {
+		return fmt.Errorf("--concurrency must be at least 1")
+	}
+
+	if page < 1 {
+		return fmt.Errorf("--page must be at least 1")
+	}
+
+	preprocessing := hocr.PreprocessOptions{
+		Deskew:          deskew,
+		Grayscale:       grayscale,
+		ContrastStretch: contrastStretch,
+		Threshold:       threshold,
+	}
+	if err := preprocessing.Validate(); err != nil {
+		return err
+	}
+	hocr.Preprocessing = preprocessing
+
+	order, err := hocr.ParseReadingOrder(readingOrder)
+	if err != nil {
+		return err
+	}
+	hocr.LineOrder = order
+
+	if tempDir != "" {
+		if info, err := os.Stat(tempDir); err != nil || !info.IsDir() {
+			return fmt.Errorf("temp directory does not exist: %s", tempDir)
+		}
+		hocr.TempDir = tempDir
+	}
 
-	// Get provider
 	providerInstance, err := registry.Get(provider)
 	if err != nil {
 		return fmt.Errorf("unsupported provider: %s", provider)
@@ -77,28 +166,51 @@ func runCreate(cmd *cobra.Command, args []string) error {
 
 	slog.Info("Creating hOCR XML from image", "image", imagePath, "provider", provider, "model", model)
 
+	// Word detection and line crops read the image with ImageMagick, so a
+	// TIFF or PDF page is rasterized to a file first
+	pagePath, err := rasterizeImageFile(imagePath, page, tempDir)
+	if err != nil {
+		return fmt.Errorf("failed to rasterize %s: %w", imagePath, err)
+	}
+	if pagePath != imagePath {
+		defer os.Remove(pagePath)
+		slog.Info("Rasterized page of input document", "page", page, "path", pagePath)
+	}
+
 	// Step 1: Detect word boundaries using custom image processing
-	ocrResponse, err := hocr.DetectWordBoundariesCustom(imagePath)
+	ocrResponse, err := hocr.DetectWordBoundariesCustom(pagePath)
 	if err != nil {
 		return fmt.Errorf("failed to detect word boundaries: %w", err)
 	}
 
 	// Step 2: Configure provider for word transcription
 	config := providers.Config{
-		Provider:    provider,
-		Model:       model,
-		Temperature: temperature,
+		Provider:     provider,
+		Model:        model,
+		Temperature:  temperature,
+		SystemPrompt: systemPrompt,
+		MaxTokens:    maxTokens,
+		Concurrency:  concurrency,
+		MaxDimension: maxDimension,
 	}
 
 	// Validate configuration
 	if err := providerInstance.ValidateConfig(config); err != nil {
-		return fmt.Errorf("provider configuration validation failed: %w", err)
+		return fmt.Errorf("provider configuration validation failed: %w", utils.MaskSensitiveError(err))
 	}
 
 	// Step 3: Transcribe individual word images
-	hocrContent, err := hocr.TranscribeWordsIndividually(imagePath, ocrResponse, providerInstance, config)
+	if outputFormat == "text" {
+		transcribed, err := hocr.TranscribeWordsToResponse(pagePath, ocrResponse, providerInstance, config)
+		if err != nil {
+			return fmt.Errorf("failed to transcribe words: %w", utils.MaskSensitiveError(err))
+		}
+		return outputResult(hocr.ExtractPlainText(transcribed) + "\n")
+	}
+
+	hocrContent, err := hocr.TranscribeWordsIndividually(pagePath, ocrResponse, providerInstance, config)
 	if err != nil {
-		slog.Warn("Individual word transcription failed, using basic hOCR", "error", err)
+		slog.Warn("Individual word transcription failed, using basic hOCR", "error", utils.MaskSensitiveError(err))
 		basicHOCR := hocr.ConvertToBasicHOCR(ocrResponse)
 		return outputResult(basicHOCR)
 	}
@@ -110,11 +222,11 @@ func runCreate(cmd *cobra.Command, args []string) error {
 	return outputResult(finalHOCR)
 }
 
-func outputResult(hocrXML string) error {
+func outputResult(content string) error {
 	if outputPath != "" {
-		return os.WriteFile(outputPath, []byte(hocrXML), 0644)
+		return os.WriteFile(outputPath, []byte(content), 0644)
 	} else {
-		fmt.Print(hocrXML)
+		fmt.Print(content)
 		return nil
 	}
 }
@@ -146,6 +258,23 @@ func getDefaultModel(providerName string) string {
 			return model
 		}
 		return "mistral-small3.2:24b"
+	case "mistral":
+		if model := os.Getenv("MISTRAL_MODEL"); model != "" {
+			return model
+		}
+		return "pixtral-large-latest"
+	case "openai-compat":
+		// Compatible services have no common default model
+		return os.Getenv("OPENAI_COMPAT_MODEL")
+	case "azure-openai":
+		// Requests are routed to a deployment, which fixes the model
+		return os.Getenv("AZURE_OPENAI_DEPLOYMENT")
+	case "huggingface":
+		// Hosted models are fine-tuned per collection, so there is no default
+		return os.Getenv("HF_MODEL")
+	case "bedrock":
+		// Model access is granted per account and region, so there is no default
+		return os.Getenv("BEDROCK_MODEL")
 	default:
 		return ""
 	}
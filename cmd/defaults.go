@@ -0,0 +1,200 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"go.yaml.in/yaml/v3"
+)
+
+// defaultsFileName is the name of the file of per-command flag defaults.
+const defaultsFileName = "htr.yaml"
+
+// mutuallyExclusiveAnnotation is the flag annotation cobra's
+// MarkFlagsMutuallyExclusive records each group of exclusive flags under.
+const mutuallyExclusiveAnnotation = "cobra_annotation_mutually_exclusive"
+
+// defaultsAnnotation marks a flag whose value came from the defaults file.
+const defaultsAnnotation = "htr_defaults_file"
+
+// flagDefaults maps a command name to the values of its flags, keyed by flag
+// name, as read from a defaults file:
+//
+//	eval:
+//	  provider: gemini
+//	  temperature: 0
+//	  ignore: ["|", ","]
+//	cost:
+//	  price-file: prices.yaml
+type flagDefaults map[string]map[string]any
+
+// findDefaultsFile returns the path of the defaults file: $HTR_CONFIG when it
+// is set, otherwise htr.yaml in the working directory, then in
+// $XDG_CONFIG_HOME/htr (~/.config/htr when XDG_CONFIG_HOME is unset). It
+// returns "" when there is none.
+func findDefaultsFile() (string, error) {
+	if path := os.Getenv("HTR_CONFIG"); path != "" {
+		if _, err := os.Stat(path); err != nil {
+			return "", fmt.Errorf("HTR_CONFIG: %w", err)
+		}
+		return path, nil
+	}
+
+	candidates := []string{defaultsFileName}
+	configDir := os.Getenv("XDG_CONFIG_HOME")
+	if configDir == "" {
+		if home, err := os.UserHomeDir(); err == nil {
+			configDir = filepath.Join(home, ".config")
+		}
+	}
+	if configDir != "" {
+		candidates = append(candidates, filepath.Join(configDir, "htr", defaultsFileName))
+	}
+
+	for _, path := range candidates {
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return "", fmt.Errorf("failed to read %s: %w", path, err)
+		}
+	}
+	return "", nil
+}
+
+// loadDefaults reads a defaults file.
+func loadDefaults(path string) (flagDefaults, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read defaults file: %w", err)
+	}
+	var defaults flagDefaults
+	if err := yaml.Unmarshal(data, &defaults); err != nil {
+		return nil, fmt.Errorf("failed to parse defaults file %s: %w", path, err)
+	}
+	return defaults, nil
+}
+
+// applyDefaults sets the flags of cmd from its section of defaults, so a flag
+// takes its value from the command line, then the defaults file, then its
+// built-in default. A default is also skipped when a flag it is mutually
+// exclusive with was given on the command line, so --prompt-file overrides a
+// default prompt. Unknown flags are an error, which catches misspelled keys,
+// but the sections of other commands are not checked.
+//
+// Defaults do not mark flags Changed, which stays reserved for the command
+// line, so an eval --config rerun keeps its saved values over the defaults
+// file. Use flagGiven where a default should count as given.
+func applyDefaults(cmd *cobra.Command, defaults flagDefaults) error {
+	section := defaults[cmd.Name()]
+	flags := cmd.Flags()
+
+	for _, name := range slices.Sorted(maps.Keys(section)) {
+		flag := flags.Lookup(name)
+		if flag == nil {
+			return fmt.Errorf("%s.%s: %s has no --%s flag", cmd.Name(), name, cmd.Name(), name)
+		}
+		if flag.Changed || exclusiveFlagChanged(flags, flag) {
+			continue
+		}
+
+		// Slice flags take list items as they are, since --ignore-regex
+		// does not split its values on commas.
+		if items, ok := section[name].([]any); ok {
+			if slice, ok := flag.Value.(pflag.SliceValue); ok {
+				values, err := defaultItems(items)
+				if err == nil {
+					err = slice.Replace(values)
+				}
+				if err != nil {
+					return fmt.Errorf("%s.%s: %w", cmd.Name(), name, err)
+				}
+				markDefault(flag)
+				continue
+			}
+		}
+
+		value, err := defaultValue(section[name])
+		if err != nil {
+			return fmt.Errorf("%s.%s: %w", cmd.Name(), name, err)
+		}
+		if err := flag.Value.Set(value); err != nil {
+			return fmt.Errorf("%s.%s: %w", cmd.Name(), name, err)
+		}
+		markDefault(flag)
+	}
+	return nil
+}
+
+// markDefault records that flag took its value from the defaults file.
+func markDefault(flag *pflag.Flag) {
+	if flag.Annotations == nil {
+		flag.Annotations = map[string][]string{}
+	}
+	flag.Annotations[defaultsAnnotation] = []string{"true"}
+}
+
+// flagGiven reports whether the named flag was set on the command line or by
+// the defaults file.
+func flagGiven(flags *pflag.FlagSet, name string) bool {
+	flag := flags.Lookup(name)
+	return flag != nil && (flag.Changed || flag.Annotations[defaultsAnnotation] != nil)
+}
+
+// exclusiveFlagChanged reports whether a flag sharing a mutually exclusive
+// group with flag was set on the command line or by an earlier default.
+func exclusiveFlagChanged(flags *pflag.FlagSet, flag *pflag.Flag) bool {
+	for _, group := range flag.Annotations[mutuallyExclusiveAnnotation] {
+		for _, name := range strings.Fields(group) {
+			if other := flags.Lookup(name); other != nil && other != flag && flagGiven(flags, name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// defaultItems formats the items of a YAML list as flag values.
+func defaultItems(list []any) ([]string, error) {
+	items := make([]string, len(list))
+	for i, item := range list {
+		switch item.(type) {
+		case []any, map[string]any:
+			return nil, fmt.Errorf("list items must be plain values")
+		}
+		items[i] = fmt.Sprint(item)
+	}
+	return items, nil
+}
+
+// defaultValue formats a YAML value as a flag argument. Lists become the
+// quoted comma-separated form slice flags parse, so items may hold commas.
+func defaultValue(value any) (string, error) {
+	switch value := value.(type) {
+	case nil:
+		return "", nil
+	case []any:
+		items, err := defaultItems(value)
+		if err != nil {
+			return "", err
+		}
+		var b strings.Builder
+		w := csv.NewWriter(&b)
+		if err := w.Write(items); err != nil {
+			return "", err
+		}
+		w.Flush()
+		return strings.TrimSuffix(b.String(), "\n"), w.Error()
+	case map[string]any:
+		return "", fmt.Errorf("expected a value or list, not a map")
+	default:
+		return fmt.Sprint(value), nil
+	}
+}
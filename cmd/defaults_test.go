@@ -0,0 +1,223 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// newDefaultsTestCommand returns an eval-like command with prompt and
+// prompt-file mutually exclusive.
+func newDefaultsTestCommand() *cobra.Command {
+	cmd := &cobra.Command{Use: "eval"}
+	cmd.Flags().String("provider", "openai", "")
+	cmd.Flags().String("model", "", "")
+	cmd.Flags().Float64("temperature", 0.3, "")
+	cmd.Flags().Duration("timeout", 5*time.Minute, "")
+	cmd.Flags().StringSlice("ignore", []string{}, "")
+	cmd.Flags().StringArray("ignore-regex", []string{}, "")
+	cmd.Flags().String("prompt", "", "")
+	cmd.Flags().String("prompt-file", "", "")
+	cmd.MarkFlagsMutuallyExclusive("prompt", "prompt-file")
+	return cmd
+}
+
+func TestApplyDefaultsPrecedence(t *testing.T) {
+	defaults := flagDefaults{
+		"eval": {
+			"provider":     "gemini",
+			"temperature":  0,
+			"timeout":      "2m",
+			"ignore":       []any{"|", ","},
+			"ignore-regex": []any{`\d{1,3}`, `\[.*?\]`},
+			"prompt":       "Transcribe the page",
+		},
+		"cost": {"price-file": "prices.yaml"},
+	}
+
+	tests := []struct {
+		name            string
+		args            []string
+		wantProvider    string
+		wantModel       string
+		wantTemperature float64
+		wantTimeout     time.Duration
+		wantIgnore      []string
+		wantIgnoreRegex []string
+		wantPrompt      string
+	}{
+		{
+			name:            "defaults file over built-in defaults",
+			wantProvider:    "gemini",
+			wantTemperature: 0,
+			wantTimeout:     2 * time.Minute,
+			wantIgnore:      []string{"|", ","},
+			wantIgnoreRegex: []string{`\d{1,3}`, `\[.*?\]`},
+			wantPrompt:      "Transcribe the page",
+		},
+		{
+			name:            "command line over defaults file",
+			args:            []string{"--provider", "claude", "--temperature", "0.7", "--ignore", "#", "--model", "opus"},
+			wantProvider:    "claude",
+			wantModel:       "opus",
+			wantTemperature: 0.7,
+			wantTimeout:     2 * time.Minute,
+			wantIgnore:      []string{"#"},
+			wantIgnoreRegex: []string{`\d{1,3}`, `\[.*?\]`},
+			wantPrompt:      "Transcribe the page",
+		},
+		{
+			name:            "exclusive flag on command line skips default",
+			args:            []string{"--prompt-file", "prompt.tmpl"},
+			wantProvider:    "gemini",
+			wantTemperature: 0,
+			wantTimeout:     2 * time.Minute,
+			wantIgnore:      []string{"|", ","},
+			wantIgnoreRegex: []string{`\d{1,3}`, `\[.*?\]`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := newDefaultsTestCommand()
+			if err := cmd.ParseFlags(tt.args); err != nil {
+				t.Fatal(err)
+			}
+			if err := applyDefaults(cmd, defaults); err != nil {
+				t.Fatalf("applyDefaults() error = %v", err)
+			}
+			if err := cmd.ValidateFlagGroups(); err != nil {
+				t.Errorf("ValidateFlagGroups() error = %v", err)
+			}
+
+			flags := cmd.Flags()
+			if got, _ := flags.GetString("provider"); got != tt.wantProvider {
+				t.Errorf("provider = %q, want %q", got, tt.wantProvider)
+			}
+			if got, _ := flags.GetString("model"); got != tt.wantModel {
+				t.Errorf("model = %q, want %q", got, tt.wantModel)
+			}
+			if got, _ := flags.GetFloat64("temperature"); got != tt.wantTemperature {
+				t.Errorf("temperature = %v, want %v", got, tt.wantTemperature)
+			}
+			if got, _ := flags.GetDuration("timeout"); got != tt.wantTimeout {
+				t.Errorf("timeout = %v, want %v", got, tt.wantTimeout)
+			}
+			if got, _ := flags.GetStringSlice("ignore"); !slices.Equal(got, tt.wantIgnore) {
+				t.Errorf("ignore = %q, want %q", got, tt.wantIgnore)
+			}
+			if got, _ := flags.GetStringArray("ignore-regex"); !slices.Equal(got, tt.wantIgnoreRegex) {
+				t.Errorf("ignore-regex = %q, want %q", got, tt.wantIgnoreRegex)
+			}
+			if got, _ := flags.GetString("prompt"); got != tt.wantPrompt {
+				t.Errorf("prompt = %q, want %q", got, tt.wantPrompt)
+			}
+		})
+	}
+}
+
+// Defaults must not mark flags Changed, or an eval --config rerun would let
+// them replace the saved limit, sample, and seed.
+func TestApplyDefaultsLeavesFlagsUnchanged(t *testing.T) {
+	cmd := newDefaultsTestCommand()
+	if err := cmd.ParseFlags([]string{"--model", "opus"}); err != nil {
+		t.Fatal(err)
+	}
+	defaults := flagDefaults{"eval": {"provider": "gemini", "ignore": []any{"|"}}}
+	if err := applyDefaults(cmd, defaults); err != nil {
+		t.Fatalf("applyDefaults() error = %v", err)
+	}
+
+	flags := cmd.Flags()
+	for _, name := range []string{"provider", "ignore"} {
+		if flags.Changed(name) || !flagGiven(flags, name) {
+			t.Errorf("%s: Changed = %v, flagGiven = %v; want false, true", name, flags.Changed(name), flagGiven(flags, name))
+		}
+	}
+	if !flags.Changed("model") || !flagGiven(flags, "model") {
+		t.Errorf("model from the command line is not Changed")
+	}
+	if flagGiven(flags, "temperature") {
+		t.Errorf("temperature was never set, but flagGiven reports it")
+	}
+}
+
+func TestApplyDefaultsErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		defaults flagDefaults
+		wantErr  string
+	}{
+		{name: "unknown flag", defaults: flagDefaults{"eval": {"provder": "gemini"}}, wantErr: "eval.provder: eval has no --provder flag"},
+		{name: "invalid value", defaults: flagDefaults{"eval": {"timeout": 120}}, wantErr: "eval.timeout:"},
+		{name: "map value", defaults: flagDefaults{"eval": {"model": map[string]any{"name": "x"}}}, wantErr: "not a map"},
+		{name: "nested list", defaults: flagDefaults{"eval": {"ignore": []any{[]any{"|"}}}}, wantErr: "list items must be plain values"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := newDefaultsTestCommand()
+			if err := cmd.ParseFlags(nil); err != nil {
+				t.Fatal(err)
+			}
+			if err := applyDefaults(cmd, tt.defaults); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("applyDefaults() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFindDefaultsFile(t *testing.T) {
+	workDir := t.TempDir()
+	configHome := t.TempDir()
+	t.Chdir(workDir)
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	t.Setenv("HTR_CONFIG", "")
+
+	if path, err := findDefaultsFile(); err != nil || path != "" {
+		t.Errorf("findDefaultsFile() with no file = %q, %v; want none", path, err)
+	}
+
+	userPath := filepath.Join(configHome, "htr", defaultsFileName)
+	if err := os.MkdirAll(filepath.Dir(userPath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(userPath, []byte("eval:\n  provider: gemini\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if path, err := findDefaultsFile(); err != nil || path != userPath {
+		t.Errorf("findDefaultsFile() = %q, %v; want %q", path, err, userPath)
+	}
+
+	if err := os.WriteFile(defaultsFileName, []byte("eval:\n  provider: claude\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if path, err := findDefaultsFile(); err != nil || path != defaultsFileName {
+		t.Errorf("findDefaultsFile() = %q, %v; want the working directory's %s", path, err, defaultsFileName)
+	}
+
+	explicitPath := filepath.Join(t.TempDir(), "team.yaml")
+	t.Setenv("HTR_CONFIG", explicitPath)
+	if _, err := findDefaultsFile(); err == nil {
+		t.Error("findDefaultsFile() with a missing HTR_CONFIG error = nil, want error")
+	}
+	if err := os.WriteFile(explicitPath, []byte("eval:\n  provider: mistral\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if path, err := findDefaultsFile(); err != nil || path != explicitPath {
+		t.Errorf("findDefaultsFile() = %q, %v; want HTR_CONFIG %q", path, err, explicitPath)
+	}
+
+	defaults, err := loadDefaults(explicitPath)
+	if err != nil {
+		t.Fatalf("loadDefaults() error = %v", err)
+	}
+	if got := defaults["eval"]["provider"]; got != "mistral" {
+		t.Errorf("loadDefaults() eval.provider = %v, want mistral", got)
+	}
+}
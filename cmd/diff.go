@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"slices"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+var diffCmd = &cobra.Command{
+	Use:   "diff [before] [after]",
+	Short: "Compare the per-document word accuracy of two evaluation files",
+	Long: `Compare two evaluation files row by row to see which documents a change,
+such as a new prompt or model, improved or regressed.
+
+Rows are matched by identifier. Each matched document is printed with its word
+accuracy before and after and the difference, largest regression first.
+Documents present in only one of the files are listed after the table.
+
+Examples:
+  htr diff gpt-4o-old-prompt gpt-4o-new-prompt
+  htr diff evals/openai_gpt-4o.yaml evals/claude_claude-sonnet-4-5.yaml --threshold 0.05`,
+	RunE: runDiff,
+	Args: cobra.ExactArgs(2),
+}
+
+var diffThreshold float64
+
+// evalRowDiff is the word accuracy of one document in two evaluation files.
+type evalRowDiff struct {
+	Identifier string
+	Before     float64
+	After      float64
+}
+
+// Delta is the change in word accuracy; negative values are regressions.
+func (d evalRowDiff) Delta() float64 {
+	return d.After - d.Before
+}
+
+// evalDiff is the row-by-row comparison of two evaluation files.
+type evalDiff struct {
+	Rows       []evalRowDiff
+	OnlyBefore []string
+	OnlyAfter  []string
+}
+
+func init() {
+	RootCmd.AddCommand(diffCmd)
+
+	diffCmd.Flags().Float64Var(&diffThreshold, "threshold", 0, "Only show documents whose word accuracy changed by more than this (e.g. 0.05)")
+}
+
+func runDiff(cmd *cobra.Command, args []string) error {
+	if diffThreshold < 0 {
+		return fmt.Errorf("--threshold cannot be negative")
+	}
+	beforeFile, afterFile := resolveEvalFile(evalsDir, args[0]), resolveEvalFile(evalsDir, args[1])
+	before, err := loadEvalSummary(beforeFile)
+	if err != nil {
+		return err
+	}
+	after, err := loadEvalSummary(afterFile)
+	if err != nil {
+		return err
+	}
+
+	printEvalDiff(os.Stdout, diffEvalResults(before.Results, after.Results), beforeFile, afterFile, diffThreshold)
+	return nil
+}
+
+// diffEvalResults matches the rows of two evaluation files by identifier.
+// Rows are sorted by delta, largest regression first, then by identifier. A
+// repeated identifier keeps its last row.
+func diffEvalResults(before, after []EvalResult) evalDiff {
+	afterByID := make(map[string]EvalResult, len(after))
+	for _, result := range after {
+		afterByID[result.Identifier] = result
+	}
+	beforeByID := make(map[string]EvalResult, len(before))
+	for _, result := range before {
+		beforeByID[result.Identifier] = result
+	}
+
+	var diff evalDiff
+	for id, result := range beforeByID {
+		match, ok := afterByID[id]
+		if !ok {
+			diff.OnlyBefore = append(diff.OnlyBefore, id)
+			continue
+		}
+		diff.Rows = append(diff.Rows, evalRowDiff{Identifier: id, Before: result.WordAccuracy, After: match.WordAccuracy})
+	}
+	for id := range afterByID {
+		if _, ok := beforeByID[id]; !ok {
+			diff.OnlyAfter = append(diff.OnlyAfter, id)
+		}
+	}
+
+	slices.SortFunc(diff.Rows, func(a, b evalRowDiff) int {
+		return cmp.Or(cmp.Compare(a.Delta(), b.Delta()), cmp.Compare(a.Identifier, b.Identifier))
+	})
+	slices.Sort(diff.OnlyBefore)
+	slices.Sort(diff.OnlyAfter)
+	return diff
+}
+
+// printEvalDiff writes the rows whose word accuracy changed by more than
+// threshold as an aligned table, followed by counts of improved, regressed,
+// and unchanged documents and the documents found in only one file.
+func printEvalDiff(w io.Writer, diff evalDiff, beforeFile, afterFile string, threshold float64) {
+	var improved, regressed, unchanged int
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "IDENTIFIER\tBEFORE\tAFTER\tDELTA")
+	for _, row := range diff.Rows {
+		delta := row.Delta()
+		switch {
+		case delta > 0:
+			improved++
+		case delta < 0:
+			regressed++
+		default:
+			unchanged++
+		}
+		if math.Abs(delta) <= threshold {
+			continue
+		}
+		fmt.Fprintf(tw, "%s\t%.3f\t%.3f\t%+.3f\n", row.Identifier, row.Before, row.After, delta)
+	}
+	tw.Flush()
+
+	fmt.Fprintf(w, "\n%d documents matched: %d improved, %d regressed, %d unchanged\n", len(diff.Rows), improved, regressed, unchanged)
+	for _, only := range []struct {
+		file string
+		ids  []string
+	}{{beforeFile, diff.OnlyBefore}, {afterFile, diff.OnlyAfter}} {
+		if len(only.ids) == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "\nOnly in %s (%d):\n", only.file, len(only.ids))
+		for _, id := range only.ids {
+			fmt.Fprintf(w, "  %s\n", id)
+		}
+	}
+}
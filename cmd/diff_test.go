@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"bytes"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDiffEvalResults(t *testing.T) {
+	before := []EvalResult{
+		{Identifier: "letter1.jpg", WordAccuracy: 0.90},
+		{Identifier: "letter2.jpg", WordAccuracy: 0.50},
+		{Identifier: "letter3.jpg", WordAccuracy: 0.80},
+		{Identifier: "letter4.jpg", WordAccuracy: 0.70},
+		{Identifier: "dropped.jpg", WordAccuracy: 0.60},
+	}
+	after := []EvalResult{
+		{Identifier: "letter4.jpg", WordAccuracy: 0.70},
+		{Identifier: "letter3.jpg", WordAccuracy: 0.60},
+		{Identifier: "letter2.jpg", WordAccuracy: 0.75},
+		{Identifier: "letter1.jpg", WordAccuracy: 0.88},
+		{Identifier: "added.jpg", WordAccuracy: 1},
+	}
+
+	diff := diffEvalResults(before, after)
+
+	var order []string
+	for _, row := range diff.Rows {
+		order = append(order, row.Identifier)
+	}
+	if want := []string{"letter3.jpg", "letter1.jpg", "letter4.jpg", "letter2.jpg"}; !reflect.DeepEqual(order, want) {
+		t.Errorf("row order = %v, want largest regression first %v", order, want)
+	}
+	if !reflect.DeepEqual(diff.OnlyBefore, []string{"dropped.jpg"}) || !reflect.DeepEqual(diff.OnlyAfter, []string{"added.jpg"}) {
+		t.Errorf("OnlyBefore, OnlyAfter = %v, %v", diff.OnlyBefore, diff.OnlyAfter)
+	}
+}
+
+func TestPrintEvalDiffThreshold(t *testing.T) {
+	diff := diffEvalResults(
+		[]EvalResult{{Identifier: "big.jpg", WordAccuracy: 0.5}, {Identifier: "small.jpg", WordAccuracy: 0.9}, {Identifier: "same.jpg", WordAccuracy: 0.7}},
+		[]EvalResult{{Identifier: "big.jpg", WordAccuracy: 0.8}, {Identifier: "small.jpg", WordAccuracy: 0.88}, {Identifier: "same.jpg", WordAccuracy: 0.7}},
+	)
+
+	tests := []struct {
+		threshold float64
+		shown     []string
+		hidden    []string
+	}{
+		{threshold: 0, shown: []string{"big.jpg", "small.jpg"}, hidden: []string{"same.jpg"}},
+		{threshold: 0.05, shown: []string{"big.jpg"}, hidden: []string{"small.jpg", "same.jpg"}},
+	}
+	for _, tt := range tests {
+		var out bytes.Buffer
+		printEvalDiff(&out, diff, "before.yaml", "after.yaml", tt.threshold)
+		for _, id := range tt.shown {
+			if !strings.Contains(out.String(), id) {
+				t.Errorf("threshold %v: output is missing %s:\n%s", tt.threshold, id, out.String())
+			}
+		}
+		for _, id := range tt.hidden {
+			if strings.Contains(out.String(), id) {
+				t.Errorf("threshold %v: output shows %s:\n%s", tt.threshold, id, out.String())
+			}
+		}
+		if !strings.Contains(out.String(), "3 documents matched: 1 improved, 1 regressed, 1 unchanged") {
+			t.Errorf("threshold %v: output is missing the counts:\n%s", tt.threshold, out.String())
+		}
+	}
+
+	var out bytes.Buffer
+	printEvalDiff(&out, diff, "before.yaml", "after.yaml", 0)
+	for _, want := range [][]string{{"small.jpg", "0.900", "0.880", "-0.020"}, {"big.jpg", "0.500", "0.800", "+0.300"}} {
+		if !containsFields(out.String(), want) {
+			t.Errorf("output is missing the row %v:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestDiffMatchedEvalFiles(t *testing.T) {
+	evalDir := t.TempDir()
+	beforePath := filepath.Join(evalDir, "before.yaml")
+	afterPath := filepath.Join(evalDir, "after.yaml")
+	if err := saveEvalResults(EvalSummary{Results: []EvalResult{{Identifier: "page1.jpg", WordAccuracy: 0.9}, {Identifier: "page2.jpg", WordAccuracy: 0.4}}}, beforePath); err != nil {
+		t.Fatal(err)
+	}
+	if err := saveEvalResults(EvalSummary{Results: []EvalResult{{Identifier: "page1.jpg", WordAccuracy: 0.6}, {Identifier: "page3.jpg", WordAccuracy: 1}}}, afterPath); err != nil {
+		t.Fatal(err)
+	}
+	before, err := loadEvalSummary(beforePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	after, err := loadEvalSummary(afterPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	printEvalDiff(&out, diffEvalResults(before.Results, after.Results), beforePath, afterPath, 0)
+
+	if !containsFields(out.String(), []string{"page1.jpg", "0.900", "0.600", "-0.300"}) {
+		t.Errorf("output is missing the page1.jpg regression:\n%s", out.String())
+	}
+	for _, want := range []string{"Only in " + beforePath + " (1):\n  page2.jpg", "Only in " + afterPath + " (1):\n  page3.jpg"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output is missing %q:\n%s", want, out.String())
+		}
+	}
+}
+
+// containsFields reports whether any line of output consists of fields.
+func containsFields(output string, fields []string) bool {
+	for _, line := range strings.Split(output, "\n") {
+		if reflect.DeepEqual(strings.Fields(line), fields) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/lehigh-university-libraries/htr/internal/utils"
+	"github.com/lehigh-university-libraries/htr/pkg/providers"
+	"github.com/spf13/cobra"
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check that each provider's credentials and configuration work",
+	Long: `Check every registered provider before a large batch, reporting OK or FAIL
+for each one. A provider passes when its configuration validates: its
+credentials and endpoint are set and, for Ollama, the server is reachable.
+
+With --live, each provider that validates is also sent a tiny real request
+with a bundled 1x1 image, which confirms the credentials are accepted. Live
+checks may be billed by the provider.
+
+Error details are masked so the output is safe to share. The command exits
+with an error when any provider fails.
+
+Examples:
+  htr doctor
+  htr doctor --live
+  htr doctor --provider gemini --live`,
+	RunE: runDoctor,
+	Args: cobra.NoArgs,
+}
+
+var (
+	doctorProvider string
+	doctorLive     bool
+	doctorTimeout  time.Duration
+)
+
+// doctorImage is a base64 encoded 1x1 white PNG sent by live checks.
+const doctorImage = "iVBORw0KGgoAAAANSUhEUgAAAAEAAAABCAAAAAA6fptVAAAAD0lEQVR4nAACAP3/Av8DAAEFAQJnLD7dAAAAAElFTkSuQmCC"
+
+// doctorPrompt is the prompt sent with doctorImage.
+const doctorPrompt = "Transcribe any text in this image."
+
+// doctorCheck is the outcome of checking one provider. Stage names the step
+// that failed, "configuration" or "test request", and is empty with Err when
+// the provider passed.
+type doctorCheck struct {
+	Provider string
+	Live     bool
+	Stage    string
+	Err      error
+}
+
+func init() {
+	RootCmd.AddCommand(doctorCmd)
+
+	doctorCmd.Flags().StringVar(&doctorProvider, "provider", "", "Check only this provider (default checks all)")
+	doctorCmd.Flags().BoolVar(&doctorLive, "live", false, "Also send each provider a tiny request with a bundled 1x1 image")
+	doctorCmd.Flags().DurationVar(&doctorTimeout, "timeout", 30*time.Second, "Timeout for each live request")
+}
+
+func runDoctor(cmd *cobra.Command, args []string) error {
+	names := providerRegistry.List()
+	if doctorProvider != "" {
+		if err := validateProvider(providerRegistry, doctorProvider); err != nil {
+			return err
+		}
+		names = []string{strings.ToLower(doctorProvider)}
+	}
+	if doctorTimeout <= 0 {
+		return fmt.Errorf("--timeout must be positive")
+	}
+
+	checks := make([]doctorCheck, 0, len(names))
+	for _, name := range names {
+		provider, err := providerRegistry.Get(name)
+		if err != nil {
+			return err
+		}
+		checks = append(checks, checkProvider(context.Background(), provider, doctorLive, doctorTimeout))
+	}
+
+	if failed := printDoctorChecks(os.Stdout, checks); failed > 0 {
+		return fmt.Errorf("%d of %d providers failed", failed, len(checks))
+	}
+	return nil
+}
+
+// checkProvider validates the configuration of provider with its default
+// model and, when live is set, sends doctorImage with doctorPrompt.
+func checkProvider(ctx context.Context, provider providers.Provider, live bool, timeout time.Duration) doctorCheck {
+	check := doctorCheck{Provider: provider.Name()}
+	config := providers.Config{
+		Provider: provider.Name(),
+		Model:    getDefaultModel(provider.Name()),
+		Prompt:   doctorPrompt,
+		Timeout:  timeout,
+	}
+	if err := provider.ValidateConfig(config); err != nil {
+		check.Stage, check.Err = "configuration", err
+		return check
+	}
+	if !live {
+		return check
+	}
+
+	check.Live = true
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	if _, _, err := provider.ExtractText(ctx, config, "doctor.png", doctorImage); err != nil {
+		check.Stage, check.Err = "test request", err
+	}
+	return check
+}
+
+// printDoctorChecks writes one row per check with masked error details and
+// returns the number of failed checks.
+func printDoctorChecks(w io.Writer, checks []doctorCheck) int {
+	failed := 0
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "PROVIDER\tSTATUS\tDETAIL")
+	for _, check := range checks {
+		if check.Err != nil {
+			failed++
+			fmt.Fprintf(tw, "%s\tFAIL\t%s: %s\n", check.Provider, check.Stage, maskedDetail(check.Err))
+			continue
+		}
+		detail := "configuration valid"
+		if check.Live {
+			detail = "configuration valid, test request succeeded"
+		}
+		fmt.Fprintf(tw, "%s\tOK\t%s\n", check.Provider, detail)
+	}
+	tw.Flush()
+	return failed
+}
+
+// maskedDetail returns err masked of sensitive data on a single line, so a
+// multi-line provider error cannot break the table.
+func maskedDetail(err error) string {
+	return strings.Join(strings.Fields(utils.MaskSensitiveError(err).Error()), " ")
+}
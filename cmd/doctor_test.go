@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"image/png"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lehigh-university-libraries/htr/pkg/providers"
+)
+
+// doctorStubProvider fails validation with validateErr and requests with
+// extractErr, recording the requests it receives.
+type doctorStubProvider struct {
+	providers.BaseProvider
+
+	name        string
+	validateErr error
+	extractErr  error
+	images      []string
+}
+
+func (p *doctorStubProvider) Name() string { return p.name }
+
+func (p *doctorStubProvider) ValidateConfig(config providers.Config) error {
+	return p.validateErr
+}
+
+func (p *doctorStubProvider) ExtractText(ctx context.Context, config providers.Config, imagePath, imageBase64 string) (string, providers.UsageInfo, error) {
+	p.images = append(p.images, imageBase64)
+	if p.extractErr != nil {
+		return "", providers.UsageInfo{}, p.extractErr
+	}
+	return "", providers.UsageInfo{}, nil
+}
+
+func TestCheckProvider(t *testing.T) {
+	tests := []struct {
+		name      string
+		provider  *doctorStubProvider
+		live      bool
+		wantStage string
+		wantCalls int
+	}{
+		{name: "valid", provider: &doctorStubProvider{name: "valid"}},
+		{name: "valid live", provider: &doctorStubProvider{name: "valid"}, live: true, wantCalls: 1},
+		{name: "missing key", provider: &doctorStubProvider{name: "unset", validateErr: errors.New("API key not set")}, live: true, wantStage: "configuration"},
+		{name: "rejected key", provider: &doctorStubProvider{name: "rejected", extractErr: errors.New("401 unauthorized")}, live: true, wantStage: "test request", wantCalls: 1},
+		{name: "rejected key without live", provider: &doctorStubProvider{name: "rejected", extractErr: errors.New("401 unauthorized")}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			check := checkProvider(context.Background(), tt.provider, tt.live, time.Second)
+			if check.Provider != tt.provider.name || check.Stage != tt.wantStage || (check.Err != nil) != (tt.wantStage != "") {
+				t.Errorf("checkProvider() = %+v, want stage %q", check, tt.wantStage)
+			}
+			if len(tt.provider.images) != tt.wantCalls {
+				t.Errorf("provider received %d requests, want %d", len(tt.provider.images), tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestDoctorImageIsOnePixelPNG(t *testing.T) {
+	data, err := base64.StdEncoding.DecodeString(doctorImage)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config, err := png.DecodeConfig(bytes.NewReader(data))
+	if err != nil || config.Width != 1 || config.Height != 1 {
+		t.Fatalf("doctorImage is %dx%d, %v; want a 1x1 PNG", config.Width, config.Height, err)
+	}
+}
+
+func TestPrintDoctorChecksMasksErrors(t *testing.T) {
+	checks := []doctorCheck{
+		{Provider: "claude", Live: true},
+		{Provider: "gemini"},
+		{Provider: "openai", Live: true, Stage: "test request", Err: errors.New("401: Incorrect API key provided: sk-proj-abcdefghijklmnopqrstuvwxyz\nsee docs")},
+		{Provider: "mistral", Stage: "configuration", Err: errors.New("MISTRAL_API_KEY environment variable not set")},
+	}
+
+	var out bytes.Buffer
+	if failed := printDoctorChecks(&out, checks); failed != 2 {
+		t.Errorf("printDoctorChecks() = %d failed, want 2", failed)
+	}
+
+	if strings.Contains(out.String(), "abcdefghijklmnop") {
+		t.Errorf("output leaked the API key:\n%s", out.String())
+	}
+	rows := make(map[string][]string)
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n")[1:] {
+		fields := strings.Fields(line)
+		rows[fields[0]] = fields[1:]
+	}
+	if len(rows) != len(checks) {
+		t.Fatalf("output has %d rows, want one per provider:\n%s", len(rows), out.String())
+	}
+	for provider, want := range map[string]string{
+		"claude":  "OK configuration valid, test request succeeded",
+		"gemini":  "OK configuration valid",
+		"openai":  "FAIL test request: 401: Incorrect API key provided: sk-proj-***MASKED*** see docs",
+		"mistral": "FAIL configuration: MISTRAL_API_KEY environment variable not set",
+	} {
+		if got := strings.Join(rows[provider], " "); got != want {
+			t.Errorf("%s row = %q, want %q", provider, got, want)
+		}
+	}
+}
+
+func TestRunDoctorChecksOneProvider(t *testing.T) {
+	failing := &doctorStubProvider{name: "failing", validateErr: errors.New("not configured")}
+	passing := &doctorStubProvider{name: "passing"}
+	originalRegistry := providerRegistry
+	t.Cleanup(func() { providerRegistry = originalRegistry })
+	providerRegistry = providers.NewRegistry()
+	providerRegistry.Register(failing)
+	providerRegistry.Register(passing)
+
+	originalProvider, originalLive, originalTimeout := doctorProvider, doctorLive, doctorTimeout
+	t.Cleanup(func() { doctorProvider, doctorLive, doctorTimeout = originalProvider, originalLive, originalTimeout })
+	doctorLive, doctorTimeout = true, time.Second
+
+	doctorProvider = "Passing"
+	if err := runDoctor(doctorCmd, nil); err != nil {
+		t.Fatalf("runDoctor() with --provider passing error = %v", err)
+	}
+	if len(passing.images) != 1 || passing.images[0] != doctorImage {
+		t.Fatalf("passing provider received %d requests, want the doctor image once", len(passing.images))
+	}
+
+	doctorProvider = ""
+	if err := runDoctor(doctorCmd, nil); err == nil || !strings.Contains(err.Error(), "1 of 2 providers failed") {
+		t.Fatalf("runDoctor() for every provider error = %v, want 1 of 2 failed", err)
+	}
+
+	doctorProvider = "pasing"
+	if err := runDoctor(doctorCmd, nil); err == nil || !strings.Contains(err.Error(), `did you mean "passing"`) {
+		t.Fatalf("runDoctor() with an unknown provider error = %v", err)
+	}
+}
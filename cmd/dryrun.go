@@ -0,0 +1,223 @@
+package cmd
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"strings"
+	"text/template"
+	"unicode/utf8"
+
+	"github.com/lehigh-university-libraries/htr/pkg/imaging"
+	"github.com/lehigh-university-libraries/htr/pkg/pricing"
+)
+
+// charsPerToken is the rough number of characters in one text token for
+// English prose across the supported model families.
+const charsPerToken = 4
+
+// dryRunEstimate is the projected usage of an eval run, built from the input
+// rows without calling the provider.
+type dryRunEstimate struct {
+	Images       int
+	Unreadable   int
+	EncodedBytes int
+	InputTokens  int
+	OutputTokens int
+	// GroundTruth is true when output tokens were estimated from transcripts.
+	GroundTruth bool
+}
+
+// estimateImageTokens approximates the input tokens an image of size costs
+// with provider at OpenAI image detail, following each vendor's published
+// image sizing rules. Providers without a rule of their own, such as Ollama
+// and OpenAI-compatible servers, get the OpenAI estimate.
+func estimateImageTokens(provider, detail string, size imaging.Dimensions) int {
+	if size.Width <= 0 || size.Height <= 0 {
+		return 0
+	}
+
+	switch provider {
+	case "claude":
+		// Scaled to at most 1568px on the long edge, then about one token
+		// per 750 pixels
+		fitted := size.Fit(1568)
+		return ceilDiv(fitted.Width*fitted.Height, 750)
+	case "gemini":
+		// 258 tokens for images up to 384px on both sides; larger images are
+		// cut into 768px tiles of 258 tokens each
+		if size.Width <= 384 && size.Height <= 384 {
+			return 258
+		}
+		return ceilDiv(size.Width, 768) * ceilDiv(size.Height, 768) * 258
+	case "mistral":
+		// Pixtral scales to at most 1024px and spends one token per 16px
+		// patch plus a line-break token per row of patches
+		fitted := size.Fit(1024)
+		rows := ceilDiv(fitted.Height, 16)
+		return ceilDiv(fitted.Width, 16)*rows + rows
+	default:
+		// Low detail sends one 512px view at a flat cost
+		if detail == "low" {
+			return 85
+		}
+		// High detail: fit within 2048px, scale the short side down to
+		// 768px, then 170 tokens per 512px tile plus 85 base tokens
+		fitted := size.Fit(2048)
+		if short := min(fitted.Width, fitted.Height); short > 768 {
+			fitted.Width = fitted.Width * 768 / short
+			fitted.Height = fitted.Height * 768 / short
+		}
+		return 85 + 170*ceilDiv(fitted.Width, 512)*ceilDiv(fitted.Height, 512)
+	}
+}
+
+// estimateTextTokens approximates the tokens in text at charsPerToken
+// characters per token.
+func estimateTextTokens(text string) int {
+	return ceilDiv(utf8.RuneCountInString(text), charsPerToken)
+}
+
+func ceilDiv(a, b int) int {
+	return (a + b - 1) / b
+}
+
+// estimateEvaluation reads every selected row's image, and transcript when
+// there is one, and projects the tokens the run would use. Rows whose image
+// cannot be read are counted and skipped.
+func estimateEvaluation(config EvalConfig) (dryRunEstimate, error) {
+	var prompt *template.Template
+	if config.PromptTemplate != "" {
+		var err error
+		if prompt, err = parsePromptTemplate(config.PromptTemplate); err != nil {
+			return dryRunEstimate{}, err
+		}
+	}
+
+	var dataRows [][]string
+	var columns []string
+	var err error
+	if config.Images != "" {
+		dataRows, err = readImageRows(config.Images)
+	} else {
+		dataRows, columns, err = readEvalRows(config.CSVPath)
+	}
+	if err != nil {
+		return dryRunEstimate{}, err
+	}
+
+	if config.TestRows, err = selectRows(config, dataRows); err != nil {
+		return dryRunEstimate{}, err
+	}
+	var duplicates map[int]int
+	if config.Dedupe {
+		duplicates = duplicateRows(dataRows, config.TestRows)
+	}
+
+	estimate := dryRunEstimate{GroundTruth: config.Images == ""}
+	for i, row := range dataRows {
+		if _, duplicate := duplicates[i]; duplicate || !slices.Contains(config.TestRows, i) || len(row) < 3 {
+			continue
+		}
+
+		imagePaths := []string{strings.TrimSpace(row[0])}
+		if config.Images == "" {
+			imagePaths = splitImagePaths(dir, row[0])
+		}
+
+		rowPrompt := config.Prompt
+		if prompt != nil {
+			var err error
+			if rowPrompt, err = renderPrompt(prompt, promptVariables(row, columns)); err != nil {
+				return dryRunEstimate{}, fmt.Errorf("row %d: %w", i+1, err)
+			}
+		}
+
+		// Each page of a multi-page row is a separate request
+		readable := 0
+		for _, imagePath := range imagePaths {
+			imageBase64, image, err := getImageAsBase64(context.Background(), config, imagePath)
+			if err == nil && image.Sent == (imaging.Dimensions{}) {
+				var data []byte
+				if data, err = base64.StdEncoding.DecodeString(imageBase64); err == nil {
+					image.Sent, err = imaging.Size(data)
+				}
+			}
+			if err != nil {
+				slog.Warn("Could not read image for estimate", "row", i+1, "image", imagePath, "err", err)
+				estimate.Unreadable++
+				continue
+			}
+
+			readable++
+			estimate.Images++
+			estimate.EncodedBytes += len(imageBase64)
+			estimate.InputTokens += estimateImageTokens(config.Provider, config.OpenAIDetail, image.Sent) + estimateTextTokens(config.SystemPrompt+appendPromptSuffix(rowPrompt, config.PromptSuffix))
+		}
+		if readable == 0 {
+			continue
+		}
+
+		if estimate.GroundTruth {
+			groundTruth, err := readTextFile(context.Background(), splitTranscriptPaths(dir, row[1])[0])
+			if err != nil {
+				slog.Warn("Could not read transcript for estimate", "row", i+1, "err", err)
+				continue
+			}
+			estimate.OutputTokens += estimateTextTokens(groundTruth)
+		}
+	}
+
+	return estimate, nil
+}
+
+// printDryRun writes the projected usage and, for token-billed providers with
+// a known price, the projected cost of the run.
+func printDryRun(w io.Writer, config EvalConfig, estimate dryRunEstimate) {
+	fmt.Fprintf(w, "=== DRY RUN: APPROXIMATE ESTIMATE, NO API CALLS MADE ===\n")
+	fmt.Fprintf(w, "Provider: %s\n", config.Provider)
+	fmt.Fprintf(w, "Model: %s\n", config.Model)
+	fmt.Fprintf(w, "Images: %d\n", estimate.Images)
+	if estimate.Unreadable > 0 {
+		fmt.Fprintf(w, "Unreadable images (not counted): %d\n", estimate.Unreadable)
+	}
+	if estimate.Images == 0 {
+		return
+	}
+	fmt.Fprintf(w, "Average encoded image size: %.1f KB\n", float64(estimate.EncodedBytes)/float64(estimate.Images)/1024)
+	fmt.Fprintf(w, "\n")
+
+	if !providerCapabilities(config.Provider).ReportsTokenUsage {
+		fmt.Fprintf(w, "Provider %q bills per page, so this run would be billed for about %d pages.\n", config.Provider, estimate.Images)
+		return
+	}
+
+	fmt.Fprintf(w, "Image tokens follow the provider's published sizing rules and text is counted at about %d characters per token.\n", charsPerToken)
+	if estimate.GroundTruth {
+		fmt.Fprintf(w, "Output tokens assume each response is as long as its ground truth transcript.\n")
+	} else {
+		fmt.Fprintf(w, "Output tokens are not estimated without ground truth transcripts.\n")
+	}
+	fmt.Fprintf(w, "\n")
+
+	cost := costEstimate{
+		PriceSource:     "pricing table",
+		Documents:       estimate.Images,
+		AvgInputTokens:  float64(estimate.InputTokens) / float64(estimate.Images),
+		AvgOutputTokens: float64(estimate.OutputTokens) / float64(estimate.Images),
+	}
+	price, ok := pricing.Default().Lookup(config.Model)
+	if !ok {
+		fmt.Fprintf(w, "Estimated input tokens: %d\n", estimate.InputTokens)
+		fmt.Fprintf(w, "Estimated output tokens: %d\n", estimate.OutputTokens)
+		fmt.Fprintf(w, "\nNo known price for model %q; run `htr cost` on the eval file with --input-price and --output-price after a run.\n", config.Model)
+		return
+	}
+	cost.Price = price
+	cost.InputCostPerDoc = (cost.AvgInputTokens / 1_000_000) * price.Input
+	cost.OutputCostPerDoc = (cost.AvgOutputTokens / 1_000_000) * price.Output
+	printCostEstimate(w, cost, estimate.Images)
+}
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lehigh-university-libraries/htr/pkg/imaging"
+)
+
+func TestEstimateImageTokens(t *testing.T) {
+	tests := []struct {
+		provider string
+		detail   string
+		size     imaging.Dimensions
+		want     int
+	}{
+		{provider: "openai", size: imaging.Dimensions{Width: 512, Height: 512}, want: 255},
+		{provider: "openai", size: imaging.Dimensions{Width: 1024, Height: 1024}, want: 765},
+		{provider: "openai", size: imaging.Dimensions{Width: 2048, Height: 4096}, want: 1105},
+		{provider: "ollama", size: imaging.Dimensions{Width: 1024, Height: 1024}, want: 765},
+		{provider: "claude", size: imaging.Dimensions{Width: 1000, Height: 1000}, want: 1334},
+		{provider: "claude", size: imaging.Dimensions{Width: 3136, Height: 1568}, want: 1640},
+		{provider: "gemini", size: imaging.Dimensions{Width: 300, Height: 384}, want: 258},
+		{provider: "gemini", size: imaging.Dimensions{Width: 1024, Height: 1024}, want: 1032},
+		{provider: "mistral", size: imaging.Dimensions{Width: 1024, Height: 1024}, want: 4160},
+		{provider: "mistral", size: imaging.Dimensions{Width: 2048, Height: 1024}, want: 2080},
+		{provider: "openai", detail: "high", size: imaging.Dimensions{Width: 1024, Height: 1024}, want: 765},
+		{provider: "openai", detail: "low", size: imaging.Dimensions{Width: 2048, Height: 4096}, want: 85},
+		{provider: "azure-openai", detail: "low", size: imaging.Dimensions{Width: 1024, Height: 1024}, want: 85},
+		{provider: "openai", size: imaging.Dimensions{}, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.provider+" "+tt.detail+" "+tt.size.String(), func(t *testing.T) {
+			if got := estimateImageTokens(tt.provider, tt.detail, tt.size); got != tt.want {
+				t.Errorf("estimateImageTokens(%q, %q, %v) = %d, want %d", tt.provider, tt.detail, tt.size, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEstimateEvaluationMakesNoProviderCalls(t *testing.T) {
+	csvPath := writeEvalFixtures(t,
+		map[string]string{"page1": "hello world", "page2": "unreadable"},
+		"image,transcript,public\npage1.jpg,page1.txt,true\npage2.jpg,page2.txt,true\n",
+	)
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, 1024, 1024))); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "page1.jpg"), buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	stub := &stubEvalProvider{}
+	useStubEvalProvider(t, stub)
+
+	config := EvalConfig{Provider: "stub", Model: "gpt-4o", Prompt: "Extract the text", CSVPath: csvPath}
+	estimate, err := estimateEvaluation(config)
+	if err != nil {
+		t.Fatalf("estimateEvaluation() error = %v", err)
+	}
+
+	if len(stub.calls) != 0 {
+		t.Errorf("provider calls = %v, want none", stub.calls)
+	}
+	want := dryRunEstimate{Images: 1, Unreadable: 1, EncodedBytes: estimate.EncodedBytes, InputTokens: 765 + 4, OutputTokens: 3, GroundTruth: true}
+	if estimate != want {
+		t.Errorf("estimateEvaluation() = %+v, want %+v", estimate, want)
+	}
+
+	var out bytes.Buffer
+	printDryRun(&out, config, estimate)
+	for _, want := range []string{"APPROXIMATE ESTIMATE, NO API CALLS MADE", "Unreadable images (not counted): 1", "Price source: pricing table", "Estimated Cost for 1 Documents"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("printDryRun() output missing %q:\n%s", want, out.String())
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"encoding/csv"
 	"fmt"
 	"log/slog"
@@ -91,7 +92,6 @@ func runEvalExternal(cmd *cobra.Command, args []string) error {
 	}
 
 	// Create evals directory if it doesn't exist
-	evalsDir := "evals"
 	if err := os.MkdirAll(evalsDir, 0755); err != nil {
 		return fmt.Errorf("failed to create evals directory: %w", err)
 	}
@@ -120,14 +120,13 @@ func runEvalExternal(cmd *cobra.Command, args []string) error {
 	}
 
 	// Save results with model name
-	m := strings.ReplaceAll(config.ModelName, ":", "_")
-	outputPath := filepath.Join(evalsDir, fmt.Sprintf("%s.yaml", m))
+	outputPath := filepath.Join(evalsDir, evalFileName(evalConfig.Provider, config.ModelName)+".yaml")
 	if err := saveEvalResults(summary, outputPath); err != nil {
 		return fmt.Errorf("failed to save results: %w", err)
 	}
 
 	fmt.Printf("\nExternal evaluation completed. Results saved to: %s\n", outputPath)
-	printSummaryStats(results)
+	printSummaryStats(results, false)
 
 	return nil
 }
@@ -195,23 +194,23 @@ func processExternalEval(config ExternalEvalConfig) ([]EvalResult, error) {
 }
 
 func processExternalEvalRow(row []string) (EvalResult, error) {
-	transcriptPath := filepath.Join(evalExternalDir, strings.TrimSpace(row[0]))
-	transcriptionPath := filepath.Join(evalExternalDir, strings.TrimSpace(row[1]))
+	transcriptPath := resolveInputPath(evalExternalDir, strings.TrimSpace(row[0]))
+	transcriptionPath := resolveInputPath(evalExternalDir, strings.TrimSpace(row[1]))
 
 	// Read ground truth
-	groundTruth, err := readTextFile(transcriptPath)
+	groundTruth, err := readTextFile(context.Background(), transcriptPath)
 	if err != nil {
 		return EvalResult{}, fmt.Errorf("failed to read ground truth transcript: %w", err)
 	}
 
 	// Read external model transcription
-	externalTranscription, err := readTextFile(transcriptionPath)
+	externalTranscription, err := readTextFile(context.Background(), transcriptionPath)
 	if err != nil {
 		return EvalResult{}, fmt.Errorf("failed to read external transcription: %w", err)
 	}
 
 	// Calculate metrics
-	metrics := CalculateAccuracyMetrics(groundTruth, externalTranscription, evalExternalIgnorePatterns, evalExternalSingleLine)
+	metrics := CalculateAccuracyMetrics(groundTruth, externalTranscription, evalExternalIgnorePatterns, evalExternalSingleLine, false)
 
 	result := EvalResult{
 		Identifier:            filepath.Base(transcriptPath),
@@ -219,6 +218,7 @@ func processExternalEvalRow(row []string) (EvalResult, error) {
 		TranscriptPath:        transcriptPath,
 		Public:                false,
 		ProviderResponse:      externalTranscription,
+		EmptyResponse:         isEmptyResponse(externalTranscription),
 		CharacterSimilarity:   metrics.CharacterSimilarity,
 		CharacterAccuracy:     metrics.CharacterAccuracy,
 		WordSimilarity:        metrics.WordSimilarity,
@@ -226,6 +226,7 @@ func processExternalEvalRow(row []string) (EvalResult, error) {
 		WordErrorRate:         metrics.WordErrorRate,
 		TotalWordsOriginal:    metrics.TotalWordsOriginal,
 		TotalWordsTranscribed: metrics.TotalWordsTranscribed,
+		TotalCharsOriginal:    metrics.TotalCharsOriginal,
 		CorrectWords:          metrics.CorrectWords,
 		Substitutions:         metrics.Substitutions,
 		Deletions:             metrics.Deletions,
@@ -2,30 +2,50 @@ package cmd
 
 import (
 	"bytes"
+	"cmp"
+	"compress/gzip"
 	"context"
 	"encoding/base64"
-	"encoding/csv"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
+	"maps"
+	"math"
+	"math/rand/v2"
 	"net/http"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"regexp"
 	"slices"
 	"strconv"
 	"strings"
+	"text/template"
 	"time"
+	"unicode"
 
 	"github.com/lehigh-university-libraries/htr/internal/utils"
 	"github.com/lehigh-university-libraries/htr/pkg/azure"
+	"github.com/lehigh-university-libraries/htr/pkg/azureopenai"
+	"github.com/lehigh-university-libraries/htr/pkg/bedrock"
 	"github.com/lehigh-university-libraries/htr/pkg/claude"
+	"github.com/lehigh-university-libraries/htr/pkg/docai"
 	"github.com/lehigh-university-libraries/htr/pkg/gemini"
+	"github.com/lehigh-university-libraries/htr/pkg/huggingface"
+	"github.com/lehigh-university-libraries/htr/pkg/imaging"
+	"github.com/lehigh-university-libraries/htr/pkg/kraken"
 	htrmetrics "github.com/lehigh-university-libraries/htr/pkg/metrics"
+	"github.com/lehigh-university-libraries/htr/pkg/mistral"
+	"github.com/lehigh-university-libraries/htr/pkg/objectstore"
 	"github.com/lehigh-university-libraries/htr/pkg/ollama"
 	"github.com/lehigh-university-libraries/htr/pkg/openai"
+	"github.com/lehigh-university-libraries/htr/pkg/openaicompat"
+	"github.com/lehigh-university-libraries/htr/pkg/pricing"
 	"github.com/lehigh-university-libraries/htr/pkg/providers"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	yaml "go.yaml.in/yaml/v3"
 )
 
@@ -33,17 +53,72 @@ type EvalConfig struct {
 	Provider       string        `json:"provider"`
 	Model          string        `json:"model"`
 	Prompt         string        `json:"prompt"`
+	SystemPrompt   string        `json:"system_prompt,omitempty"`
 	Temperature    float64       `json:"temperature"`
 	Timeout        time.Duration `json:"timeout"`
 	CSVPath        string        `json:"csv_path"`
+	Images         string        `json:"images,omitempty"`
 	TestRows       []int         `json:"rows"`
 	Timestamp      string        `json:"timestamp"`
 	IgnorePatterns []string      `json:"ignore_patterns,omitempty"`
+	IgnoreRegex    []string      `json:"ignore_regex,omitempty"`
+	StripPatterns  []string      `json:"strip_patterns,omitempty"`
+
+	// RowSpec selects rows with --rows syntax, such as "10-50,75,-5". Eval
+	// files written before it list the selected indices in TestRows.
+	RowSpec string `json:"row_spec,omitempty"`
+	// Limit caps the run at the first Limit selected rows. Zero runs them all.
+	Limit int `json:"limit,omitempty"`
+	// Sample evaluates that many rows drawn at random with Seed from the
+	// selected rows. SampledRows lists the rows drawn; rerunning with the same
+	// Seed and input draws them again.
+	Sample      int   `json:"sample,omitempty"`
+	Seed        int64 `json:"seed,omitempty"`
+	SampledRows []int `json:"sampled_rows,omitempty"`
+	// LineBreakTolerance is how far line-break mismatches are forgiven, from
+	// 0 (strict) to 1. Nil leaves line breaks out of the word metrics.
+	LineBreakTolerance *float64 `json:"line_break_tolerance,omitempty"`
 
 	SingleLine            bool   `json:"single_line,omitempty"`
+	IgnoreCase            bool   `json:"ignore_case,omitempty"`
+	BLEU                  bool   `json:"bleu,omitempty"`
+	BagOfWords            bool   `json:"bag_of_words,omitempty"`
+	SortWords             bool   `json:"sort_words,omitempty"`
+	PerLine               bool   `json:"per_line,omitempty"`
+	Logprobs              bool   `json:"logprobs,omitempty"`
+	StoreNormalized       bool   `json:"store_normalized,omitempty"`
+	Dedupe                bool   `json:"dedupe,omitempty"`
 	Debug                 bool   `json:"debug,omitempty"`
 	MaxResolution         string `json:"max_resolution,omitempty"`
 	MaxResolutionFallback bool   `json:"max_resolution_fallback,omitempty"`
+	OllamaKeepAlive       string `json:"ollama_keep_alive,omitempty"`
+	OllamaNumCtx          int    `json:"ollama_num_ctx,omitempty"`
+	OpenAIDetail          string `json:"openai_detail,omitempty"`
+
+	MaxTokens    int           `json:"max_tokens,omitempty"`
+	PollInterval time.Duration `json:"poll_interval,omitempty"`
+	MaxDimension int           `json:"max_dimension,omitempty"`
+	// Page selects the page, numbered from 1, sent for TIFF and PDF inputs.
+	// Zero sends the first page.
+	Page int `json:"page,omitempty"`
+
+	// PromptTemplate is the text of --prompt-file, rendered per row in place
+	// of Prompt. The text is stored so --config reruns do not need the file.
+	PromptTemplate string `json:"prompt_template,omitempty"`
+	// PromptID names the --prompt-id prompt from the prompt library whose
+	// text is Prompt. The text is stored too, so later edits to the library
+	// do not change what a --config rerun sends.
+	PromptID string `json:"prompt_id,omitempty"`
+	// PromptSuffix is appended to the prompt, or to each rendered template,
+	// before it is sent. It is stored apart from the prompt it extends.
+	PromptSuffix string `json:"prompt_suffix,omitempty"`
+
+	MaxRetries     int           `json:"max_retries,omitempty"`
+	RetryBaseDelay time.Duration `json:"retry_base_delay,omitempty"`
+
+	// CacheDir enables the response cache for this run. It is a local
+	// execution option, so it is never written to the eval file.
+	CacheDir string `json:"-" yaml:"-"`
 }
 
 type EvalResult struct {
@@ -66,6 +141,50 @@ type EvalResult struct {
 	IgnoredCharsCount     int     `json:"ignored_chars_count"`
 	InputTokens           int     `json:"input_tokens,omitempty"`
 	OutputTokens          int     `json:"output_tokens,omitempty"`
+	PageCount             int     `json:"page_count,omitempty"`
+	// TotalCharsOriginal is the length of the compared ground truth in
+	// characters. Eval files written before it have none.
+	TotalCharsOriginal int `json:"total_chars_original,omitempty" yaml:"totalcharsoriginal,omitempty"`
+	// Truncated marks responses cut off at the provider's output limit.
+	Truncated bool `json:"truncated,omitempty" yaml:"truncated,omitempty"`
+	// EmptyResponse marks responses with no text after cleanup, which usually
+	// means the model refused or failed rather than misread the page.
+	EmptyResponse bool `json:"empty_response,omitempty" yaml:"emptyresponse,omitempty"`
+	// LatencyMS is the time spent in provider calls in milliseconds, summed
+	// over retries. Rate limiting and retry backoff are not counted.
+	LatencyMS int64 `json:"latency_ms,omitempty" yaml:"latencyms,omitempty"`
+	// Cached marks rows whose every page was served from the response cache,
+	// which have no latency.
+	Cached bool `json:"cached,omitempty" yaml:"cached,omitempty"`
+	// OriginalSize and SentSize are only set for runs with --max-dimension,
+	// formatted as WIDTHxHEIGHT.
+	OriginalSize string `json:"original_size,omitempty" yaml:"originalsize,omitempty"`
+	SentSize     string `json:"sent_size,omitempty" yaml:"sentsize,omitempty"`
+	// BLEUScore is only set for runs with --bleu.
+	BLEUScore *float64 `json:"bleu_score,omitempty" yaml:"bleuscore,omitempty"`
+	// BagOfWords is only set for runs with --bag-of-words.
+	BagOfWords *htrmetrics.BagOfWordsScore `json:"bag_of_words,omitempty" yaml:"bagofwords,omitempty"`
+	// SortedWords is only set for runs with --sort-words.
+	SortedWords *htrmetrics.SortedWordScore `json:"sorted_words,omitempty" yaml:"sortedwords,omitempty"`
+	// References lists every transcript of a row with more than one, and
+	// Reference is the 1-based position of the one the response was scored
+	// against, which is also TranscriptPath.
+	References []string `json:"references,omitempty" yaml:"references,omitempty"`
+	Reference  int      `json:"reference,omitempty" yaml:"reference,omitempty"`
+	// LineResults is only set for runs with --per-line.
+	LineResults []htrmetrics.LineMetric `json:"line_results,omitempty" yaml:"lineresults,omitempty"`
+	// WordConfidences is only set for runs with --logprobs against a provider
+	// that returns token log probabilities.
+	WordConfidences []WordConfidence `json:"word_confidences,omitempty" yaml:"wordconfidences,omitempty"`
+	// NormalizedGroundTruth and NormalizedResponse are the strings the metrics
+	// compared after --single-line, --ignore-case, and --ignore, set only for
+	// runs with --store-normalized when normalization changed either text.
+	NormalizedGroundTruth string `json:"normalized_ground_truth,omitempty" yaml:"normalizedgroundtruth,omitempty"`
+	NormalizedResponse    string `json:"normalized_response,omitempty" yaml:"normalizedresponse,omitempty"`
+
+	// WordDiff is the rendered word-level diff shown with --show-diff. It is
+	// only used for terminal output and is never written to the eval file.
+	WordDiff string `json:"-" yaml:"-"`
 }
 
 type EvalSummary struct {
@@ -83,20 +202,81 @@ type ModelSummary struct {
 	AvgWordSimilarity float64
 	AvgWordAccuracy   float64
 	AvgWordErrorRate  float64
-	AvgInputTokens    float64
-	AvgOutputTokens   float64
-	PageCost          float64
+	// WeightedWordAccuracy, WeightedWordErrorRate, and WeightedCharErrorRate
+	// are the corpus-level rates set with --weighted; HasWeighted is false
+	// otherwise.
+	WeightedWordAccuracy  float64
+	WeightedWordErrorRate float64
+	WeightedCharErrorRate float64
+	HasWeighted           bool
+	// AvgBagOfWordsF1 averages the rows scored with --bag-of-words;
+	// HasBagOfWords is false when no row was.
+	AvgBagOfWordsF1 float64
+	HasBagOfWords   bool
+	// TruncatedRows counts responses cut off at the provider's output limit.
+	TruncatedRows   int
+	AvgInputTokens  float64
+	AvgOutputTokens float64
+	PageCost        float64
+	// Unpriced marks models with no known token prices; PageCost is left blank.
+	Unpriced bool
+	// NoTokenUsage marks providers that do not report token usage; the token
+	// and cost columns are left blank.
+	NoTokenUsage bool
+	// AvgLatencyMS and MedianLatencyMS cover the rows with a recorded
+	// latency; HasLatency is false when no row has one.
+	AvgLatencyMS    float64
+	MedianLatencyMS float64
+	HasLatency      bool
+
+	CharAccuracyStats  htrmetrics.Stats
+	WordAccuracyStats  htrmetrics.Stats
+	WordErrorRateStats htrmetrics.Stats
 }
 
 // Provider registry for managing all providers
 var providerRegistry *providers.Registry
 
+// providerCapabilities returns the capabilities of a registered provider.
+// Providers this build does not know, such as those named in older eval
+// files, get the vision language model defaults.
+func providerCapabilities(name string) providers.Capabilities {
+	provider, err := providerRegistry.Get(name)
+	if err != nil {
+		return providers.BaseProvider{}.Capabilities()
+	}
+	return provider.Capabilities()
+}
+
 var evalCmd = &cobra.Command{
 	Use:   "eval",
 	Short: "Evaluate OCR performance using vision models",
 	Long: `Evaluate OCR performance by comparing vision model outputs with ground truth transcripts.
 
 You can either provide individual flags or use a previous evaluation config file.
+Without --prompt, --prompt-file, or --prompt-id, a generic transcription prompt
+is sent and recorded in the eval file. --prompt-id sends a named prompt from the
+prompt library (see htr prompts) and records both its name and its text.
+
+The --input file (alias --csv) lists image, transcript, and public columns. It may be
+CSV, tab-separated (.tsv), or a JSON array of {"image", "transcript", "public"} objects
+(.json). A leading header row starting with "image" is skipped.
+
+TRANSCRIBE-ONLY MODE:
+
+Pass --images with a directory or glob (e.g. --images ./pages or --images 'pages/*.jpg')
+instead of --input to run the provider on each image without ground truth. Each
+transcription is printed, accuracy metrics are skipped, and the results file is written
+with an empty transcript path. Directories are not searched recursively and only image
+files (.jpg, .jpeg, .png, .gif, .webp, .tif, .tiff, .bmp) are used. --dir is not applied
+to --images, and --rows selects images by their position in sorted order.
+
+ERRORS:
+
+A row that fails, such as an unreadable image or a rejected request, is logged and
+skipped. An invalid provider configuration or rejected credentials stop the run at the
+first row, since every row would fail the same way. With --fail-fast, any row error
+stops the run. Rows finished before the error are kept for --resume.
 
 HANDLING UNKNOWN CHARACTERS:
 
@@ -109,10 +289,10 @@ HTR automatically skips the corresponding LLM output when calculating accuracy m
 
 Examples:
   # Mark unknown characters with pipe
-  htr eval --provider openai --model gpt-4o --prompt "Extract text" --csv data.csv --ignore '|'
+  htr eval --provider openai --model gpt-4o --prompt "Extract text" --input data.csv --ignore '|'
 
   # Use multiple ignore patterns
-  htr eval --provider gemini --model gemini-1.5-flash --prompt "Extract text" --csv data.csv --ignore '|' --ignore ','
+  htr eval --provider gemini --model gemini-1.5-flash --prompt "Extract text" --input data.csv --ignore '|' --ignore ','
 
 Ground truth examples:
   GT: "The quick | fox"     Trans: "The quick brown fox"     -> Compares "The quick fox" (skips "brown")
@@ -124,9 +304,17 @@ Ground truth examples:
 var summaryCmd = &cobra.Command{
 	Use:   "summary [eval-file]",
 	Short: "Print summary statistics from an existing evaluation file",
-	Long: `Print summary statistics from an existing evaluation file in the evals/ directory.
+	Long: `Print summary statistics from an existing evaluation file in the evals directory
+(evals/ unless --evals-dir or HTR_EVALS_DIR is set).
+
+If no file is specified, lists available evaluation files.
 
-If no file is specified, lists available evaluation files.`,
+Averages give every document equal weight. With --weighted, the word accuracy
+and word error rate over the whole corpus are also printed, so a 500-word page
+counts a hundred times as much as a 5-word caption.
+
+With --public-only, only rows marked public in the input are counted, so the
+numbers can be published without the restricted materials.`,
 	RunE: runSummary,
 	Args: cobra.MaximumNArgs(1),
 }
@@ -134,11 +322,22 @@ If no file is specified, lists available evaluation files.`,
 var csvCmd = &cobra.Command{
 	Use:   "csv",
 	Short: "Export evaluation results as CSV sorted by model performance",
-	Long: `Scan all YAML files in the evals directory and export summary statistics as CSV.
+	Long: `Scan all YAML and JSON files in the evals directory and export summary statistics as CSV.
 
 Results are sorted by word accuracy (best to worst) and printed to terminal.
 
-If --input-price and --output-price are provided, a PageCost column will be included.`,
+A PageCost column is included when --input-price and --output-price are provided,
+or when prices for at least one model are known from the built-in pricing table
+(override or extend it with --price-file). Models without known prices get a blank PageCost.
+An AvgBagOfWordsF1 column is included when any evaluation was run with --bag-of-words.
+If --markdown is set, results are rendered as a GitHub-flavored Markdown table instead of TSV.
+If --verbose is set, distribution columns (median, standard deviation, min, max) are
+included for character accuracy, word accuracy, and word error rate.
+If --weighted is set, WeightedWordAccuracy, WeightedWordErrorRate, and WeightedCharErrorRate
+columns give the corpus-level rates, which weight each document by its length in ground
+truth words or characters.
+If --public-only is set, only rows marked public in the input are counted, and eval
+files without any public rows are skipped.`,
 	RunE: runCSV,
 	Args: cobra.NoArgs,
 }
@@ -146,10 +345,10 @@ If --input-price and --output-price are provided, a PageCost column will be incl
 var backfillCmd = &cobra.Command{
 	Use:   "backfill",
 	Short: "Backfill metrics for existing evaluation files",
-	Long: `Scan all YAML files in the evals directory and recalculate metrics for existing evaluations.
+	Long: `Scan all YAML and JSON files in the evals directory and recalculate metrics for existing evaluations.
 
 This command reads the provider response and ground truth from existing evaluation files,
-recalculates metrics (character accuracy, word similarity), and updates the YAML files in place.
+recalculates metrics (character accuracy, word similarity), and updates the files in place.
 
 By default, uses the --single-line and --ignore flags saved in each evaluation's config.
 You can override these by passing --single-line or --ignore flags to this command.
@@ -161,14 +360,25 @@ This is useful after upgrading to a version with improved metric calculations.`,
 
 var costCmd = &cobra.Command{
 	Use:   "cost [eval-file]",
-	Short: "Calculate cost estimates based on token usage from an evaluation file",
-	Long: `Calculate cost estimates based on token usage from an evaluation file.
+	Short: "Calculate cost estimates based on token or page usage from an evaluation file",
+	Long: `Calculate cost estimates based on token or page usage from an evaluation file.
 
-This command reads an evaluation file containing token usage data and calculates:
-- Average input and output tokens per document
+This command reads an evaluation file containing usage data and calculates:
+- Average input and output tokens (or pages) per document
 - Estimated cost for a given number of documents
 
-Requires --input-price and --output-price flags (cost per million tokens).
+Prices (cost per million tokens) come from --input-price and --output-price when given,
+otherwise from the built-in pricing table for the evaluated model. Use --price-file to
+supply a YAML file of model prices that overrides or extends the built-in table:
+
+  gpt-4o:
+    input: 2.50
+    output: 10.00
+
+OCR services such as Azure and Document AI bill per page rather than per token.
+Pass --per-page-price to price each document by its recorded page count instead;
+rows without a recorded page count are treated as a single page.
+
 Optionally specify --doc-count to estimate cost for a specific number of documents.`,
 	RunE: runCost,
 	Args: cobra.ExactArgs(1),
@@ -178,17 +388,60 @@ var (
 	evalProvider          string
 	evalModel             string
 	evalPrompt            string
+	evalSystemPrompt      string
+	evalPromptFile        string
+	evalPromptSuffix      string
 	evalTemperature       float64
 	evalTimeout           time.Duration
 	evalCSVPath           string
+	evalImages            string
+	evalShowDiff          bool
+	evalBLEU              bool
+	evalBagOfWords        bool
+	evalSortWords         bool
+	evalPerLine           bool
+	evalLogprobs          bool
+	evalPollInterval      time.Duration
+	evalMaxTokens         int
+	evalMaxDimension      int
+	evalPage              int
 	evalConfigPath        string
 	evalTemplate          string
+	evalPromptID          string
 	dir                   string
-	rows                  []int
+	rows                  []string
+	evalLimit             int
+	evalSample            int
+	evalSeed              int64
 	ignorePatterns        []string
+	ignoreRegex           []string
+	stripPatterns         []string
 	singleLine            bool
+	lineBreakTolerance    float64
+	ignoreCase            bool
 	maxResolution         string
 	maxResolutionFallback bool
+	ollamaKeepAlive       string
+	ollamaNumCtx          int
+	openAIDetail          string
+	maxRetries            int
+	retryBaseDelay        time.Duration
+	resume                bool
+	evalFormat            string
+	evalCompress          bool
+	evalQuiet             bool
+	evalFailFast          bool
+	evalMinWordAccuracy   float64
+	evalMinCharAccuracy   float64
+	evalCache             bool
+	evalRPM               int
+	evalNoCache           bool
+	evalCacheDir          string
+	evalDryRun            bool
+	evalDedupe            bool
+	evalStoreNormalized   bool
+	fetchTimeout          = time.Minute
+	maxFetchMB            = 50
 
 	// from https://ai.google.dev/gemini-api/docs/media-resolution#available_resolution_values
 	allowedMediaResolutions = []string{
@@ -199,19 +452,36 @@ var (
 		"MEDIA_RESOLUTION_ULTRA_HIGH",
 	}
 
+	// from https://platform.openai.com/docs/guides/images-vision#specify-image-input-detail-level
+	allowedOpenAIDetails = []string{"low", "high", "auto"}
+	// openAIDetailProviders send --openai-detail with each image
+	openAIDetailProviders = []string{"azure-openai", "openai", "openai-compat"}
+
 	// Backfill command flags
 	backfillIgnorePatterns []string
 	backfillSingleLine     bool
+	backfillIgnoreCase     bool
 	backfillOverride       bool
 
 	// Cost command flags
 	costInputPrice  float64
 	costOutputPrice float64
 	costDocCount    int
+	costPriceFile   string
+	costPagePrice   float64
+
+	// Summary command flags
+	summaryWeighted   bool
+	summaryPublicOnly bool
 
 	// CSV command flags
+	csvWeighted    bool
+	csvPublicOnly  bool
 	csvInputPrice  float64
 	csvOutputPrice float64
+	csvVerbose     bool
+	csvMarkdown    bool
+	csvPriceFile   string
 )
 
 func init() {
@@ -219,9 +489,16 @@ func init() {
 	providerRegistry = providers.NewRegistry()
 	providerRegistry.Register(openai.New())
 	providerRegistry.Register(azure.New())
+	providerRegistry.Register(azureopenai.New())
 	providerRegistry.Register(claude.New())
 	providerRegistry.Register(gemini.New())
 	providerRegistry.Register(ollama.New())
+	providerRegistry.Register(mistral.New())
+	providerRegistry.Register(docai.New())
+	providerRegistry.Register(openaicompat.New())
+	providerRegistry.Register(huggingface.New())
+	providerRegistry.Register(kraken.New())
+	providerRegistry.Register(bedrock.New())
 
 	RootCmd.AddCommand(evalCmd)
 	RootCmd.AddCommand(summaryCmd)
@@ -230,40 +507,147 @@ func init() {
 	RootCmd.AddCommand(costCmd)
 
 	// Eval command flags
-	evalCmd.Flags().StringVar(&evalProvider, "provider", "openai", "Provider to use: openai, azure, claude, gemini, ollama")
+	evalCmd.Flags().StringVar(&evalProvider, "provider", "openai", "Provider to use: openai, azure, azure-openai, claude, gemini, ollama, mistral, docai, openai-compat, huggingface, kraken, bedrock")
 	evalCmd.Flags().StringVarP(&evalModel, "model", "m", "gpt-4o", "Model to use")
-	evalCmd.Flags().StringVarP(&evalPrompt, "prompt", "p", "", "Prompt to send to the provider")
+	evalCmd.Flags().StringVarP(&evalPrompt, "prompt", "p", "", "Prompt to send to the provider (default: the ocr command's generic transcription prompt)")
+	evalCmd.Flags().StringVar(&evalPromptFile, "prompt-file", "", "Go text/template file rendered per row with CSV columns and {{.filename}} as variables")
+	evalCmd.Flags().StringVar(&evalPromptID, "prompt-id", "", "Name of a prompt in the prompt library (--prompts-dir) to send; see htr prompts")
+	evalCmd.Flags().StringVar(&evalPromptSuffix, "prompt-suffix", "", "Text appended to --prompt or the rendered --prompt-file, such as document-specific guidance")
+	evalCmd.Flags().StringVar(&evalSystemPrompt, "system-prompt", "", "System prompt to send ahead of --prompt (optional)")
 	evalCmd.Flags().Float64VarP(&evalTemperature, "temperature", "t", 0.0, "Temperature for API")
 	evalCmd.Flags().DurationVar(&evalTimeout, "timeout", 5*time.Minute, "Timeout for API requests (e.g., 5m, 30s, 1h)")
-	evalCmd.Flags().StringVarP(&evalCSVPath, "csv", "c", "", "Path to CSV file with evaluation data")
+	evalCmd.Flags().StringVarP(&evalCSVPath, "input", "c", "", "Path to the evaluation input: CSV, TSV (.tsv), or JSON (.json); --csv is an alias")
+	evalCmd.Flags().StringVar(&evalImages, "images", "", "Directory or glob of images to transcribe without ground truth (transcribe-only mode)")
 	evalCmd.Flags().StringVar(&evalConfigPath, "config", "", "Path to previous evaluation config file to rerun")
 	evalCmd.Flags().StringVar(&evalTemplate, "template", "", "Custom JSON template file for API (optional)")
+	evalCmd.Flags().DurationVar(&fetchTimeout, "fetch-timeout", fetchTimeout, "Timeout for downloading each http(s) image or transcript")
+	evalCmd.Flags().IntVar(&maxFetchMB, "max-fetch-mb", maxFetchMB, "Largest http(s) image or transcript to download, in megabytes")
 	evalCmd.Flags().StringVar(&dir, "dir", "./", "Prepend your CSV file paths with a directory")
-	evalCmd.Flags().IntSliceVar(&rows, "rows", []int{}, "A list of row numbers to run the test on")
+	evalCmd.Flags().StringSliceVar(&rows, "rows", []string{}, "Rows to run, numbered from 0: indices, ranges such as 10-50, open-ended ranges such as 10-, and -N for the last N rows (e.g. 10-50,75,-5)")
+	evalCmd.Flags().IntVar(&evalLimit, "limit", 0, "Process only the first N rows, after --rows selects them (0 processes all)")
+	evalCmd.Flags().IntVar(&evalSample, "sample", 0, "Process N rows drawn at random, after --rows selects them (0 processes all)")
+	evalCmd.Flags().Int64Var(&evalSeed, "seed", 0, "Random seed for --sample, to draw the same rows again (0 picks one and records it in the eval file)")
 	evalCmd.Flags().StringSliceVar(&ignorePatterns, "ignore", []string{}, "Characters or strings to ignore in ground truth (e.g., --ignore '|' --ignore ',')")
+	evalCmd.Flags().StringArrayVar(&stripPatterns, "strip-pattern", []string{}, "Regular expression for text the model adds around transcriptions, removed where it matches at the start or end of a response (e.g., --strip-pattern '(?i)transcribed text:')")
+	evalCmd.Flags().StringArrayVar(&ignoreRegex, "ignore-regex", []string{}, "Regular expressions matching whole ground truth words to ignore, skipping one transcription word each (e.g., --ignore-regex '\\[.*?\\]')")
 
 	evalCmd.Flags().BoolVar(&singleLine, "single-line", false, "Convert ground truth and transcripts to single line (remove newlines, carriage returns, tabs, and normalize spaces)")
+	evalCmd.Flags().Float64Var(&lineBreakTolerance, "line-break-tolerance", 0, "Score line breaks as layout, forgiving each mismatch by this fraction from 0 (strict) to 1 (same as --single-line)")
+	evalCmd.Flags().BoolVar(&ignoreCase, "ignore-case", false, "Compare ground truth and transcripts case-insensitively")
 	evalCmd.Flags().StringVar(&maxResolution, "gemini-max-resolution", "MEDIA_RESOLUTION_UNSPECIFIED", "Max resolution for Gemini models (e.g., MEDIA_RESOLUTION_HIGH)")
 	evalCmd.Flags().BoolVar(&maxResolutionFallback, "gemini-max-resolution-fallback", false, "Automatically retry with lower resolution if MAX_TOKENS error occurs")
-
-	evalCmd.MarkFlagsRequiredTogether("csv", "prompt")
-	evalCmd.MarkFlagsMutuallyExclusive("csv", "config")
+	evalCmd.Flags().StringVar(&ollamaKeepAlive, "ollama-keep-alive", defaultOllamaKeepAlive, "How long Ollama keeps the model loaded between requests: a duration such as 30m, or seconds, with -1 for indefinitely")
+	evalCmd.Flags().IntVar(&ollamaNumCtx, "ollama-num-ctx", 0, "Ollama context window in tokens (0 uses the model default)")
+	evalCmd.Flags().StringVar(&openAIDetail, "openai-detail", "", "OpenAI image detail: low, high, or auto (empty uses the service default); low is much cheaper for legible pages")
+	evalCmd.Flags().IntVar(&evalMaxTokens, "max-tokens", 0, "Maximum response tokens for Claude and OpenAI-style providers (Claude defaults to 4096)")
+	evalCmd.Flags().IntVar(&evalMaxDimension, "max-dimension", 0, "Downscale images whose longest side exceeds this many pixels before upload (0 sends images as-is)")
+	evalCmd.Flags().IntVar(&evalPage, "page", 1, "Page of each TIFF or PDF input to rasterize and send, numbered from 1")
+	evalCmd.Flags().DurationVar(&evalPollInterval, "poll-interval", 0, "Delay between Azure OCR result polls (defaults to 1s); polling stops at --timeout")
+
+	evalCmd.Flags().IntVar(&maxRetries, "max-retries", 3, "Retry transient provider failures (HTTP 429/5xx, timeouts) up to this many times")
+	evalCmd.Flags().DurationVar(&retryBaseDelay, "retry-base-delay", 2*time.Second, "Initial backoff between retries; doubles on each retry with jitter")
+
+	evalCmd.Flags().BoolVar(&resume, "resume", false, "Resume an interrupted run: skip rows that already have a result in the output (or .partial) eval file")
+
+	evalCmd.Flags().StringVar(&evalFormat, "format", "yaml", "Output format for the eval file: yaml or json")
+	evalCmd.Flags().BoolVar(&evalCompress, "compress", false, "Gzip the eval file, saving it as .yaml.gz or .json.gz")
+	evalCmd.Flags().IntVar(&evalRPM, "rpm", 0, "Pace provider requests, including retries, to at most this many per minute (0 does not limit)")
+	evalCmd.Flags().BoolVar(&evalCache, "cache", false, "Cache provider responses on disk keyed by provider, model, prompt, temperature, and image")
+	evalCmd.Flags().BoolVar(&evalNoCache, "no-cache", false, "Disable the response cache (the default)")
+	evalCmd.Flags().StringVar(&evalCacheDir, "cache-dir", defaultCacheDir, "Directory for cached provider responses")
+	evalCmd.MarkFlagsMutuallyExclusive("cache", "no-cache")
+	evalCmd.Flags().BoolVar(&evalBLEU, "bleu", false, "Also compute a sentence-level BLEU score (1-4 grams with brevity penalty) for each row")
+	evalCmd.Flags().BoolVar(&evalBagOfWords, "bag-of-words", false, "Also compute order-insensitive word precision, recall, and F1 for each row")
+	evalCmd.Flags().BoolVar(&evalSortWords, "sort-words", false, "Also compute word accuracy and similarity with both word lists sorted; a diagnostic for reordered text such as tables, not a replacement for the ordered metrics")
+	evalCmd.Flags().BoolVar(&evalPerLine, "per-line", false, "Also align ground-truth and transcribed lines and record per-line accuracy (not with --single-line)")
+	evalCmd.Flags().BoolVar(&evalLogprobs, "logprobs", false, "Also record the model's confidence in each response word from its token log probabilities (OpenAI only)")
+	evalCmd.Flags().BoolVar(&evalShowDiff, "show-diff", false, "Print a word-level diff of ground truth and transcription for each row")
+	evalCmd.Flags().BoolVar(&evalStoreNormalized, "store-normalized", false, "Also store the ground truth and response as compared, after --single-line, --ignore-case, and --ignore, when they differ from the originals")
+	evalCmd.Flags().BoolVar(&evalDedupe, "dedupe", false, "Skip rows that repeat an earlier row's image and transcript so they are not counted twice")
+	evalCmd.Flags().BoolVar(&evalDryRun, "dry-run", false, "Read the input and print approximate token usage and cost without calling the provider or writing an eval file")
+	evalCmd.Flags().BoolVar(&evalQuiet, "quiet", false, "Suppress per-row results; a progress counter is still shown on stderr when stdout is a terminal")
+	evalCmd.Flags().BoolVar(&evalFailFast, "fail-fast", false, "Stop at the first row that fails instead of logging the error and continuing")
+	evalCmd.Flags().Float64Var(&evalMinWordAccuracy, "min-word-accuracy", 0, "Exit with status 3 when the average word accuracy of the run is below this value (0 disables)")
+	evalCmd.Flags().Float64Var(&evalMinCharAccuracy, "min-char-accuracy", 0, "Exit with status 3 when the average character accuracy of the run is below this value (0 disables)")
+
+	evalCmd.Flags().SetNormalizeFunc(func(f *pflag.FlagSet, name string) pflag.NormalizedName {
+		if name == "csv" {
+			name = "input"
+		}
+		return pflag.NormalizedName(name)
+	})
+	evalCmd.MarkFlagsMutuallyExclusive("prompt", "prompt-file", "prompt-id")
+	evalCmd.MarkFlagsMutuallyExclusive("input", "config")
+	evalCmd.MarkFlagsMutuallyExclusive("input", "images")
+	evalCmd.MarkFlagsMutuallyExclusive("images", "config")
 
 	// Backfill command flags
 	backfillCmd.Flags().StringSliceVar(&backfillIgnorePatterns, "ignore", []string{}, "Override ignore patterns for all evaluations (e.g., --ignore '|' --ignore ',')")
 	backfillCmd.Flags().BoolVar(&backfillSingleLine, "single-line", false, "Override single-line flag for all evaluations")
+	backfillCmd.Flags().BoolVar(&backfillIgnoreCase, "ignore-case", false, "Override ignore-case flag for all evaluations")
 	backfillCmd.Flags().BoolVar(&backfillOverride, "override", false, "Force override of saved flags (use CLI flags for all evaluations)")
 
 	// Cost command flags
 	costCmd.Flags().Float64Var(&costInputPrice, "input-price", 0.0, "Cost per million input tokens (e.g., 1.25 for $1.25/1M)")
 	costCmd.Flags().Float64Var(&costOutputPrice, "output-price", 0.0, "Cost per million output tokens (e.g., 10.0 for $10.00/1M)")
 	costCmd.Flags().IntVar(&costDocCount, "doc-count", 1000, "Number of documents to estimate cost for")
-	_ = costCmd.MarkFlagRequired("input-price")
-	_ = costCmd.MarkFlagRequired("output-price")
+	costCmd.Flags().StringVar(&costPriceFile, "price-file", "", "YAML file of model prices that overrides the built-in pricing table")
+	costCmd.Flags().Float64Var(&costPagePrice, "per-page-price", 0.0, "Cost per page for page-billed OCR providers (e.g., 0.0015 for $1.50/1000 pages)")
+	costCmd.MarkFlagsMutuallyExclusive("per-page-price", "input-price")
+	costCmd.MarkFlagsMutuallyExclusive("per-page-price", "output-price")
+	costCmd.MarkFlagsMutuallyExclusive("per-page-price", "price-file")
 
 	// CSV command flags
+	summaryCmd.Flags().BoolVar(&summaryWeighted, "weighted", false, "Also print word accuracy, word error rate, and character error rate over the whole corpus, weighting each document by its length")
+	summaryCmd.Flags().BoolVar(&summaryPublicOnly, "public-only", false, "Only include rows marked public in the input")
+
+	csvCmd.Flags().BoolVar(&csvWeighted, "weighted", false, "Include WeightedWordAccuracy, WeightedWordErrorRate, and WeightedCharErrorRate columns computed over the whole corpus")
+	csvCmd.Flags().BoolVar(&csvPublicOnly, "public-only", false, "Only include rows marked public in the input")
 	csvCmd.Flags().Float64Var(&csvInputPrice, "input-price", 0.0, "Cost per million input tokens (optional)")
 	csvCmd.Flags().Float64Var(&csvOutputPrice, "output-price", 0.0, "Cost per million output tokens (optional)")
+	csvCmd.Flags().StringVar(&csvPriceFile, "price-file", "", "YAML file of model prices that overrides the built-in pricing table")
+	csvCmd.Flags().BoolVar(&csvMarkdown, "markdown", false, "Render results as a GitHub-flavored Markdown table instead of TSV")
+	csvCmd.Flags().BoolVar(&csvVerbose, "verbose", false, "Include median, standard deviation, min, and max columns for character accuracy, word accuracy, and word error rate")
+}
+
+// evalConfigFromFlags builds the configuration for a new run from the eval flags.
+func evalConfigFromFlags() EvalConfig {
+	return EvalConfig{
+		Provider:       evalProvider,
+		Model:          evalModel,
+		Prompt:         evalPrompt,
+		PromptSuffix:   evalPromptSuffix,
+		SystemPrompt:   evalSystemPrompt,
+		Temperature:    evalTemperature,
+		Timeout:        evalTimeout,
+		CSVPath:        evalCSVPath,
+		Images:         evalImages,
+		Timestamp:      time.Now().Format("2006-01-02_15-04-05"),
+		IgnorePatterns: ignorePatterns,
+		IgnoreRegex:    ignoreRegex,
+		StripPatterns:  stripPatterns,
+
+		SingleLine:            singleLine,
+		IgnoreCase:            ignoreCase,
+		BLEU:                  evalBLEU,
+		BagOfWords:            evalBagOfWords,
+		SortWords:             evalSortWords,
+		PerLine:               evalPerLine,
+		Logprobs:              evalLogprobs,
+		Dedupe:                evalDedupe,
+		StoreNormalized:       evalStoreNormalized,
+		MaxResolution:         maxResolution,
+		MaxResolutionFallback: maxResolutionFallback,
+		OllamaKeepAlive:       ollamaKeepAlive,
+		OllamaNumCtx:          ollamaNumCtx,
+		OpenAIDetail:          openAIDetail,
+		MaxTokens:             evalMaxTokens,
+		PollInterval:          evalPollInterval,
+		MaxDimension:          evalMaxDimension,
+		Page:                  evalPage,
+		MaxRetries:            maxRetries,
+		RetryBaseDelay:        retryBaseDelay,
+	}
 }
 
 func runEval(cmd *cobra.Command, args []string) error {
@@ -278,37 +662,214 @@ func runEval(cmd *cobra.Command, args []string) error {
 		}
 		fmt.Printf("Loaded configuration from %s\n", evalConfigPath)
 	} else {
-		config = EvalConfig{
-			Provider:       evalProvider,
-			Model:          evalModel,
-			Prompt:         evalPrompt,
-			Temperature:    evalTemperature,
-			Timeout:        evalTimeout,
-			CSVPath:        evalCSVPath,
-			Timestamp:      time.Now().Format("2006-01-02_15-04-05"),
-			IgnorePatterns: ignorePatterns,
+		config = evalConfigFromFlags()
+		if flagGiven(cmd.Flags(), "line-break-tolerance") {
+			config.LineBreakTolerance = &lineBreakTolerance
+		}
+		if evalPromptFile != "" {
+			promptTemplate, err := os.ReadFile(evalPromptFile)
+			if err != nil {
+				return fmt.Errorf("failed to read prompt file: %w", err)
+			}
+			config.PromptTemplate = string(promptTemplate)
+		}
+		if evalPromptID != "" {
+			if config.Prompt, err = loadNamedPrompt(promptsDir, evalPromptID); err != nil {
+				return err
+			}
+			config.PromptID = evalPromptID
+		}
+	}
 
-			SingleLine:            singleLine,
-			MaxResolution:         maxResolution,
-			MaxResolutionFallback: maxResolutionFallback,
+	if err := validateProvider(providerRegistry, config.Provider); err != nil {
+		return err
+	}
+
+	// Bedrock has no default model, since model access is granted per
+	// account, so the OpenAI default gives way to BEDROCK_MODEL
+	if config.Provider == "bedrock" && (config.Model == "" || config.Model == "gpt-4o") {
+		config.Model = strings.TrimSpace(os.Getenv("BEDROCK_MODEL"))
+		if config.Model == "" {
+			return fmt.Errorf("provider bedrock needs a model: set --model or BEDROCK_MODEL to a vision model enabled for your account")
 		}
 	}
 
+	// The default prompt is recorded in the eval file like one given with
+	// --prompt, so --config reruns send the same text
+	if config.Prompt == "" && config.PromptTemplate == "" {
+		config.Prompt = defaultOCRPrompt
+	}
+
+	if _, err := compileIgnoreRegex(config.IgnoreRegex); err != nil {
+		return err
+	}
+	if _, err := compileStripPatterns(config.StripPatterns); err != nil {
+		return err
+	}
+
 	if !slices.Contains(allowedMediaResolutions, config.MaxResolution) {
 		return fmt.Errorf("invalid --gemini-max-resolution value '%s'. Allowed values are: %s", config.MaxResolution, strings.Join(allowedMediaResolutions, ", "))
 	}
 
-	testRows, err := cmd.Flags().GetIntSlice("rows")
+	if config.MaxTokens < 0 {
+		return fmt.Errorf("--max-tokens cannot be negative")
+	}
+
+	if config.PollInterval < 0 {
+		return fmt.Errorf("--poll-interval cannot be negative")
+	}
+	if err := validateOllamaOptions(config.OllamaKeepAlive, config.OllamaNumCtx); err != nil {
+		return err
+	}
+	if err := validateOpenAIDetail(config.Provider, config.OpenAIDetail); err != nil {
+		return err
+	}
+
+	if config.MaxDimension < 0 {
+		return fmt.Errorf("--max-dimension cannot be negative")
+	}
+
+	if config.Page < 0 {
+		return fmt.Errorf("--page cannot be negative")
+	}
+
+	if fetchTimeout <= 0 || maxFetchMB <= 0 {
+		return fmt.Errorf("--fetch-timeout and --max-fetch-mb must be positive")
+	}
+
+	if config.PerLine && config.SingleLine {
+		return fmt.Errorf("--per-line cannot be combined with --single-line")
+	}
+
+	if tolerance := config.LineBreakTolerance; tolerance != nil {
+		if *tolerance < 0 || *tolerance > 1 {
+			return fmt.Errorf("--line-break-tolerance must be between 0 and 1")
+		}
+		if config.SingleLine {
+			return fmt.Errorf("--line-break-tolerance cannot be combined with --single-line, which already ignores line breaks")
+		}
+	}
+
+	if ((config.Prompt != "" && config.Prompt != defaultOCRPrompt) || config.PromptTemplate != "" || config.PromptSuffix != "" || config.SystemPrompt != "") && !providerCapabilities(config.Provider).SupportsCustomPrompt {
+		slog.Warn("Provider ignores prompts; they are recorded in the eval file but not sent", "provider", config.Provider)
+	}
+
+	if config.Logprobs {
+		if provider, err := providerRegistry.Get(config.Provider); err == nil {
+			if _, ok := provider.(providers.LogprobProvider); !ok {
+				slog.Warn("Provider does not return token log probabilities; --logprobs is ignored", "provider", config.Provider)
+			}
+		}
+	}
+
+	if evalCache && !evalNoCache {
+		config.CacheDir = evalCacheDir
+	}
+
+	if evalRPM < 0 {
+		return fmt.Errorf("--rpm cannot be negative")
+	}
+	if evalMinWordAccuracy < 0 || evalMinWordAccuracy > 1 || evalMinCharAccuracy < 0 || evalMinCharAccuracy > 1 {
+		return fmt.Errorf("--min-word-accuracy and --min-char-accuracy must be between 0 and 1")
+	}
+	if config.Images != "" && (evalMinWordAccuracy > 0 || evalMinCharAccuracy > 0) {
+		return fmt.Errorf("--min-word-accuracy and --min-char-accuracy need ground truth and cannot be combined with --images")
+	}
+	requestLimiter = providers.NewRateLimiter(evalRPM)
+
+	if evalFormat != "yaml" && evalFormat != "json" {
+		return fmt.Errorf("invalid --format value '%s'. Allowed values are: yaml, json", evalFormat)
+	}
+
+	rowSpec, err := cmd.Flags().GetStringSlice("rows")
 	if err != nil {
 		return fmt.Errorf("failed to fetch rows flag: %w", err)
 	}
-	config.TestRows = testRows
-	evalsDir := "evals"
+
+	// A rerun keeps the eval file's row selection and limit and draws the
+	// same sample unless --rows, --limit, --sample, or --seed is given again.
+	// Files written before RowSpec list their rows in TestRows, which is
+	// otherwise the previous run's result and is selected again.
+	if evalConfigPath == "" || cmd.Flags().Changed("rows") {
+		config.RowSpec = strings.Join(rowSpec, ",")
+		config.TestRows = nil
+	} else if config.RowSpec != "" || config.Limit > 0 || config.Sample > 0 {
+		config.TestRows = nil
+	}
+	if evalConfigPath == "" || cmd.Flags().Changed("limit") {
+		config.Limit = evalLimit
+	}
+	if config.Limit < 0 {
+		return fmt.Errorf("--limit cannot be negative")
+	}
+	if evalConfigPath == "" || cmd.Flags().Changed("sample") {
+		config.Sample = evalSample
+	}
+	if evalConfigPath == "" || cmd.Flags().Changed("seed") {
+		config.Seed = evalSeed
+	}
+	if config.Sample < 0 {
+		return fmt.Errorf("--sample cannot be negative")
+	}
+	config.SampledRows = nil
+	if config.Sample > 0 {
+		if config.Seed == 0 {
+			config.Seed = rand.Int64N(math.MaxInt64) + 1
+		}
+		if config.SampledRows, err = sampledRows(config); err != nil {
+			return fmt.Errorf("failed to sample rows: %w", err)
+		}
+	}
+
+	if evalDryRun {
+		estimate, err := estimateEvaluation(config)
+		if err != nil {
+			return fmt.Errorf("dry run failed: %w", err)
+		}
+		printDryRun(os.Stdout, config, estimate)
+		return nil
+	}
+
 	if err := os.MkdirAll(evalsDir, 0755); err != nil {
 		return fmt.Errorf("failed to create evals directory: %w", err)
 	}
 
-	results, err := processEvaluation(config)
+	ext := evalFormat
+	if evalCompress {
+		ext += compressedEvalExt
+	}
+	outputPath := filepath.Join(evalsDir, evalFileName(config.Provider, config.Model)+"."+ext)
+	partialPath := outputPath + ".partial"
+
+	var existing []EvalResult
+	if resume {
+		// Runs interrupted before eval files were named by provider left
+		// their results under the model name alone
+		legacyPath := filepath.Join(evalsDir, strings.ReplaceAll(config.Model, ":", "_")+"."+ext)
+		existing, err = loadResumeResults(partialPath, outputPath, legacyPath+".partial", legacyPath)
+		if err != nil {
+			return fmt.Errorf("failed to load results to resume: %w", err)
+		}
+		fmt.Printf("Resuming with %d existing results\n", len(existing))
+	}
+
+	// The first Ctrl-C lets the current row finish and saves what has been
+	// done; a second one exits immediately.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	results, err := processEvaluation(ctx, config, existing, partialPath)
+	if errors.Is(err, context.Canceled) {
+		if err := saveEvalResults(EvalSummary{Config: config, Results: results}, partialPath); err != nil {
+			return fmt.Errorf("interrupted and failed to save partial results: %w", err)
+		}
+		fmt.Printf("\nInterrupted: saved %d partial results to %s. Rerun with --resume to continue.\n", len(results), partialPath)
+		return fmt.Errorf("evaluation interrupted")
+	}
 	if err != nil {
 		return fmt.Errorf("evaluation failed: %w", err)
 	}
@@ -318,30 +879,63 @@ func runEval(cmd *cobra.Command, args []string) error {
 		Results: results,
 	}
 
-	m := strings.ReplaceAll(config.Model, ":", "_")
-	outputPath := filepath.Join(evalsDir, fmt.Sprintf("%s.yaml", m))
 	if err := saveEvalResults(summary, outputPath); err != nil {
 		return fmt.Errorf("failed to save results: %w", err)
 	}
+	if err := os.Remove(partialPath); err != nil && !os.IsNotExist(err) {
+		slog.Warn("Failed to remove partial results", "path", partialPath, "err", err)
+	}
+
+	if config.Images != "" {
+		fmt.Printf("\nTranscribed %d images. Results saved to: %s\n", len(results), outputPath)
+		return nil
+	}
 
 	fmt.Printf("\nEvaluation completed. Results saved to: %s\n", outputPath)
-	printSummaryStats(results)
+	printSummaryStats(results, false)
 
-	return nil
+	return checkAccuracyThresholds(results, evalMinWordAccuracy, evalMinCharAccuracy)
 }
 
-func runSummary(cmd *cobra.Command, args []string) error {
-	evalsDir := "evals"
+// errBelowAccuracyThreshold marks a completed run whose average accuracy
+// is below --min-word-accuracy or --min-char-accuracy.
+var errBelowAccuracyThreshold = errors.New("accuracy below threshold")
+
+// checkAccuracyThresholds returns an error wrapping
+// errBelowAccuracyThreshold when the average word or character accuracy of
+// results is below its minimum. A minimum of 0 is not checked, and a run
+// without results fails any minimum that is set.
+func checkAccuracyThresholds(results []EvalResult, minWordAccuracy, minCharAccuracy float64) error {
+	wordAccs := make([]float64, len(results))
+	charAccs := make([]float64, len(results))
+	for i, result := range results {
+		wordAccs[i] = result.WordAccuracy
+		charAccs[i] = result.CharacterAccuracy
+	}
+
+	var failures []string
+	if wordAccuracy := htrmetrics.Summarize(wordAccs).Mean; minWordAccuracy > 0 && wordAccuracy < minWordAccuracy {
+		failures = append(failures, fmt.Sprintf("average word accuracy %.3f is below --min-word-accuracy %.3f", wordAccuracy, minWordAccuracy))
+	}
+	if charAccuracy := htrmetrics.Summarize(charAccs).Mean; minCharAccuracy > 0 && charAccuracy < minCharAccuracy {
+		failures = append(failures, fmt.Sprintf("average character accuracy %.3f is below --min-char-accuracy %.3f", charAccuracy, minCharAccuracy))
+	}
+	if len(failures) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", errBelowAccuracyThreshold, strings.Join(failures, "; "))
+}
 
+func runSummary(cmd *cobra.Command, args []string) error {
 	// If no argument provided, list available eval files
 	if len(args) == 0 {
-		files, err := filepath.Glob(filepath.Join(evalsDir, "*.yaml"))
+		files, err := listEvalFiles(evalsDir)
 		if err != nil {
 			return fmt.Errorf("failed to list eval files: %w", err)
 		}
 
 		if len(files) == 0 {
-			fmt.Println("No evaluation files found in evals/ directory.")
+			fmt.Printf("No evaluation files found in %s.\n", evalsDir)
 			return nil
 		}
 
@@ -354,24 +948,10 @@ func runSummary(cmd *cobra.Command, args []string) error {
 	}
 
 	// Load and display summary for specified file
-	evalFile := args[0]
-	if !strings.HasSuffix(evalFile, ".yaml") {
-		evalFile += ".yaml"
-	}
-
-	// If no path separator, assume it's in evals directory
-	if !strings.Contains(evalFile, string(filepath.Separator)) {
-		evalFile = filepath.Join(evalsDir, evalFile)
-	}
-
-	data, err := os.ReadFile(evalFile)
+	evalFile := resolveEvalFile(evalsDir, args[0])
+	summary, err := loadEvalSummary(evalFile)
 	if err != nil {
-		return fmt.Errorf("failed to read eval file %s: %w", evalFile, err)
-	}
-
-	var summary EvalSummary
-	if err := yaml.Unmarshal(data, &summary); err != nil {
-		return fmt.Errorf("failed to parse eval file: %w", err)
+		return err
 	}
 
 	// Display configuration
@@ -379,61 +959,78 @@ func runSummary(cmd *cobra.Command, args []string) error {
 	fmt.Printf("File: %s\n", filepath.Base(evalFile))
 	fmt.Printf("Provider: %s\n", summary.Config.Provider)
 	fmt.Printf("Model: %s\n", summary.Config.Model)
+	if summary.Config.PromptID != "" {
+		fmt.Printf("Prompt ID: %s\n", summary.Config.PromptID)
+	}
 	fmt.Printf("Temperature: %.1f\n", summary.Config.Temperature)
 	fmt.Printf("CSV Path: %s\n", summary.Config.CSVPath)
 	fmt.Printf("Timestamp: %s\n", summary.Config.Timestamp)
+	if summaryPublicOnly {
+		total := len(summary.Results)
+		summary.Results = publicResults(summary.Results)
+		fmt.Printf("Public Only: %d of %d rows\n", len(summary.Results), total)
+	}
 	fmt.Printf("Total Images Evaluated: %d\n", len(summary.Results))
 
 	// Display summary statistics
-	printSummaryStats(summary.Results)
+	printSummaryStats(summary.Results, summaryWeighted)
 
 	return nil
 }
 
 func runCSV(cmd *cobra.Command, args []string) error {
-	evalsDir := "evals"
-
-	// Find all YAML files
-	files, err := filepath.Glob(filepath.Join(evalsDir, "*.yaml"))
+	// Find all YAML and JSON files
+	files, err := listEvalFiles(evalsDir)
 	if err != nil {
 		return fmt.Errorf("failed to list eval files: %w", err)
 	}
 
 	if len(files) == 0 {
-		fmt.Println("No evaluation files found in evals/ directory.")
+		fmt.Printf("No evaluation files found in %s.\n", evalsDir)
 		return nil
 	}
 
+	priceTable, err := loadPriceTable(csvPriceFile)
+	if err != nil {
+		return err
+	}
+	explicitPrices := flagGiven(cmd.Flags(), "input-price") || flagGiven(cmd.Flags(), "output-price")
+
 	var modelSummaries []ModelSummary
 
 	// Process each file
 	for _, file := range files {
-		data, err := os.ReadFile(file)
+		summary, err := loadEvalSummary(file)
 		if err != nil {
-			fmt.Printf("Warning: failed to read %s: %v\n", file, err)
+			fmt.Printf("Warning: %v\n", err)
 			continue
 		}
-
-		var summary EvalSummary
-		if err := yaml.Unmarshal(data, &summary); err != nil {
-			fmt.Printf("Warning: failed to parse %s: %v\n", file, err)
-			continue
+		if csvPublicOnly {
+			summary.Results = publicResults(summary.Results)
 		}
 
 		if len(summary.Results) == 0 {
 			continue
 		}
 
-		// Get the original flags from config for backward compatibility
-		ignorePatterns := summary.Config.IgnorePatterns
-		if ignorePatterns == nil {
-			ignorePatterns = []string{}
+		// Score legacy rows with the options the evaluation was run with
+		ignoreRegex, err := compileIgnoreRegex(summary.Config.IgnoreRegex)
+		if err != nil {
+			fmt.Printf("Warning: skipping %s: %v\n", filepath.Base(file), err)
+			continue
+		}
+		options := htrmetrics.Options{
+			IgnorePatterns:     summary.Config.IgnorePatterns,
+			IgnoreRegex:        ignoreRegex,
+			SingleLine:         summary.Config.SingleLine,
+			LineBreakTolerance: summary.Config.LineBreakTolerance,
+			IgnoreCase:         summary.Config.IgnoreCase,
 		}
-		singleLine := summary.Config.SingleLine
 
 		// Calculate aggregated metrics
 		var totalCharSim, totalCharAcc, totalWordSim, totalWordAcc, totalWER float64
 		var totalInputTokens, totalOutputTokens int
+		var charAccs, wordAccs, wers []float64
 		for _, result := range summary.Results {
 			totalCharSim += result.CharacterSimilarity
 
@@ -442,16 +1039,18 @@ func runCSV(cmd *cobra.Command, args []string) error {
 			if charAcc == 0.0 && result.ProviderResponse != "" {
 				// Calculate on the fly using ground truth from TranscriptPath
 				// Use the original flags from the evaluation config
-				if groundTruth, err := readTextFile(result.TranscriptPath); err == nil {
-					metrics := CalculateAccuracyMetrics(groundTruth, result.ProviderResponse, ignorePatterns, singleLine)
-					charAcc = metrics.CharacterAccuracy
+				if groundTruth, err := readTextFile(context.Background(), result.TranscriptPath); err == nil {
+					charAcc = htrmetrics.Evaluate(groundTruth, result.ProviderResponse, options).CharacterAccuracy
 				}
 			}
 			totalCharAcc += charAcc
+			charAccs = append(charAccs, charAcc)
 
 			totalWordSim += result.WordSimilarity
 			totalWordAcc += result.WordAccuracy
 			totalWER += result.WordErrorRate
+			wordAccs = append(wordAccs, result.WordAccuracy)
+			wers = append(wers, result.WordErrorRate)
 			totalInputTokens += result.InputTokens
 			totalOutputTokens += result.OutputTokens
 		}
@@ -460,11 +1059,14 @@ func runCSV(cmd *cobra.Command, args []string) error {
 		avgInputTokens := float64(totalInputTokens) / count
 		avgOutputTokens := float64(totalOutputTokens) / count
 
-		// Calculate page cost if prices are provided
+		// Calculate page cost from explicit or known model prices
 		pageCost := 0.0
-		if csvInputPrice > 0 || csvOutputPrice > 0 {
-			inputCost := (avgInputTokens / 1_000_000) * csvInputPrice
-			outputCost := (avgOutputTokens / 1_000_000) * csvOutputPrice
+		reportsUsage := providerCapabilities(summary.Config.Provider).ReportsTokenUsage
+		price, priced := resolveTokenPrice(priceTable, summary.Config.Model, explicitPrices, csvInputPrice, csvOutputPrice)
+		priced = priced && reportsUsage
+		if priced {
+			inputCost := (avgInputTokens / 1_000_000) * price.Input
+			outputCost := (avgOutputTokens / 1_000_000) * price.Output
 			pageCost = inputCost + outputCost
 		}
 
@@ -481,6 +1083,28 @@ func runCSV(cmd *cobra.Command, args []string) error {
 			AvgInputTokens:    avgInputTokens,
 			AvgOutputTokens:   avgOutputTokens,
 			PageCost:          pageCost,
+			Unpriced:          !priced,
+			NoTokenUsage:      !reportsUsage,
+			TruncatedRows:     countTruncated(summary.Results),
+
+			CharAccuracyStats:  htrmetrics.Summarize(charAccs),
+			WordAccuracyStats:  htrmetrics.Summarize(wordAccs),
+			WordErrorRateStats: htrmetrics.Summarize(wers),
+		}
+		if csvWeighted {
+			modelSummary.WeightedWordAccuracy, modelSummary.WeightedWordErrorRate = weightedWordRates(summary.Results)
+			modelSummary.WeightedCharErrorRate = weightedCharErrorRate(summary.Results)
+			modelSummary.HasWeighted = true
+		}
+		if f1Scores := collectBagOfWordsF1(summary.Results); len(f1Scores) > 0 {
+			modelSummary.AvgBagOfWordsF1 = htrmetrics.Summarize(f1Scores).Mean
+			modelSummary.HasBagOfWords = true
+		}
+		if latencies := collectLatencies(summary.Results); len(latencies) > 0 {
+			stats := htrmetrics.Summarize(latencies)
+			modelSummary.AvgLatencyMS = stats.Mean
+			modelSummary.MedianLatencyMS = stats.Median
+			modelSummary.HasLatency = true
 		}
 
 		modelSummaries = append(modelSummaries, modelSummary)
@@ -502,63 +1126,177 @@ func runCSV(cmd *cobra.Command, args []string) error {
 		return 0
 	})
 
-	// Determine if we should include PageCost column
-	includeCost := csvInputPrice > 0 || csvOutputPrice > 0
+	// Include the PageCost column when any model could be priced
+	includeCost := slices.ContainsFunc(modelSummaries, func(ms ModelSummary) bool { return !ms.Unpriced })
+
+	header, rows := modelSummaryTable(modelSummaries, includeCost, csvVerbose)
+	if csvMarkdown {
+		writeMarkdownTable(os.Stdout, header, rows)
+		return nil
+	}
+
+	// Print TSV
+	fmt.Println(strings.Join(header, "\t"))
+	for _, row := range rows {
+		fmt.Println(strings.Join(row, "\t"))
+	}
+
+	return nil
+}
 
-	// Print TSV header
+// modelSummaryTable formats model summaries as a header and data rows shared
+// by the TSV and Markdown outputs of the csv command.
+func modelSummaryTable(modelSummaries []ModelSummary, includeCost, verbose bool) ([]string, [][]string) {
+	header := []string{"Model", "TotalEvaluations", "AvgCharSimilarity", "AvgCharAccuracy", "AvgWordSimilarity", "AvgWordAccuracy", "AvgWordErrorRate"}
+	includeWeighted := slices.ContainsFunc(modelSummaries, func(ms ModelSummary) bool { return ms.HasWeighted })
+	if includeWeighted {
+		header = append(header, "WeightedWordAccuracy", "WeightedWordErrorRate", "WeightedCharErrorRate")
+	}
+	includeBagOfWords := slices.ContainsFunc(modelSummaries, func(ms ModelSummary) bool { return ms.HasBagOfWords })
+	if includeBagOfWords {
+		header = append(header, "AvgBagOfWordsF1")
+	}
+	includeTruncated := slices.ContainsFunc(modelSummaries, func(ms ModelSummary) bool { return ms.TruncatedRows > 0 })
+	if includeTruncated {
+		header = append(header, "TruncatedRows")
+	}
+	includeLatency := slices.ContainsFunc(modelSummaries, func(ms ModelSummary) bool { return ms.HasLatency })
+	if includeLatency {
+		header = append(header, "AvgLatencyMS", "MedianLatencyMS")
+	}
 	if includeCost {
-		fmt.Println("Model\tTotalEvaluations\tAvgCharSimilarity\tAvgCharAccuracy\tAvgWordSimilarity\tAvgWordAccuracy\tAvgWordErrorRate\tAvgInputTokens\tAvgOutputTokens\tPageCost")
-	} else {
-		fmt.Println("Model\tTotalEvaluations\tAvgCharSimilarity\tAvgCharAccuracy\tAvgWordSimilarity\tAvgWordAccuracy\tAvgWordErrorRate")
+		header = append(header, "AvgInputTokens", "AvgOutputTokens", "PageCost")
+	}
+	if verbose {
+		for _, metric := range []string{"CharAccuracy", "WordAccuracy", "WordErrorRate"} {
+			header = append(header, "Median"+metric, "StdDev"+metric, "Min"+metric, "Max"+metric)
+		}
 	}
 
-	// Print TSV data
+	rows := make([][]string, 0, len(modelSummaries))
 	for _, ms := range modelSummaries {
+		row := []string{
+			ms.Model,
+			strconv.Itoa(ms.TotalEvaluations),
+			fmt.Sprintf("%.6f", ms.AvgCharSimilarity),
+			fmt.Sprintf("%.6f", ms.AvgCharAccuracy),
+			fmt.Sprintf("%.6f", ms.AvgWordSimilarity),
+			fmt.Sprintf("%.6f", ms.AvgWordAccuracy),
+			fmt.Sprintf("%.6f", ms.AvgWordErrorRate),
+		}
+		if includeWeighted {
+			accuracy, errorRate, charErrorRate := "", "", ""
+			if ms.HasWeighted {
+				accuracy = fmt.Sprintf("%.6f", ms.WeightedWordAccuracy)
+				errorRate = fmt.Sprintf("%.6f", ms.WeightedWordErrorRate)
+				charErrorRate = fmt.Sprintf("%.6f", ms.WeightedCharErrorRate)
+			}
+			row = append(row, accuracy, errorRate, charErrorRate)
+		}
+		if includeBagOfWords {
+			f1 := ""
+			if ms.HasBagOfWords {
+				f1 = fmt.Sprintf("%.6f", ms.AvgBagOfWordsF1)
+			}
+			row = append(row, f1)
+		}
+		if includeTruncated {
+			row = append(row, strconv.Itoa(ms.TruncatedRows))
+		}
+		if includeLatency {
+			avgLatency, medianLatency := "", ""
+			if ms.HasLatency {
+				avgLatency = fmt.Sprintf("%.0f", ms.AvgLatencyMS)
+				medianLatency = fmt.Sprintf("%.0f", ms.MedianLatencyMS)
+			}
+			row = append(row, avgLatency, medianLatency)
+		}
 		if includeCost {
-			fmt.Printf("%s\t%d\t%.6f\t%.6f\t%.6f\t%.6f\t%.6f\t%.2f\t%.2f\t%.6f\n",
-				ms.Model,
-				ms.TotalEvaluations,
-				ms.AvgCharSimilarity,
-				ms.AvgCharAccuracy,
-				ms.AvgWordSimilarity,
-				ms.AvgWordAccuracy,
-				ms.AvgWordErrorRate,
-				ms.AvgInputTokens,
-				ms.AvgOutputTokens,
-				ms.PageCost)
-		} else {
-			fmt.Printf("%s\t%d\t%.6f\t%.6f\t%.6f\t%.6f\t%.6f\n",
-				ms.Model,
-				ms.TotalEvaluations,
-				ms.AvgCharSimilarity,
-				ms.AvgCharAccuracy,
-				ms.AvgWordSimilarity,
-				ms.AvgWordAccuracy,
-				ms.AvgWordErrorRate)
+			inputTokens := fmt.Sprintf("%.2f", ms.AvgInputTokens)
+			outputTokens := fmt.Sprintf("%.2f", ms.AvgOutputTokens)
+			if ms.NoTokenUsage {
+				inputTokens, outputTokens = "", ""
+			}
+			pageCost := fmt.Sprintf("%.6f", ms.PageCost)
+			if ms.Unpriced {
+				pageCost = ""
+			}
+			row = append(row, inputTokens, outputTokens, pageCost)
+		}
+		if verbose {
+			for _, stats := range []htrmetrics.Stats{ms.CharAccuracyStats, ms.WordAccuracyStats, ms.WordErrorRateStats} {
+				row = append(row,
+					fmt.Sprintf("%.6f", stats.Median),
+					fmt.Sprintf("%.6f", stats.StdDev),
+					fmt.Sprintf("%.6f", stats.Min),
+					fmt.Sprintf("%.6f", stats.Max))
+			}
 		}
+		rows = append(rows, row)
 	}
+	return header, rows
+}
 
-	return nil
+// writeMarkdownTable renders a GitHub-flavored Markdown table with padded
+// columns. The first column is left-aligned and the numeric columns are
+// right-aligned.
+func writeMarkdownTable(w io.Writer, header []string, rows [][]string) {
+	widths := make([]int, len(header))
+	for column, cell := range header {
+		widths[column] = max(len(cell), 3)
+	}
+	for _, row := range rows {
+		for column, cell := range row {
+			widths[column] = max(widths[column], len(cell))
+		}
+	}
+
+	writeRow := func(cells []string) {
+		var line strings.Builder
+		line.WriteString("|")
+		for column, cell := range cells {
+			padding := strings.Repeat(" ", widths[column]-len(cell))
+			if column == 0 {
+				line.WriteString(" " + cell + padding + " |")
+			} else {
+				line.WriteString(" " + padding + cell + " |")
+			}
+		}
+		fmt.Fprintln(w, line.String())
+	}
+
+	writeRow(header)
+	separator := make([]string, len(header))
+	for column, width := range widths {
+		if column == 0 {
+			separator[column] = ":" + strings.Repeat("-", width-1)
+		} else {
+			separator[column] = strings.Repeat("-", width-1) + ":"
+		}
+	}
+	writeRow(separator)
+	for _, row := range rows {
+		writeRow(row)
+	}
 }
 
 func runBackfill(cmd *cobra.Command, args []string) error {
-	evalsDir := "evals"
-
-	// Find all YAML files
-	files, err := filepath.Glob(filepath.Join(evalsDir, "*.yaml"))
+	// Find all YAML and JSON files
+	files, err := listEvalFiles(evalsDir)
 	if err != nil {
 		return fmt.Errorf("failed to list eval files: %w", err)
 	}
 
 	if len(files) == 0 {
-		fmt.Println("No evaluation files found in evals/ directory.")
+		fmt.Printf("No evaluation files found in %s.\n", evalsDir)
 		return nil
 	}
 
 	// Check if user wants to override flags
-	hasIgnoreFlag := cmd.Flags().Changed("ignore")
-	hasSingleLineFlag := cmd.Flags().Changed("single-line")
-	useOverride := backfillOverride || hasIgnoreFlag || hasSingleLineFlag
+	hasIgnoreFlag := flagGiven(cmd.Flags(), "ignore")
+	hasSingleLineFlag := flagGiven(cmd.Flags(), "single-line")
+	hasIgnoreCaseFlag := flagGiven(cmd.Flags(), "ignore-case")
+	useOverride := backfillOverride || hasIgnoreFlag || hasSingleLineFlag || hasIgnoreCaseFlag
 
 	if useOverride {
 		fmt.Printf("Using CLI flags for recalculation:\n")
@@ -568,6 +1306,9 @@ func runBackfill(cmd *cobra.Command, args []string) error {
 		if hasSingleLineFlag {
 			fmt.Printf("  --single-line: %v\n", backfillSingleLine)
 		}
+		if hasIgnoreCaseFlag {
+			fmt.Printf("  --ignore-case: %v\n", backfillIgnoreCase)
+		}
 		fmt.Println()
 	}
 
@@ -576,15 +1317,9 @@ func runBackfill(cmd *cobra.Command, args []string) error {
 
 	// Process each file
 	for _, file := range files {
-		data, err := os.ReadFile(file)
+		summary, err := loadEvalSummary(file)
 		if err != nil {
-			fmt.Printf("Warning: failed to read %s: %v\n", file, err)
-			continue
-		}
-
-		var summary EvalSummary
-		if err := yaml.Unmarshal(data, &summary); err != nil {
-			fmt.Printf("Warning: failed to parse %s: %v\n", file, err)
+			fmt.Printf("Warning: %v\n", err)
 			continue
 		}
 
@@ -594,7 +1329,10 @@ func runBackfill(cmd *cobra.Command, args []string) error {
 
 		// Determine which flags to use
 		var ignorePatterns []string
+		var ignoreRegex []*regexp.Regexp
 		var singleLine bool
+		var lineBreakTolerance *float64
+		var ignoreCase bool
 
 		if useOverride {
 			// Use CLI flags (override)
@@ -608,6 +1346,11 @@ func runBackfill(cmd *cobra.Command, args []string) error {
 			} else {
 				singleLine = false
 			}
+			if hasIgnoreCaseFlag {
+				ignoreCase = backfillIgnoreCase
+			} else {
+				ignoreCase = false
+			}
 		} else {
 			// Use saved flags from config (default behavior)
 			ignorePatterns = summary.Config.IgnorePatterns
@@ -615,6 +1358,12 @@ func runBackfill(cmd *cobra.Command, args []string) error {
 				ignorePatterns = []string{}
 			}
 			singleLine = summary.Config.SingleLine
+			lineBreakTolerance = summary.Config.LineBreakTolerance
+			ignoreCase = summary.Config.IgnoreCase
+			if ignoreRegex, err = compileIgnoreRegex(summary.Config.IgnoreRegex); err != nil {
+				fmt.Printf("Warning: skipping %s: %v\n", filepath.Base(file), err)
+				continue
+			}
 		}
 
 		// Recalculate metrics for all results
@@ -625,20 +1374,32 @@ func runBackfill(cmd *cobra.Command, args []string) error {
 			}
 
 			// Read ground truth from stored path
-			groundTruth, err := readTextFile(summary.Results[i].TranscriptPath)
+			groundTruth, err := readTextFile(context.Background(), summary.Results[i].TranscriptPath)
 			if err != nil {
 				fmt.Printf("Warning: failed to read transcript %s: %v\n", summary.Results[i].TranscriptPath, err)
 				continue
 			}
 
 			// Recalculate all metrics
-			metrics := CalculateAccuracyMetrics(groundTruth, summary.Results[i].ProviderResponse, ignorePatterns, singleLine)
+			options := htrmetrics.Options{
+				IgnorePatterns:     ignorePatterns,
+				IgnoreRegex:        ignoreRegex,
+				SingleLine:         singleLine,
+				LineBreakTolerance: lineBreakTolerance,
+				IgnoreCase:         ignoreCase,
+			}
+			metrics := evalResultFromMetrics(htrmetrics.Evaluate(groundTruth, summary.Results[i].ProviderResponse, options))
+			if summary.Config.StoreNormalized && recordNormalized(&summary.Results[i], groundTruth, options) {
+				needsUpdate = true
+			}
 
 			// Check if we need to update any metrics
 			if summary.Results[i].CharacterAccuracy != metrics.CharacterAccuracy ||
-				summary.Results[i].WordSimilarity != metrics.WordSimilarity {
+				summary.Results[i].WordSimilarity != metrics.WordSimilarity ||
+				summary.Results[i].TotalCharsOriginal != metrics.TotalCharsOriginal {
 				summary.Results[i].CharacterAccuracy = metrics.CharacterAccuracy
 				summary.Results[i].WordSimilarity = metrics.WordSimilarity
+				summary.Results[i].TotalCharsOriginal = metrics.TotalCharsOriginal
 				needsUpdate = true
 			}
 		}
@@ -664,17 +1425,11 @@ func runBackfill(cmd *cobra.Command, args []string) error {
 }
 
 func loadEvalConfig(configPath string) (EvalConfig, error) {
-	var summary EvalSummary
-
-	data, err := os.ReadFile(configPath)
+	summary, err := loadEvalSummary(configPath)
 	if err != nil {
 		return EvalConfig{}, err
 	}
 
-	if err := yaml.Unmarshal(data, &summary); err != nil {
-		return EvalConfig{}, err
-	}
-
 	// Update timestamp for rerun
 	summary.Config.Timestamp = time.Now().Format("2006-01-02_15-04-05")
 
@@ -686,39 +1441,82 @@ func loadEvalConfig(configPath string) (EvalConfig, error) {
 	return summary.Config, nil
 }
 
-func processEvaluation(config EvalConfig) ([]EvalResult, error) {
-	// Read CSV file
-	file, err := os.Open(config.CSVPath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to open CSV file: %w", err)
+// loadResumeResults returns the results recorded by a previous run, preferring
+// the incremental .partial sidecar over the completed eval file.
+func loadResumeResults(paths ...string) ([]EvalResult, error) {
+	for _, path := range paths {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			continue
+		}
+
+		summary, err := loadEvalSummary(path)
+		if err != nil {
+			return nil, err
+		}
+		return summary.Results, nil
+	}
+	return nil, nil
+}
+
+// processEvaluation evaluates each selected CSV row. Rows whose identifier
+// already appears in existing are skipped, and after every new result the
+// accumulated results are written to partialPath so an interrupted run can
+// be resumed. When ctx is canceled it stops before the next row and returns
+// the results so far along with ctx's error.
+func processEvaluation(ctx context.Context, config EvalConfig, existing []EvalResult, partialPath string) ([]EvalResult, error) {
+	var prompt *template.Template
+	if config.PromptTemplate != "" {
+		var err error
+		if prompt, err = parsePromptTemplate(config.PromptTemplate); err != nil {
+			return nil, err
+		}
 	}
-	defer file.Close()
 
-	reader := csv.NewReader(file)
-	records, err := reader.ReadAll()
+	process := processRow
+	var dataRows [][]string
+	var columns []string
+	var err error
+	if config.Images != "" {
+		process = processImageRow
+		dataRows, err = readImageRows(config.Images)
+	} else {
+		dataRows, columns, err = readEvalRows(config.CSVPath)
+	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to read CSV: %w", err)
+		return nil, err
+	}
+
+	if config.TestRows, err = selectRows(config, dataRows); err != nil {
+		return nil, err
 	}
 
-	if len(records) == 0 {
-		return nil, fmt.Errorf("CSV file is empty")
+	results := slices.Clone(existing)
+	completed := make(map[string]bool, len(existing))
+	for _, result := range existing {
+		completed[result.Identifier] = true
 	}
 
-	// Skip header row if present
-	dataRows := records
-	if len(records) > 0 && strings.EqualFold(records[0][0], "image") {
-		dataRows = records[1:]
+	var duplicates map[int]int
+	if config.Dedupe {
+		duplicates = duplicateRows(dataRows, config.TestRows)
 	}
 
-	if len(config.TestRows) == 0 {
-		config.TestRows = []int{}
-		for i := 0; i < len(dataRows); i++ {
-			config.TestRows = append(config.TestRows, i)
+	pending := 0
+	for i, row := range dataRows {
+		if _, duplicate := duplicates[i]; duplicate {
+			continue
+		}
+		if slices.Contains(config.TestRows, i) && len(row) >= 3 && !completed[rowIdentifier(row)] {
+			pending++
 		}
 	}
+	progress := newEvalProgress(pending)
+	defer progress.Finish()
 
-	var results []EvalResult
 	for i, row := range dataRows {
+		if err := ctx.Err(); err != nil {
+			return results, err
+		}
 		if !slices.Contains(config.TestRows, i) {
 			slog.Warn("Skipping row", "row", i+1)
 			continue
@@ -727,8 +1525,29 @@ func processEvaluation(config EvalConfig) ([]EvalResult, error) {
 			slog.Warn("Insufficient columns", "row", i+1)
 			continue
 		}
+		if first, duplicate := duplicates[i]; duplicate {
+			slog.Warn("Skipping duplicate row", "row", i+1, "first_row", first+1, "image", strings.TrimSpace(row[0]))
+			continue
+		}
+		if completed[rowIdentifier(row)] {
+			slog.Info("Skipping row with existing result", "row", i+1, "identifier", rowIdentifier(row))
+			continue
+		}
 
-		result, err := processRow(row, config)
+		rowConfig := config
+		if prompt != nil {
+			if rowConfig.Prompt, err = renderPrompt(prompt, promptVariables(row, columns)); err != nil {
+				return nil, fmt.Errorf("row %d: %w", i+1, err)
+			}
+		}
+
+		// Canceling ctx stops the run between rows, so the row in flight
+		// finishes rather than failing partway through its request
+		result, err := process(context.WithoutCancel(ctx), row, rowConfig)
+		progress.Clear()
+		if err != nil && (evalFailFast || isFatalEvalError(err)) {
+			return results, fmt.Errorf("row %d: %w", i+1, utils.MaskSensitiveError(err))
+		}
 		if err != nil {
 			errMsg := utils.MaskSensitiveError(err)
 			formattedErr, formatErr := formatErrorToPlaintext(errMsg.Error())
@@ -745,50 +1564,343 @@ func processEvaluation(config EvalConfig) ([]EvalResult, error) {
 				"err", formattedErr,
 			)
 
+			progress.Step()
 			continue
 		}
 
 		results = append(results, result)
+		if partialPath != "" {
+			if err := saveEvalResults(EvalSummary{Config: config, Results: results}, partialPath); err != nil {
+				slog.Warn("Failed to save partial results", "path", partialPath, "err", err)
+			}
+		}
 
-		printRowResult(result)
+		if !evalQuiet {
+			printRowResult(result)
+		}
+		progress.Step()
 	}
 
 	return results, nil
 }
 
-func processRow(row []string, config EvalConfig) (EvalResult, error) {
-	imagePath := filepath.Join(dir, strings.TrimSpace(row[0]))
-	transcriptPath := filepath.Join(dir, strings.TrimSpace(row[1]))
-	publicStr := strings.TrimSpace(row[2])
+// errInvalidProviderConfig marks a provider configuration that fails
+// validation, such as a missing API key.
+var errInvalidProviderConfig = errors.New("invalid configuration")
 
-	public, err := strconv.ParseBool(publicStr)
-	if err != nil && publicStr != "0" && publicStr != "1" {
-		return EvalResult{}, fmt.Errorf("invalid public value: %s", publicStr)
+// isFatalEvalError reports whether a row error would repeat for every
+// remaining row: the provider configuration is invalid or the provider
+// rejected the credentials. These end the run even without --fail-fast.
+func isFatalEvalError(err error) bool {
+	if errors.Is(err, errInvalidProviderConfig) {
+		return true
 	}
-	if publicStr == "1" {
-		public = true
+	return errors.Is(err, providers.ErrAuth)
+}
+
+// selectRows returns the indices of the rows to evaluate: those chosen by
+// config.RowSpec or config.TestRows, or every row when neither is set. When
+// config.Sample is set, that many are drawn at random with config.Seed, and
+// the result is cut to the first config.Limit in input order when a limit is
+// set. Rows missing the image, transcript, and public columns do not count
+// toward the sample or the limit.
+func selectRows(config EvalConfig, dataRows [][]string) ([]int, error) {
+	testRows := config.TestRows
+	if config.RowSpec != "" {
+		var err error
+		if testRows, err = expandRows(config.RowSpec, len(dataRows)); err != nil {
+			return nil, err
+		}
+		if len(testRows) == 0 {
+			return nil, nil
+		}
 	}
 
-	groundTruth, err := readTextFile(transcriptPath)
-	if err != nil {
-		return EvalResult{}, fmt.Errorf("failed to read transcript: %w", err)
+	var selected []int
+	for i, row := range dataRows {
+		if len(testRows) > 0 && !slices.Contains(testRows, i) {
+			continue
+		}
+		if (config.Limit > 0 || config.Sample > 0) && len(row) < 3 {
+			continue
+		}
+		selected = append(selected, i)
+	}
+	if config.Sample > 0 {
+		selected = sampleRows(selected, config.Sample, config.Seed)
+		slog.Info("Sampling rows", "sample", config.Sample, "seed", config.Seed, "rows", len(selected))
 	}
+	if config.Limit > 0 {
+		selected = selected[:min(config.Limit, len(selected))]
+		slog.Info("Limiting rows", "limit", config.Limit, "rows", len(selected))
+	}
+	return selected, nil
+}
 
-	imageBase64, err := getImageAsBase64(imagePath)
-	if err != nil {
-		return EvalResult{}, fmt.Errorf("failed to process image: %w", err)
+// sampleRows returns n of rows drawn at random with seed, in input order. The
+// same rows and seed always draw the same sample.
+func sampleRows(rows []int, n int, seed int64) []int {
+	if n >= len(rows) {
+		return rows
 	}
+	sample := slices.Clone(rows)
+	random := rand.New(rand.NewPCG(uint64(seed), 0))
+	random.Shuffle(len(sample), func(i, j int) { sample[i], sample[j] = sample[j], sample[i] })
+	sample = sample[:n]
+	slices.Sort(sample)
+	return sample
+}
 
-	providerResponse, usage, err := extractTextWithProvider(config, imagePath, imageBase64)
+// sampledRows reads the input of config and returns the rows config.Sample
+// draws from it, before any limit, for recording in the eval file.
+func sampledRows(config EvalConfig) ([]int, error) {
+	config.Limit = 0
+	var dataRows [][]string
+	var err error
+	if config.Images != "" {
+		dataRows, err = readImageRows(config.Images)
+	} else {
+		dataRows, _, err = readEvalRows(config.CSVPath)
+	}
 	if err != nil {
-		return EvalResult{}, fmt.Errorf("provider API call failed: %w", err)
+		return nil, err
 	}
+	return selectRows(config, dataRows)
+}
 
-	metrics := CalculateAccuracyMetrics(groundTruth, providerResponse, ignorePatterns, singleLine)
-
-	result := EvalResult{
+// expandRows expands a --rows spec into the sorted, distinct indices it
+// selects from an input of total rows. Each comma-separated term is an index,
+// an inclusive range such as 10-50, an open-ended range such as 10- that runs
+// to the last row, or -N for the last N rows.
+func expandRows(spec string, total int) ([]int, error) {
+	selected := make(map[int]bool)
+	for _, term := range strings.Split(spec, ",") {
+		start, end, err := parseRowTerm(strings.TrimSpace(term), total)
+		if err != nil {
+			return nil, err
+		}
+		for i := start; i <= end; i++ {
+			selected[i] = true
+		}
+	}
+	return slices.Sorted(maps.Keys(selected)), nil
+}
+
+// parseRowTerm returns the first and last index one --rows term selects. An
+// index past the last row is an error, but -N takes every row when the input
+// has fewer than N.
+func parseRowTerm(term string, total int) (start, end int, err error) {
+	if last, ok := strings.CutPrefix(term, "-"); ok {
+		n, err := strconv.Atoi(last)
+		if err != nil || n < 1 {
+			return 0, 0, fmt.Errorf("invalid --rows term %q: use -N for the last N rows", term)
+		}
+		return max(total-n, 0), total - 1, nil
+	}
+
+	first, rest, isRange := strings.Cut(term, "-")
+	if start, err = strconv.Atoi(first); err != nil || start < 0 {
+		return 0, 0, fmt.Errorf("invalid --rows term %q: use an index, a range such as 10-50, or -N", term)
+	}
+	end = start
+	if isRange {
+		if rest == "" {
+			end = total - 1
+		} else if end, err = strconv.Atoi(rest); err != nil || end < 0 {
+			return 0, 0, fmt.Errorf("invalid --rows term %q: use an index, a range such as 10-50, or -N", term)
+		}
+	}
+	if start >= total {
+		return 0, 0, fmt.Errorf("--rows term %q is out of range: the input has %d rows, numbered from 0", term, total)
+	}
+	if end < start {
+		return 0, 0, fmt.Errorf("--rows range %q ends before it starts", term)
+	}
+	if end >= total {
+		return 0, 0, fmt.Errorf("--rows term %q is out of range: the input has %d rows, numbered from 0", term, total)
+	}
+	return start, end, nil
+}
+
+// duplicateRows maps the index of each selected row that repeats an earlier
+// selected row's image and transcript to the index of that first row.
+func duplicateRows(dataRows [][]string, testRows []int) map[int]int {
+	first := make(map[[2]string]int)
+	duplicates := make(map[int]int)
+	for i, row := range dataRows {
+		if !slices.Contains(testRows, i) || len(row) < 3 {
+			continue
+		}
+		key := [2]string{strings.TrimSpace(row[0]), strings.TrimSpace(row[1])}
+		if j, ok := first[key]; ok {
+			duplicates[i] = j
+			continue
+		}
+		first[key] = i
+	}
+	return duplicates
+}
+
+// processImageRow transcribes the image in row[0] without ground truth, so
+// the result carries the response and usage but no accuracy metrics.
+func processImageRow(ctx context.Context, row []string, config EvalConfig) (EvalResult, error) {
+	imagePath := strings.TrimSpace(row[0])
+
+	imageBase64, image, err := getImageAsBase64(ctx, config, imagePath)
+	if err != nil {
+		return EvalResult{}, fmt.Errorf("failed to process image: %w", err)
+	}
+
+	extracted, err := extractTextAndLogprobs(ctx, config, imagePath, imageBase64)
+	if err != nil {
+		return EvalResult{}, fmt.Errorf("provider API call failed: %w", err)
+	}
+
+	result := EvalResult{
 		Identifier:            filepath.Base(imagePath),
 		ImagePath:             imagePath,
+		ProviderResponse:      extracted.Text,
+		TotalWordsTranscribed: len(strings.Fields(extracted.Text)),
+		InputTokens:           extracted.Usage.InputTokens,
+		OutputTokens:          extracted.Usage.OutputTokens,
+		PageCount:             extracted.Usage.Pages,
+		Truncated:             extracted.Usage.Truncated,
+		EmptyResponse:         isEmptyResponse(extracted.Text),
+		LatencyMS:             extracted.Latency.Milliseconds(),
+		Cached:                extracted.Cached,
+		WordConfidences:       wordConfidences(extracted.Logprobs),
+	}
+	recordImageSize(&result, image)
+	return result, nil
+}
+
+// pageSeparator joins the responses for the pages of a multi-page row.
+const pageSeparator = "\n"
+
+// splitImagePaths returns the page images listed in an image column, which
+// holds one path or several separated by ";" in reading order, resolved
+// against baseDir.
+func splitImagePaths(baseDir, column string) []string {
+	var paths []string
+	for _, path := range strings.Split(column, ";") {
+		if path = strings.TrimSpace(path); path != "" {
+			paths = append(paths, resolveInputPath(baseDir, path))
+		}
+	}
+	if len(paths) == 0 {
+		return []string{resolveInputPath(baseDir, "")}
+	}
+	return paths
+}
+
+// rowIdentifier returns the identifier of an input row's result: the file
+// name of its first page image.
+func rowIdentifier(row []string) string {
+	first, _, _ := strings.Cut(row[0], ";")
+	return filepath.Base(strings.TrimSpace(first))
+}
+
+// splitTranscriptPaths returns the transcripts listed in a transcript
+// column, which holds one path or several separated by "|", resolved against
+// baseDir.
+func splitTranscriptPaths(baseDir, column string) []string {
+	var paths []string
+	for _, path := range strings.Split(column, "|") {
+		if path = strings.TrimSpace(path); path != "" {
+			paths = append(paths, resolveInputPath(baseDir, path))
+		}
+	}
+	if len(paths) == 0 {
+		return []string{resolveInputPath(baseDir, "")}
+	}
+	return paths
+}
+
+// processRow transcribes and scores one input row. A row with several page
+// images is transcribed page by page, and the responses are joined in order
+// and scored against the row's transcript as one text, with token usage and
+// latency summed across pages.
+func processRow(ctx context.Context, row []string, config EvalConfig) (EvalResult, error) {
+	imagePaths := splitImagePaths(dir, row[0])
+	transcriptPaths := splitTranscriptPaths(dir, row[1])
+	publicStr := strings.TrimSpace(row[2])
+
+	public, err := strconv.ParseBool(publicStr)
+	if err != nil && publicStr != "0" && publicStr != "1" {
+		return EvalResult{}, fmt.Errorf("invalid public value: %s", publicStr)
+	}
+	if publicStr == "1" {
+		public = true
+	}
+
+	groundTruths := make([]string, len(transcriptPaths))
+	for i, transcriptPath := range transcriptPaths {
+		if groundTruths[i], err = readTextFile(ctx, transcriptPath); err != nil {
+			return EvalResult{}, fmt.Errorf("failed to read transcript: %w", err)
+		}
+	}
+	ignoreRegex, err := compileIgnoreRegex(config.IgnoreRegex)
+	if err != nil {
+		return EvalResult{}, err
+	}
+
+	var image imaging.Result
+	var usage providers.UsageInfo
+	var latency time.Duration
+	cached := true
+	var confidences []WordConfidence
+	responses := make([]string, len(imagePaths))
+	for i, imagePath := range imagePaths {
+		imageBase64, pageImage, err := getImageAsBase64(ctx, config, imagePath)
+		if err != nil {
+			return EvalResult{}, fmt.Errorf("failed to process image: %w", err)
+		}
+		if i == 0 {
+			image = pageImage
+		}
+
+		extracted, err := extractTextAndLogprobs(ctx, config, imagePath, imageBase64)
+		if err != nil {
+			return EvalResult{}, fmt.Errorf("provider API call failed: %w", err)
+		}
+		latency += extracted.Latency
+		cached = cached && extracted.Cached
+		responses[i] = extracted.Text
+		confidences = append(confidences, wordConfidences(extracted.Logprobs)...)
+		pageUsage := extracted.Usage
+		usage.InputTokens += pageUsage.InputTokens
+		usage.OutputTokens += pageUsage.OutputTokens
+		usage.Pages += pageUsage.Pages
+		usage.Truncated = usage.Truncated || pageUsage.Truncated
+	}
+	providerResponse := strings.Join(responses, pageSeparator)
+
+	options := htrmetrics.Options{
+		IgnorePatterns:     ignorePatterns,
+		IgnoreRegex:        ignoreRegex,
+		SingleLine:         singleLine,
+		LineBreakTolerance: config.LineBreakTolerance,
+		IgnoreCase:         config.IgnoreCase,
+		Alignment:          evalShowDiff,
+		BLEU:               config.BLEU,
+		BagOfWords:         config.BagOfWords,
+		SortWords:          config.SortWords,
+	}
+	// With several references, score against the one the response is
+	// closest to; ties go to the earlier reference
+	best := 0
+	evaluated := htrmetrics.Evaluate(groundTruths[0], providerResponse, options)
+	for i := 1; i < len(groundTruths); i++ {
+		if candidate := htrmetrics.Evaluate(groundTruths[i], providerResponse, options); candidate.WordAccuracy > evaluated.WordAccuracy {
+			best, evaluated = i, candidate
+		}
+	}
+	groundTruth, transcriptPath := groundTruths[best], transcriptPaths[best]
+	metrics := evalResultFromMetrics(evaluated)
+
+	result := EvalResult{
+		Identifier:            filepath.Base(imagePaths[0]),
+		ImagePath:             strings.Join(imagePaths, ";"),
 		TranscriptPath:        transcriptPath,
 		Public:                public,
 		ProviderResponse:      providerResponse,
@@ -799,6 +1911,7 @@ func processRow(row []string, config EvalConfig) (EvalResult, error) {
 		WordErrorRate:         metrics.WordErrorRate,
 		TotalWordsOriginal:    metrics.TotalWordsOriginal,
 		TotalWordsTranscribed: metrics.TotalWordsTranscribed,
+		TotalCharsOriginal:    metrics.TotalCharsOriginal,
 		CorrectWords:          metrics.CorrectWords,
 		Substitutions:         metrics.Substitutions,
 		Deletions:             metrics.Deletions,
@@ -806,103 +1919,546 @@ func processRow(row []string, config EvalConfig) (EvalResult, error) {
 		IgnoredCharsCount:     metrics.IgnoredCharsCount,
 		InputTokens:           usage.InputTokens,
 		OutputTokens:          usage.OutputTokens,
+		PageCount:             usage.Pages,
+		Truncated:             usage.Truncated,
+		EmptyResponse:         isEmptyResponse(providerResponse),
+		LatencyMS:             latency.Milliseconds(),
+		Cached:                cached,
+		WordConfidences:       confidences,
+	}
+	recordImageSize(&result, image)
+	if len(transcriptPaths) > 1 {
+		result.References = transcriptPaths
+		result.Reference = best + 1
+	}
+	if config.BLEU {
+		result.BLEUScore = &evaluated.BLEU
+	}
+	if config.BagOfWords {
+		result.BagOfWords = &evaluated.BagOfWords
+	}
+	if config.SortWords {
+		result.SortedWords = &evaluated.SortedWords
+	}
+	if config.StoreNormalized {
+		recordNormalized(&result, groundTruth, options)
+	}
+	if config.PerLine {
+		result.LineResults = htrmetrics.EvaluateLines(groundTruth, providerResponse, htrmetrics.Options{
+			IgnorePatterns: ignorePatterns,
+			IgnoreRegex:    ignoreRegex,
+			IgnoreCase:     config.IgnoreCase,
+		})
+	}
+	if evalShowDiff {
+		result.WordDiff = formatWordDiff(evaluated)
 	}
 
 	return result, nil
 }
 
-func readTextFile(path string) (string, error) {
-	// Check if it's a URL
-	if strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://") {
-		resp, err := http.Get(path)
-		if err != nil {
-			return "", err
-		}
-		defer resp.Body.Close()
+// recordNormalized sets the normalized ground truth and response of result to
+// the strings options compares, or clears them when normalization changed
+// neither text. It reports whether the stored strings changed.
+func recordNormalized(result *EvalResult, groundTruth string, options htrmetrics.Options) bool {
+	truth, response, _ := htrmetrics.Normalize(groundTruth, result.ProviderResponse, options)
+	if truth == groundTruth && response == result.ProviderResponse {
+		truth, response = "", ""
+	}
+	changed := truth != result.NormalizedGroundTruth || response != result.NormalizedResponse
+	result.NormalizedGroundTruth, result.NormalizedResponse = truth, response
+	return changed
+}
 
-		data, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return "", err
-		}
-		return string(data), nil
+// objectStore reads s3:// and gs:// inputs with credentials from the
+// environment. Tests replace it to avoid network calls.
+var objectStore = &objectstore.Store{}
+
+// fetchBytes reads a local file, an http(s) URL, or an s3:// or gs:// object.
+// Remote reads stop when ctx is done.
+func fetchBytes(ctx context.Context, uri string) ([]byte, error) {
+	switch {
+	case objectstore.IsURI(uri):
+		return objectStore.Fetch(ctx, uri)
+	case strings.HasPrefix(uri, "http://") || strings.HasPrefix(uri, "https://"):
+		return fetchURL(ctx, uri)
+	default:
+		return os.ReadFile(uri)
 	}
+}
 
-	// Local file
-	data, err := os.ReadFile(path)
+// fetchURL downloads uri, giving up after --fetch-timeout and refusing bodies
+// larger than --max-fetch-mb so a stalled or huge download cannot hang the run
+// or exhaust memory. A response other than 2xx is an error, so an error page
+// is never scored or sent as an image.
+func fetchURL(ctx context.Context, uri string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, uri, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", uri, err)
+	}
+	client := &http.Client{Timeout: fetchTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", uri, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("failed to fetch %s: %s", uri, resp.Status)
+	}
+
+	limit := int64(maxFetchMB) << 20
+	data, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", uri, err)
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("%s is larger than the %d MB limit; raise --max-fetch-mb to allow it", uri, maxFetchMB)
+	}
+	return data, nil
+}
+
+// resolveInputPath joins a path from an input file onto base, leaving URLs
+// and object URIs untouched.
+func resolveInputPath(base, path string) string {
+	if isRemoteResource(path) {
+		return path
+	}
+	return filepath.Join(base, path)
+}
+
+func readTextFile(ctx context.Context, path string) (string, error) {
+	data, err := fetchBytes(ctx, path)
 	if err != nil {
 		return "", err
 	}
 	return string(data), nil
 }
 
-func getImageAsBase64(imagePath string) (string, error) {
-	var imageData []byte
-	var err error
+// getImageAsBase64 reads a local, remote, or cloud storage image and returns
+// it base64 encoded. When maxDimension is positive, larger images are
+// downscaled first and the returned result reports the original and sent
+// dimensions.
+func getImageAsBase64(ctx context.Context, config EvalConfig, imagePath string) (string, imaging.Result, error) {
+	imageData, err := fetchBytes(ctx, imagePath)
+	if err != nil {
+		return "", imaging.Result{}, err
+	}
+	if imageData, err = rasterizeImage(imageData, config.Page); err != nil {
+		return "", imaging.Result{}, fmt.Errorf("failed to rasterize %s: %w", filepath.Base(imagePath), err)
+	}
+	if err := checkImageFormat(config.Provider, imagePath, imageData); err != nil {
+		return "", imaging.Result{}, err
+	}
 
-	// Check if it's a URL
-	if strings.HasPrefix(imagePath, "http://") || strings.HasPrefix(imagePath, "https://") {
-		resp, err := http.Get(imagePath)
-		if err != nil {
-			return "", err
-		}
-		defer resp.Body.Close()
+	image, err := imaging.Downscale(imageData, config.MaxDimension)
+	if err != nil {
+		return "", imaging.Result{}, fmt.Errorf("failed to downscale image: %w", err)
+	}
 
-		imageData, err = io.ReadAll(resp.Body)
-		if err != nil {
-			return "", err
-		}
-	} else {
-		// Local file
-		imageData, err = os.ReadFile(imagePath)
-		if err != nil {
-			return "", err
+	return base64.StdEncoding.EncodeToString(image.Data), image, nil
+}
+
+// checkImageFormat sniffs the format of an image and returns an error that
+// suggests a conversion when the provider does not accept it.
+func checkImageFormat(provider, imagePath string, data []byte) error {
+	registered, err := providerRegistry.Get(provider)
+	if err != nil {
+		return nil
+	}
+	limited, ok := registered.(providers.MediaTypeProvider)
+	if !ok {
+		return nil
+	}
+	accepted := limited.MediaTypes()
+	mediaType := providers.DetectMediaType(data)
+	if slices.Contains(accepted, mediaType) {
+		return nil
+	}
+
+	name := filepath.Base(imagePath)
+	if strings.HasPrefix(mediaType, "image/") {
+		format := strings.ToUpper(strings.TrimPrefix(mediaType, "image/"))
+		return fmt.Errorf("%s is a %s image, which %s does not accept; convert it to PNG or JPEG first, e.g. magick '%s[0]' page.png", name, format, provider, name)
+	}
+	return fmt.Errorf("%s is not a supported image (detected %s); %s accepts %s", name, mediaType, provider, strings.Join(accepted, ", "))
+}
+
+// recordImageSize copies the dimensions from a --max-dimension run onto result.
+func recordImageSize(result *EvalResult, image imaging.Result) {
+	if image.Original == (imaging.Dimensions{}) {
+		return
+	}
+	result.OriginalSize = image.Original.String()
+	result.SentSize = image.Sent.String()
+}
+
+// defaultOllamaKeepAlive keeps an Ollama model loaded between the rows of a
+// run, so each request does not pay to reload it.
+const defaultOllamaKeepAlive = "5m"
+
+// validateOllamaOptions checks --ollama-keep-alive, which Ollama reads as a
+// duration or a number of seconds, and --ollama-num-ctx.
+func validateOllamaOptions(keepAlive string, numCtx int) error {
+	if keepAlive = strings.TrimSpace(keepAlive); keepAlive != "" {
+		if _, err := strconv.Atoi(keepAlive); err != nil {
+			if _, err := time.ParseDuration(keepAlive); err != nil {
+				return fmt.Errorf("invalid --ollama-keep-alive %q: use a duration such as 30m or a number of seconds", keepAlive)
+			}
 		}
 	}
+	if numCtx < 0 {
+		return fmt.Errorf("--ollama-num-ctx cannot be negative")
+	}
+	return nil
+}
 
-	return base64.StdEncoding.EncodeToString(imageData), nil
+// validateOpenAIDetail checks --openai-detail, which is empty or one of the
+// detail levels OpenAI accepts, and only given to a provider that sends it.
+func validateOpenAIDetail(provider, detail string) error {
+	if detail == "" {
+		return nil
+	}
+	if !slices.Contains(allowedOpenAIDetails, detail) {
+		return fmt.Errorf("invalid --openai-detail value '%s'. Allowed values are: %s", detail, strings.Join(allowedOpenAIDetails, ", "))
+	}
+	if !slices.Contains(openAIDetailProviders, provider) {
+		return fmt.Errorf("--openai-detail is not supported by provider %s. Supported providers are: %s", provider, strings.Join(openAIDetailProviders, ", "))
+	}
+	return nil
 }
 
+// requestLimiter paces the provider requests of extractTextWithProvider. It
+// is nil, which does not limit, unless eval sets --rpm.
+var requestLimiter *providers.RateLimiter
+
 // extractTextWithProvider extracts text using the appropriate provider
-func extractTextWithProvider(config EvalConfig, imagePath, imageBase64 string) (string, providers.UsageInfo, error) {
+func extractTextWithProvider(ctx context.Context, config EvalConfig, imagePath, imageBase64 string) (string, providers.UsageInfo, error) {
+	extracted, err := extractTextAndLogprobs(ctx, config, imagePath, imageBase64)
+	return extracted.Text, extracted.Usage, err
+}
+
+// extraction is the outcome of one image's transcription.
+type extraction struct {
+	Text     string
+	Usage    providers.UsageInfo
+	Logprobs []providers.TokenLogprob
+	// Latency is the time spent in provider calls, summed over retries.
+	// Rate limiting and retry backoff are not counted.
+	Latency time.Duration
+	// Cached is set when the response came from the response cache, which
+	// takes no provider call and so has no latency.
+	Cached bool
+}
+
+// extractTextAndLogprobs is extractTextWithProvider that also returns the
+// response's token log probabilities when config.Logprobs is set and the
+// provider implements providers.LogprobProvider, and the provider latency.
+// Cached responses have no log probabilities.
+func extractTextAndLogprobs(ctx context.Context, config EvalConfig, imagePath, imageBase64 string) (extraction, error) {
 	// Get provider from registry
 	provider, err := providerRegistry.Get(config.Provider)
 	if err != nil {
-		return "", providers.UsageInfo{}, fmt.Errorf("unsupported provider: %s", config.Provider)
+		return extraction{}, fmt.Errorf("unsupported provider: %s", config.Provider)
+	}
+	// Strip patterns apply after the cache, so changing them needs no new
+	// requests
+	strip, err := compileStripPatterns(config.StripPatterns)
+	if err != nil {
+		return extraction{}, err
 	}
 
 	// Convert EvalConfig to providers.Config
 	providerConfig := providers.Config{
 		Provider:              config.Provider,
 		Model:                 config.Model,
-		Prompt:                config.Prompt,
+		Prompt:                appendPromptSuffix(config.Prompt, config.PromptSuffix),
+		SystemPrompt:          config.SystemPrompt,
 		Temperature:           config.Temperature,
 		Timeout:               config.Timeout,
 		Debug:                 config.Debug,
 		MaxResolution:         config.MaxResolution,
 		MaxResolutionFallback: config.MaxResolutionFallback,
+		MaxTokens:             config.MaxTokens,
+		PollInterval:          config.PollInterval,
+		KeepAlive:             config.OllamaKeepAlive,
+		NumCtx:                config.OllamaNumCtx,
+		ImageDetail:           config.OpenAIDetail,
+	}
+
+	// Serve repeated requests from the response cache without a network call
+	var cacheKey string
+	if config.CacheDir != "" {
+		cacheKey = responseCacheKey(config, imageBase64)
+		cached, ok, err := loadCachedResponse(config.CacheDir, cacheKey)
+		if err != nil {
+			slog.Warn("Failed to read cached response", "image", filepath.Base(imagePath), "err", err)
+		} else if ok {
+			slog.Debug("Using cached response", "image", filepath.Base(imagePath))
+			return extraction{Text: providers.StripResponse(cached.Text, strip), Usage: cached.Usage, Cached: true}, nil
+		}
 	}
 
 	// Validate configuration
 	if err := provider.ValidateConfig(providerConfig); err != nil {
-		return "", providers.UsageInfo{}, fmt.Errorf("invalid configuration for provider %s: %w", config.Provider, err)
+		return extraction{}, fmt.Errorf("%w for provider %s: %w", errInvalidProviderConfig, config.Provider, err)
+	}
+
+	// Extract text using the provider, retrying transient failures
+	var extracted extraction
+	logprobProvider, withLogprobs := provider.(providers.LogprobProvider)
+	withLogprobs = withLogprobs && config.Logprobs
+	policy := providers.RetryPolicy{
+		MaxRetries: config.MaxRetries,
+		BaseDelay:  config.RetryBaseDelay,
+		OnRetry: func(attempt int, delay time.Duration, err error) {
+			slog.Warn("Retrying provider request",
+				"provider", config.Provider,
+				"image", filepath.Base(imagePath),
+				"attempt", attempt,
+				"delay", delay,
+				"err", utils.MaskSensitiveError(err),
+			)
+		},
+	}
+	// config.Timeout bounds the attempts and the backoff between them
+	if config.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, config.Timeout)
+		defer cancel()
+	}
+	err = providers.Retry(ctx, policy, func(ctx context.Context) error {
+		if err := requestLimiter.Wait(ctx); err != nil {
+			return err
+		}
+		started := time.Now()
+		defer func() { extracted.Latency += time.Since(started) }()
+		var extractErr error
+		if withLogprobs {
+			extracted.Text, extracted.Usage, extracted.Logprobs, extractErr = logprobProvider.ExtractTextWithLogprobs(ctx, providerConfig, imagePath, imageBase64)
+			return extractErr
+		}
+		extracted.Text, extracted.Usage, extractErr = provider.ExtractText(ctx, providerConfig, imagePath, imageBase64)
+		return extractErr
+	})
+	if err == nil && cacheKey != "" {
+		if err := saveCachedResponse(config.CacheDir, cacheKey, cachedResponse{Text: extracted.Text, Usage: extracted.Usage}); err != nil {
+			slog.Warn("Failed to cache response", "image", filepath.Base(imagePath), "err", err)
+		}
 	}
+	extracted.Text = providers.StripResponse(extracted.Text, strip)
+	// Provider errors can echo request fragments and keys back from the
+	// response body, so mask them before any caller logs or prints them
+	return extracted, utils.MaskSensitiveError(err)
+}
+
+// WordConfidence is the probability the model gave one response word, the
+// product of the probabilities of the tokens that spell it.
+type WordConfidence struct {
+	Word       string  `json:"word"`
+	Confidence float64 `json:"confidence"`
+}
 
-	// Extract text using the provider
-	return provider.ExtractText(context.Background(), providerConfig, imagePath, imageBase64)
+// wordConfidences groups tokens into the whitespace-separated words of the
+// raw response. A token that spans a word break counts toward both words.
+// Words come from the response before cleaning, so a prefix removed from
+// ProviderResponse can still appear.
+func wordConfidences(logprobs []providers.TokenLogprob) []WordConfidence {
+	var words []WordConfidence
+	var word strings.Builder
+	var logprob float64
+	flush := func() {
+		if word.Len() > 0 {
+			words = append(words, WordConfidence{Word: word.String(), Confidence: math.Exp(logprob)})
+		}
+		word.Reset()
+		logprob = 0
+	}
+	for _, token := range logprobs {
+		counted := false
+		for _, r := range token.Token {
+			if unicode.IsSpace(r) {
+				flush()
+				counted = false
+				continue
+			}
+			if !counted {
+				logprob += token.Logprob
+				counted = true
+			}
+			word.WriteRune(r)
+		}
+	}
+	flush()
+	return words
 }
 
+// compressedEvalExt follows the .yaml or .json extension of gzip compressed
+// eval files.
+const compressedEvalExt = ".gz"
+
+// evalFileExtensions lists the eval file extensions in lookup order.
+var evalFileExtensions = []string{".yaml", ".json", ".yaml" + compressedEvalExt, ".json" + compressedEvalExt}
+
+// saveEvalResults writes summary as JSON when outputPath has a .json
+// extension and as YAML otherwise, gzip compressed when the extension is
+// followed by .gz.
 func saveEvalResults(summary EvalSummary, outputPath string) error {
-	data, err := yaml.Marshal(summary)
+	var data []byte
+	var err error
+	if evalFileFormat(outputPath) == "json" {
+		data, err = json.MarshalIndent(summary, "", "  ")
+	} else {
+		data, err = yaml.Marshal(summary)
+	}
 	if err != nil {
 		return err
 	}
+	if strings.HasSuffix(strings.TrimSuffix(outputPath, ".partial"), compressedEvalExt) {
+		if data, err = gzipBytes(data); err != nil {
+			return err
+		}
+	}
 
 	return os.WriteFile(outputPath, data, 0644)
 }
 
+// gzipBytes returns data gzip compressed.
+func gzipBytes(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write(data); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// loadEvalSummary reads a YAML or JSON eval file, detected by its extension.
+// Gzip compressed files are decompressed first.
+func loadEvalSummary(path string) (EvalSummary, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return EvalSummary{}, fmt.Errorf("failed to read eval file %s: %w", path, err)
+	}
+	if data, err = decompressEvalFile(data); err != nil {
+		return EvalSummary{}, fmt.Errorf("failed to decompress eval file %s: %w", path, err)
+	}
+
+	var summary EvalSummary
+	if evalFileFormat(path) == "json" {
+		err = json.Unmarshal(data, &summary)
+	} else {
+		err = yaml.Unmarshal(data, &summary)
+	}
+	if err != nil {
+		return EvalSummary{}, fmt.Errorf("failed to parse eval file %s: %w", path, err)
+	}
+	return summary, nil
+}
+
+// decompressEvalFile returns data unchanged unless it starts with the gzip
+// magic number, in which case it returns the decompressed contents.
+func decompressEvalFile(data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, []byte{0x1f, 0x8b}) {
+		return data, nil
+	}
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
+
+// evalFileFormat returns "json" for .json and .json.gz eval files (and their
+// .partial sidecars) and "yaml" for everything else.
+func evalFileFormat(path string) string {
+	path = strings.TrimSuffix(strings.TrimSuffix(path, ".partial"), compressedEvalExt)
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		return "json"
+	}
+	return "yaml"
+}
+
+// listEvalFiles returns the YAML and JSON eval files in evalsDir, compressed
+// or not.
+func listEvalFiles(evalsDir string) ([]string, error) {
+	var files []string
+	for _, ext := range evalFileExtensions {
+		matches, err := filepath.Glob(filepath.Join(evalsDir, "*"+ext))
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, matches...)
+	}
+	slices.Sort(files)
+	return files, nil
+}
+
+// evalFileName returns the base name, without extension, of the eval file
+// for a run of model through provider, so runs of the same model through
+// different providers are kept apart.
+func evalFileName(provider, model string) string {
+	return strings.ReplaceAll(provider+"_"+model, ":", "_")
+}
+
+// resolveEvalFile maps a command argument to an eval file. Names without a
+// path separator are looked up in evalsDir, and names without an eval file
+// extension match an existing .yaml file first, then .json, .yaml.gz, and
+// .json.gz. A bare model name that matches no file is also looked up as
+// <provider>_<model> for every provider, and the match is used when there
+// is exactly one.
+func resolveEvalFile(evalsDir, name string) string {
+	bare := !strings.Contains(name, string(filepath.Separator))
+	if bare {
+		name = filepath.Join(evalsDir, name)
+	}
+	for _, ext := range evalFileExtensions {
+		if strings.HasSuffix(name, ext) {
+			return name
+		}
+	}
+	for _, ext := range evalFileExtensions {
+		if _, err := os.Stat(name + ext); err == nil {
+			return name + ext
+		}
+	}
+	if bare {
+		var matches []string
+		for _, provider := range append(providerRegistry.List(), "external") {
+			prefixed := filepath.Join(evalsDir, evalFileName(provider, filepath.Base(name)))
+			for _, ext := range evalFileExtensions {
+				if _, err := os.Stat(prefixed + ext); err == nil {
+					matches = append(matches, prefixed+ext)
+					break
+				}
+			}
+		}
+		if len(matches) == 1 {
+			return matches[0]
+		}
+		if len(matches) > 1 {
+			slog.Warn("Several providers have an eval file for this model; pass the full file name", "model", filepath.Base(name), "files", matches)
+		}
+	}
+	return name + ".yaml"
+}
+
 func printRowResult(result EvalResult) {
 	fmt.Printf("\n=== Results for %s ===\n", result.Identifier)
 	fmt.Printf("Image: %s\n", result.ImagePath)
+	if result.Truncated {
+		fmt.Printf("Warning: response was truncated at the provider's output limit\n")
+	}
+	if result.EmptyResponse {
+		fmt.Printf("Warning: provider returned an empty response\n")
+	}
+	if result.TranscriptPath == "" {
+		fmt.Printf("Transcription:\n%s\n", result.ProviderResponse)
+		return
+	}
 	fmt.Printf("Transcript: %s\n", result.TranscriptPath)
 	fmt.Printf("Character Similarity: %.3f\n", result.CharacterSimilarity)
 	fmt.Printf("Character Accuracy: %.3f\n", result.CharacterAccuracy)
@@ -916,16 +2472,90 @@ func printRowResult(result EvalResult) {
 	fmt.Printf("Deletions: %d\n", result.Deletions)
 	fmt.Printf("Insertions: %d\n", result.Insertions)
 	if result.IgnoredCharsCount > 0 {
-		fmt.Printf("Ignored Characters: %d\n", result.IgnoredCharsCount)
+		fmt.Printf("Ignored Markers: %d\n", result.IgnoredCharsCount)
+	}
+	if result.BLEUScore != nil {
+		fmt.Printf("BLEU Score: %.3f\n", *result.BLEUScore)
+	}
+	if result.BagOfWords != nil {
+		fmt.Printf("Bag-of-Words Precision: %.3f\n", result.BagOfWords.Precision)
+		fmt.Printf("Bag-of-Words Recall: %.3f\n", result.BagOfWords.Recall)
+		fmt.Printf("Bag-of-Words F1: %.3f\n", result.BagOfWords.F1)
+	}
+	if result.SortedWords != nil {
+		fmt.Printf("Sorted Word Accuracy: %.3f\n", result.SortedWords.Accuracy)
+		fmt.Printf("Sorted Word Similarity: %.3f\n", result.SortedWords.Similarity)
+	}
+	if len(result.LineResults) > 0 {
+		printWorstLines(os.Stdout, result.LineResults, worstLineCount)
+	}
+	if result.WordDiff != "" {
+		fmt.Printf("Word Diff:\n%s\n", result.WordDiff)
+	}
+}
+
+// worstLineCount is how many lines printRowResult shows for --per-line runs.
+const worstLineCount = 3
+
+// printWorstLines prints the count lines with the lowest character accuracy,
+// in page order.
+func printWorstLines(w io.Writer, lines []htrmetrics.LineMetric, count int) {
+	order := make([]int, len(lines))
+	for index := range order {
+		order[index] = index
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		return cmp.Compare(lines[a].CharacterAccuracy, lines[b].CharacterAccuracy)
+	})
+	order = order[:min(count, len(order))]
+	slices.Sort(order)
+
+	worst := make([]htrmetrics.LineMetric, 0, len(order))
+	for _, index := range order {
+		worst = append(worst, lines[index])
+	}
+
+	fmt.Fprintf(w, "Worst Lines (%d of %d):\n", len(worst), len(lines))
+	for _, line := range worst {
+		label := fmt.Sprintf("line %d", line.GroundTruthLine)
+		if line.GroundTruthLine == 0 {
+			label = fmt.Sprintf("extra line %d", line.TranscribedLine)
+		}
+		fmt.Fprintf(w, "  %s: char %.3f, word %.3f\n", label, line.CharacterAccuracy, line.WordAccuracy)
+		fmt.Fprintf(w, "    GT: %q\n", line.GroundTruth)
+		fmt.Fprintf(w, "    TR: %q\n", line.Transcription)
 	}
 }
 
-func printSummaryStats(results []EvalResult) {
+// formatWordDiff renders the word alignment of result on one line. Words only
+// in the ground truth are prefixed with "-", words only in the transcription
+// with "+", and substitutions are shown as "gt→tr".
+func formatWordDiff(result htrmetrics.Result) string {
+	tokens := make([]string, 0, len(result.Alignment))
+	for _, step := range result.Alignment {
+		switch step.Op {
+		case htrmetrics.EditEqual:
+			tokens = append(tokens, result.OriginalWords[step.Original])
+		case htrmetrics.EditSubstitute:
+			tokens = append(tokens, result.OriginalWords[step.Original]+"→"+result.TranscribedWords[step.Transcribed])
+		case htrmetrics.EditDelete:
+			tokens = append(tokens, "-"+result.OriginalWords[step.Original])
+		case htrmetrics.EditInsert:
+			tokens = append(tokens, "+"+result.TranscribedWords[step.Transcribed])
+		}
+	}
+	return strings.Join(tokens, " ")
+}
+
+func printSummaryStats(results []EvalResult, weighted bool) {
 	if len(results) == 0 {
 		return
 	}
 
 	var totalCharSim, totalCharAcc, totalWordSim, totalWordAcc, totalWER float64
+	charAccs := make([]float64, 0, len(results))
+	wordAccs := make([]float64, 0, len(results))
+	wers := make([]float64, 0, len(results))
 
 	for _, result := range results {
 		totalCharSim += result.CharacterSimilarity
@@ -933,6 +2563,9 @@ func printSummaryStats(results []EvalResult) {
 		totalWordSim += result.WordSimilarity
 		totalWordAcc += result.WordAccuracy
 		totalWER += result.WordErrorRate
+		charAccs = append(charAccs, result.CharacterAccuracy)
+		wordAccs = append(wordAccs, result.WordAccuracy)
+		wers = append(wers, result.WordErrorRate)
 	}
 
 	count := float64(len(results))
@@ -944,12 +2577,200 @@ func printSummaryStats(results []EvalResult) {
 	fmt.Printf("Average Word Similarity: %.3f\n", totalWordSim/count)
 	fmt.Printf("Average Word Accuracy: %.3f\n", totalWordAcc/count)
 	fmt.Printf("Average Word Error Rate: %.3f\n", totalWER/count)
+	if weighted {
+		wordAccuracy, wordErrorRate := weightedWordRates(results)
+		fmt.Printf("Weighted Word Accuracy: %.3f\n", wordAccuracy)
+		fmt.Printf("Weighted Word Error Rate: %.3f\n", wordErrorRate)
+		fmt.Printf("Weighted Character Error Rate: %.3f\n", weightedCharErrorRate(results))
+	}
+	if bleuScores := collectBLEUScores(results); len(bleuScores) > 0 {
+		fmt.Printf("Average BLEU Score: %.3f\n", htrmetrics.Summarize(bleuScores).Mean)
+	}
+	if f1Scores := collectBagOfWordsF1(results); len(f1Scores) > 0 {
+		fmt.Printf("Average Bag-of-Words F1: %.3f\n", htrmetrics.Summarize(f1Scores).Mean)
+	}
+	if sortedScores := collectSortedWordAccuracy(results); len(sortedScores) > 0 {
+		fmt.Printf("Average Sorted Word Accuracy: %.3f\n", htrmetrics.Summarize(sortedScores).Mean)
+	}
+	if truncated := countTruncated(results); truncated > 0 {
+		fmt.Printf("Truncated Responses: %d\n", truncated)
+	}
+	if empty := countEmptyResponses(results); empty > 0 {
+		fmt.Printf("Empty Responses: %d\n", empty)
+	}
+	if latencies := collectLatencies(results); len(latencies) > 0 {
+		stats := htrmetrics.Summarize(latencies)
+		fmt.Printf("Average Latency: %.0f ms (median %.0f ms)\n", stats.Mean, stats.Median)
+	}
+
+	fmt.Printf("\n=== DISTRIBUTION ===\n")
+	printDistribution("Character Accuracy", htrmetrics.Summarize(charAccs))
+	printDistribution("Word Accuracy", htrmetrics.Summarize(wordAccs))
+	printDistribution("Word Error Rate", htrmetrics.Summarize(wers))
+}
+
+// weightedWordRates returns the word accuracy and word error rate of the
+// whole corpus: the word errors of every row over the total ground truth
+// words. Unlike the averages, long documents count for more than short ones.
+// Rows without ground truth words are skipped.
+func weightedWordRates(results []EvalResult) (wordAccuracy, wordErrorRate float64) {
+	var wordErrors float64
+	var words int
+	for _, result := range results {
+		if result.TotalWordsOriginal == 0 {
+			continue
+		}
+		// WordErrorRate is the row's word errors over its ground truth words.
+		wordErrors += result.WordErrorRate * float64(result.TotalWordsOriginal)
+		words += result.TotalWordsOriginal
+	}
+	if words == 0 {
+		return 0, 0
+	}
+	wordErrorRate = wordErrors / float64(words)
+	return 1 - wordErrorRate, wordErrorRate
+}
+
+// weightedCharErrorRate returns the character error rate of the whole corpus:
+// the character edits of every row over the total ground truth characters.
+// Rows without ground truth characters, including those from eval files
+// written before the count was recorded, are skipped.
+func weightedCharErrorRate(results []EvalResult) float64 {
+	var charErrors float64
+	var chars int
+	for _, result := range results {
+		if result.TotalCharsOriginal == 0 {
+			continue
+		}
+		// CharacterAccuracy is 1 minus the row's character edits over its
+		// ground truth characters.
+		charErrors += (1 - result.CharacterAccuracy) * float64(result.TotalCharsOriginal)
+		chars += result.TotalCharsOriginal
+	}
+	if chars == 0 {
+		return 0
+	}
+	return charErrors / float64(chars)
+}
+
+// countTruncated returns how many results were cut off at the provider's
+// output limit.
+func countTruncated(results []EvalResult) int {
+	count := 0
+	for _, result := range results {
+		if result.Truncated {
+			count++
+		}
+	}
+	return count
+}
+
+// isEmptyResponse reports whether a cleaned provider response has no text
+// beyond whitespace.
+func isEmptyResponse(response string) bool {
+	return strings.TrimSpace(response) == ""
+}
+
+// countEmptyResponses returns how many results have an empty provider
+// response, so refusals and failures are not mistaken for poor
+// transcriptions.
+func countEmptyResponses(results []EvalResult) int {
+	count := 0
+	for _, result := range results {
+		if result.EmptyResponse {
+			count++
+		}
+	}
+	return count
+}
+
+// publicResults returns the results marked public in the input, for numbers
+// that can be shared without the restricted materials.
+func publicResults(results []EvalResult) []EvalResult {
+	var public []EvalResult
+	for _, result := range results {
+		if result.Public {
+			public = append(public, result)
+		}
+	}
+	return public
+}
+
+// collectBLEUScores returns the BLEU scores of the results that have one.
+func collectBLEUScores(results []EvalResult) []float64 {
+	var scores []float64
+	for _, result := range results {
+		if result.BLEUScore != nil {
+			scores = append(scores, *result.BLEUScore)
+		}
+	}
+	return scores
+}
+
+// collectBagOfWordsF1 returns the bag-of-words F1 of the results that have one.
+func collectBagOfWordsF1(results []EvalResult) []float64 {
+	var scores []float64
+	for _, result := range results {
+		if result.BagOfWords != nil {
+			scores = append(scores, result.BagOfWords.F1)
+		}
+	}
+	return scores
+}
+
+// collectLatencies returns the provider latencies, in milliseconds, of the
+// results that recorded one. Cached rows and eval files written before
+// latency was recorded have none.
+func collectLatencies(results []EvalResult) []float64 {
+	var latencies []float64
+	for _, result := range results {
+		if !result.Cached && result.LatencyMS > 0 {
+			latencies = append(latencies, float64(result.LatencyMS))
+		}
+	}
+	return latencies
+}
+
+// collectSortedWordAccuracy returns the sorted word accuracy of the results
+// that have one.
+func collectSortedWordAccuracy(results []EvalResult) []float64 {
+	var scores []float64
+	for _, result := range results {
+		if result.SortedWords != nil {
+			scores = append(scores, result.SortedWords.Accuracy)
+		}
+	}
+	return scores
+}
+
+func printDistribution(label string, stats htrmetrics.Stats) {
+	fmt.Printf("%s: median %.3f, std dev %.3f, min %.3f, max %.3f\n",
+		label, stats.Median, stats.StdDev, stats.Min, stats.Max)
 }
 
 func applyIgnorePatterns(groundTruth, transcription string, ignorePatterns []string) (string, string, int) {
 	return htrmetrics.ApplyIgnorePatterns(groundTruth, transcription, ignorePatterns)
 }
 
+// compileStripPatterns compiles --strip-pattern expressions for
+// extractTextWithProvider.
+func compileStripPatterns(expressions []string) ([]*regexp.Regexp, error) {
+	compiled, err := providers.CompileStripPatterns(expressions)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --strip-pattern: %w", err)
+	}
+	return compiled, nil
+}
+
+// compileIgnoreRegex compiles --ignore-regex expressions for the metrics.
+func compileIgnoreRegex(expressions []string) ([]*regexp.Regexp, error) {
+	compiled, err := htrmetrics.CompileIgnoreRegex(expressions)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --ignore-regex: %w", err)
+	}
+	return compiled, nil
+}
+
 func normalizeSpaces(text string) string {
 	return htrmetrics.NormalizeSingleLine(text)
 }
@@ -962,11 +2783,16 @@ func calculateSimilarity(s1, s2 string) float64 {
 	return htrmetrics.Similarity(s1, s2)
 }
 
-func CalculateAccuracyMetrics(original, transcribed string, ignorePatterns []string, singleLine bool) EvalResult {
-	result := htrmetrics.Evaluate(original, transcribed, htrmetrics.Options{
+func CalculateAccuracyMetrics(original, transcribed string, ignorePatterns []string, singleLine, ignoreCase bool) EvalResult {
+	return evalResultFromMetrics(htrmetrics.Evaluate(original, transcribed, htrmetrics.Options{
 		IgnorePatterns: ignorePatterns,
 		SingleLine:     singleLine,
-	})
+		IgnoreCase:     ignoreCase,
+	}))
+}
+
+// evalResultFromMetrics copies the accuracy metrics of result into an EvalResult.
+func evalResultFromMetrics(result htrmetrics.Result) EvalResult {
 	return EvalResult{
 		CharacterSimilarity:   result.CharacterSimilarity,
 		CharacterAccuracy:     result.CharacterAccuracy,
@@ -980,38 +2806,94 @@ func CalculateAccuracyMetrics(original, transcribed string, ignorePatterns []str
 		Deletions:             result.Deletions,
 		Insertions:            result.Insertions,
 		IgnoredCharsCount:     result.IgnoredCharsCount,
+		TotalCharsOriginal:    result.TotalCharsOriginal,
 	}
 }
 
 func runCost(cmd *cobra.Command, args []string) error {
-	evalsDir := "evals"
-	evalFile := args[0]
-
-	if !strings.HasSuffix(evalFile, ".yaml") {
-		evalFile += ".yaml"
-	}
-
-	// If no path separator, assume it's in evals directory
-	if !strings.Contains(evalFile, string(filepath.Separator)) {
-		evalFile = filepath.Join(evalsDir, evalFile)
-	}
-
 	// Read and parse the eval file
-	data, err := os.ReadFile(evalFile)
+	evalFile := resolveEvalFile(evalsDir, args[0])
+	summary, err := loadEvalSummary(evalFile)
 	if err != nil {
-		return fmt.Errorf("failed to read eval file %s: %w", evalFile, err)
+		return err
 	}
 
-	var summary EvalSummary
-	if err := yaml.Unmarshal(data, &summary); err != nil {
-		return fmt.Errorf("failed to parse eval file: %w", err)
+	var estimate costEstimate
+	if flagGiven(cmd.Flags(), "per-page-price") {
+		estimate = estimatePageCost(summary.Results, costPagePrice)
+		estimate.PriceSource = "--per-page-price flag"
+	} else {
+		if !providerCapabilities(summary.Config.Provider).ReportsTokenUsage {
+			return fmt.Errorf("provider %q does not report token usage; use --per-page-price to estimate its cost", summary.Config.Provider)
+		}
+
+		priceTable, err := loadPriceTable(costPriceFile)
+		if err != nil {
+			return err
+		}
+		explicitPrices := flagGiven(cmd.Flags(), "input-price") || flagGiven(cmd.Flags(), "output-price")
+		price, ok := resolveTokenPrice(priceTable, summary.Config.Model, explicitPrices, costInputPrice, costOutputPrice)
+		if !ok {
+			return fmt.Errorf("no known prices for model %q; pass --input-price and --output-price, add the model to --price-file, or use --per-page-price (known models: %s)",
+				summary.Config.Model, strings.Join(priceTable.Models(), ", "))
+		}
+
+		estimate, err = estimateTokenCost(summary.Results, price)
+		if err != nil {
+			return err
+		}
+		estimate.PriceSource = "--input-price/--output-price flags"
+		if !explicitPrices {
+			estimate.PriceSource = "pricing table"
+			if costPriceFile != "" {
+				estimate.PriceSource = "pricing table with " + costPriceFile
+			}
+		}
 	}
 
-	// Calculate average tokens per document
+	// Display results
+	fmt.Printf("=== COST ESTIMATION ===\n")
+	fmt.Printf("File: %s\n", filepath.Base(evalFile))
+	fmt.Printf("Provider: %s\n", summary.Config.Provider)
+	fmt.Printf("Model: %s\n", summary.Config.Model)
+	fmt.Printf("\n")
+
+	printCostEstimate(os.Stdout, estimate, costDocCount)
+	return nil
+}
+
+// costEstimate is the average per-document cost of an evaluation under either
+// token-based or page-based pricing.
+type costEstimate struct {
+	PageBased   bool
+	PriceSource string
+	Documents   int
+
+	// Token-based pricing
+	Price           pricing.Price
+	AvgInputTokens  float64
+	AvgOutputTokens float64
+
+	// Page-based pricing
+	PagePrice float64
+	AvgPages  float64
+
+	InputCostPerDoc  float64
+	OutputCostPerDoc float64
+}
+
+// TotalCostPerDoc returns the combined per-document cost.
+func (e costEstimate) TotalCostPerDoc() float64 {
+	return e.InputCostPerDoc + e.OutputCostPerDoc
+}
+
+// estimateTokenCost averages token usage over the results that recorded any
+// tokens and prices it per million tokens.
+func estimateTokenCost(results []EvalResult, price pricing.Price) (costEstimate, error) {
 	var totalInputTokens, totalOutputTokens int
 	var docsWithTokens int
 
-	for _, result := range summary.Results {
+	for _, result := range results {
 		if result.InputTokens > 0 || result.OutputTokens > 0 {
 			totalInputTokens += result.InputTokens
 			totalOutputTokens += result.OutputTokens
@@ -1020,53 +2902,107 @@ func runCost(cmd *cobra.Command, args []string) error {
 	}
 
 	if docsWithTokens == 0 {
-		return fmt.Errorf("no token usage data found in evaluation file (run eval with a provider that supports token tracking)")
+		return costEstimate{}, fmt.Errorf("no token usage data found in evaluation file (run eval with a provider that supports token tracking, or use --per-page-price for page-billed providers)")
 	}
 
-	avgInputTokens := float64(totalInputTokens) / float64(docsWithTokens)
-	avgOutputTokens := float64(totalOutputTokens) / float64(docsWithTokens)
-
-	// Calculate costs
+	estimate := costEstimate{
+		Price:           price,
+		Documents:       docsWithTokens,
+		AvgInputTokens:  float64(totalInputTokens) / float64(docsWithTokens),
+		AvgOutputTokens: float64(totalOutputTokens) / float64(docsWithTokens),
+	}
 	// Price is per million tokens, so divide by 1,000,000
-	inputCostPerDoc := (avgInputTokens / 1_000_000) * costInputPrice
-	outputCostPerDoc := (avgOutputTokens / 1_000_000) * costOutputPrice
-	totalCostPerDoc := inputCostPerDoc + outputCostPerDoc
-
-	estimatedInputCost := inputCostPerDoc * float64(costDocCount)
-	estimatedOutputCost := outputCostPerDoc * float64(costDocCount)
-	estimatedTotalCost := totalCostPerDoc * float64(costDocCount)
+	estimate.InputCostPerDoc = (estimate.AvgInputTokens / 1_000_000) * price.Input
+	estimate.OutputCostPerDoc = (estimate.AvgOutputTokens / 1_000_000) * price.Output
+	return estimate, nil
+}
 
-	// Display results
-	fmt.Printf("=== COST ESTIMATION ===\n")
-	fmt.Printf("File: %s\n", filepath.Base(evalFile))
-	fmt.Printf("Provider: %s\n", summary.Config.Provider)
-	fmt.Printf("Model: %s\n", summary.Config.Model)
-	fmt.Printf("\n")
+// estimatePageCost prices every result by its page count. Results without a
+// recorded page count, such as those from token-billed providers or older
+// eval files, count as one page.
+func estimatePageCost(results []EvalResult, pagePrice float64) costEstimate {
+	totalPages := 0
+	for _, result := range results {
+		totalPages += max(result.PageCount, 1)
+	}
 
-	fmt.Printf("=== Token Usage Statistics ===\n")
-	fmt.Printf("Documents analyzed: %d\n", docsWithTokens)
-	fmt.Printf("Average input tokens per document: %.2f\n", avgInputTokens)
-	fmt.Printf("Average output tokens per document: %.2f\n", avgOutputTokens)
-	fmt.Printf("Average total tokens per document: %.2f\n", avgInputTokens+avgOutputTokens)
-	fmt.Printf("\n")
+	estimate := costEstimate{
+		PageBased: true,
+		PagePrice: pagePrice,
+		Documents: len(results),
+	}
+	if len(results) > 0 {
+		estimate.AvgPages = float64(totalPages) / float64(len(results))
+	}
+	estimate.InputCostPerDoc = estimate.AvgPages * pagePrice
+	return estimate
+}
 
-	fmt.Printf("=== Pricing Configuration ===\n")
-	fmt.Printf("Input token price: $%.2f per 1M tokens\n", costInputPrice)
-	fmt.Printf("Output token price: $%.2f per 1M tokens\n", costOutputPrice)
-	fmt.Printf("\n")
+// printCostEstimate writes the usage, pricing, and projected cost sections of
+// the cost report for docCount documents.
+func printCostEstimate(w io.Writer, estimate costEstimate, docCount int) {
+	if estimate.PageBased {
+		fmt.Fprintf(w, "=== Page Usage Statistics ===\n")
+		fmt.Fprintf(w, "Documents analyzed: %d\n", estimate.Documents)
+		fmt.Fprintf(w, "Average pages per document: %.2f\n", estimate.AvgPages)
+		fmt.Fprintf(w, "\n")
+
+		fmt.Fprintf(w, "=== Pricing Configuration ===\n")
+		fmt.Fprintf(w, "Pricing mode: page-based\n")
+		fmt.Fprintf(w, "Price source: %s\n", estimate.PriceSource)
+		fmt.Fprintf(w, "Page price: $%.4f per page\n", estimate.PagePrice)
+		fmt.Fprintf(w, "\n")
+
+		fmt.Fprintf(w, "=== Per Document Cost ===\n")
+		fmt.Fprintf(w, "Total cost: $%.6f\n", estimate.TotalCostPerDoc())
+		fmt.Fprintf(w, "\n")
+
+		fmt.Fprintf(w, "=== Estimated Cost for %d Documents ===\n", docCount)
+		fmt.Fprintf(w, "Total cost: $%.2f\n", estimate.TotalCostPerDoc()*float64(docCount))
+		return
+	}
 
-	fmt.Printf("=== Per Document Cost ===\n")
-	fmt.Printf("Input cost: $%.6f\n", inputCostPerDoc)
-	fmt.Printf("Output cost: $%.6f\n", outputCostPerDoc)
-	fmt.Printf("Total cost: $%.6f\n", totalCostPerDoc)
-	fmt.Printf("\n")
+	fmt.Fprintf(w, "=== Token Usage Statistics ===\n")
+	fmt.Fprintf(w, "Documents analyzed: %d\n", estimate.Documents)
+	fmt.Fprintf(w, "Average input tokens per document: %.2f\n", estimate.AvgInputTokens)
+	fmt.Fprintf(w, "Average output tokens per document: %.2f\n", estimate.AvgOutputTokens)
+	fmt.Fprintf(w, "Average total tokens per document: %.2f\n", estimate.AvgInputTokens+estimate.AvgOutputTokens)
+	fmt.Fprintf(w, "\n")
+
+	fmt.Fprintf(w, "=== Pricing Configuration ===\n")
+	fmt.Fprintf(w, "Pricing mode: token-based\n")
+	fmt.Fprintf(w, "Price source: %s\n", estimate.PriceSource)
+	fmt.Fprintf(w, "Input token price: $%.2f per 1M tokens\n", estimate.Price.Input)
+	fmt.Fprintf(w, "Output token price: $%.2f per 1M tokens\n", estimate.Price.Output)
+	fmt.Fprintf(w, "\n")
+
+	fmt.Fprintf(w, "=== Per Document Cost ===\n")
+	fmt.Fprintf(w, "Input cost: $%.6f\n", estimate.InputCostPerDoc)
+	fmt.Fprintf(w, "Output cost: $%.6f\n", estimate.OutputCostPerDoc)
+	fmt.Fprintf(w, "Total cost: $%.6f\n", estimate.TotalCostPerDoc())
+	fmt.Fprintf(w, "\n")
+
+	fmt.Fprintf(w, "=== Estimated Cost for %d Documents ===\n", docCount)
+	fmt.Fprintf(w, "Input cost: $%.2f\n", estimate.InputCostPerDoc*float64(docCount))
+	fmt.Fprintf(w, "Output cost: $%.2f\n", estimate.OutputCostPerDoc*float64(docCount))
+	fmt.Fprintf(w, "Total cost: $%.2f\n", estimate.TotalCostPerDoc()*float64(docCount))
+}
 
-	fmt.Printf("=== Estimated Cost for %d Documents ===\n", costDocCount)
-	fmt.Printf("Input cost: $%.2f\n", estimatedInputCost)
-	fmt.Printf("Output cost: $%.2f\n", estimatedOutputCost)
-	fmt.Printf("Total cost: $%.2f\n", estimatedTotalCost)
+// loadPriceTable returns the built-in model prices, overridden by priceFile when set.
+func loadPriceTable(priceFile string) (pricing.Table, error) {
+	if priceFile == "" {
+		return pricing.Default(), nil
+	}
+	return pricing.LoadFile(priceFile, pricing.Default())
+}
 
-	return nil
+// resolveTokenPrice returns the explicit flag prices when either flag was set,
+// and otherwise the table price for model.
+func resolveTokenPrice(table pricing.Table, model string, explicit bool, inputPrice, outputPrice float64) (pricing.Price, bool) {
+	if explicit {
+		return pricing.Price{Input: inputPrice, Output: outputPrice}, true
+	}
+	return table.Lookup(model)
 }
 
 func formatErrorToPlaintext(minifiedJSON string) (string, error) {
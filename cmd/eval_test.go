@@ -1,7 +1,34 @@
 package cmd
 
 import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/lehigh-university-libraries/htr/internal/utils"
+	htrmetrics "github.com/lehigh-university-libraries/htr/pkg/metrics"
+	"github.com/lehigh-university-libraries/htr/pkg/objectstore"
+	"github.com/lehigh-university-libraries/htr/pkg/pricing"
+	"github.com/lehigh-university-libraries/htr/pkg/providers"
+	"github.com/spf13/pflag"
+	yaml "go.yaml.in/yaml/v3"
 )
 
 func TestApplyIgnorePatterns(t *testing.T) {
@@ -30,8 +57,8 @@ func TestApplyIgnorePatterns(t *testing.T) {
 			groundTruth:     "hello | world",
 			transcription:   "hello foo world",
 			ignorePatterns:  []string{"|"},
-			expectedGT:      "hello  world",
-			expectedTrans:   "hello  world",
+			expectedGT:      "hello world",
+			expectedTrans:   "hello world",
 			expectedIgnored: 1,
 			description:     "Pipe surrounded by spaces represents unknown word - skip 'foo' in transcription",
 		},
@@ -50,8 +77,8 @@ func TestApplyIgnorePatterns(t *testing.T) {
 			groundTruth:     "the | cat | jumped",
 			transcription:   "the quick cat suddenly jumped",
 			ignorePatterns:  []string{"|"},
-			expectedGT:      "the  cat  jumped",
-			expectedTrans:   "the  cat  jumped",
+			expectedGT:      "the cat jumped",
+			expectedTrans:   "the cat jumped",
 			expectedIgnored: 2,
 			description:     "Multiple unknown words - skip 'quick' and 'suddenly'",
 		},
@@ -70,8 +97,8 @@ func TestApplyIgnorePatterns(t *testing.T) {
 			groundTruth:     "hel|o | world",
 			transcription:   "hello foo world",
 			ignorePatterns:  []string{"|"},
-			expectedGT:      "helo  world",
-			expectedTrans:   "helo  world",
+			expectedGT:      "helo world",
+			expectedTrans:   "helo world",
 			expectedIgnored: 2,
 			description:     "Mix of character and word unknowns",
 		},
@@ -80,8 +107,8 @@ func TestApplyIgnorePatterns(t *testing.T) {
 			groundTruth:     "| hello world",
 			transcription:   "foo hello world",
 			ignorePatterns:  []string{"|"},
-			expectedGT:      " hello world",
-			expectedTrans:   " hello world",
+			expectedGT:      "hello world",
+			expectedTrans:   "hello world",
 			expectedIgnored: 1,
 			description:     "Unknown word at the beginning",
 		},
@@ -90,8 +117,8 @@ func TestApplyIgnorePatterns(t *testing.T) {
 			groundTruth:     "hello world |",
 			transcription:   "hello world foo",
 			ignorePatterns:  []string{"|"},
-			expectedGT:      "hello world ",
-			expectedTrans:   "hello world ",
+			expectedGT:      "hello world",
+			expectedTrans:   "hello world",
 			expectedIgnored: 1,
 			description:     "Unknown word at the end",
 		},
@@ -100,8 +127,8 @@ func TestApplyIgnorePatterns(t *testing.T) {
 			groundTruth:     "hello | world , test",
 			transcription:   "hello foo world bar test",
 			ignorePatterns:  []string{"|", ","},
-			expectedGT:      "hello  world  test",
-			expectedTrans:   "hello  world  test",
+			expectedGT:      "hello world test",
+			expectedTrans:   "hello world test",
 			expectedIgnored: 2,
 			description:     "Multiple different ignore patterns (pipe and comma)",
 		},
@@ -110,8 +137,8 @@ func TestApplyIgnorePatterns(t *testing.T) {
 			groundTruth:     "hello , world",
 			transcription:   "hello something world",
 			ignorePatterns:  []string{","},
-			expectedGT:      "hello  world",
-			expectedTrans:   "hello  world",
+			expectedGT:      "hello world",
+			expectedTrans:   "hello world",
 			expectedIgnored: 1,
 			description:     "Using comma as ignore pattern",
 		},
@@ -120,10 +147,20 @@ func TestApplyIgnorePatterns(t *testing.T) {
 			groundTruth:     "hello [?] world",
 			transcription:   "hello unknown world",
 			ignorePatterns:  []string{"[?]"},
-			expectedGT:      "hello  world",
-			expectedTrans:   "hello  world",
-			expectedIgnored: 3,
-			description:     "Multi-character ignore pattern as standalone word",
+			expectedGT:      "hello world",
+			expectedTrans:   "hello world",
+			expectedIgnored: 1,
+			description:     "Multi-character ignore pattern counts once per occurrence",
+		},
+		{
+			name:            "multi-byte ignore pattern",
+			groundTruth:     "d〔te 〔 world",
+			transcription:   "date hello world",
+			ignorePatterns:  []string{"〔"},
+			expectedGT:      "dte world",
+			expectedTrans:   "dte world",
+			expectedIgnored: 2,
+			description:     "A multi-byte marker skips one rune and counts once, not once per byte",
 		},
 		{
 			name:            "consecutive pipes in word",
@@ -140,8 +177,8 @@ func TestApplyIgnorePatterns(t *testing.T) {
 			groundTruth:     "The quick | fox jumps",
 			transcription:   "The quick brown fox jumps",
 			ignorePatterns:  []string{"|"},
-			expectedGT:      "The quick  fox jumps",
-			expectedTrans:   "The quick  fox jumps",
+			expectedGT:      "The quick fox jumps",
+			expectedTrans:   "The quick fox jumps",
 			expectedIgnored: 1,
 			description:     "Unknown adjective before 'fox'",
 		},
@@ -160,7 +197,7 @@ func TestApplyIgnorePatterns(t *testing.T) {
 			groundTruth:     "hello | world | test",
 			transcription:   "hello foo",
 			ignorePatterns:  []string{"|"},
-			expectedGT:      "hello  world  test",
+			expectedGT:      "hello world test",
 			expectedTrans:   "hello ",
 			expectedIgnored: 2,
 			description:     "Transcription ends before all words processed",
@@ -280,7 +317,7 @@ func TestCalculateAccuracyMetricsWithIgnore(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := CalculateAccuracyMetrics(tt.groundTruth, tt.transcription, tt.ignorePatterns, false)
+			result := CalculateAccuracyMetrics(tt.groundTruth, tt.transcription, tt.ignorePatterns, false, false)
 
 			if result.IgnoredCharsCount != tt.expectedIgnoredCount {
 				t.Errorf("IgnoredCharsCount = %d, want %d\n  description: %s",
@@ -467,7 +504,7 @@ func TestCalculateAccuracyMetricsWithSingleLine(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := CalculateAccuracyMetrics(tt.groundTruth, tt.transcription, []string{}, tt.singleLine)
+			result := CalculateAccuracyMetrics(tt.groundTruth, tt.transcription, []string{}, tt.singleLine, false)
 
 			if result.CorrectWords != tt.expectedCorrectWords {
 				t.Errorf("CorrectWords = %d, want %d\n  description: %s",
@@ -601,7 +638,113 @@ func TestCalculateAccuracyMetricsWithSingleLineNormalization(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := CalculateAccuracyMetrics(tt.groundTruth, tt.transcription, []string{}, tt.singleLine)
+			result := CalculateAccuracyMetrics(tt.groundTruth, tt.transcription, []string{}, tt.singleLine, false)
+
+			if result.CorrectWords != tt.expectedCorrectWords {
+				t.Errorf("CorrectWords = %d, want %d\n  description: %s",
+					result.CorrectWords, tt.expectedCorrectWords, tt.description)
+			}
+
+			if result.TotalWordsOriginal != tt.expectedTotalWordsOrig {
+				t.Errorf("TotalWordsOriginal = %d, want %d\n  description: %s",
+					result.TotalWordsOriginal, tt.expectedTotalWordsOrig, tt.description)
+			}
+
+			if diff := result.WordAccuracy - tt.expectedWordAccuracy; diff > 0.01 || diff < -0.01 {
+				t.Errorf("WordAccuracy = %.3f, want %.3f\n  description: %s",
+					result.WordAccuracy, tt.expectedWordAccuracy, tt.description)
+			}
+		})
+	}
+}
+
+func TestCalculateAccuracyMetricsWithIgnoreCase(t *testing.T) {
+	tests := []struct {
+		name                   string
+		groundTruth            string
+		transcription          string
+		ignorePatterns         []string
+		singleLine             bool
+		ignoreCase             bool
+		expectedCharAccuracy   float64
+		expectedWordAccuracy   float64
+		expectedCorrectWords   int
+		expectedTotalWordsOrig int
+		description            string
+	}{
+		{
+			name:                   "mixed case without ignore-case",
+			groundTruth:            "The Quick Brown Fox",
+			transcription:          "the quick brown fox",
+			ignoreCase:             false,
+			expectedCharAccuracy:   0.789,
+			expectedWordAccuracy:   0.0,
+			expectedCorrectWords:   0,
+			expectedTotalWordsOrig: 4,
+			description:            "Capitalization differences count as edits by default",
+		},
+		{
+			name:                   "mixed case with ignore-case",
+			groundTruth:            "The Quick Brown Fox",
+			transcription:          "the quick brown fox",
+			ignoreCase:             true,
+			expectedCharAccuracy:   1.0,
+			expectedWordAccuracy:   1.0,
+			expectedCorrectWords:   4,
+			expectedTotalWordsOrig: 4,
+			description:            "Capitalization is ignored at both character and word level",
+		},
+		{
+			name:                   "ignore-case keeps real errors",
+			groundTruth:            "LONDON Bridge",
+			transcription:          "london bridg",
+			ignoreCase:             true,
+			expectedCharAccuracy:   0.923,
+			expectedWordAccuracy:   0.5,
+			expectedCorrectWords:   1,
+			expectedTotalWordsOrig: 2,
+			description:            "Only the missing letter counts once case is ignored",
+		},
+		{
+			name:                   "ignore-case with non-ASCII letters",
+			groundTruth:            "École Ärzte",
+			transcription:          "école ärzte",
+			ignoreCase:             true,
+			expectedCharAccuracy:   1.0,
+			expectedWordAccuracy:   1.0,
+			expectedCorrectWords:   2,
+			expectedTotalWordsOrig: 2,
+			description:            "Unicode letters are lowercased too",
+		},
+		{
+			name:                   "ignore-case with single-line",
+			groundTruth:            "Line One\nLINE two",
+			transcription:          "line one line Two",
+			singleLine:             true,
+			ignoreCase:             true,
+			expectedCharAccuracy:   1.0,
+			expectedWordAccuracy:   1.0,
+			expectedCorrectWords:   4,
+			expectedTotalWordsOrig: 4,
+			description:            "Case folding applies after single-line normalization",
+		},
+		{
+			name:                   "ignore-case with uppercase ignore pattern",
+			groundTruth:            "Hello X World",
+			transcription:          "hello foo world",
+			ignorePatterns:         []string{"X"},
+			ignoreCase:             true,
+			expectedCharAccuracy:   1.0,
+			expectedWordAccuracy:   1.0,
+			expectedCorrectWords:   2,
+			expectedTotalWordsOrig: 2,
+			description:            "Ignore patterns are folded so they still match lowercased ground truth",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := CalculateAccuracyMetrics(tt.groundTruth, tt.transcription, tt.ignorePatterns, tt.singleLine, tt.ignoreCase)
 
 			if result.CorrectWords != tt.expectedCorrectWords {
 				t.Errorf("CorrectWords = %d, want %d\n  description: %s",
@@ -613,6 +756,11 @@ func TestCalculateAccuracyMetricsWithSingleLineNormalization(t *testing.T) {
 					result.TotalWordsOriginal, tt.expectedTotalWordsOrig, tt.description)
 			}
 
+			if diff := result.CharacterAccuracy - tt.expectedCharAccuracy; diff > 0.01 || diff < -0.01 {
+				t.Errorf("CharacterAccuracy = %.3f, want %.3f\n  description: %s",
+					result.CharacterAccuracy, tt.expectedCharAccuracy, tt.description)
+			}
+
 			if diff := result.WordAccuracy - tt.expectedWordAccuracy; diff > 0.01 || diff < -0.01 {
 				t.Errorf("WordAccuracy = %.3f, want %.3f\n  description: %s",
 					result.WordAccuracy, tt.expectedWordAccuracy, tt.description)
@@ -766,3 +914,2472 @@ func TestPageCostCalculation(t *testing.T) {
 		})
 	}
 }
+
+type stubEvalProvider struct {
+	providers.BaseProvider
+
+	// name defaults to "stub".
+	name      string
+	responses map[string]string
+	truncated map[string]bool
+	calls     []string
+	configs   []providers.Config
+	images    []string
+	err       error
+	// errs fails the calls for single images, keyed by file name.
+	errs map[string]error
+	// onCall runs after each call is recorded.
+	onCall func()
+}
+
+func (p *stubEvalProvider) Name() string {
+	if p.name != "" {
+		return p.name
+	}
+	return "stub"
+}
+
+func (p *stubEvalProvider) ValidateConfig(config providers.Config) error {
+	return nil
+}
+
+func (p *stubEvalProvider) ExtractText(ctx context.Context, config providers.Config, imagePath, imageBase64 string) (string, providers.UsageInfo, error) {
+	p.calls = append(p.calls, filepath.Base(imagePath))
+	p.configs = append(p.configs, config)
+	p.images = append(p.images, imageBase64)
+	if p.onCall != nil {
+		p.onCall()
+	}
+	if err := ctx.Err(); err != nil {
+		return "", providers.UsageInfo{}, err
+	}
+	if p.err != nil {
+		return "", providers.UsageInfo{}, p.err
+	}
+	if err := p.errs[filepath.Base(imagePath)]; err != nil {
+		return "", providers.UsageInfo{}, err
+	}
+	usage := providers.UsageInfo{InputTokens: 10, OutputTokens: 5, Truncated: p.truncated[filepath.Base(imagePath)]}
+	return p.responses[filepath.Base(imagePath)], usage, nil
+}
+
+// fakeJPEG returns content behind a JPEG signature, which is enough to pass
+// the image format check without decoding.
+func fakeJPEG(content string) []byte {
+	return append([]byte("\xff\xd8\xff"), content...)
+}
+
+// writeEvalFixtures creates images, transcripts, and a CSV in a temp dir and
+// points the eval command's --dir at it.
+func writeEvalFixtures(t *testing.T, transcripts map[string]string, csvRows string) string {
+	t.Helper()
+	tmpDir := t.TempDir()
+	for name, text := range transcripts {
+		if err := os.WriteFile(filepath.Join(tmpDir, name+".jpg"), fakeJPEG("image-"+name), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(tmpDir, name+".txt"), []byte(text), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	csvPath := filepath.Join(tmpDir, "images.csv")
+	if err := os.WriteFile(csvPath, []byte(csvRows), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	originalDir := dir
+	t.Cleanup(func() { dir = originalDir })
+	dir = tmpDir
+	return csvPath
+}
+
+// useStubEvalProvider registers stub as the only provider for the test.
+func useStubEvalProvider(t *testing.T, stub *stubEvalProvider) {
+	t.Helper()
+	originalRegistry := providerRegistry
+	t.Cleanup(func() { providerRegistry = originalRegistry })
+	providerRegistry = providers.NewRegistry()
+	providerRegistry.Register(stub)
+}
+
+// setEvalFlags sets flags of evalCmd as if given on the command line and
+// restores their values and Changed state when the test ends, so runEval's
+// Changed checks do not see flags set by earlier tests.
+func setEvalFlags(t *testing.T, values map[string]string) {
+	t.Helper()
+	flags := evalCmd.Flags()
+	for name, value := range values {
+		flag := flags.Lookup(name)
+		if flag == nil {
+			t.Fatalf("eval has no --%s flag", name)
+		}
+		original, changed := flag.Value.String(), flag.Changed
+		if slice, ok := flag.Value.(pflag.SliceValue); ok {
+			items := slice.GetSlice()
+			t.Cleanup(func() { _ = slice.Replace(items) })
+		} else {
+			t.Cleanup(func() { _ = flag.Value.Set(original) })
+		}
+		t.Cleanup(func() { flag.Changed = changed })
+		if err := flags.Set(name, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestProcessEvaluationRowErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		failFast    bool
+		err         error
+		wantErr     bool
+		wantResults int
+		wantCalls   int
+	}{
+		{name: "image error is skipped", err: errors.New("image rejected"), wantResults: 2, wantCalls: 3},
+		{name: "image error stops with fail-fast", failFast: true, err: errors.New("image rejected"), wantErr: true, wantResults: 1, wantCalls: 2},
+		{name: "auth error always stops", err: providers.ErrorForStatus(http.StatusUnauthorized), wantErr: true, wantResults: 1, wantCalls: 2},
+		{name: "rate limit is skipped", err: providers.ErrorForStatus(http.StatusTooManyRequests), wantResults: 2, wantCalls: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			csvPath := writeEvalFixtures(t,
+				map[string]string{"page1": "one", "page2": "two", "page3": "three"},
+				"image,transcript,public\npage1.jpg,page1.txt,true\npage2.jpg,page2.txt,true\npage3.jpg,page3.txt,true\n",
+			)
+			stub := &stubEvalProvider{
+				responses: map[string]string{"page1.jpg": "one", "page3.jpg": "three"},
+				errs:      map[string]error{"page2.jpg": tt.err},
+			}
+			useStubEvalProvider(t, stub)
+			originalFailFast := evalFailFast
+			t.Cleanup(func() { evalFailFast = originalFailFast })
+			evalFailFast = tt.failFast
+
+			config := EvalConfig{Provider: "stub", Model: "model", Prompt: "Extract", CSVPath: csvPath}
+			results, err := processEvaluation(context.Background(), config, nil, "")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("processEvaluation() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !strings.Contains(err.Error(), "row 2") {
+				t.Errorf("processEvaluation() error = %v, want it to name row 2", err)
+			}
+			if len(results) != tt.wantResults || len(stub.calls) != tt.wantCalls {
+				t.Errorf("got %d results after %d calls, want %d after %d", len(results), len(stub.calls), tt.wantResults, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestIsFatalEvalError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"invalid configuration", fmt.Errorf("provider API call failed: %w", fmt.Errorf("%w for provider stub: %w", errInvalidProviderConfig, errors.New("API key not set"))), true},
+		{"rejected credentials", fmt.Errorf("provider API call failed: %w", utils.MaskSensitiveError(providers.ErrorForStatus(http.StatusForbidden))), true},
+		{"invalid request", providers.ErrorForStatus(http.StatusBadRequest), false},
+		{"upstream failure", providers.ErrorForStatus(http.StatusInternalServerError), false},
+		{"missing transcript", errors.New("failed to read transcript"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isFatalEvalError(tt.err); got != tt.want {
+				t.Errorf("isFatalEvalError(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProcessEvaluationResumesAndCheckpoints(t *testing.T) {
+	csvPath := writeEvalFixtures(t,
+		map[string]string{"page1": "hello world", "page2": "second page"},
+		"image,transcript,public\npage1.jpg,page1.txt,true\npage2.jpg,page2.txt,false\n",
+	)
+	stub := &stubEvalProvider{responses: map[string]string{"page1.jpg": "hello world", "page2.jpg": "second page"}}
+	useStubEvalProvider(t, stub)
+
+	existing := []EvalResult{{Identifier: "page1.jpg", ProviderResponse: "hello world", WordAccuracy: 1}}
+	partialPath := filepath.Join(t.TempDir(), "model.yaml.partial")
+	config := EvalConfig{Provider: "stub", Model: "model", Prompt: "Extract", CSVPath: csvPath}
+
+	results, err := processEvaluation(context.Background(), config, existing, partialPath)
+	if err != nil {
+		t.Fatalf("processEvaluation() error = %v", err)
+	}
+
+	if len(stub.calls) != 1 || stub.calls[0] != "page2.jpg" {
+		t.Fatalf("provider calls = %v, want only page2.jpg", stub.calls)
+	}
+	if len(results) != 2 || results[0].Identifier != "page1.jpg" || results[1].Identifier != "page2.jpg" {
+		t.Fatalf("results = %+v, want existing page1 followed by new page2", results)
+	}
+
+	partial, err := loadResumeResults(partialPath)
+	if err != nil {
+		t.Fatalf("loadResumeResults() error = %v", err)
+	}
+	if len(partial) != 2 || partial[1].ProviderResponse != "second page" {
+		t.Fatalf("partial results = %+v, want both rows checkpointed", partial)
+	}
+}
+
+func TestLoadResumeResultsPrefersPartial(t *testing.T) {
+	tmpDir := t.TempDir()
+	outputPath := filepath.Join(tmpDir, "model.yaml")
+	partialPath := outputPath + ".partial"
+
+	results, err := loadResumeResults(partialPath, outputPath)
+	if err != nil || len(results) != 0 {
+		t.Fatalf("loadResumeResults() with no files = %v, %v; want no results", results, err)
+	}
+
+	for path, identifiers := range map[string][]string{outputPath: {"a.jpg"}, partialPath: {"a.jpg", "b.jpg"}} {
+		summary := EvalSummary{}
+		for _, identifier := range identifiers {
+			summary.Results = append(summary.Results, EvalResult{Identifier: identifier})
+		}
+		data, err := yaml.Marshal(summary)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	results, err = loadResumeResults(partialPath, outputPath)
+	if err != nil || len(results) != 2 {
+		t.Fatalf("loadResumeResults() = %v, %v; want the two partial results", results, err)
+	}
+}
+
+func TestSaveEvalResultsJSONRoundTrip(t *testing.T) {
+	summary := EvalSummary{
+		Config: EvalConfig{
+			Provider:       "openai",
+			Model:          "gpt-4o",
+			Prompt:         "Extract all text",
+			Temperature:    0.2,
+			Timeout:        5 * time.Minute,
+			CSVPath:        "fixtures/images.csv",
+			TestRows:       []int{0, 2},
+			Timestamp:      "2025-01-02_03-04-05",
+			IgnorePatterns: []string{"|"},
+			SingleLine:     true,
+		},
+		Results: []EvalResult{{
+			Identifier:          "page1.jpg",
+			ImagePath:           "images/page1.jpg",
+			TranscriptPath:      "transcripts/page1.txt",
+			Public:              true,
+			ProviderResponse:    "école 世界\nline two",
+			CharacterSimilarity: 0.95,
+			CharacterAccuracy:   0.9,
+			WordAccuracy:        0.5,
+			TotalWordsOriginal:  4,
+			InputTokens:         1200,
+			OutputTokens:        34,
+		}},
+	}
+
+	outputPath := filepath.Join(t.TempDir(), "gpt-4o.json")
+	if err := saveEvalResults(summary, outputPath); err != nil {
+		t.Fatalf("saveEvalResults() error = %v", err)
+	}
+
+	data, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !json.Valid(data) {
+		t.Fatalf("saved file is not JSON: %s", data)
+	}
+
+	loaded, err := loadEvalSummary(outputPath)
+	if err != nil {
+		t.Fatalf("loadEvalSummary() error = %v", err)
+	}
+	if !reflect.DeepEqual(loaded, summary) {
+		t.Fatalf("round trip mismatch:\n  got:  %+v\n  want: %+v", loaded, summary)
+	}
+}
+
+func TestSaveEvalResultsCompressedRoundTrip(t *testing.T) {
+	summary := EvalSummary{
+		Config: EvalConfig{
+			Provider:  "openai",
+			Model:     "gpt-4o",
+			Prompt:    "Extract all text",
+			Timeout:   5 * time.Minute,
+			CSVPath:   "fixtures/images.csv",
+			Timestamp: "2025-01-02_03-04-05",
+		},
+		Results: []EvalResult{{
+			Identifier:       "page1.jpg",
+			ProviderResponse: strings.Repeat("école 世界\n", 200),
+			WordAccuracy:     0.5,
+		}},
+	}
+
+	for _, name := range []string{"gpt-4o.yaml.gz", "gpt-4o.json.gz", "gpt-4o.yaml.gz.partial"} {
+		t.Run(name, func(t *testing.T) {
+			outputPath := filepath.Join(t.TempDir(), name)
+			if err := saveEvalResults(summary, outputPath); err != nil {
+				t.Fatalf("saveEvalResults() error = %v", err)
+			}
+
+			data, err := os.ReadFile(outputPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			reader, err := gzip.NewReader(bytes.NewReader(data))
+			if err != nil {
+				t.Fatalf("saved file is not gzip compressed: %v", err)
+			}
+			plain, err := io.ReadAll(reader)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(data) >= len(plain) {
+				t.Errorf("compressed file is %d bytes, want fewer than the %d uncompressed", len(data), len(plain))
+			}
+			if wantJSON := evalFileFormat(outputPath) == "json"; json.Valid(plain) != wantJSON {
+				t.Errorf("decompressed %s: valid JSON = %v, want %v", name, !wantJSON, wantJSON)
+			}
+
+			loaded, err := loadEvalSummary(outputPath)
+			if err != nil {
+				t.Fatalf("loadEvalSummary() error = %v", err)
+			}
+			plainPath := filepath.Join(t.TempDir(), strings.Replace(name, ".gz", "", 1))
+			if err := saveEvalResults(summary, plainPath); err != nil {
+				t.Fatal(err)
+			}
+			want, err := loadEvalSummary(plainPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(loaded, want) || loaded.Results[0].ProviderResponse != summary.Results[0].ProviderResponse {
+				t.Fatalf("round trip mismatch:\n  got:  %+v\n  want: %+v", loaded, want)
+			}
+			config, err := loadEvalConfig(outputPath)
+			if err != nil || config.Model != "gpt-4o" {
+				t.Fatalf("loadEvalConfig() = %+v, %v", config, err)
+			}
+		})
+	}
+}
+
+func TestResolveEvalFile(t *testing.T) {
+	evalsDir := t.TempDir()
+	for _, name := range []string{"yaml-model.yaml", "json-model.json", "both.yaml", "both.json", "gz-model.yaml.gz", "openai_gpt-4o.yaml", "openai_shared.yaml", "azure_shared.json"} {
+		if err := os.WriteFile(filepath.Join(evalsDir, name), []byte("{}"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name string
+		arg  string
+		want string
+	}{
+		{"bare yaml name", "yaml-model", filepath.Join(evalsDir, "yaml-model.yaml")},
+		{"bare json name", "json-model", filepath.Join(evalsDir, "json-model.json")},
+		{"yaml preferred when both exist", "both", filepath.Join(evalsDir, "both.yaml")},
+		{"explicit json extension", "both.json", filepath.Join(evalsDir, "both.json")},
+		{"bare compressed name", "gz-model", filepath.Join(evalsDir, "gz-model.yaml.gz")},
+		{"explicit compressed extension", "gz-model.yaml.gz", filepath.Join(evalsDir, "gz-model.yaml.gz")},
+		{"missing defaults to yaml", "gemini-2.5-flash", filepath.Join(evalsDir, "gemini-2.5-flash.yaml")},
+		{"model name finds its provider's file", "gpt-4o", filepath.Join(evalsDir, "openai_gpt-4o.yaml")},
+		{"full provider file name", "openai_gpt-4o", filepath.Join(evalsDir, "openai_gpt-4o.yaml")},
+		{"model name run by several providers", "shared", filepath.Join(evalsDir, "shared.yaml")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := resolveEvalFile(evalsDir, tt.arg); got != tt.want {
+				t.Errorf("resolveEvalFile(%q) = %q, want %q", tt.arg, got, tt.want)
+			}
+		})
+	}
+
+	files, err := listEvalFiles(evalsDir)
+	if err != nil || len(files) != 8 {
+		t.Fatalf("listEvalFiles() = %v, %v; want 8 files", files, err)
+	}
+}
+
+func TestWriteMarkdownTable(t *testing.T) {
+	summaries := []ModelSummary{
+		{Model: "gpt-4o", TotalEvaluations: 3, AvgCharSimilarity: 0.9, AvgCharAccuracy: 0.85, AvgWordSimilarity: 0.8, AvgWordAccuracy: 0.75, AvgWordErrorRate: 0.25, AvgInputTokens: 1500, AvgOutputTokens: 750, PageCost: 0.01125},
+		{Model: "claude-sonnet-4-5-20250929", TotalEvaluations: 12, AvgCharSimilarity: 0.5, AvgCharAccuracy: 0.5, AvgWordSimilarity: 0.5, AvgWordAccuracy: 0.5, AvgWordErrorRate: 0.5},
+	}
+
+	tests := []struct {
+		name        string
+		includeCost bool
+		wantHeader  string
+		wantRow     string
+	}{
+		{
+			name:        "without cost",
+			includeCost: false,
+			wantHeader:  "| Model                      | TotalEvaluations | AvgCharSimilarity | AvgCharAccuracy | AvgWordSimilarity | AvgWordAccuracy | AvgWordErrorRate |",
+			wantRow:     "| gpt-4o                     |                3 |          0.900000 |        0.850000 |          0.800000 |        0.750000 |         0.250000 |",
+		},
+		{
+			name:        "with cost",
+			includeCost: true,
+			wantHeader:  "| Model                      | TotalEvaluations | AvgCharSimilarity | AvgCharAccuracy | AvgWordSimilarity | AvgWordAccuracy | AvgWordErrorRate | AvgInputTokens | AvgOutputTokens | PageCost |",
+			wantRow:     "| gpt-4o                     |                3 |          0.900000 |        0.850000 |          0.800000 |        0.750000 |         0.250000 |        1500.00 |          750.00 | 0.011250 |",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header, rows := modelSummaryTable(summaries, tt.includeCost, false)
+			var output strings.Builder
+			writeMarkdownTable(&output, header, rows)
+
+			lines := strings.Split(strings.TrimSuffix(output.String(), "\n"), "\n")
+			if len(lines) != 4 {
+				t.Fatalf("got %d lines, want header, separator, and 2 rows:\n%s", len(lines), output.String())
+			}
+			if lines[0] != tt.wantHeader {
+				t.Errorf("header:\n  got:  %q\n  want: %q", lines[0], tt.wantHeader)
+			}
+			if !strings.HasPrefix(lines[1], "| :---") || !strings.HasSuffix(lines[1], "-: |") {
+				t.Errorf("separator row = %q, want left-aligned model and right-aligned metrics", lines[1])
+			}
+			if lines[2] != tt.wantRow {
+				t.Errorf("row:\n  got:  %q\n  want: %q", lines[2], tt.wantRow)
+			}
+			for _, line := range lines {
+				if len(line) != len(lines[0]) {
+					t.Errorf("line %q is not aligned with the header", line)
+				}
+			}
+		})
+	}
+}
+
+func TestGeminiResolutionFallbackReachesProvider(t *testing.T) {
+	setEvalFlags(t, map[string]string{
+		"provider":                       "stub",
+		"gemini-max-resolution":          "MEDIA_RESOLUTION_HIGH",
+		"gemini-max-resolution-fallback": "true",
+	})
+	stub := &stubEvalProvider{responses: map[string]string{"page.jpg": "text"}}
+	useStubEvalProvider(t, stub)
+
+	config := evalConfigFromFlags()
+	configPath := filepath.Join(t.TempDir(), "previous.yaml")
+	if err := saveEvalResults(EvalSummary{Config: config}, configPath); err != nil {
+		t.Fatal(err)
+	}
+	rerun, err := loadEvalConfig(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, config := range map[string]EvalConfig{"flags": config, "--config rerun": rerun} {
+		stub.configs = nil
+		if _, _, err := extractTextWithProvider(context.Background(), config, "page.jpg", ""); err != nil {
+			t.Fatalf("%s: extractTextWithProvider() error = %v", name, err)
+		}
+		got := stub.configs[0]
+		if got.MaxResolution != "MEDIA_RESOLUTION_HIGH" || !got.MaxResolutionFallback {
+			t.Errorf("%s: provider config = %+v, want high resolution with fallback", name, got)
+		}
+	}
+}
+
+func TestPollIntervalReachesProvider(t *testing.T) {
+	setEvalFlags(t, map[string]string{"provider": "stub", "poll-interval": "3s"})
+	stub := &stubEvalProvider{responses: map[string]string{"page.jpg": "text"}}
+	useStubEvalProvider(t, stub)
+
+	config := evalConfigFromFlags()
+	configPath := filepath.Join(t.TempDir(), "previous.yaml")
+	if err := saveEvalResults(EvalSummary{Config: config}, configPath); err != nil {
+		t.Fatal(err)
+	}
+	rerun, err := loadEvalConfig(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, config := range map[string]EvalConfig{"flags": config, "--config rerun": rerun} {
+		stub.configs = nil
+		if _, _, err := extractTextWithProvider(context.Background(), config, "page.jpg", ""); err != nil {
+			t.Fatalf("%s: extractTextWithProvider() error = %v", name, err)
+		}
+		if got := stub.configs[0].PollInterval; got != 3*time.Second {
+			t.Errorf("%s: PollInterval = %v, want 3s", name, got)
+		}
+	}
+}
+
+func TestExtractTextWithProviderHonorsRPM(t *testing.T) {
+	stub := &stubEvalProvider{responses: map[string]string{"page.jpg": "text"}}
+	useStubEvalProvider(t, stub)
+	original := requestLimiter
+	t.Cleanup(func() { requestLimiter = original })
+	// One request every 50ms
+	requestLimiter = providers.NewRateLimiter(1200)
+
+	config := EvalConfig{Provider: "stub", Model: "model", Prompt: "Extract"}
+	start := time.Now()
+	for range 3 {
+		if _, _, err := extractTextWithProvider(context.Background(), config, "page.jpg", ""); err != nil {
+			t.Fatalf("extractTextWithProvider() error = %v", err)
+		}
+	}
+	if elapsed, want := time.Since(start), 100*time.Millisecond; elapsed < want {
+		t.Fatalf("3 requests at 1200 rpm took %s, want at least %s", elapsed, want)
+	}
+	if len(stub.calls) != 3 {
+		t.Fatalf("provider calls = %d, want 3", len(stub.calls))
+	}
+}
+
+func TestExtractTextWithProviderStripsPatterns(t *testing.T) {
+	stub := &stubEvalProvider{responses: map[string]string{"page.jpg": "| Transcription |\n|---|\nDear sir,\n(end of page)"}}
+	useStubEvalProvider(t, stub)
+
+	config := EvalConfig{
+		Provider:      "stub",
+		Model:         "model",
+		Prompt:        "Extract",
+		StripPatterns: []string{`\| Transcription \|\s*\|---\|`, `\(end of page\)`},
+		CacheDir:      t.TempDir(),
+	}
+	text, _, err := extractTextWithProvider(context.Background(), config, "page.jpg", "aW1hZ2U=")
+	if err != nil {
+		t.Fatalf("extractTextWithProvider() error = %v", err)
+	}
+	if text != "Dear sir," {
+		t.Errorf("text = %q, want the wrappers stripped", text)
+	}
+
+	// The cache holds the unstripped response, so other patterns apply to it
+	config.StripPatterns = nil
+	text, _, err = extractTextWithProvider(context.Background(), config, "page.jpg", "aW1hZ2U=")
+	if err != nil {
+		t.Fatalf("extractTextWithProvider() error = %v", err)
+	}
+	if len(stub.calls) != 1 || !strings.HasSuffix(text, "(end of page)") {
+		t.Errorf("cached text = %q after %d calls, want the unstripped response from the cache", text, len(stub.calls))
+	}
+
+	config.StripPatterns = []string{"(unclosed"}
+	if _, _, err := extractTextWithProvider(context.Background(), config, "page.jpg", "aW1hZ2U="); err == nil || !strings.Contains(err.Error(), "invalid --strip-pattern") {
+		t.Errorf("extractTextWithProvider() error = %v, want an invalid --strip-pattern error", err)
+	}
+}
+
+func TestExtractTextWithProviderMasksErrors(t *testing.T) {
+	stub := &stubEvalProvider{err: fmt.Errorf(`openAI API error: 401 - {"message": "Incorrect API key provided: sk-proj-abcdefghijklmnop"}`)}
+	useStubEvalProvider(t, stub)
+
+	_, _, err := extractTextWithProvider(context.Background(), EvalConfig{Provider: "stub", Model: "model"}, "page.jpg", "")
+	if err == nil {
+		t.Fatal("extractTextWithProvider() error = nil, want error")
+	}
+	if strings.Contains(err.Error(), "abcdefghijklmnop") || !strings.Contains(err.Error(), "sk-proj-***MASKED***") {
+		t.Errorf("extractTextWithProvider() error = %q, want the key masked", err)
+	}
+	if !errors.Is(err, stub.err) {
+		t.Errorf("extractTextWithProvider() error does not wrap the provider error")
+	}
+}
+
+func TestExtractTextWithProviderStopsRetryingWhenCanceled(t *testing.T) {
+	stub := &stubEvalProvider{err: providers.ErrorForStatus(http.StatusServiceUnavailable)}
+	useStubEvalProvider(t, stub)
+	ctx, cancel := context.WithCancel(context.Background())
+	stub.onCall = cancel
+	config := EvalConfig{Provider: "stub", Model: "model", MaxRetries: 3, RetryBaseDelay: time.Hour}
+
+	done := make(chan error, 1)
+	go func() {
+		_, _, err := extractTextWithProvider(ctx, config, "page.jpg", "")
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) || len(stub.calls) != 1 {
+			t.Errorf("error = %v after %d calls, want context.Canceled after 1 call", err, len(stub.calls))
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("extractTextWithProvider() kept backing off after the context was canceled")
+	}
+}
+
+func TestExtractTextWithProviderBoundsRetriesByTimeout(t *testing.T) {
+	stub := &stubEvalProvider{err: providers.ErrorForStatus(http.StatusTooManyRequests)}
+	useStubEvalProvider(t, stub)
+	config := EvalConfig{Provider: "stub", Model: "model", Timeout: time.Second, MaxRetries: 3, RetryBaseDelay: time.Hour}
+
+	start := time.Now()
+	_, _, err := extractTextWithProvider(context.Background(), config, "page.jpg", "")
+	if !errors.Is(err, providers.ErrRateLimited) || len(stub.calls) != 1 {
+		t.Errorf("error = %v after %d calls, want the rate limit error after 1 call", err, len(stub.calls))
+	}
+	if elapsed := time.Since(start); elapsed > config.Timeout {
+		t.Errorf("extractTextWithProvider() took %v, want a backoff past --timeout skipped", elapsed)
+	}
+}
+
+func TestSystemPromptReachesProvider(t *testing.T) {
+	setEvalFlags(t, map[string]string{"provider": "stub", "system-prompt": "You are a paleographer."})
+	stub := &stubEvalProvider{responses: map[string]string{"page.jpg": "text"}}
+	useStubEvalProvider(t, stub)
+
+	config := evalConfigFromFlags()
+	configPath := filepath.Join(t.TempDir(), "previous.yaml")
+	if err := saveEvalResults(EvalSummary{Config: config}, configPath); err != nil {
+		t.Fatal(err)
+	}
+	rerun, err := loadEvalConfig(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, config := range map[string]EvalConfig{"flags": config, "--config rerun": rerun} {
+		stub.configs = nil
+		if _, _, err := extractTextWithProvider(context.Background(), config, "page.jpg", ""); err != nil {
+			t.Fatalf("%s: extractTextWithProvider() error = %v", name, err)
+		}
+		if got := stub.configs[0].SystemPrompt; got != "You are a paleographer." {
+			t.Errorf("%s: SystemPrompt = %q", name, got)
+		}
+	}
+}
+
+func TestProcessEvaluationMultiPageRows(t *testing.T) {
+	csvPath := writeEvalFixtures(t,
+		map[string]string{"page1": "", "page2": "", "letter": "Dear Sir,\nI remain yours"},
+		"image,transcript,public\npage1.jpg; page2.jpg,letter.txt,true\npage2.jpg;page1.jpg,letter.txt,true\n",
+	)
+	stub := &stubEvalProvider{
+		responses: map[string]string{"page1.jpg": "Dear Sir,", "page2.jpg": "I remain yours"},
+		truncated: map[string]bool{"page2.jpg": true},
+	}
+	useStubEvalProvider(t, stub)
+
+	config := EvalConfig{Provider: "stub", Model: "model", Prompt: "Extract", CSVPath: csvPath, RowSpec: "0"}
+	results, err := processEvaluation(context.Background(), config, nil, "")
+	if err != nil {
+		t.Fatalf("processEvaluation() error = %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("processEvaluation() returned %d results, want 1", len(results))
+	}
+	result := results[0]
+	if !slices.Equal(stub.calls, []string{"page1.jpg", "page2.jpg"}) {
+		t.Errorf("provider calls = %v, want each page in order", stub.calls)
+	}
+	if result.ProviderResponse != "Dear Sir,\nI remain yours" || result.WordAccuracy != 1 || result.TotalWordsOriginal != 5 {
+		t.Errorf("combined result = %q, word accuracy %v over %d words", result.ProviderResponse, result.WordAccuracy, result.TotalWordsOriginal)
+	}
+	if result.InputTokens != 20 || result.OutputTokens != 10 || !result.Truncated {
+		t.Errorf("usage = %d in, %d out, truncated %v; want the sum of both pages", result.InputTokens, result.OutputTokens, result.Truncated)
+	}
+	if result.Identifier != "page1.jpg" || !strings.HasSuffix(result.ImagePath, "page1.jpg;"+filepath.Join(dir, "page2.jpg")) {
+		t.Errorf("Identifier = %q, ImagePath = %q", result.Identifier, result.ImagePath)
+	}
+
+	// Pages out of order are scored as transcribed
+	stub.calls = nil
+	config.RowSpec = "1"
+	results, err = processEvaluation(context.Background(), config, nil, "")
+	if err != nil {
+		t.Fatalf("processEvaluation() error = %v", err)
+	}
+	if results[0].ProviderResponse != "I remain yours\nDear Sir," || results[0].WordAccuracy >= 1 || results[0].Identifier != "page2.jpg" {
+		t.Errorf("reversed result = %q, word accuracy %v, identifier %q", results[0].ProviderResponse, results[0].WordAccuracy, results[0].Identifier)
+	}
+
+	// A resumed run recognizes the row by its first page
+	stub.calls = nil
+	config.RowSpec = "0"
+	if _, err := processEvaluation(context.Background(), config, []EvalResult{result}, ""); err != nil {
+		t.Fatalf("processEvaluation() error = %v", err)
+	}
+	if len(stub.calls) != 0 {
+		t.Errorf("resumed run called the provider for %v", stub.calls)
+	}
+}
+
+func TestWordConfidences(t *testing.T) {
+	tests := []struct {
+		name     string
+		logprobs []providers.TokenLogprob
+		want     []WordConfidence
+	}{
+		{name: "no tokens"},
+		{
+			name:     "tokens joined into words",
+			logprobs: []providers.TokenLogprob{{Token: "Dear", Logprob: -0.1}, {Token: " Sir", Logprob: 0}, {Token: ",", Logprob: -0.2}, {Token: "\nI", Logprob: 0}, {Token: " re", Logprob: -0.3}, {Token: "main", Logprob: -0.4}},
+			want:     []WordConfidence{{Word: "Dear", Confidence: math.Exp(-0.1)}, {Word: "Sir,", Confidence: math.Exp(-0.2)}, {Word: "I", Confidence: 1}, {Word: "remain", Confidence: math.Exp(-0.7)}},
+		},
+		{
+			name:     "token spanning a word break",
+			logprobs: []providers.TokenLogprob{{Token: "New", Logprob: -0.5}, {Token: " York", Logprob: -0.25}, {Token: "ed  by", Logprob: -1}},
+			want:     []WordConfidence{{Word: "New", Confidence: math.Exp(-0.5)}, {Word: "Yorked", Confidence: math.Exp(-1.25)}, {Word: "by", Confidence: math.Exp(-1)}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := wordConfidences(tt.logprobs)
+			if len(got) != len(tt.want) {
+				t.Fatalf("wordConfidences() = %+v, want %+v", got, tt.want)
+			}
+			for i := range got {
+				if got[i].Word != tt.want[i].Word || math.Abs(got[i].Confidence-tt.want[i].Confidence) > 1e-9 {
+					t.Errorf("word %d = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestProcessRowRecordsOpenAILogprobs(t *testing.T) {
+	writeEvalFixtures(t, map[string]string{"letter": "Dear Sir"}, "letter.jpg,letter.txt,true\n")
+	row := []string{"letter.jpg", "letter.txt", "true"}
+	var requests []map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Error(err)
+		}
+		requests = append(requests, body)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"model":"gpt-4o","choices":[{"message":{"content":"Dear Sir"},"finish_reason":"stop","logprobs":{"content":[{"token":"Dear","logprob":-0.05,"bytes":[68,101,97,114],"top_logprobs":[]},{"token":" Sir","logprob":-2.3,"bytes":[32,83,105,114],"top_logprobs":[]}]}}],"usage":{"prompt_tokens":100,"completion_tokens":2}}`))
+	}))
+	t.Cleanup(server.Close)
+	t.Setenv("OPENAI_API_KEY", "test-key")
+	t.Setenv("OPENAI_BASE_URL", server.URL)
+
+	config := EvalConfig{Provider: "openai", Model: "gpt-4o", Prompt: "Transcribe", Logprobs: true}
+	result, err := processRow(context.Background(), row, config)
+	if err != nil {
+		t.Fatalf("processRow() error = %v", err)
+	}
+	if len(requests) != 1 || requests[0]["logprobs"] != true {
+		t.Fatalf("requests = %v, want one asking for logprobs", requests)
+	}
+	want := []WordConfidence{{Word: "Dear", Confidence: math.Exp(-0.05)}, {Word: "Sir", Confidence: math.Exp(-2.3)}}
+	if !reflect.DeepEqual(result.WordConfidences, want) {
+		t.Errorf("WordConfidences = %+v, want %+v", result.WordConfidences, want)
+	}
+	if result.WordAccuracy != 1 || result.InputTokens != 100 {
+		t.Errorf("result = %+v, want the usual metrics alongside the confidences", result)
+	}
+
+	config.Logprobs = false
+	result, err = processRow(context.Background(), row, config)
+	if err != nil {
+		t.Fatalf("processRow() without --logprobs error = %v", err)
+	}
+	if _, sent := requests[1]["logprobs"]; sent || result.WordConfidences != nil {
+		t.Errorf("without --logprobs the request sent logprobs = %v and WordConfidences = %+v", sent, result.WordConfidences)
+	}
+}
+
+func TestProcessRowLogprobsIgnoredByOtherProviders(t *testing.T) {
+	writeEvalFixtures(t, map[string]string{"letter": "Dear Sir"}, "letter.jpg,letter.txt,true\n")
+	row := []string{"letter.jpg", "letter.txt", "true"}
+	stub := &stubEvalProvider{responses: map[string]string{"letter.jpg": "Dear Sir"}}
+	useStubEvalProvider(t, stub)
+
+	result, err := processRow(context.Background(), row, EvalConfig{Provider: "stub", Model: "model", Prompt: "Transcribe", Logprobs: true})
+	if err != nil {
+		t.Fatalf("processRow() error = %v", err)
+	}
+	if len(stub.calls) != 1 || result.WordAccuracy != 1 || result.WordConfidences != nil {
+		t.Errorf("stub received %d calls and result has WordConfidences %+v; want a normal row without confidences", len(stub.calls), result.WordConfidences)
+	}
+}
+
+func TestProcessRowStoresNormalizedTexts(t *testing.T) {
+	writeEvalFixtures(t, map[string]string{"letter": "Dear Sir,\nI remain"}, "letter.jpg,letter.txt,true\n")
+	row := []string{"letter.jpg", "letter.txt", "true"}
+	setEvalFlags(t, map[string]string{"single-line": "false"})
+	flags := evalCmd.Flags()
+
+	tests := []struct {
+		name         string
+		singleLine   bool
+		store        bool
+		response     string
+		wantTruth    string
+		wantResponse string
+	}{
+		{name: "single line", singleLine: true, store: true, response: "Dear  Sir,\nI remane", wantTruth: "Dear Sir, I remain", wantResponse: "Dear Sir, I remane"},
+		{name: "not requested", singleLine: true, response: "Dear  Sir,\nI remane"},
+		{name: "nothing normalized", store: true, response: "Dear Sir,\nI remane"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useStubEvalProvider(t, &stubEvalProvider{responses: map[string]string{"letter.jpg": tt.response}})
+			if err := flags.Set("single-line", fmt.Sprint(tt.singleLine)); err != nil {
+				t.Fatal(err)
+			}
+
+			result, err := processRow(context.Background(), row, EvalConfig{Provider: "stub", Model: "model", Prompt: "Transcribe", SingleLine: tt.singleLine, StoreNormalized: tt.store})
+			if err != nil {
+				t.Fatalf("processRow() error = %v", err)
+			}
+			if result.NormalizedGroundTruth != tt.wantTruth || result.NormalizedResponse != tt.wantResponse {
+				t.Errorf("normalized = %q, %q; want %q, %q", result.NormalizedGroundTruth, result.NormalizedResponse, tt.wantTruth, tt.wantResponse)
+			}
+			if result.ProviderResponse != tt.response {
+				t.Errorf("ProviderResponse = %q, want the raw response %q", result.ProviderResponse, tt.response)
+			}
+		})
+	}
+}
+
+func TestBackfillRecordsNormalizedTexts(t *testing.T) {
+	dir := t.TempDir()
+	transcript := filepath.Join(dir, "letter.txt")
+	if err := os.WriteFile(transcript, []byte("Dear Sir,\nI remain"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	originalEvalsDir := evalsDir
+	t.Cleanup(func() { evalsDir = originalEvalsDir })
+	evalsDir = dir
+	evalFile := filepath.Join(dir, "stub_model.yaml")
+	summary := EvalSummary{
+		Config:  EvalConfig{Provider: "stub", Model: "model", SingleLine: true, IgnoreCase: true, StoreNormalized: true},
+		Results: []EvalResult{{Identifier: "letter.jpg", TranscriptPath: transcript, ProviderResponse: "Dear sir,\nI remain"}},
+	}
+	if err := saveEvalResults(summary, evalFile); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := runBackfill(backfillCmd, nil); err != nil {
+		t.Fatalf("runBackfill() error = %v", err)
+	}
+	backfilled, err := loadEvalSummary(evalFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := backfilled.Results[0]
+	if result.NormalizedGroundTruth != "dear sir, i remain" || result.NormalizedResponse != "dear sir, i remain" {
+		t.Errorf("normalized = %q, %q; want both texts as compared", result.NormalizedGroundTruth, result.NormalizedResponse)
+	}
+}
+
+func TestEvalWithoutPromptRecordsDefault(t *testing.T) {
+	csvPath := writeEvalFixtures(t, map[string]string{"page": "hello world"}, "page.jpg,page.txt,true\n")
+	setEvalFlags(t, map[string]string{"provider": "stub", "model": "stub-model", "csv": csvPath, "quiet": "true"})
+	stub := &stubEvalProvider{responses: map[string]string{"page.jpg": "hello world"}}
+	useStubEvalProvider(t, stub)
+	t.Chdir(t.TempDir())
+
+	if err := runEval(evalCmd, nil); err != nil {
+		t.Fatalf("runEval() without --prompt error = %v", err)
+	}
+
+	if len(stub.configs) != 1 || stub.configs[0].Prompt != defaultOCRPrompt {
+		t.Fatalf("provider configs = %+v, want the default prompt", stub.configs)
+	}
+	summary, err := loadEvalSummary(filepath.Join(evalsDir, "stub_stub-model.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.Config.Prompt != defaultOCRPrompt {
+		t.Errorf("recorded prompt = %q, want %q", summary.Config.Prompt, defaultOCRPrompt)
+	}
+}
+
+func TestEvalWritesToEvalsDir(t *testing.T) {
+	csvPath := writeEvalFixtures(t, map[string]string{"page": "hello world"}, "page.jpg,page.txt,true\n")
+	setEvalFlags(t, map[string]string{"provider": "stub", "model": "stub-model", "csv": csvPath, "quiet": "true"})
+	originalEvalsDir := evalsDir
+	t.Cleanup(func() { evalsDir = originalEvalsDir })
+	useStubEvalProvider(t, &stubEvalProvider{responses: map[string]string{"page.jpg": "hello world"}})
+	t.Chdir(t.TempDir())
+
+	if err := RootCmd.PersistentFlags().Set("evals-dir", filepath.Join("results", "corpus-a")); err != nil {
+		t.Fatal(err)
+	}
+	if err := runEval(evalCmd, nil); err != nil {
+		t.Fatalf("runEval() error = %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join("results", "corpus-a", "stub_stub-model.yaml")); err != nil {
+		t.Fatalf("eval file was not written to --evals-dir: %v", err)
+	}
+	if _, err := os.Stat("evals"); !os.IsNotExist(err) {
+		t.Errorf("default evals directory was created: %v", err)
+	}
+	if _, err := loadEvalSummary(resolveEvalFile(evalsDir, "stub-model")); err != nil {
+		t.Errorf("eval file is not found by name in --evals-dir: %v", err)
+	}
+}
+
+func TestCheckAccuracyThresholds(t *testing.T) {
+	results := []EvalResult{
+		{WordAccuracy: 0.9, CharacterAccuracy: 0.95},
+		{WordAccuracy: 0.7, CharacterAccuracy: 0.85},
+	}
+
+	tests := []struct {
+		name    string
+		results []EvalResult
+		minWord float64
+		minChar float64
+		wantErr string
+	}{
+		{name: "no thresholds", results: results},
+		{name: "both met", results: results, minWord: 0.75, minChar: 0.85},
+		{name: "word below", results: results, minWord: 0.85, wantErr: "average word accuracy 0.800 is below --min-word-accuracy 0.850"},
+		{name: "char below", results: results, minChar: 0.95, wantErr: "average character accuracy 0.900 is below --min-char-accuracy 0.950"},
+		{name: "no results", minWord: 0.5, wantErr: "average word accuracy 0.000"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkAccuracyThresholds(tt.results, tt.minWord, tt.minChar)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("checkAccuracyThresholds() error = %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, errBelowAccuracyThreshold) || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("checkAccuracyThresholds() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestEvalExitCodeForAccuracyThresholds(t *testing.T) {
+	csvPath := writeEvalFixtures(t, map[string]string{"page": "hello world"}, "page.jpg,page.txt,true\n")
+	setEvalFlags(t, map[string]string{"provider": "stub", "model": "stub-model", "csv": csvPath, "quiet": "true", "min-word-accuracy": "0", "min-char-accuracy": "0"})
+	flags := evalCmd.Flags()
+	originalEvalsDir := evalsDir
+	t.Cleanup(func() { evalsDir = originalEvalsDir })
+	evalsDir = t.TempDir()
+	// "hello word" gets one of two words right
+	useStubEvalProvider(t, &stubEvalProvider{responses: map[string]string{"page.jpg": "hello word"}})
+
+	tests := []struct {
+		name     string
+		minWord  string
+		minChar  string
+		wantCode int
+	}{
+		{name: "passing run", minWord: "0.5", minChar: "0.5", wantCode: 0},
+		{name: "word accuracy regression", minWord: "0.9", minChar: "0", wantCode: 3},
+		{name: "character accuracy regression", minWord: "0", minChar: "0.99", wantCode: 3},
+		{name: "invalid threshold", minWord: "1.5", minChar: "0", wantCode: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := flags.Set("min-word-accuracy", tt.minWord); err != nil {
+				t.Fatal(err)
+			}
+			if err := flags.Set("min-char-accuracy", tt.minChar); err != nil {
+				t.Fatal(err)
+			}
+			err := runEval(evalCmd, nil)
+			if got := ExitCode(err); got != tt.wantCode {
+				t.Errorf("ExitCode(%v) = %d, want %d", err, got, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestEvalBedrockReplacesOpenAIDefaultModel(t *testing.T) {
+	csvPath := writeEvalFixtures(t, map[string]string{"page": "hello world"}, "page.jpg,page.txt,true\n")
+	setEvalFlags(t, map[string]string{"provider": "bedrock", "model": "gpt-4o", "csv": csvPath, "quiet": "true"})
+	useStubEvalProvider(t, &stubEvalProvider{name: "bedrock", responses: map[string]string{"page.jpg": "hello world"}})
+	t.Chdir(t.TempDir())
+
+	t.Setenv("BEDROCK_MODEL", "")
+	if err := runEval(evalCmd, nil); err == nil || !strings.Contains(err.Error(), "BEDROCK_MODEL") {
+		t.Fatalf("runEval() without a Bedrock model error = %v, want a BEDROCK_MODEL hint", err)
+	}
+
+	t.Setenv("BEDROCK_MODEL", "amazon.nova-pro-v1:0")
+	if err := runEval(evalCmd, nil); err != nil {
+		t.Fatalf("runEval() error = %v", err)
+	}
+	files, err := listEvalFiles(evalsDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 || !strings.Contains(files[0], "amazon.nova-pro-v1") {
+		t.Fatalf("eval files = %v, want one recorded under BEDROCK_MODEL", files)
+	}
+}
+
+func TestEvalKeepsProvidersOfOneModelApart(t *testing.T) {
+	csvPath := writeEvalFixtures(t, map[string]string{"page": "hello world"}, "page.jpg,page.txt,true\n")
+	setEvalFlags(t, map[string]string{"provider": "direct", "model": "gpt-4o", "csv": csvPath, "quiet": "true"})
+	flags := evalCmd.Flags()
+	useStubEvalProvider(t, &stubEvalProvider{name: "direct", responses: map[string]string{"page.jpg": "hello world"}})
+	providerRegistry.Register(&stubEvalProvider{name: "proxy", responses: map[string]string{"page.jpg": "hello"}})
+	t.Chdir(t.TempDir())
+
+	for _, provider := range []string{"direct", "proxy"} {
+		if err := flags.Set("provider", provider); err != nil {
+			t.Fatal(err)
+		}
+		if err := runEval(evalCmd, nil); err != nil {
+			t.Fatalf("runEval() with provider %s error = %v", provider, err)
+		}
+	}
+
+	files, err := listEvalFiles(evalsDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{filepath.Join(evalsDir, "direct_gpt-4o.yaml"), filepath.Join(evalsDir, "proxy_gpt-4o.yaml")}
+	if !slices.Equal(files, want) {
+		t.Fatalf("eval files = %v, want one per provider %v", files, want)
+	}
+	for i, provider := range []string{"direct", "proxy"} {
+		summary, err := loadEvalSummary(files[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if summary.Config.Provider != provider {
+			t.Errorf("%s holds the %s run, want %s", files[i], summary.Config.Provider, provider)
+		}
+	}
+}
+
+func TestResumeReadsLegacyModelNamedFile(t *testing.T) {
+	csvPath := writeEvalFixtures(t,
+		map[string]string{"page1": "hello world", "page2": "second page"},
+		"page1.jpg,page1.txt,true\npage2.jpg,page2.txt,true\n",
+	)
+	setEvalFlags(t, map[string]string{"provider": "stub", "model": "stub-model", "csv": csvPath, "quiet": "true", "resume": "true"})
+	stub := &stubEvalProvider{responses: map[string]string{"page1.jpg": "hello world", "page2.jpg": "second page"}}
+	useStubEvalProvider(t, stub)
+	t.Chdir(t.TempDir())
+
+	legacyPartial := filepath.Join(evalsDir, "stub-model.yaml.partial")
+	if err := os.MkdirAll(evalsDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := saveEvalResults(EvalSummary{Results: []EvalResult{{Identifier: "page1.jpg", WordAccuracy: 1}}}, legacyPartial); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := runEval(evalCmd, nil); err != nil {
+		t.Fatalf("runEval() error = %v", err)
+	}
+	if !slices.Equal(stub.calls, []string{"page2.jpg"}) {
+		t.Errorf("provider calls = %v, want only the row missing from the legacy partial file", stub.calls)
+	}
+	summary, err := loadEvalSummary(filepath.Join(evalsDir, "stub_stub-model.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(summary.Results) != 2 {
+		t.Errorf("resumed eval file has %d results, want 2", len(summary.Results))
+	}
+}
+
+func TestDefaultEvalsDir(t *testing.T) {
+	t.Setenv("HTR_EVALS_DIR", "")
+	if got := defaultEvalsDir(); got != "evals" {
+		t.Errorf("defaultEvalsDir() = %q, want evals", got)
+	}
+	t.Setenv("HTR_EVALS_DIR", "/data/htr/letters")
+	if got := defaultEvalsDir(); got != "/data/htr/letters" {
+		t.Errorf("defaultEvalsDir() with HTR_EVALS_DIR = %q", got)
+	}
+}
+
+func TestPromptSuffixIsAppendedAndPersisted(t *testing.T) {
+	setEvalFlags(t, map[string]string{"provider": "stub", "prompt": "Transcribe the page.\n", "prompt-suffix": "This is Secretary hand."})
+	stub := &stubEvalProvider{responses: map[string]string{"page.jpg": "text"}}
+	useStubEvalProvider(t, stub)
+
+	config := evalConfigFromFlags()
+	configPath := filepath.Join(t.TempDir(), "previous.yaml")
+	if err := saveEvalResults(EvalSummary{Config: config}, configPath); err != nil {
+		t.Fatal(err)
+	}
+	rerun, err := loadEvalConfig(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rerun.Prompt != "Transcribe the page.\n" || rerun.PromptSuffix != "This is Secretary hand." {
+		t.Errorf("saved Prompt, PromptSuffix = %q, %q; want them stored separately", rerun.Prompt, rerun.PromptSuffix)
+	}
+
+	for name, config := range map[string]EvalConfig{"flags": config, "--config rerun": rerun} {
+		stub.configs = nil
+		if _, _, err := extractTextWithProvider(context.Background(), config, "page.jpg", ""); err != nil {
+			t.Fatalf("%s: extractTextWithProvider() error = %v", name, err)
+		}
+		if got, want := stub.configs[0].Prompt, "Transcribe the page.\n\nThis is Secretary hand."; got != want {
+			t.Errorf("%s: Prompt = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestOllamaOptionsReachProvider(t *testing.T) {
+	setEvalFlags(t, map[string]string{"provider": "stub", "ollama-keep-alive": "-1", "ollama-num-ctx": "8192"})
+	stub := &stubEvalProvider{responses: map[string]string{"page.jpg": "text"}}
+	useStubEvalProvider(t, stub)
+
+	config := evalConfigFromFlags()
+	configPath := filepath.Join(t.TempDir(), "previous.yaml")
+	if err := saveEvalResults(EvalSummary{Config: config}, configPath); err != nil {
+		t.Fatal(err)
+	}
+	rerun, err := loadEvalConfig(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, config := range map[string]EvalConfig{"flags": config, "--config rerun": rerun} {
+		stub.configs = nil
+		if _, _, err := extractTextWithProvider(context.Background(), config, "page.jpg", ""); err != nil {
+			t.Fatalf("%s: extractTextWithProvider() error = %v", name, err)
+		}
+		if got := stub.configs[0]; got.KeepAlive != "-1" || got.NumCtx != 8192 {
+			t.Errorf("%s: KeepAlive, NumCtx = %q, %d; want -1, 8192", name, got.KeepAlive, got.NumCtx)
+		}
+	}
+}
+
+func TestValidateOllamaOptions(t *testing.T) {
+	tests := []struct {
+		keepAlive string
+		numCtx    int
+		wantErr   string
+	}{
+		{keepAlive: "5m"},
+		{keepAlive: "1h30m", numCtx: 4096},
+		{keepAlive: "-1"},
+		{keepAlive: "0"},
+		{keepAlive: ""},
+		{keepAlive: "forever", wantErr: "invalid --ollama-keep-alive"},
+		{keepAlive: "5 minutes", wantErr: "invalid --ollama-keep-alive"},
+		{keepAlive: "5m", numCtx: -1, wantErr: "--ollama-num-ctx cannot be negative"},
+	}
+	for _, tt := range tests {
+		err := validateOllamaOptions(tt.keepAlive, tt.numCtx)
+		if tt.wantErr == "" && err != nil {
+			t.Errorf("validateOllamaOptions(%q, %d) error = %v", tt.keepAlive, tt.numCtx, err)
+		}
+		if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+			t.Errorf("validateOllamaOptions(%q, %d) error = %v, want %q", tt.keepAlive, tt.numCtx, err, tt.wantErr)
+		}
+	}
+}
+
+func TestValidateOpenAIDetail(t *testing.T) {
+	tests := []struct {
+		provider string
+		detail   string
+		wantErr  string
+	}{
+		{provider: "openai", detail: ""},
+		{provider: "openai", detail: "low"},
+		{provider: "openai", detail: "high"},
+		{provider: "azure-openai", detail: "auto"},
+		{provider: "openai-compat", detail: "low"},
+		{provider: "claude", detail: ""},
+		{provider: "openai", detail: "LOW", wantErr: "invalid --openai-detail"},
+		{provider: "openai", detail: "medium", wantErr: "invalid --openai-detail"},
+		{provider: "claude", detail: "low", wantErr: "not supported by provider claude"},
+	}
+	for _, tt := range tests {
+		err := validateOpenAIDetail(tt.provider, tt.detail)
+		if tt.wantErr == "" && err != nil {
+			t.Errorf("validateOpenAIDetail(%q, %q) error = %v", tt.provider, tt.detail, err)
+		}
+		if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+			t.Errorf("validateOpenAIDetail(%q, %q) error = %v, want %q", tt.provider, tt.detail, err, tt.wantErr)
+		}
+	}
+}
+
+func TestProcessEvaluationDownscalesLargeImages(t *testing.T) {
+	imageDir := t.TempDir()
+	sizes := map[string][2]int{"large.png": {400, 200}, "small.png": {80, 60}}
+	for name, size := range sizes {
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, size[0], size[1]))); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(imageDir, name), buf.Bytes(), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	stub := &stubEvalProvider{}
+	useStubEvalProvider(t, stub)
+
+	config := EvalConfig{Provider: "stub", Model: "model", Prompt: "Extract", Images: imageDir, MaxDimension: 100}
+	results, err := processEvaluation(context.Background(), config, nil, "")
+	if err != nil {
+		t.Fatalf("processEvaluation() error = %v", err)
+	}
+
+	var sent []image.Config
+	for _, encoded := range stub.images {
+		data, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			t.Fatal(err)
+		}
+		config, err := png.DecodeConfig(bytes.NewReader(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		sent = append(sent, config)
+	}
+
+	// Images are processed in sorted order: large.png, then small.png.
+	if len(sent) != 2 || sent[0].Width != 100 || sent[0].Height != 50 || sent[1].Width != 80 || sent[1].Height != 60 {
+		t.Fatalf("sent image sizes = %+v, want 100x50 and 80x60", sent)
+	}
+	if results[0].OriginalSize != "400x200" || results[0].SentSize != "100x50" {
+		t.Errorf("large image sizes = %q -> %q, want 400x200 -> 100x50", results[0].OriginalSize, results[0].SentSize)
+	}
+	if results[1].OriginalSize != "80x60" || results[1].SentSize != "80x60" {
+		t.Errorf("small image sizes = %q -> %q, want it left at 80x60", results[1].OriginalSize, results[1].SentSize)
+	}
+}
+
+func TestResolveTokenPrice(t *testing.T) {
+	table := pricing.Table{"gpt-4o": {Input: 2.5, Output: 10}}
+
+	tests := []struct {
+		name      string
+		model     string
+		explicit  bool
+		input     float64
+		output    float64
+		wantPrice pricing.Price
+		wantOK    bool
+	}{
+		{name: "known model uses table", model: "gpt-4o", wantPrice: pricing.Price{Input: 2.5, Output: 10}, wantOK: true},
+		{name: "dated model uses prefix", model: "gpt-4o-2024-08-06", wantPrice: pricing.Price{Input: 2.5, Output: 10}, wantOK: true},
+		{name: "flags override table", model: "gpt-4o", explicit: true, input: 1, output: 2, wantPrice: pricing.Price{Input: 1, Output: 2}, wantOK: true},
+		{name: "flags price unknown model", model: "custom", explicit: true, input: 1, output: 0, wantPrice: pricing.Price{Input: 1}, wantOK: true},
+		{name: "unknown model without flags", model: "custom", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			price, ok := resolveTokenPrice(table, tt.model, tt.explicit, tt.input, tt.output)
+			if ok != tt.wantOK {
+				t.Fatalf("resolveTokenPrice() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if ok && price != tt.wantPrice {
+				t.Errorf("resolveTokenPrice() = %+v, want %+v", price, tt.wantPrice)
+			}
+		})
+	}
+}
+
+func TestLoadPriceTableOverridesDefaults(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prices.yaml")
+	if err := os.WriteFile(path, []byte("gpt-4o:\n  input: 1.0\n  output: 3.0\nlocal-model:\n  input: 0\n  output: 0\n"), 0o644); err != nil {
+		t.Fatalf("failed to write price file: %v", err)
+	}
+
+	table, err := loadPriceTable(path)
+	if err != nil {
+		t.Fatalf("loadPriceTable() error = %v", err)
+	}
+	if price, _ := table.Lookup("gpt-4o"); price != (pricing.Price{Input: 1, Output: 3}) {
+		t.Errorf("gpt-4o price = %+v, want override", price)
+	}
+	if _, ok := table.Lookup("local-model"); !ok {
+		t.Error("expected local-model to be added from price file")
+	}
+	if _, ok := table.Lookup("claude-sonnet-4-5"); !ok {
+		t.Error("expected built-in prices to remain after override")
+	}
+}
+
+func TestEstimateTokenCost(t *testing.T) {
+	results := []EvalResult{
+		{InputTokens: 1000, OutputTokens: 500},
+		{InputTokens: 2000, OutputTokens: 1000},
+		{PageCount: 1}, // no token data; excluded from the averages
+	}
+
+	estimate, err := estimateTokenCost(results, pricing.Price{Input: 2.5, Output: 10})
+	if err != nil {
+		t.Fatalf("estimateTokenCost() error = %v", err)
+	}
+	if estimate.PageBased {
+		t.Error("expected token-based estimate")
+	}
+	if estimate.Documents != 2 {
+		t.Errorf("Documents = %d, want 2", estimate.Documents)
+	}
+	if math.Abs(estimate.TotalCostPerDoc()-0.01125) > 1e-9 {
+		t.Errorf("TotalCostPerDoc() = %f, want 0.01125", estimate.TotalCostPerDoc())
+	}
+
+	if _, err := estimateTokenCost([]EvalResult{{PageCount: 2}}, pricing.Price{Input: 1}); err == nil {
+		t.Error("expected an error when no result has token data")
+	}
+}
+
+func TestEstimatePageCost(t *testing.T) {
+	tests := []struct {
+		name         string
+		results      []EvalResult
+		pagePrice    float64
+		wantAvgPages float64
+		wantPerDoc   float64
+	}{
+		{
+			name:         "recorded page counts",
+			results:      []EvalResult{{PageCount: 1}, {PageCount: 3}},
+			pagePrice:    0.0015,
+			wantAvgPages: 2,
+			wantPerDoc:   0.003,
+		},
+		{
+			name:         "missing page counts count as one page",
+			results:      []EvalResult{{InputTokens: 1000}, {PageCount: 2}},
+			pagePrice:    0.01,
+			wantAvgPages: 1.5,
+			wantPerDoc:   0.015,
+		},
+		{
+			name:         "no results",
+			pagePrice:    0.01,
+			wantAvgPages: 0,
+			wantPerDoc:   0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			estimate := estimatePageCost(tt.results, tt.pagePrice)
+			if !estimate.PageBased {
+				t.Error("expected page-based estimate")
+			}
+			if math.Abs(estimate.AvgPages-tt.wantAvgPages) > 1e-9 {
+				t.Errorf("AvgPages = %f, want %f", estimate.AvgPages, tt.wantAvgPages)
+			}
+			if math.Abs(estimate.TotalCostPerDoc()-tt.wantPerDoc) > 1e-9 {
+				t.Errorf("TotalCostPerDoc() = %f, want %f", estimate.TotalCostPerDoc(), tt.wantPerDoc)
+			}
+		})
+	}
+}
+
+func TestPrintCostEstimateIndicatesPricingMode(t *testing.T) {
+	tests := []struct {
+		name     string
+		estimate costEstimate
+		want     []string
+	}{
+		{
+			name:     "token-based",
+			estimate: costEstimate{Documents: 1, Price: pricing.Price{Input: 2.5, Output: 10}, AvgInputTokens: 1000, InputCostPerDoc: 0.0025},
+			want:     []string{"Pricing mode: token-based", "Input token price: $2.50 per 1M tokens", "Total cost: $2.50"},
+		},
+		{
+			name:     "page-based",
+			estimate: costEstimate{PageBased: true, Documents: 1, PagePrice: 0.0015, AvgPages: 1, InputCostPerDoc: 0.0015},
+			want:     []string{"Pricing mode: page-based", "Page price: $0.0015 per page", "Total cost: $1.50"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf strings.Builder
+			printCostEstimate(&buf, tt.estimate, 1000)
+			for _, want := range tt.want {
+				if !strings.Contains(buf.String(), want) {
+					t.Errorf("output missing %q:\n%s", want, buf.String())
+				}
+			}
+		})
+	}
+}
+
+func TestFormatWordDiff(t *testing.T) {
+	tests := []struct {
+		name        string
+		groundTruth string
+		response    string
+		ignoreCase  bool
+		want        string
+	}{
+		{name: "identical", groundTruth: "the quick fox", response: "the quick fox", want: "the quick fox"},
+		{name: "substitution", groundTruth: "the quick fox", response: "the quack fox", want: "the quick→quack fox"},
+		{name: "deletion", groundTruth: "the quick brown fox", response: "the quick fox", want: "the quick -brown fox"},
+		{name: "insertion", groundTruth: "the fox", response: "the fox jumps", want: "the fox +jumps"},
+		{name: "ignore case uses normalized words", groundTruth: "The Fox", response: "the fox", ignoreCase: true, want: "the fox"},
+		{name: "empty transcription", groundTruth: "a b", response: "", want: "-a -b"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := htrmetrics.Evaluate(tt.groundTruth, tt.response, htrmetrics.Options{IgnoreCase: tt.ignoreCase, Alignment: true})
+			if got := formatWordDiff(result); got != tt.want {
+				t.Errorf("formatWordDiff() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProcessEvaluationScoresBestReference(t *testing.T) {
+	csvPath := writeEvalFixtures(t,
+		map[string]string{
+			"page1":   "the kyng hath sent his letters",
+			"page1-b": "the king has sent his letters",
+			"page2":   "one reference only",
+		},
+		"image,transcript,public\npage1.jpg,page1.txt | page1-b.txt,true\npage2.jpg,page2.txt,true\n",
+	)
+	useStubEvalProvider(t, &stubEvalProvider{responses: map[string]string{
+		"page1.jpg": "the king has sent his letters",
+		"page2.jpg": "one reference only",
+	}})
+
+	config := EvalConfig{Provider: "stub", Model: "model", Prompt: "Extract", CSVPath: csvPath}
+	results, err := processEvaluation(context.Background(), config, nil, "")
+	if err != nil {
+		t.Fatalf("processEvaluation() error = %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("processEvaluation() returned %d results, want 2", len(results))
+	}
+
+	multi := results[0]
+	if multi.WordAccuracy != 1 || multi.CharacterAccuracy != 1 {
+		t.Errorf("word accuracy = %v, character accuracy = %v; want 1 against the second reference", multi.WordAccuracy, multi.CharacterAccuracy)
+	}
+	if multi.Reference != 2 || filepath.Base(multi.TranscriptPath) != "page1-b.txt" {
+		t.Errorf("reference = %d (%s), want 2 (page1-b.txt)", multi.Reference, multi.TranscriptPath)
+	}
+	if len(multi.References) != 2 || filepath.Base(multi.References[0]) != "page1.txt" {
+		t.Errorf("references = %q, want both transcripts in column order", multi.References)
+	}
+
+	single := results[1]
+	if single.Reference != 0 || single.References != nil || filepath.Base(single.TranscriptPath) != "page2.txt" {
+		t.Errorf("single-reference row = %d, %q, %s; want no references recorded", single.Reference, single.References, single.TranscriptPath)
+	}
+}
+
+func TestProcessEvaluationBLEUIsOptIn(t *testing.T) {
+	csvPath := writeEvalFixtures(t,
+		map[string]string{"page1": "the cat is on the mat"},
+		"image,transcript,public\npage1.jpg,page1.txt,true\n",
+	)
+	useStubEvalProvider(t, &stubEvalProvider{responses: map[string]string{"page1.jpg": "the cat is on a mat"}})
+
+	for _, enabled := range []bool{false, true} {
+		config := EvalConfig{Provider: "stub", Model: "model", Prompt: "Extract", CSVPath: csvPath, BLEU: enabled}
+		results, err := processEvaluation(context.Background(), config, nil, "")
+		if err != nil {
+			t.Fatalf("processEvaluation() error = %v", err)
+		}
+
+		out, err := yaml.Marshal(EvalSummary{Config: config, Results: results})
+		if err != nil {
+			t.Fatalf("yaml.Marshal() error = %v", err)
+		}
+		if !enabled {
+			if results[0].BLEUScore != nil || strings.Contains(string(out), "bleuscore") {
+				t.Fatalf("BLEU recorded without --bleu: %+v\n%s", results[0], out)
+			}
+			continue
+		}
+
+		want := math.Pow(5.0/6*3.0/5*2.0/4*1.0/3, 0.25)
+		if results[0].BLEUScore == nil || math.Abs(*results[0].BLEUScore-want) > 1e-9 {
+			t.Fatalf("BLEUScore = %v, want %f", results[0].BLEUScore, want)
+		}
+		if !strings.Contains(string(out), "bleuscore:") {
+			t.Fatalf("expected bleuscore in YAML output:\n%s", out)
+		}
+	}
+}
+
+func TestProcessEvaluationBagOfWordsIgnoresOrder(t *testing.T) {
+	csvPath := writeEvalFixtures(t,
+		map[string]string{"page1": "left column words\nright column text"},
+		"image,transcript,public\npage1.jpg,page1.txt,true\n",
+	)
+	useStubEvalProvider(t, &stubEvalProvider{responses: map[string]string{"page1.jpg": "right column text\nleft column words"}})
+
+	config := EvalConfig{Provider: "stub", Model: "model", Prompt: "Extract", CSVPath: csvPath, BagOfWords: true}
+	results, err := processEvaluation(context.Background(), config, nil, "")
+	if err != nil {
+		t.Fatalf("processEvaluation() error = %v", err)
+	}
+
+	result := results[0]
+	if result.BagOfWords == nil || result.BagOfWords.F1 != 1 {
+		t.Fatalf("BagOfWords = %+v, want perfect F1 for reordered columns", result.BagOfWords)
+	}
+	if result.WordErrorRate < 0.5 {
+		t.Fatalf("WordErrorRate = %f, want reordering to be penalized", result.WordErrorRate)
+	}
+}
+
+func TestProcessEvaluationSortWordsIgnoresOrder(t *testing.T) {
+	csvPath := writeEvalFixtures(t,
+		map[string]string{"page1": "name date\nSmith 1850\nJones 1851"},
+		"image,transcript,public\npage1.jpg,page1.txt,true\n",
+	)
+	useStubEvalProvider(t, &stubEvalProvider{responses: map[string]string{"page1.jpg": "name Smith Jones\ndate 1850 1851"}})
+
+	for _, enabled := range []bool{false, true} {
+		config := EvalConfig{Provider: "stub", Model: "model", Prompt: "Extract", CSVPath: csvPath, SortWords: enabled}
+		results, err := processEvaluation(context.Background(), config, nil, "")
+		if err != nil {
+			t.Fatalf("processEvaluation() error = %v", err)
+		}
+
+		result := results[0]
+		if !enabled {
+			if result.SortedWords != nil {
+				t.Fatalf("SortedWords recorded without --sort-words: %+v", result.SortedWords)
+			}
+			continue
+		}
+		if result.SortedWords == nil || result.SortedWords.Accuracy != 1 || result.SortedWords.Similarity != 1 {
+			t.Fatalf("SortedWords = %+v, want perfect sorted accuracy for reordered cells", result.SortedWords)
+		}
+		if result.WordAccuracy >= 1 {
+			t.Fatalf("WordAccuracy = %f, want the ordered metric to still penalize reordering", result.WordAccuracy)
+		}
+	}
+}
+
+func TestModelSummaryTableBagOfWordsColumn(t *testing.T) {
+	summaries := []ModelSummary{
+		{Model: "scored", TotalEvaluations: 1, AvgBagOfWordsF1: 0.95, HasBagOfWords: true},
+		{Model: "unscored", TotalEvaluations: 1},
+	}
+
+	header, rows := modelSummaryTable(summaries, false, false)
+	column := slices.Index(header, "AvgBagOfWordsF1")
+	if column < 0 {
+		t.Fatalf("header = %v, want AvgBagOfWordsF1", header)
+	}
+	if rows[0][column] != "0.950000" || rows[1][column] != "" {
+		t.Fatalf("bag-of-words cells = %q, %q", rows[0][column], rows[1][column])
+	}
+
+	header, _ = modelSummaryTable(summaries[1:], false, false)
+	if slices.Contains(header, "AvgBagOfWordsF1") {
+		t.Fatalf("header = %v, want no bag-of-words column without scores", header)
+	}
+}
+
+func TestProcessEvaluationRecordsTruncation(t *testing.T) {
+	csvPath := writeEvalFixtures(t,
+		map[string]string{"page1": "complete text", "page2": "long text that was cut off"},
+		"image,transcript,public\npage1.jpg,page1.txt,true\npage2.jpg,page2.txt,true\n",
+	)
+	useStubEvalProvider(t, &stubEvalProvider{
+		responses: map[string]string{"page1.jpg": "complete text", "page2.jpg": "long text"},
+		truncated: map[string]bool{"page2.jpg": true},
+	})
+
+	config := EvalConfig{Provider: "stub", Model: "model", Prompt: "Extract", CSVPath: csvPath}
+	results, err := processEvaluation(context.Background(), config, nil, "")
+	if err != nil {
+		t.Fatalf("processEvaluation() error = %v", err)
+	}
+	if results[0].Truncated || !results[1].Truncated {
+		t.Fatalf("Truncated = %v, %v, want false, true", results[0].Truncated, results[1].Truncated)
+	}
+	if got := countTruncated(results); got != 1 {
+		t.Fatalf("countTruncated() = %d, want 1", got)
+	}
+
+	out, err := yaml.Marshal(results[0])
+	if err != nil {
+		t.Fatalf("yaml.Marshal() error = %v", err)
+	}
+	if strings.Contains(string(out), "truncated") {
+		t.Fatalf("complete result should omit truncated:\n%s", out)
+	}
+}
+
+func TestProcessEvaluationRecordsEmptyResponses(t *testing.T) {
+	csvPath := writeEvalFixtures(t,
+		map[string]string{"empty": "some text", "blank": "some text", "normal": "some text"},
+		"image,transcript,public\nempty.jpg,empty.txt,true\nblank.jpg,blank.txt,true\nnormal.jpg,normal.txt,true\n",
+	)
+	useStubEvalProvider(t, &stubEvalProvider{
+		responses: map[string]string{"empty.jpg": "", "blank.jpg": " \n\t ", "normal.jpg": "some text"},
+	})
+
+	config := EvalConfig{Provider: "stub", Model: "model", Prompt: "Extract", CSVPath: csvPath}
+	results, err := processEvaluation(context.Background(), config, nil, "")
+	if err != nil {
+		t.Fatalf("processEvaluation() error = %v", err)
+	}
+	want := map[string]bool{"empty.jpg": true, "blank.jpg": true, "normal.jpg": false}
+	for _, result := range results {
+		if result.EmptyResponse != want[result.Identifier] {
+			t.Errorf("%s: EmptyResponse = %v, want %v", result.Identifier, result.EmptyResponse, want[result.Identifier])
+		}
+	}
+	if got := countEmptyResponses(results); got != 2 {
+		t.Fatalf("countEmptyResponses() = %d, want 2", got)
+	}
+
+	out, err := yaml.Marshal(results[2])
+	if err != nil {
+		t.Fatalf("yaml.Marshal() error = %v", err)
+	}
+	if strings.Contains(string(out), "emptyresponse") {
+		t.Fatalf("normal result should omit emptyresponse:\n%s", out)
+	}
+}
+
+func TestProcessImageRowRecordsEmptyResponse(t *testing.T) {
+	dir := t.TempDir()
+	imagePath := filepath.Join(dir, "blank.jpg")
+	if err := os.WriteFile(imagePath, fakeJPEG("blank"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	useStubEvalProvider(t, &stubEvalProvider{responses: map[string]string{"blank.jpg": "\n"}})
+
+	result, err := processImageRow(context.Background(), []string{imagePath}, EvalConfig{Provider: "stub", Model: "model", Prompt: "Extract"})
+	if err != nil {
+		t.Fatalf("processImageRow() error = %v", err)
+	}
+	if !result.EmptyResponse {
+		t.Error("EmptyResponse = false, want true for a whitespace-only response")
+	}
+}
+
+func TestProcessEvaluationRecordsLatency(t *testing.T) {
+	csvPath := writeEvalFixtures(t,
+		map[string]string{"page1": "slow page"},
+		"image,transcript,public\npage1.jpg,page1.txt,true\n",
+	)
+	stub := &stubEvalProvider{responses: map[string]string{"page1.jpg": "slow page"}}
+	stub.onCall = func() { time.Sleep(5 * time.Millisecond) }
+	useStubEvalProvider(t, stub)
+
+	config := EvalConfig{Provider: "stub", Model: "model", Prompt: "Extract", CSVPath: csvPath}
+	results, err := processEvaluation(context.Background(), config, nil, "")
+	if err != nil {
+		t.Fatalf("processEvaluation() error = %v", err)
+	}
+	if got := results[0].LatencyMS; got < 5 {
+		t.Fatalf("LatencyMS = %d, want at least the 5ms the provider took", got)
+	}
+	if got := collectLatencies(append(results, EvalResult{})); len(got) != 1 {
+		t.Fatalf("collectLatencies() = %v, want only the row with a latency", got)
+	}
+}
+
+func TestCachedRowsAreLeftOutOfLatency(t *testing.T) {
+	csvPath := writeEvalFixtures(t,
+		map[string]string{"page1": "slow page"},
+		"image,transcript,public\npage1.jpg,page1.txt,true\n",
+	)
+	stub := &stubEvalProvider{responses: map[string]string{"page1.jpg": "slow page"}}
+	stub.onCall = func() { time.Sleep(2 * time.Millisecond) }
+	useStubEvalProvider(t, stub)
+
+	config := EvalConfig{Provider: "stub", Model: "model", Prompt: "Extract", CSVPath: csvPath, CacheDir: t.TempDir()}
+	cold, err := processEvaluation(context.Background(), config, nil, "")
+	if err != nil {
+		t.Fatalf("cold processEvaluation() error = %v", err)
+	}
+	warm, err := processEvaluation(context.Background(), config, nil, "")
+	if err != nil {
+		t.Fatalf("warm processEvaluation() error = %v", err)
+	}
+	if cold[0].Cached || !warm[0].Cached || warm[0].LatencyMS != 0 {
+		t.Fatalf("Cached = %v then %v, warm LatencyMS = %d; want only the warm row cached, with no latency", cold[0].Cached, warm[0].Cached, warm[0].LatencyMS)
+	}
+	// A cache hit slow enough to measure is still not a provider latency
+	warm[0].LatencyMS = 3
+	if got := collectLatencies(append(cold, warm...)); len(got) != 1 {
+		t.Errorf("collectLatencies() = %v, want only the cold row", got)
+	}
+}
+
+func TestLatencyLeavesOutRateLimiting(t *testing.T) {
+	stub := &stubEvalProvider{responses: map[string]string{"page.jpg": "text"}}
+	useStubEvalProvider(t, stub)
+	original := requestLimiter
+	t.Cleanup(func() { requestLimiter = original })
+	// One request every 100ms
+	requestLimiter = providers.NewRateLimiter(600)
+
+	config := EvalConfig{Provider: "stub", Model: "model", Prompt: "Extract"}
+	for i := range 2 {
+		extracted, err := extractTextAndLogprobs(context.Background(), config, "page.jpg", "")
+		if err != nil {
+			t.Fatalf("extractTextAndLogprobs() error = %v", err)
+		}
+		if extracted.Latency >= 50*time.Millisecond {
+			t.Errorf("request %d: Latency = %s, want the rate limit wait left out", i+1, extracted.Latency)
+		}
+	}
+}
+
+func TestModelSummaryTableLatencyColumns(t *testing.T) {
+	summaries := []ModelSummary{
+		{Model: "timed", TotalEvaluations: 2, AvgLatencyMS: 1250.4, MedianLatencyMS: 980, HasLatency: true},
+		{Model: "untimed", TotalEvaluations: 2},
+	}
+
+	header, rows := modelSummaryTable(summaries, false, false)
+	column := slices.Index(header, "AvgLatencyMS")
+	if column < 0 || header[column+1] != "MedianLatencyMS" {
+		t.Fatalf("header = %v, want AvgLatencyMS and MedianLatencyMS", header)
+	}
+	if got := rows[0][column : column+2]; !slices.Equal(got, []string{"1250", "980"}) {
+		t.Fatalf("latency cells = %q, want 1250, 980", got)
+	}
+	if got := rows[1][column : column+2]; !slices.Equal(got, []string{"", ""}) {
+		t.Fatalf("latency cells without latency = %q, want blank", got)
+	}
+
+	header, _ = modelSummaryTable(summaries[1:], false, false)
+	if slices.Contains(header, "AvgLatencyMS") {
+		t.Fatalf("header = %v, want no latency columns without latencies", header)
+	}
+}
+
+func TestModelSummaryTableTruncatedRowsColumn(t *testing.T) {
+	summaries := []ModelSummary{
+		{Model: "clipped", TotalEvaluations: 3, TruncatedRows: 2},
+		{Model: "complete", TotalEvaluations: 3},
+	}
+
+	header, rows := modelSummaryTable(summaries, false, false)
+	column := slices.Index(header, "TruncatedRows")
+	if column < 0 {
+		t.Fatalf("header = %v, want TruncatedRows", header)
+	}
+	if rows[0][column] != "2" || rows[1][column] != "0" {
+		t.Fatalf("truncated cells = %q, %q", rows[0][column], rows[1][column])
+	}
+
+	header, _ = modelSummaryTable(summaries[1:], false, false)
+	if slices.Contains(header, "TruncatedRows") {
+		t.Fatalf("header = %v, want no truncation column without truncated rows", header)
+	}
+}
+
+func TestWeightedWordRatesWeighDocumentsByLength(t *testing.T) {
+	results := []EvalResult{
+		{Identifier: "caption.jpg", TotalWordsOriginal: 5, WordAccuracy: 0.6, WordErrorRate: 0.4},
+		{Identifier: "page.jpg", TotalWordsOriginal: 500, WordAccuracy: 0.98, WordErrorRate: 0.02},
+		{Identifier: "blank.jpg", TotalWordsOriginal: 0, WordAccuracy: 0, WordErrorRate: 0},
+	}
+
+	wordAccuracy, wordErrorRate := weightedWordRates(results)
+	// 2 errors in the caption and 10 on the page over 505 words
+	if want := 12.0 / 505; math.Abs(wordErrorRate-want) > 1e-9 || math.Abs(wordAccuracy-(1-want)) > 1e-9 {
+		t.Fatalf("weightedWordRates() = %v, %v; want %v, %v", wordAccuracy, wordErrorRate, 1-want, want)
+	}
+	macro := (results[0].WordAccuracy + results[1].WordAccuracy) / 2
+	if wordAccuracy-macro < 0.15 {
+		t.Fatalf("weighted word accuracy %v should follow the long page, not the macro average %v", wordAccuracy, macro)
+	}
+
+	if wordAccuracy, wordErrorRate := weightedWordRates(results[2:]); wordAccuracy != 0 || wordErrorRate != 0 {
+		t.Fatalf("weightedWordRates() without words = %v, %v; want 0, 0", wordAccuracy, wordErrorRate)
+	}
+}
+
+func TestWeightedCharErrorRateWeighsDocumentsByLength(t *testing.T) {
+	results := []EvalResult{
+		{Identifier: "caption.jpg", TotalCharsOriginal: 20, CharacterAccuracy: 0.5},
+		{Identifier: "page.jpg", TotalCharsOriginal: 2000, CharacterAccuracy: 0.99},
+		{Identifier: "legacy.jpg", CharacterAccuracy: 0.1},
+	}
+
+	// 10 edits in the caption and 20 on the page over 2020 characters
+	if got, want := weightedCharErrorRate(results), 30.0/2020; math.Abs(got-want) > 1e-9 {
+		t.Fatalf("weightedCharErrorRate() = %v, want %v", got, want)
+	}
+	if got := weightedCharErrorRate(results[2:]); got != 0 {
+		t.Fatalf("weightedCharErrorRate() without character counts = %v, want 0", got)
+	}
+
+	evaluated := evalResultFromMetrics(htrmetrics.Evaluate("Dear Sir", "Dear Sit", htrmetrics.Options{}))
+	if evaluated.TotalCharsOriginal != 8 || weightedCharErrorRate([]EvalResult{evaluated}) != 1.0/8 {
+		t.Fatalf("evaluated row = %+v, want 8 ground truth characters and one edit", evaluated)
+	}
+}
+
+func TestModelSummaryTableWeightedColumns(t *testing.T) {
+	summaries := []ModelSummary{
+		{Model: "weighted", TotalEvaluations: 2, AvgWordAccuracy: 0.79, WeightedWordAccuracy: 0.976238, WeightedWordErrorRate: 0.023762, WeightedCharErrorRate: 0.014851, HasWeighted: true},
+		{Model: "unweighted", TotalEvaluations: 2, AvgWordAccuracy: 0.79},
+	}
+
+	header, rows := modelSummaryTable(summaries, false, false)
+	column := slices.Index(header, "WeightedWordAccuracy")
+	if column < 0 || header[column+1] != "WeightedWordErrorRate" || header[column+2] != "WeightedCharErrorRate" || header[column-1] != "AvgWordErrorRate" {
+		t.Fatalf("header = %v, want weighted columns after AvgWordErrorRate", header)
+	}
+	if got := rows[0][column : column+3]; !slices.Equal(got, []string{"0.976238", "0.023762", "0.014851"}) {
+		t.Fatalf("weighted cells = %q", got)
+	}
+	if got := rows[1][column : column+3]; !slices.Equal(got, []string{"", "", ""}) {
+		t.Fatalf("weighted cells without --weighted = %q, want blank", got)
+	}
+
+	header, _ = modelSummaryTable(summaries[1:], false, false)
+	if slices.Contains(header, "WeightedWordAccuracy") {
+		t.Fatalf("header = %v, want no weighted columns without --weighted", header)
+	}
+}
+
+func TestPublicResults(t *testing.T) {
+	results := []EvalResult{
+		{Identifier: "open1.jpg", Public: true},
+		{Identifier: "restricted.jpg"},
+		{Identifier: "open2.jpg", Public: true},
+	}
+
+	var got []string
+	for _, result := range publicResults(results) {
+		got = append(got, result.Identifier)
+	}
+	if want := []string{"open1.jpg", "open2.jpg"}; !slices.Equal(got, want) {
+		t.Errorf("publicResults() = %v, want %v", got, want)
+	}
+	if public := publicResults(results[1:2]); len(public) != 0 {
+		t.Errorf("publicResults() without public rows = %v, want none", public)
+	}
+}
+
+func TestModelSummaryTableBlanksTokensWithoutUsage(t *testing.T) {
+	summaries := []ModelSummary{
+		{Model: "gpt-4o", TotalEvaluations: 1, AvgInputTokens: 1500, AvgOutputTokens: 750, PageCost: 0.01125},
+		{Model: "azure", TotalEvaluations: 1, AvgInputTokens: 1, AvgOutputTokens: 30, Unpriced: true, NoTokenUsage: true},
+	}
+
+	header, rows := modelSummaryTable(summaries, true, false)
+	column := slices.Index(header, "AvgInputTokens")
+	if column < 0 {
+		t.Fatalf("header = %v, want token columns", header)
+	}
+	if got := rows[0][column : column+3]; !slices.Equal(got, []string{"1500.00", "750.00", "0.011250"}) {
+		t.Errorf("token-reporting row = %q", got)
+	}
+	if got := rows[1][column : column+3]; !slices.Equal(got, []string{"", "", ""}) {
+		t.Errorf("row without token usage = %q, want blank token and cost cells", got)
+	}
+}
+
+func TestProviderCapabilities(t *testing.T) {
+	tests := []struct {
+		provider   string
+		wantTokens bool
+		wantPrompt bool
+	}{
+		{provider: "openai", wantTokens: true, wantPrompt: true},
+		{provider: "azure", wantTokens: false, wantPrompt: false},
+		{provider: "docai", wantTokens: false, wantPrompt: false},
+		{provider: "unknown", wantTokens: true, wantPrompt: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.provider, func(t *testing.T) {
+			capabilities := providerCapabilities(tt.provider)
+			if capabilities.ReportsTokenUsage != tt.wantTokens {
+				t.Errorf("ReportsTokenUsage = %v, want %v", capabilities.ReportsTokenUsage, tt.wantTokens)
+			}
+			if capabilities.SupportsCustomPrompt != tt.wantPrompt {
+				t.Errorf("SupportsCustomPrompt = %v, want %v", capabilities.SupportsCustomPrompt, tt.wantPrompt)
+			}
+		})
+	}
+}
+
+func TestProcessEvaluationPerLine(t *testing.T) {
+	csvPath := writeEvalFixtures(t,
+		map[string]string{"page1": "first line here\nsecond line here\nthird line here"},
+		"image,transcript,public\npage1.jpg,page1.txt,true\n",
+	)
+	useStubEvalProvider(t, &stubEvalProvider{responses: map[string]string{"page1.jpg": "first line here\nthird line here"}})
+
+	config := EvalConfig{Provider: "stub", Model: "model", Prompt: "Extract", CSVPath: csvPath, PerLine: true}
+	results, err := processEvaluation(context.Background(), config, nil, "")
+	if err != nil {
+		t.Fatalf("processEvaluation() error = %v", err)
+	}
+
+	lines := results[0].LineResults
+	if len(lines) != 3 || lines[1].TranscribedLine != 0 || lines[2].CharacterAccuracy != 1 {
+		t.Fatalf("LineResults = %+v, want the missing second line unpaired", lines)
+	}
+}
+
+func TestPrintWorstLines(t *testing.T) {
+	lines := []htrmetrics.LineMetric{
+		{GroundTruthLine: 1, TranscribedLine: 1, GroundTruth: "good", Transcription: "good", CharacterAccuracy: 1, WordAccuracy: 1},
+		{GroundTruthLine: 2, TranscribedLine: 0, GroundTruth: "missing"},
+		{GroundTruthLine: 3, TranscribedLine: 2, GroundTruth: "close", Transcription: "clase", CharacterAccuracy: 0.8},
+		{GroundTruthLine: 0, TranscribedLine: 3, Transcription: "extra"},
+	}
+
+	var buf strings.Builder
+	printWorstLines(&buf, lines, 2)
+	out := buf.String()
+
+	if !strings.HasPrefix(out, "Worst Lines (2 of 4):") {
+		t.Fatalf("output = %q, want header", out)
+	}
+	missing, extra := strings.Index(out, "line 2:"), strings.Index(out, "extra line 3:")
+	if missing < 0 || extra < 0 || missing > extra {
+		t.Fatalf("output = %q, want the missing and extra lines in page order", out)
+	}
+	if strings.Contains(out, "\"close\"") || strings.Contains(out, "\"good\"") {
+		t.Fatalf("output = %q, want only the two worst lines", out)
+	}
+}
+
+func TestProcessEvaluationDedupe(t *testing.T) {
+	csvPath := writeEvalFixtures(t,
+		map[string]string{"page1": "hello world", "page2": "second page"},
+		"image,transcript,public\npage1.jpg,page1.txt,true\npage2.jpg,page2.txt,true\n page1.jpg ,page1.txt,true\npage1.jpg,page2.txt,true\n",
+	)
+
+	tests := []struct {
+		name      string
+		dedupe    bool
+		wantCalls []string
+	}{
+		{name: "disabled", wantCalls: []string{"page1.jpg", "page2.jpg", "page1.jpg", "page1.jpg"}},
+		{name: "enabled", dedupe: true, wantCalls: []string{"page1.jpg", "page2.jpg", "page1.jpg"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stub := &stubEvalProvider{responses: map[string]string{"page1.jpg": "hello world", "page2.jpg": "second page"}}
+			useStubEvalProvider(t, stub)
+
+			config := EvalConfig{Provider: "stub", Model: "model", Prompt: "Extract", CSVPath: csvPath, Dedupe: tt.dedupe}
+			results, err := processEvaluation(context.Background(), config, nil, "")
+			if err != nil {
+				t.Fatalf("processEvaluation() error = %v", err)
+			}
+			if len(results) != len(tt.wantCalls) {
+				t.Errorf("processEvaluation() returned %d results, want %d", len(results), len(tt.wantCalls))
+			}
+			if !slices.Equal(stub.calls, tt.wantCalls) {
+				t.Errorf("provider calls = %v, want %v", stub.calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestSelectRows(t *testing.T) {
+	dataRows := [][]string{
+		{"page1.jpg", "page1.txt", "true"},
+		{"page2.jpg", "page2.txt", "true"},
+		{"short.jpg"},
+		{"page4.jpg", "page4.txt", "true"},
+		{"page5.jpg", "page5.txt", "true"},
+	}
+
+	tests := []struct {
+		name     string
+		rowSpec  string
+		testRows []int
+		limit    int
+		want     []int
+	}{
+		{name: "all rows", want: []int{0, 1, 2, 3, 4}},
+		{name: "row spec", rowSpec: "3-,0", want: []int{0, 3, 4}},
+		{name: "row spec with limit", rowSpec: "-4", limit: 2, want: []int{1, 3}},
+		{name: "rows only", testRows: []int{4, 1}, want: []int{1, 4}},
+		{name: "limit", limit: 2, want: []int{0, 1}},
+		{name: "limit skips short rows", limit: 3, want: []int{0, 1, 3}},
+		{name: "limit after rows", testRows: []int{4, 3, 1}, limit: 2, want: []int{1, 3}},
+		{name: "limit above row count", testRows: []int{0, 4}, limit: 10, want: []int{0, 4}},
+		{name: "rows out of range", testRows: []int{9}, limit: 1, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := selectRows(EvalConfig{RowSpec: tt.rowSpec, TestRows: tt.testRows, Limit: tt.limit}, dataRows)
+			if err != nil {
+				t.Fatalf("selectRows() error = %v", err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("selectRows() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSelectRowsSample(t *testing.T) {
+	var dataRows [][]string
+	for i := range 100 {
+		dataRows = append(dataRows, []string{fmt.Sprintf("page%d.jpg", i), fmt.Sprintf("page%d.txt", i), "true"})
+	}
+	dataRows[7] = []string{"short.jpg"}
+
+	config := EvalConfig{Sample: 10, Seed: 42}
+	first, err := selectRows(config, dataRows)
+	if err != nil {
+		t.Fatalf("selectRows() error = %v", err)
+	}
+	if len(first) != 10 {
+		t.Fatalf("selectRows() = %v, want 10 rows", first)
+	}
+	if !slices.IsSorted(first) || slices.Contains(first, 7) {
+		t.Fatalf("selectRows() = %v, want sorted rows without the short row", first)
+	}
+	for range 3 {
+		again, err := selectRows(config, dataRows)
+		if err != nil {
+			t.Fatalf("selectRows() error = %v", err)
+		}
+		if !slices.Equal(again, first) {
+			t.Fatalf("selectRows() with seed 42 = %v, then %v", first, again)
+		}
+	}
+	if other, _ := selectRows(EvalConfig{Sample: 10, Seed: 43}, dataRows); slices.Equal(other, first) {
+		t.Fatalf("selectRows() drew %v with both seed 42 and 43", first)
+	}
+
+	sampled, err := selectRows(EvalConfig{RowSpec: "50-", Sample: 5, Seed: 42, Limit: 3}, dataRows)
+	if err != nil {
+		t.Fatalf("selectRows() error = %v", err)
+	}
+	if len(sampled) != 3 || sampled[0] < 50 {
+		t.Fatalf("selectRows() with --rows 50- and --limit 3 = %v, want 3 rows from 50 on", sampled)
+	}
+
+	all, err := selectRows(EvalConfig{RowSpec: "0-4", Sample: 10, Seed: 42}, dataRows)
+	if err != nil {
+		t.Fatalf("selectRows() error = %v", err)
+	}
+	if !slices.Equal(all, []int{0, 1, 2, 3, 4}) {
+		t.Fatalf("selectRows() with a sample above the row count = %v, want every row", all)
+	}
+}
+
+func TestSampledRowsAreRecordedForReruns(t *testing.T) {
+	var csvRows strings.Builder
+	for i := range 20 {
+		fmt.Fprintf(&csvRows, "page%d.jpg,page%d.txt,true\n", i, i)
+	}
+	csvPath := writeEvalFixtures(t, nil, csvRows.String())
+
+	config := EvalConfig{CSVPath: csvPath, Sample: 4, Seed: 7, Limit: 2}
+	recorded, err := sampledRows(config)
+	if err != nil {
+		t.Fatalf("sampledRows() error = %v", err)
+	}
+	if len(recorded) != 4 {
+		t.Fatalf("sampledRows() = %v, want the 4 sampled rows before --limit", recorded)
+	}
+
+	config.SampledRows = recorded
+	evalPath := filepath.Join(t.TempDir(), "eval.yaml")
+	if err := saveEvalResults(EvalSummary{Config: config}, evalPath); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := loadEvalConfig(evalPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Sample != 4 || loaded.Seed != 7 || !slices.Equal(loaded.SampledRows, recorded) {
+		t.Fatalf("loaded sample = %d, seed %d, rows %v; want 4, 7, %v", loaded.Sample, loaded.Seed, loaded.SampledRows, recorded)
+	}
+	if rerun, _ := sampledRows(loaded); !slices.Equal(rerun, recorded) {
+		t.Fatalf("rerun sampled %v, want %v", rerun, recorded)
+	}
+}
+
+func TestConfigRerunKeepsRowSpecAndSample(t *testing.T) {
+	transcripts := map[string]string{}
+	var csvRows strings.Builder
+	for i := range 20 {
+		transcripts[fmt.Sprintf("page%d", i)] = "text"
+		fmt.Fprintf(&csvRows, "page%d.jpg,page%d.txt,true\n", i, i)
+	}
+	csvPath := writeEvalFixtures(t, transcripts, csvRows.String())
+	stub := &stubEvalProvider{responses: map[string]string{}}
+	useStubEvalProvider(t, stub)
+	t.Chdir(t.TempDir())
+
+	saved := evalConfigFromFlags()
+	saved.Provider, saved.Model, saved.CSVPath = "stub", "stub-model", csvPath
+	saved.RowSpec, saved.Sample, saved.Seed = "10-19", 3, 7
+	want, err := sampledRows(saved)
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved.SampledRows = want
+	configPath := filepath.Join(t.TempDir(), "previous.yaml")
+	if err := saveEvalResults(EvalSummary{Config: saved}, configPath); err != nil {
+		t.Fatal(err)
+	}
+	setEvalFlags(t, map[string]string{"config": configPath, "quiet": "true"})
+
+	if err := runEval(evalCmd, nil); err != nil {
+		t.Fatalf("runEval() error = %v", err)
+	}
+	var wantCalls []string
+	for _, row := range want {
+		wantCalls = append(wantCalls, fmt.Sprintf("page%d.jpg", row))
+	}
+	if !slices.Equal(stub.calls, wantCalls) {
+		t.Errorf("rerun evaluated %v, want the recorded sample %v from rows 10-19", stub.calls, wantCalls)
+	}
+}
+
+func TestExpandRows(t *testing.T) {
+	tests := []struct {
+		spec    string
+		total   int
+		want    []int
+		wantErr string
+	}{
+		{spec: "3", total: 10, want: []int{3}},
+		{spec: "0,5,2", total: 10, want: []int{0, 2, 5}},
+		{spec: "2-5", total: 10, want: []int{2, 3, 4, 5}},
+		{spec: "4-4", total: 10, want: []int{4}},
+		{spec: "7-", total: 10, want: []int{7, 8, 9}},
+		{spec: "-3", total: 10, want: []int{7, 8, 9}},
+		{spec: "-10", total: 10, want: []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}},
+		{spec: "-20", total: 3, want: []int{0, 1, 2}},
+		{spec: "1-3,2-4,3", total: 10, want: []int{1, 2, 3, 4}},
+		{spec: "8-,-3", total: 10, want: []int{7, 8, 9}},
+		{spec: " 1 , 3-4 ", total: 10, want: []int{1, 3, 4}},
+		{spec: "2-5,8,-1", total: 10, want: []int{2, 3, 4, 5, 8, 9}},
+		{spec: "-1", total: 0, want: []int{}},
+		{spec: "10", total: 10, wantErr: `--rows term "10" is out of range: the input has 10 rows`},
+		{spec: "5-12", total: 10, wantErr: `--rows term "5-12" is out of range`},
+		{spec: "10-", total: 10, wantErr: `--rows term "10-" is out of range`},
+		{spec: "5-2", total: 10, wantErr: `--rows range "5-2" ends before it starts`},
+		{spec: "-0", total: 10, wantErr: `invalid --rows term "-0"`},
+		{spec: "--2", total: 10, wantErr: `invalid --rows term "--2"`},
+		{spec: "a-b", total: 10, wantErr: `invalid --rows term "a-b"`},
+		{spec: "3-x", total: 10, wantErr: `invalid --rows term "3-x"`},
+		{spec: "1,,2", total: 10, wantErr: `invalid --rows term ""`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := expandRows(tt.spec, tt.total)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expandRows(%q, %d) error = %v, want %q", tt.spec, tt.total, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expandRows(%q, %d) error = %v", tt.spec, tt.total, err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("expandRows(%q, %d) = %v, want %v", tt.spec, tt.total, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProcessEvaluationLimit(t *testing.T) {
+	transcripts := make(map[string]string)
+	csvRows := "image,transcript,public\n"
+	for i := 1; i <= 8; i++ {
+		name := fmt.Sprintf("page%d", i)
+		transcripts[name] = "text of " + name
+		csvRows += name + ".jpg," + name + ".txt,true\n"
+	}
+	csvPath := writeEvalFixtures(t, transcripts, csvRows)
+	stub := &stubEvalProvider{}
+	useStubEvalProvider(t, stub)
+
+	config := EvalConfig{Provider: "stub", Model: "model", Prompt: "Extract", CSVPath: csvPath, TestRows: []int{1, 2, 4, 5, 7}, Limit: 3}
+	results, err := processEvaluation(context.Background(), config, nil, "")
+	if err != nil {
+		t.Fatalf("processEvaluation() error = %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("processEvaluation() returned %d results, want 3", len(results))
+	}
+	if want := []string{"page2.jpg", "page3.jpg", "page5.jpg"}; !slices.Equal(stub.calls, want) {
+		t.Errorf("provider calls = %v, want %v", stub.calls, want)
+	}
+}
+
+func TestProcessEvaluationStopsWhenCanceled(t *testing.T) {
+	csvPath := writeEvalFixtures(t, map[string]string{
+		"page1": "first page",
+		"page2": "second page",
+		"page3": "third page",
+	}, "image,transcript,public\npage1.jpg,page1.txt,true\npage2.jpg,page2.txt,true\npage3.jpg,page3.txt,true\n")
+	stub := &stubEvalProvider{responses: map[string]string{"page1.jpg": "first page", "page2.jpg": "second page"}}
+	useStubEvalProvider(t, stub)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stub.onCall = func() {
+		if len(stub.calls) == 2 {
+			cancel()
+		}
+	}
+
+	partialPath := filepath.Join(t.TempDir(), "results.yaml.partial")
+	config := EvalConfig{Provider: "stub", Model: "model", Prompt: "Extract", CSVPath: csvPath}
+	results, err := processEvaluation(ctx, config, nil, partialPath)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("processEvaluation() error = %v, want context.Canceled", err)
+	}
+	if want := []string{"page1.jpg", "page2.jpg"}; !slices.Equal(stub.calls, want) {
+		t.Errorf("provider calls = %v, want %v", stub.calls, want)
+	}
+	if len(results) != 2 {
+		t.Fatalf("processEvaluation() returned %d results, want the 2 finished before the cancel", len(results))
+	}
+
+	saved, err := loadEvalSummary(partialPath)
+	if err != nil {
+		t.Fatalf("loadEvalSummary() error = %v", err)
+	}
+	if len(saved.Results) != 2 {
+		t.Errorf("partial file has %d results, want 2", len(saved.Results))
+	}
+}
+
+// fakeObjectFetcher serves objects keyed by "bucket/key".
+type fakeObjectFetcher map[string]string
+
+func (f fakeObjectFetcher) Fetch(ctx context.Context, bucket, key string) ([]byte, error) {
+	data, ok := f[bucket+"/"+key]
+	if !ok {
+		return nil, fmt.Errorf("no object %s/%s", bucket, key)
+	}
+	return []byte(data), nil
+}
+
+// useFakeObjectStore serves s3:// and gs:// URIs from objects for the test.
+func useFakeObjectStore(t *testing.T, objects map[string]fakeObjectFetcher) {
+	t.Helper()
+	original := objectStore
+	t.Cleanup(func() { objectStore = original })
+	objectStore = &objectstore.Store{NewFetcher: func(scheme string) (objectstore.Fetcher, error) {
+		return objects[scheme], nil
+	}}
+}
+
+func TestFetchBytes(t *testing.T) {
+	useFakeObjectStore(t, map[string]fakeObjectFetcher{
+		"s3": {"letters/page1.txt": "from s3"},
+		"gs": {"letters/page1.txt": "from gcs"},
+	})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("from http"))
+	}))
+	defer server.Close()
+	localPath := filepath.Join(t.TempDir(), "page1.txt")
+	if err := os.WriteFile(localPath, []byte("from disk"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]string{
+		"s3://letters/page1.txt":  "from s3",
+		"gs://letters/page1.txt":  "from gcs",
+		server.URL + "/page1.txt": "from http",
+		localPath:                 "from disk",
+	}
+	for uri, want := range tests {
+		got, err := fetchBytes(context.Background(), uri)
+		if err != nil {
+			t.Errorf("fetchBytes(%q) error = %v", uri, err)
+			continue
+		}
+		if string(got) != want {
+			t.Errorf("fetchBytes(%q) = %q, want %q", uri, got, want)
+		}
+	}
+
+	if _, err := fetchBytes(context.Background(), "s3://letters/missing.txt"); err == nil {
+		t.Error("fetchBytes() of a missing object error = nil, want error")
+	}
+}
+
+func TestProcessEvaluationReadsCloudStorage(t *testing.T) {
+	useFakeObjectStore(t, map[string]fakeObjectFetcher{
+		"s3": {"letters/1850/page1.jpg": string(fakeJPEG("image-page1"))},
+		"gs": {"transcripts/page1.txt": "hello world"},
+	})
+	csvPath := writeEvalFixtures(t, nil, "image,transcript,public\ns3://letters/1850/page1.jpg,gs://transcripts/page1.txt,true\n")
+	stub := &stubEvalProvider{responses: map[string]string{"page1.jpg": "hello world"}}
+	useStubEvalProvider(t, stub)
+
+	results, err := processEvaluation(context.Background(), EvalConfig{Provider: "stub", Model: "model", Prompt: "Extract", CSVPath: csvPath}, nil, "")
+	if err != nil {
+		t.Fatalf("processEvaluation() error = %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("processEvaluation() returned %d results, want 1", len(results))
+	}
+	result := results[0]
+	if result.ImagePath != "s3://letters/1850/page1.jpg" || result.TranscriptPath != "gs://transcripts/page1.txt" || result.Identifier != "page1.jpg" {
+		t.Errorf("result paths = %q, %q, %q", result.ImagePath, result.TranscriptPath, result.Identifier)
+	}
+	if result.WordAccuracy != 1 {
+		t.Errorf("WordAccuracy = %v, want 1", result.WordAccuracy)
+	}
+	if want := base64.StdEncoding.EncodeToString(fakeJPEG("image-page1")); len(stub.images) != 1 || stub.images[0] != want {
+		t.Errorf("provider received %v, want the S3 object", stub.images)
+	}
+}
+
+func TestFetchURLLimits(t *testing.T) {
+	originalTimeout, originalMax := fetchTimeout, maxFetchMB
+	t.Cleanup(func() { fetchTimeout, maxFetchMB = originalTimeout, originalMax })
+	fetchTimeout, maxFetchMB = 100*time.Millisecond, 1
+
+	release := make(chan struct{})
+	hanging := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer hanging.Close()
+	defer close(release)
+
+	oversized := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(bytes.Repeat([]byte("x"), 1<<20+1))
+	}))
+	defer oversized.Close()
+
+	atLimit := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(bytes.Repeat([]byte("x"), 1<<20))
+	}))
+	defer atLimit.Close()
+
+	start := time.Now()
+	if _, err := fetchBytes(context.Background(), hanging.URL); err == nil || !strings.Contains(err.Error(), "failed to fetch") {
+		t.Errorf("fetchBytes() of a hanging server error = %v, want timeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("fetchBytes() took %v, want it bounded by --fetch-timeout", elapsed)
+	}
+
+	if _, err := fetchBytes(context.Background(), oversized.URL); err == nil || !strings.Contains(err.Error(), "larger than the 1 MB limit") {
+		t.Errorf("fetchBytes() of an oversized body error = %v, want size limit error", err)
+	}
+
+	data, err := fetchBytes(context.Background(), atLimit.URL)
+	if err != nil || len(data) != 1<<20 {
+		t.Errorf("fetchBytes() at the limit = %d bytes, %v", len(data), err)
+	}
+}
+
+func TestFetchURLRejectsErrorStatusAndHonorsContext(t *testing.T) {
+	missing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "<html>Not Found</html>", http.StatusNotFound)
+	}))
+	defer missing.Close()
+
+	if data, err := fetchBytes(context.Background(), missing.URL); err == nil || !strings.Contains(err.Error(), "404 Not Found") {
+		t.Errorf("fetchBytes() of a 404 = %q, %v, want a status error", data, err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("fetchBytes() with a canceled context reached the server")
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := fetchBytes(ctx, server.URL); !errors.Is(err, context.Canceled) {
+		t.Errorf("fetchBytes() with a canceled context error = %v, want context.Canceled", err)
+	}
+}
+
+func TestGetImageAsBase64ChecksFormat(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		"page.jpg":  "\xff\xd8\xff\xe0\x00\x10JFIF",
+		"page.png":  "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR",
+		"page.bmp":  "BM\x00\x00\x00\x00\x00\x00\x00\x00",
+		"bogus.jpg": "not an image",
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		provider string
+		file     string
+		wantErr  string
+	}{
+		{provider: "openai", file: "page.jpg"},
+		{provider: "openai", file: "page.png"},
+		{provider: "openai", file: "page.bmp", wantErr: "page.bmp is a BMP image, which openai does not accept; convert it to PNG or JPEG first, e.g. magick 'page.bmp[0]' page.png"},
+		{provider: "gemini", file: "page.bmp", wantErr: "page.bmp is a BMP image, which gemini does not accept"},
+		{provider: "openai", file: "bogus.jpg", wantErr: "bogus.jpg is not a supported image (detected text/plain)"},
+		{provider: "docai", file: "page.bmp"},
+		{provider: "azure", file: "page.bmp"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.provider+" "+tt.file, func(t *testing.T) {
+			imageBase64, _, err := getImageAsBase64(context.Background(), EvalConfig{Provider: tt.provider}, filepath.Join(tmpDir, tt.file))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("getImageAsBase64() error = %v", err)
+				}
+				if want := base64.StdEncoding.EncodeToString([]byte(files[tt.file])); imageBase64 != want {
+					t.Errorf("getImageAsBase64() = %q, want %q", imageBase64, want)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("getImageAsBase64() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRasterizeImageLeavesSinglePageFormats(t *testing.T) {
+	jpeg := fakeJPEG("page")
+	for _, page := range []int{0, 1, 3} {
+		got, err := rasterizeImage(jpeg, page)
+		if err != nil || !bytes.Equal(got, jpeg) {
+			t.Errorf("rasterizeImage(JPEG, page %d) = %q, %v; want the JPEG unchanged", page, got, err)
+		}
+	}
+
+	tmpDir := t.TempDir()
+	imagePath := filepath.Join(tmpDir, "page.jpg")
+	if err := os.WriteFile(imagePath, jpeg, 0644); err != nil {
+		t.Fatal(err)
+	}
+	pagePath, err := rasterizeImageFile(imagePath, 2, tmpDir)
+	if err != nil || pagePath != imagePath {
+		t.Errorf("rasterizeImageFile(JPEG) = %q, %v; want %q", pagePath, err, imagePath)
+	}
+	if entries, _ := os.ReadDir(tmpDir); len(entries) != 1 {
+		t.Errorf("rasterizeImageFile(JPEG) wrote files: %v", entries)
+	}
+}
@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var extractCmd = &cobra.Command{
+	Use:   "extract [eval-file]",
+	Short: "Write each provider response in an evaluation file to a text file",
+	Long: `Write the stored provider response of every row in an evaluation file to its
+own text file, for manual review or for feeding into other tools.
+
+Each file is named after the row's identifier with its extension replaced by
+.txt, so letter1.jpg is written to letter1.txt in --output-dir. With
+--ground-truth, the row's ground truth is also written next to it as
+letter1.gt.txt for side-by-side inspection.
+
+Examples:
+  htr extract gpt-4o --output-dir transcriptions
+  htr extract evals/openai_gpt-4o.yaml --output-dir review --ground-truth`,
+	RunE: runExtract,
+	Args: cobra.ExactArgs(1),
+}
+
+var (
+	extractOutputDir   string
+	extractGroundTruth bool
+)
+
+func init() {
+	RootCmd.AddCommand(extractCmd)
+
+	extractCmd.Flags().StringVar(&extractOutputDir, "output-dir", "", "Directory to write the text files to (required)")
+	extractCmd.Flags().BoolVar(&extractGroundTruth, "ground-truth", false, "Also write each row's ground truth to <identifier>.gt.txt")
+
+	if err := extractCmd.MarkFlagRequired("output-dir"); err != nil {
+		panic(err)
+	}
+}
+
+func runExtract(cmd *cobra.Command, args []string) error {
+	evalFile := resolveEvalFile(evalsDir, args[0])
+	summary, err := loadEvalSummary(evalFile)
+	if err != nil {
+		return err
+	}
+
+	written, err := extractResponses(summary.Results, extractOutputDir, extractGroundTruth)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Wrote %d files to %s\n", written, extractOutputDir)
+	return nil
+}
+
+// extractResponses writes each result's provider response, and with
+// groundTruth its ground truth, to outputDir and returns the number of files
+// written. Identifiers that would share a file name are rejected before
+// anything is written.
+func extractResponses(results []EvalResult, outputDir string, groundTruth bool) (int, error) {
+	names := make([]string, len(results))
+	seen := make(map[string]string, len(results))
+	for i, result := range results {
+		name := extractFileName(result.Identifier)
+		if name == "" {
+			return 0, fmt.Errorf("row %d has no identifier to name its text file", i+1)
+		}
+		if previous, ok := seen[name]; ok {
+			return 0, fmt.Errorf("identifiers %q and %q would both be written to %s.txt", previous, result.Identifier, name)
+		}
+		seen[name] = result.Identifier
+		names[i] = name
+	}
+
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return 0, fmt.Errorf("failed to create output directory: %w", err)
+	}
+	written := 0
+	for i, result := range results {
+		path := filepath.Join(outputDir, names[i]+".txt")
+		if err := os.WriteFile(path, []byte(result.ProviderResponse), 0644); err != nil {
+			return written, fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		written++
+
+		if !groundTruth {
+			continue
+		}
+		truth, err := readTextFile(context.Background(), result.TranscriptPath)
+		if err != nil {
+			return written, fmt.Errorf("failed to read ground truth for %s: %w", result.Identifier, err)
+		}
+		path = filepath.Join(outputDir, names[i]+".gt.txt")
+		if err := os.WriteFile(path, []byte(truth), 0644); err != nil {
+			return written, fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		written++
+	}
+	return written, nil
+}
+
+// extractFileName returns the base name of an identifier without its
+// extension. Only the last path element is kept so every file lands in the
+// output directory.
+func extractFileName(identifier string) string {
+	name := strings.TrimSpace(identifier)
+	name = name[strings.LastIndexAny(name, `/\`)+1:]
+	name = strings.TrimSuffix(name, filepath.Ext(name))
+	if strings.Trim(name, ".") == "" {
+		return ""
+	}
+	return name
+}
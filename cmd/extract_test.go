@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestExtractResponsesWritesOneFilePerResult(t *testing.T) {
+	dir := t.TempDir()
+	truthPath := filepath.Join(dir, "letter1.txt")
+	if err := os.WriteFile(truthPath, []byte("Dear Sir,\nI remain"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	otherTruthPath := filepath.Join(dir, "letter2.txt")
+	if err := os.WriteFile(otherTruthPath, []byte("Yours truly"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	results := []EvalResult{
+		{Identifier: "letter1.jpg", TranscriptPath: truthPath, ProviderResponse: "Dear Sir,\nI remane"},
+		{Identifier: "letter2.png", TranscriptPath: otherTruthPath, ProviderResponse: "Yours truely"},
+	}
+
+	tests := []struct {
+		name        string
+		groundTruth bool
+		want        map[string]string
+	}{
+		{
+			name: "responses only",
+			want: map[string]string{
+				"letter1.txt": "Dear Sir,\nI remane",
+				"letter2.txt": "Yours truely",
+			},
+		},
+		{
+			name:        "with ground truth",
+			groundTruth: true,
+			want: map[string]string{
+				"letter1.txt":    "Dear Sir,\nI remane",
+				"letter1.gt.txt": "Dear Sir,\nI remain",
+				"letter2.txt":    "Yours truely",
+				"letter2.gt.txt": "Yours truly",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outputDir := filepath.Join(t.TempDir(), "review")
+			written, err := extractResponses(results, outputDir, tt.groundTruth)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if written != len(tt.want) {
+				t.Errorf("extractResponses() wrote %d files, want %d", written, len(tt.want))
+			}
+
+			entries, err := os.ReadDir(outputDir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var names []string
+			for _, entry := range entries {
+				names = append(names, entry.Name())
+			}
+			var wantNames []string
+			for name := range tt.want {
+				wantNames = append(wantNames, name)
+			}
+			slices.Sort(wantNames)
+			if !slices.Equal(names, wantNames) {
+				t.Fatalf("files = %v, want %v", names, wantNames)
+			}
+			for name, want := range tt.want {
+				got, err := os.ReadFile(filepath.Join(outputDir, name))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if string(got) != want {
+					t.Errorf("%s = %q, want %q", name, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestExtractResponsesRejectsCollidingIdentifiers(t *testing.T) {
+	outputDir := filepath.Join(t.TempDir(), "review")
+	results := []EvalResult{
+		{Identifier: "page1.jpg", ProviderResponse: "first"},
+		{Identifier: "page1.png", ProviderResponse: "second"},
+	}
+
+	_, err := extractResponses(results, outputDir, false)
+	if err == nil || !strings.Contains(err.Error(), "page1.txt") {
+		t.Fatalf("extractResponses() error = %v, want a collision on page1.txt", err)
+	}
+	if _, err := os.Stat(outputDir); !os.IsNotExist(err) {
+		t.Fatalf("output directory was created before the collision was found: %v", err)
+	}
+}
+
+func TestExtractFileName(t *testing.T) {
+	tests := []struct {
+		identifier string
+		want       string
+	}{
+		{"letter1.jpg", "letter1"},
+		{"scan.2024.tiff", "scan.2024"},
+		{"no-extension", "no-extension"},
+		{"../../etc/passwd", "passwd"},
+		{`batch\page3.png`, "page3"},
+		{"..", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := extractFileName(tt.identifier); got != tt.want {
+			t.Errorf("extractFileName(%q) = %q, want %q", tt.identifier, got, tt.want)
+		}
+	}
+}
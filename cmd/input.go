@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// evalInputRow is one entry of a JSON evaluation input file.
+type evalInputRow struct {
+	Image      string `json:"image"`
+	Transcript string `json:"transcript"`
+	Public     bool   `json:"public"`
+}
+
+// readEvalRows reads the evaluation input at path and returns its data rows
+// as image, transcript, and public columns, followed by any extra columns.
+// The format is chosen by file extension: .tsv is tab-delimited, .json is an
+// array of objects, and anything else is read as CSV. A leading header row is
+// skipped for delimited files, and its lowercased names are returned as the
+// column names so extra columns can be used as prompt template variables.
+// Columns is nil when the input has no header.
+func readEvalRows(path string) (rows [][]string, columns []string, err error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open input file: %w", err)
+	}
+	defer file.Close()
+
+	var records [][]string
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		var entries []evalInputRow
+		if err := json.NewDecoder(file).Decode(&entries); err != nil {
+			return nil, nil, fmt.Errorf("failed to read JSON input: %w", err)
+		}
+		for _, entry := range entries {
+			records = append(records, []string{entry.Image, entry.Transcript, strconv.FormatBool(entry.Public)})
+		}
+	case ".tsv":
+		reader := csv.NewReader(file)
+		reader.Comma = '\t'
+		reader.LazyQuotes = true
+		if records, err = reader.ReadAll(); err != nil {
+			return nil, nil, fmt.Errorf("failed to read TSV: %w", err)
+		}
+	default:
+		if records, err = csv.NewReader(file).ReadAll(); err != nil {
+			return nil, nil, fmt.Errorf("failed to read CSV: %w", err)
+		}
+	}
+
+	if len(records) == 0 {
+		return nil, nil, fmt.Errorf("input file is empty")
+	}
+
+	// Skip header row if present
+	if len(records[0]) > 0 && strings.EqualFold(strings.TrimSpace(records[0][0]), "image") {
+		for _, name := range records[0] {
+			columns = append(columns, strings.ToLower(strings.TrimSpace(name)))
+		}
+		records = records[1:]
+	}
+	return records, columns, nil
+}
+
+// imageExtensions lists the file types picked up when --images names a directory.
+var imageExtensions = []string{".bmp", ".gif", ".jpeg", ".jpg", ".png", ".tif", ".tiff", ".webp"}
+
+// readImageRows expands pattern, either a directory or a glob, into sorted
+// image paths. Each path is returned as a row with an empty transcript column
+// so it can flow through the same loop as manifest rows. Directories are not
+// searched recursively and only files with an image extension are included.
+func readImageRows(pattern string) ([][]string, error) {
+	var paths []string
+	if info, err := os.Stat(pattern); err == nil && info.IsDir() {
+		entries, err := os.ReadDir(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read image directory: %w", err)
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() && slices.Contains(imageExtensions, strings.ToLower(filepath.Ext(entry.Name()))) {
+				paths = append(paths, filepath.Join(pattern, entry.Name()))
+			}
+		}
+	} else {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --images pattern: %w", err)
+		}
+		for _, match := range matches {
+			if info, err := os.Stat(match); err == nil && !info.IsDir() {
+				paths = append(paths, match)
+			}
+		}
+	}
+
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no images found for %q", pattern)
+	}
+
+	slices.Sort(paths)
+	rows := make([][]string, 0, len(paths))
+	for _, path := range paths {
+		rows = append(rows, []string{path, "", "false"})
+	}
+	return rows, nil
+}
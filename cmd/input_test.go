@@ -0,0 +1,211 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestReadEvalRows(t *testing.T) {
+	want := [][]string{
+		{"page1.jpg", "page1.txt", "true"},
+		{"page 2, verso.jpg", "page2.txt", "false"},
+	}
+
+	header := []string{"image", "transcript", "public"}
+
+	tests := []struct {
+		name        string
+		filename    string
+		content     string
+		want        [][]string
+		wantColumns []string
+	}{
+		{
+			name:        "csv with header",
+			filename:    "images.csv",
+			content:     "image,transcript,public\npage1.jpg,page1.txt,true\n\"page 2, verso.jpg\",page2.txt,false\n",
+			want:        want,
+			wantColumns: header,
+		},
+		{
+			name:     "csv with extra columns",
+			filename: "images.csv",
+			content:  "image,transcript,public, Language ,date\npage1.jpg,page1.txt,true,German,1850\n",
+			want: [][]string{
+				{"page1.jpg", "page1.txt", "true", "German", "1850"},
+			},
+			wantColumns: []string{"image", "transcript", "public", "language", "date"},
+		},
+		{
+			name:     "csv without header",
+			filename: "images.csv",
+			content:  "page1.jpg,page1.txt,true\n\"page 2, verso.jpg\",page2.txt,false\n",
+			want:     want,
+		},
+		{
+			name:        "tsv with header",
+			filename:    "images.tsv",
+			content:     "Image\ttranscript\tpublic\npage1.jpg\tpage1.txt\ttrue\npage 2, verso.jpg\tpage2.txt\tfalse\n",
+			want:        want,
+			wantColumns: header,
+		},
+		{
+			name:     "json array",
+			filename: "images.json",
+			content:  `[{"image": "page1.jpg", "transcript": "page1.txt", "public": true}, {"image": "page 2, verso.jpg", "transcript": "page2.txt"}]`,
+			want:     want,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.filename)
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			got, columns, err := readEvalRows(path)
+			if err != nil {
+				t.Fatalf("readEvalRows() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("readEvalRows() = %q, want %q", got, tt.want)
+			}
+			if !reflect.DeepEqual(columns, tt.wantColumns) {
+				t.Errorf("readEvalRows() columns = %q, want %q", columns, tt.wantColumns)
+			}
+		})
+	}
+}
+
+func TestReadEvalRowsErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		filename string
+		content  string
+	}{
+		{name: "empty csv", filename: "images.csv", content: ""},
+		{name: "empty json array", filename: "images.json", content: "[]"},
+		{name: "malformed json", filename: "images.json", content: `{"image": "page1.jpg"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.filename)
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if _, _, err := readEvalRows(path); err == nil {
+				t.Error("readEvalRows() error = nil, want error")
+			}
+		})
+	}
+
+	if _, _, err := readEvalRows(filepath.Join(t.TempDir(), "missing.csv")); err == nil {
+		t.Error("readEvalRows() on missing file error = nil, want error")
+	}
+}
+
+func TestEvalInputFlagAcceptsCSVAlias(t *testing.T) {
+	flag := evalCmd.Flags().Lookup("csv")
+	if flag == nil || flag.Name != "input" {
+		t.Fatalf("Lookup(csv) = %v, want the input flag", flag)
+	}
+}
+
+func TestReadImageRows(t *testing.T) {
+	tmpDir := t.TempDir()
+	for _, name := range []string{"b.jpg", "a.PNG", "notes.txt", "c.tif"} {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(tmpDir, "nested.jpg"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		pattern string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:    "directory keeps image files only",
+			pattern: tmpDir,
+			want:    []string{"a.PNG", "b.jpg", "c.tif"},
+		},
+		{
+			name:    "glob",
+			pattern: filepath.Join(tmpDir, "*.jpg"),
+			want:    []string{"b.jpg"},
+		},
+		{
+			name:    "no matches",
+			pattern: filepath.Join(tmpDir, "*.jp2"),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := readImageRows(tt.pattern)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("readImageRows() error = nil, want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("readImageRows() error = %v", err)
+			}
+
+			var got []string
+			for _, row := range rows {
+				if len(row) != 3 || row[1] != "" {
+					t.Fatalf("row = %q, want image path with empty transcript", row)
+				}
+				got = append(got, filepath.Base(row[0]))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("readImageRows() images = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProcessEvaluationTranscribeOnly(t *testing.T) {
+	tmpDir := t.TempDir()
+	for _, name := range []string{"page1.jpg", "page2.jpg"} {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), fakeJPEG("image-"+name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	stub := &stubEvalProvider{responses: map[string]string{"page1.jpg": "hello world", "page2.jpg": "second page"}}
+	useStubEvalProvider(t, stub)
+
+	config := EvalConfig{Provider: "stub", Model: "model", Prompt: "Extract", Images: tmpDir}
+	results, err := processEvaluation(context.Background(), config, nil, "")
+	if err != nil {
+		t.Fatalf("processEvaluation() error = %v", err)
+	}
+
+	if len(results) != 2 {
+		t.Fatalf("results = %+v, want two transcriptions", results)
+	}
+	for index, want := range []string{"hello world", "second page"} {
+		result := results[index]
+		if result.ProviderResponse != want {
+			t.Errorf("results[%d].ProviderResponse = %q, want %q", index, result.ProviderResponse, want)
+		}
+		if result.TranscriptPath != "" || result.WordAccuracy != 0 {
+			t.Errorf("results[%d] = %+v, want no transcript or metrics", index, result)
+		}
+		if result.InputTokens != 10 || result.TotalWordsTranscribed != 2 {
+			t.Errorf("results[%d] = %+v, want usage and word count recorded", index, result)
+		}
+	}
+}
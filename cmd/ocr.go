@@ -1,25 +1,32 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"net/url"
 	"os"
 	"slices"
 	"strings"
 	"time"
 
+	"github.com/lehigh-university-libraries/htr/pkg/objectstore"
+	"github.com/lehigh-university-libraries/htr/pkg/providers"
 	"github.com/spf13/cobra"
 )
 
-const defaultOCRPrompt = "Extract all text from this image. Return only the transcribed text."
-
 var ocrCmd = &cobra.Command{
-	Use:   "ocr",
-	Short: "Extract OCR text from a single image",
+	Use:     "ocr",
+	Aliases: []string{"transcribe"},
+	Short:   "Extract OCR text from a single image",
 	Long: `Extract OCR text from a single image using a configured provider and model.
 
 This command sends one image to the selected OCR/vision provider and prints the
-transcribed text directly, without running an evaluation.`,
+transcribed text directly, without running an evaluation. The image may be a local
+path or an http(s) URL. It is also available as "htr transcribe".
+
+With --show-usage, the token and page counts reported by the provider are printed
+to stderr so stdout carries only the transcription.`,
 	RunE: runOCR,
 }
 
@@ -34,13 +41,18 @@ var (
 	ocrDebug                 bool
 	ocrMaxResolution         string
 	ocrMaxResolutionFallback bool
+	ocrShowUsage             bool
+	ocrPollInterval          time.Duration
+	ocrOllamaKeepAlive       string
+	ocrOllamaNumCtx          int
+	ocrOpenAIDetail          string
 )
 
 func init() {
 	RootCmd.AddCommand(ocrCmd)
 
 	ocrCmd.Flags().StringVar(&ocrImagePath, "image", "", "Path or URL to the input image")
-	ocrCmd.Flags().StringVar(&ocrProvider, "provider", "openai", "Provider to use: openai, azure, claude, gemini, ollama")
+	ocrCmd.Flags().StringVar(&ocrProvider, "provider", "openai", "Provider to use: openai, azure, azure-openai, claude, gemini, ollama, mistral, docai, openai-compat, huggingface, kraken, bedrock")
 	ocrCmd.Flags().StringVarP(&ocrModel, "model", "m", "", "Model to use (uses provider default when available)")
 	ocrCmd.Flags().StringVarP(&ocrPrompt, "prompt", "p", defaultOCRPrompt, "Prompt to send to the provider")
 	ocrCmd.Flags().Float64VarP(&ocrTemperature, "temperature", "t", 0.0, "Temperature for API")
@@ -49,6 +61,11 @@ func init() {
 	ocrCmd.Flags().BoolVar(&ocrDebug, "debug", false, "Print provider debug output when supported")
 	ocrCmd.Flags().StringVar(&ocrMaxResolution, "gemini-max-resolution", "MEDIA_RESOLUTION_UNSPECIFIED", "Max resolution for Gemini models (e.g., MEDIA_RESOLUTION_HIGH)")
 	ocrCmd.Flags().BoolVar(&ocrMaxResolutionFallback, "gemini-max-resolution-fallback", false, "Automatically retry with lower resolution if MAX_TOKENS error occurs")
+	ocrCmd.Flags().StringVar(&ocrOllamaKeepAlive, "ollama-keep-alive", defaultOllamaKeepAlive, "How long Ollama keeps the model loaded between requests: a duration such as 30m, or seconds, with -1 for indefinitely")
+	ocrCmd.Flags().IntVar(&ocrOllamaNumCtx, "ollama-num-ctx", 0, "Ollama context window in tokens (0 uses the model default)")
+	ocrCmd.Flags().StringVar(&ocrOpenAIDetail, "openai-detail", "", "OpenAI image detail: low, high, or auto (empty uses the service default); low is much cheaper for legible pages")
+	ocrCmd.Flags().DurationVar(&ocrPollInterval, "poll-interval", 0, "Delay between Azure OCR result polls (defaults to 1s); polling stops at --timeout")
+	ocrCmd.Flags().BoolVar(&ocrShowUsage, "show-usage", false, "Print provider token and page usage to stderr")
 
 	err := ocrCmd.MarkFlagRequired("image")
 	if err != nil {
@@ -62,12 +79,18 @@ func runOCR(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	text, err := processOCRImage(config, ocrImagePath)
+	text, usage, err := processOCRImage(context.Background(), config, ocrImagePath)
 	if err != nil {
 		return err
 	}
 
-	return outputOCRText(text, ocrOutputPath)
+	if err := outputOCRText(text, ocrOutputPath); err != nil {
+		return err
+	}
+	if ocrShowUsage {
+		writeOCRUsage(os.Stderr, usage)
+	}
+	return nil
 }
 
 func buildOCRConfig() (EvalConfig, error) {
@@ -75,6 +98,16 @@ func buildOCRConfig() (EvalConfig, error) {
 		return EvalConfig{}, fmt.Errorf("invalid --gemini-max-resolution value '%s'. Allowed values are: %s", ocrMaxResolution, strings.Join(allowedMediaResolutions, ", "))
 	}
 
+	if ocrPollInterval < 0 {
+		return EvalConfig{}, fmt.Errorf("--poll-interval cannot be negative")
+	}
+	if err := validateOllamaOptions(ocrOllamaKeepAlive, ocrOllamaNumCtx); err != nil {
+		return EvalConfig{}, err
+	}
+	if err := validateOpenAIDetail(ocrProvider, ocrOpenAIDetail); err != nil {
+		return EvalConfig{}, err
+	}
+
 	if !isRemoteResource(ocrImagePath) {
 		if _, err := os.Stat(ocrImagePath); err != nil {
 			return EvalConfig{}, fmt.Errorf("failed to access image %s: %w", ocrImagePath, err)
@@ -95,21 +128,35 @@ func buildOCRConfig() (EvalConfig, error) {
 		Debug:                 ocrDebug,
 		MaxResolution:         ocrMaxResolution,
 		MaxResolutionFallback: ocrMaxResolutionFallback,
+		PollInterval:          ocrPollInterval,
+		OllamaKeepAlive:       ocrOllamaKeepAlive,
+		OllamaNumCtx:          ocrOllamaNumCtx,
+		OpenAIDetail:          ocrOpenAIDetail,
 	}, nil
 }
 
-func processOCRImage(config EvalConfig, imagePath string) (string, error) {
-	imageBase64, err := getImageAsBase64(imagePath)
+func processOCRImage(ctx context.Context, config EvalConfig, imagePath string) (string, providers.UsageInfo, error) {
+	imageBase64, _, err := getImageAsBase64(ctx, config, imagePath)
 	if err != nil {
-		return "", fmt.Errorf("failed to process image: %w", err)
+		return "", providers.UsageInfo{}, fmt.Errorf("failed to process image: %w", err)
 	}
 
-	text, _, err := extractTextWithProvider(config, imagePath, imageBase64)
+	text, usage, err := extractTextWithProvider(ctx, config, imagePath, imageBase64)
 	if err != nil {
-		return "", fmt.Errorf("provider API call failed: %w", err)
+		return "", providers.UsageInfo{}, fmt.Errorf("provider API call failed: %w", err)
 	}
 
-	return text, nil
+	return strings.TrimSpace(text), usage, nil
+}
+
+// writeOCRUsage prints the provider-reported usage. Page counts are only
+// shown for providers that bill per page.
+func writeOCRUsage(w io.Writer, usage providers.UsageInfo) {
+	fmt.Fprintf(w, "Input tokens: %d\n", usage.InputTokens)
+	fmt.Fprintf(w, "Output tokens: %d\n", usage.OutputTokens)
+	if usage.Pages > 0 {
+		fmt.Fprintf(w, "Pages: %d\n", usage.Pages)
+	}
 }
 
 func outputOCRText(text, outputPath string) error {
@@ -121,11 +168,13 @@ func outputOCRText(text, outputPath string) error {
 	return nil
 }
 
+// isRemoteResource reports whether path is an http(s) URL or an s3:// or
+// gs:// object rather than a local file.
 func isRemoteResource(path string) bool {
 	u, err := url.Parse(path)
 	if err != nil {
 		return false
 	}
 
-	return u.Scheme == "http" || u.Scheme == "https"
+	return u.Scheme == "http" || u.Scheme == "https" || objectstore.IsURI(path)
 }
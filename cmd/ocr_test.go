@@ -12,11 +12,14 @@ import (
 )
 
 type stubOCRProvider struct {
+	providers.BaseProvider
+
 	validateConfig providers.Config
 	extractConfig  providers.Config
 	imagePath      string
 	imageBase64    string
 	response       string
+	usage          providers.UsageInfo
 }
 
 func (p *stubOCRProvider) Name() string {
@@ -32,7 +35,7 @@ func (p *stubOCRProvider) ExtractText(ctx context.Context, config providers.Conf
 	p.extractConfig = config
 	p.imagePath = imagePath
 	p.imageBase64 = imageBase64
-	return p.response, providers.UsageInfo{}, nil
+	return p.response, p.usage, nil
 }
 
 func TestBuildOCRConfigUsesDefaults(t *testing.T) {
@@ -59,7 +62,7 @@ func TestBuildOCRConfigUsesDefaults(t *testing.T) {
 
 	tmpDir := t.TempDir()
 	imagePath := filepath.Join(tmpDir, "page.jpg")
-	if err := os.WriteFile(imagePath, []byte("image-data"), 0644); err != nil {
+	if err := os.WriteFile(imagePath, fakeJPEG("image-data"), 0644); err != nil {
 		t.Fatalf("failed to create temp image: %v", err)
 	}
 
@@ -126,7 +129,7 @@ func TestRunOCRWritesExtractedTextToFile(t *testing.T) {
 	tmpDir := t.TempDir()
 	imagePath := filepath.Join(tmpDir, "page.jpg")
 	outputPath := filepath.Join(tmpDir, "page.txt")
-	if err := os.WriteFile(imagePath, []byte("image-data"), 0644); err != nil {
+	if err := os.WriteFile(imagePath, fakeJPEG("image-data"), 0644); err != nil {
 		t.Fatalf("failed to create temp image: %v", err)
 	}
 
@@ -173,3 +176,54 @@ func TestRunOCRWritesExtractedTextToFile(t *testing.T) {
 		t.Fatal("imageBase64 should not be empty")
 	}
 }
+
+func TestTranscribeAliasPrintsTextAndUsage(t *testing.T) {
+	cmd, _, err := RootCmd.Find([]string{"transcribe"})
+	if err != nil || cmd != ocrCmd {
+		t.Fatalf("RootCmd.Find(transcribe) = %v, %v; want the ocr command", cmd, err)
+	}
+
+	originalRegistry := providerRegistry
+	originalImagePath := ocrImagePath
+	originalModel := ocrModel
+	t.Cleanup(func() {
+		providerRegistry = originalRegistry
+		ocrImagePath = originalImagePath
+		ocrModel = originalModel
+	})
+
+	imagePath := filepath.Join(t.TempDir(), "page.jpg")
+	if err := os.WriteFile(imagePath, fakeJPEG("image-data"), 0644); err != nil {
+		t.Fatalf("failed to create temp image: %v", err)
+	}
+
+	stub := &stubOCRProvider{
+		response: "  hello from transcribe\n",
+		usage:    providers.UsageInfo{InputTokens: 120, OutputTokens: 8},
+	}
+	registry := providers.NewRegistry()
+	registry.Register(stub)
+	providerRegistry = registry
+
+	ocrImagePath = imagePath
+	ocrModel = "gpt-test"
+	config, err := buildOCRConfig()
+	if err != nil {
+		t.Fatalf("buildOCRConfig() error = %v", err)
+	}
+
+	text, usage, err := processOCRImage(context.Background(), config, imagePath)
+	if err != nil {
+		t.Fatalf("processOCRImage() error = %v", err)
+	}
+	if text != "hello from transcribe" {
+		t.Fatalf("text = %q, want trimmed transcription", text)
+	}
+
+	var buf strings.Builder
+	writeOCRUsage(&buf, usage)
+	want := "Input tokens: 120\nOutput tokens: 8\n"
+	if buf.String() != want {
+		t.Fatalf("usage output = %q, want %q", buf.String(), want)
+	}
+}
@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// progressWindow is the number of recent rows used to estimate time remaining.
+const progressWindow = 10
+
+// progressReporter draws a single-line "N/total" counter with an ETA based on
+// a rolling average of recent row durations.
+type progressReporter struct {
+	w         io.Writer
+	total     int
+	done      int
+	durations []time.Duration
+	last      time.Time
+	now       func() time.Time
+}
+
+func newProgressReporter(w io.Writer, total int, now func() time.Time) *progressReporter {
+	return &progressReporter{w: w, total: total, last: now(), now: now}
+}
+
+// newEvalProgress returns a reporter on stderr, or nil when stdout is not a
+// terminal so piped and redirected runs stay free of control characters.
+func newEvalProgress(total int) *progressReporter {
+	if total == 0 || !isTerminal(os.Stdout) {
+		return nil
+	}
+	progress := newProgressReporter(os.Stderr, total, time.Now)
+	progress.draw()
+	return progress
+}
+
+// Step records one finished row and redraws the counter.
+func (p *progressReporter) Step() {
+	if p == nil {
+		return
+	}
+	now := p.now()
+	p.durations = append(p.durations, now.Sub(p.last))
+	if len(p.durations) > progressWindow {
+		p.durations = p.durations[1:]
+	}
+	p.last = now
+	p.done++
+	p.draw()
+}
+
+// Clear erases the counter so other output can be printed in its place.
+func (p *progressReporter) Clear() {
+	if p == nil {
+		return
+	}
+	fmt.Fprint(p.w, "\r\033[K")
+}
+
+// Finish clears the counter once all rows are processed.
+func (p *progressReporter) Finish() {
+	p.Clear()
+}
+
+func (p *progressReporter) draw() {
+	fmt.Fprintf(p.w, "\r\033[K%s", p.status())
+}
+
+func (p *progressReporter) status() string {
+	percent := 0
+	if p.total > 0 {
+		percent = p.done * 100 / p.total
+	}
+	status := fmt.Sprintf("[%d/%d] %d%%", p.done, p.total, percent)
+	if len(p.durations) == 0 || p.done >= p.total {
+		return status
+	}
+
+	var sum time.Duration
+	for _, duration := range p.durations {
+		sum += duration
+	}
+	eta := sum / time.Duration(len(p.durations)) * time.Duration(p.total-p.done)
+	return fmt.Sprintf("%s ETA %s", status, eta.Round(time.Second))
+}
+
+func isTerminal(file *os.File) bool {
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
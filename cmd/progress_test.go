@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProgressReporterStatus(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name  string
+		total int
+		steps []time.Duration
+		want  string
+	}{
+		{"not started", 4, nil, "[0/4] 0%"},
+		{"eta from average", 4, []time.Duration{2 * time.Second, 4 * time.Second}, "[2/4] 50% ETA 6s"},
+		{"complete", 2, []time.Duration{time.Second, time.Second}, "[2/2] 100%"},
+		{
+			name:  "eta uses rolling window",
+			total: progressWindow + 2,
+			steps: append([]time.Duration{time.Hour}, repeatDuration(time.Second, progressWindow)...),
+			want:  "[11/12] 91% ETA 1s",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := start
+			var out strings.Builder
+			progress := newProgressReporter(&out, tt.total, func() time.Time { return now })
+			for _, step := range tt.steps {
+				now = now.Add(step)
+				progress.Step()
+			}
+			if got := progress.status(); got != tt.want {
+				t.Errorf("status() = %q, want %q", got, tt.want)
+			}
+			if !strings.HasSuffix(out.String(), tt.want) && len(tt.steps) > 0 {
+				t.Errorf("output %q does not end with %q", out.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestProgressReporterNilIsNoop(t *testing.T) {
+	var progress *progressReporter
+	progress.Step()
+	progress.Clear()
+	progress.Finish()
+}
+
+func repeatDuration(d time.Duration, n int) []time.Duration {
+	durations := make([]time.Duration, n)
+	for i := range durations {
+		durations[i] = d
+	}
+	return durations
+}
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// defaultOCRPrompt is sent by ocr, and by eval when neither --prompt nor
+// --prompt-file is given.
+const defaultOCRPrompt = "Extract all text from this image. Return only the transcribed text."
+
+// parsePromptTemplate parses a --prompt-file template. Referencing a variable
+// the row does not define is an error rather than an empty string, so a typo
+// in a column name cannot silently drop context from the prompt.
+func parsePromptTemplate(text string) (*template.Template, error) {
+	tmpl, err := template.New("prompt").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid prompt template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// promptVariables returns the template variables for row: the file name of
+// its first page image as "filename", then each named column, so a column called filename wins.
+func promptVariables(row, columns []string) map[string]string {
+	variables := map[string]string{"filename": rowIdentifier(row)}
+	for i, name := range columns {
+		if name != "" && i < len(row) {
+			variables[name] = strings.TrimSpace(row[i])
+		}
+	}
+	return variables
+}
+
+// renderPrompt executes tmpl with variables and trims surrounding whitespace,
+// such as the trailing newline of the template file.
+func renderPrompt(tmpl *template.Template, variables map[string]string) (string, error) {
+	var prompt strings.Builder
+	if err := tmpl.Execute(&prompt, variables); err != nil {
+		return "", fmt.Errorf("failed to render prompt template: %w", err)
+	}
+	return strings.TrimSpace(prompt.String()), nil
+}
+
+// appendPromptSuffix appends --prompt-suffix to prompt as its own paragraph.
+func appendPromptSuffix(prompt, suffix string) string {
+	suffix = strings.TrimSpace(suffix)
+	if suffix == "" {
+		return prompt
+	}
+	if prompt = strings.TrimSpace(prompt); prompt == "" {
+		return suffix
+	}
+	return prompt + "\n\n" + suffix
+}
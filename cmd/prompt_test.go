@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+)
+
+func TestRenderPrompt(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		row      []string
+		columns  []string
+		want     string
+		wantErr  bool
+	}{
+		{
+			name:     "extra columns",
+			template: "Transcribe this {{.language}} letter from {{.date}}.\n",
+			row:      []string{"letters/page1.jpg", "page1.txt", "true", " German ", "1850"},
+			columns:  []string{"image", "transcript", "public", "language", "date"},
+			want:     "Transcribe this German letter from 1850.",
+		},
+		{
+			name:     "filename without extra columns",
+			template: "Transcribe {{.filename}}.",
+			row:      []string{"letters/page1.jpg", "page1.txt", "true"},
+			columns:  []string{"image", "transcript", "public"},
+			want:     "Transcribe page1.jpg.",
+		},
+		{
+			name:     "filename without header",
+			template: "Transcribe {{.filename}}.",
+			row:      []string{"page1.jpg", "page1.txt", "true"},
+			want:     "Transcribe page1.jpg.",
+		},
+		{
+			name:     "conditional on optional column",
+			template: `Transcribe the page.{{if .script}} It is written in {{.script}}.{{end}}`,
+			row:      []string{"page1.jpg", "page1.txt", "true", ""},
+			columns:  []string{"image", "transcript", "public", "script"},
+			want:     "Transcribe the page.",
+		},
+		{
+			name:     "missing column",
+			template: "Transcribe this {{.language}} letter.",
+			row:      []string{"page1.jpg", "page1.txt", "true"},
+			columns:  []string{"image", "transcript", "public"},
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := parsePromptTemplate(tt.template)
+			if err != nil {
+				t.Fatalf("parsePromptTemplate() error = %v", err)
+			}
+			got, err := renderPrompt(tmpl, promptVariables(tt.row, tt.columns))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("renderPrompt() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("renderPrompt() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsePromptTemplateRejectsInvalidSyntax(t *testing.T) {
+	if _, err := parsePromptTemplate("Transcribe {{.language"); err == nil {
+		t.Fatal("parsePromptTemplate() error = nil, want error")
+	}
+}
+
+func TestProcessEvaluationRendersPromptTemplate(t *testing.T) {
+	csvPath := writeEvalFixtures(t,
+		map[string]string{"page1": "Brief", "page2": "Lettre"},
+		"image,transcript,public,language\npage1.jpg,page1.txt,true,German\npage2.jpg,page2.txt,true,French\n",
+	)
+	stub := &stubEvalProvider{responses: map[string]string{"page1.jpg": "Brief", "page2.jpg": "Lettre"}}
+	useStubEvalProvider(t, stub)
+
+	config := EvalConfig{Provider: "stub", Model: "model", CSVPath: csvPath, PromptTemplate: "Transcribe {{.filename}}, written in {{.language}}."}
+	if _, err := processEvaluation(context.Background(), config, nil, ""); err != nil {
+		t.Fatalf("processEvaluation() error = %v", err)
+	}
+
+	want := []string{"Transcribe page1.jpg, written in German.", "Transcribe page2.jpg, written in French."}
+	if len(stub.configs) != len(want) {
+		t.Fatalf("provider called %d times, want %d", len(stub.configs), len(want))
+	}
+	for i, config := range stub.configs {
+		if config.Prompt != want[i] {
+			t.Errorf("prompt %d = %q, want %q", i, config.Prompt, want[i])
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+var promptsCmd = &cobra.Command{
+	Use:   "prompts",
+	Short: "List the named prompts available to --prompt-id",
+	Long: `List the prompts in the prompt library (prompts/ unless --prompts-dir or
+HTR_PROMPTS_DIR is set) with the first line of each.
+
+Every .txt or .md file in the directory is a prompt named after the file
+without its extension, so prompts/secretary-hand-v3.txt is used with
+--prompt-id secretary-hand-v3. When both a .txt and a .md file share a name,
+the .txt file is used.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ids, err := listPromptIDs(promptsDir)
+		if err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			fmt.Printf("No prompts found in %s.\n", promptsDir)
+			return nil
+		}
+		printPrompts(os.Stdout, promptsDir, ids)
+		return nil
+	},
+}
+
+// promptExtensions are the file extensions of named prompts, in the order
+// a name is looked up.
+var promptExtensions = []string{".txt", ".md"}
+
+func init() {
+	RootCmd.AddCommand(promptsCmd)
+}
+
+// listPromptIDs returns the sorted names of the prompts in dir. A missing
+// directory has no prompts.
+func listPromptIDs(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list prompts: %w", err)
+	}
+	var ids []string
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if entry.IsDir() || !slices.Contains(promptExtensions, ext) {
+			continue
+		}
+		ids = append(ids, strings.TrimSuffix(entry.Name(), ext))
+	}
+	slices.Sort(ids)
+	return slices.Compact(ids), nil
+}
+
+// loadNamedPrompt returns the text of the prompt id in dir, with surrounding
+// whitespace trimmed. An unknown id is an error that lists the known ones.
+func loadNamedPrompt(dir, id string) (string, error) {
+	if id == "" || id != filepath.Base(id) || strings.HasPrefix(id, ".") {
+		return "", fmt.Errorf("invalid prompt id %q: use the file name of a prompt in %s without its extension", id, dir)
+	}
+	for _, ext := range promptExtensions {
+		data, err := os.ReadFile(filepath.Join(dir, id+ext))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to read prompt %q: %w", id, err)
+		}
+		text := strings.TrimSpace(string(data))
+		if text == "" {
+			return "", fmt.Errorf("prompt %q in %s is empty", id, dir)
+		}
+		return text, nil
+	}
+
+	ids, err := listPromptIDs(dir)
+	if err != nil {
+		return "", err
+	}
+	if len(ids) == 0 {
+		return "", fmt.Errorf("unknown prompt id %q: no prompts found in %s", id, dir)
+	}
+	return "", fmt.Errorf("unknown prompt id %q. Available prompts in %s: %s", id, dir, strings.Join(ids, ", "))
+}
+
+// printPrompts writes each prompt id with the first line of its text, or
+// the error a prompt that cannot be used would give.
+func printPrompts(w io.Writer, dir string, ids []string) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tPROMPT")
+	for _, id := range ids {
+		text, err := loadNamedPrompt(dir, id)
+		if err != nil {
+			text = err.Error()
+		}
+		firstLine, _, _ := strings.Cut(text, "\n")
+		fmt.Fprintf(tw, "%s\t%s\n", id, strings.TrimSpace(firstLine))
+	}
+	tw.Flush()
+}
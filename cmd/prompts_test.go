@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func writePromptLibrary(t *testing.T, prompts map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, text := range prompts {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(text), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestLoadNamedPrompt(t *testing.T) {
+	dir := writePromptLibrary(t, map[string]string{
+		"secretary-hand-v3.txt": "Transcribe this Secretary hand letter.\nKeep abbreviations.\n",
+		"census.md":             "# Census\n\nTranscribe each row.",
+		"both.txt":              "from txt",
+		"both.md":               "from md",
+		"empty.txt":             " \n",
+		"notes.json":            "{}",
+	})
+
+	tests := []struct {
+		id      string
+		want    string
+		wantErr string
+	}{
+		{id: "secretary-hand-v3", want: "Transcribe this Secretary hand letter.\nKeep abbreviations."},
+		{id: "census", want: "# Census\n\nTranscribe each row."},
+		{id: "both", want: "from txt"},
+		{id: "empty", wantErr: `prompt "empty" in ` + dir + " is empty"},
+		{id: "secretary-hand-v2", wantErr: "Available prompts in " + dir + ": both, census, empty, secretary-hand-v3"},
+		{id: "notes", wantErr: `unknown prompt id "notes"`},
+		{id: "../secretary-hand-v3", wantErr: "invalid prompt id"},
+		{id: "", wantErr: "invalid prompt id"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			got, err := loadNamedPrompt(dir, tt.id)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("loadNamedPrompt() error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("loadNamedPrompt() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("loadNamedPrompt() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadNamedPromptWithoutLibrary(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "prompts")
+	if ids, err := listPromptIDs(dir); err != nil || len(ids) != 0 {
+		t.Errorf("listPromptIDs() = %v, %v; want no prompts", ids, err)
+	}
+	_, err := loadNamedPrompt(dir, "secretary-hand-v3")
+	if err == nil || !strings.Contains(err.Error(), "no prompts found in "+dir) {
+		t.Errorf("loadNamedPrompt() error = %v, want no prompts found", err)
+	}
+}
+
+func TestPrintPrompts(t *testing.T) {
+	dir := writePromptLibrary(t, map[string]string{
+		"census.md":          "Transcribe each row.\nKeep blank cells.",
+		"secretary-hand.txt": "Transcribe this letter.",
+	})
+	ids, err := listPromptIDs(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"census", "secretary-hand"}; !reflect.DeepEqual(ids, want) {
+		t.Fatalf("listPromptIDs() = %v, want %v", ids, want)
+	}
+
+	var out bytes.Buffer
+	printPrompts(&out, dir, ids)
+	want := "ID              PROMPT\n" +
+		"census          Transcribe each row.\n" +
+		"secretary-hand  Transcribe this letter.\n"
+	if out.String() != want {
+		t.Errorf("printPrompts() =\n%s\nwant\n%s", out.String(), want)
+	}
+}
+
+func TestEvalWithPromptIDRecordsIDAndText(t *testing.T) {
+	csvPath := writeEvalFixtures(t, map[string]string{"page": "hello world"}, "page.jpg,page.txt,true\n")
+	setEvalFlags(t, map[string]string{"provider": "stub", "model": "stub-model", "csv": csvPath, "quiet": "true", "prompt-id": "secretary-hand-v3"})
+	originalEvalsDir, originalPromptsDir := evalsDir, promptsDir
+	t.Cleanup(func() { evalsDir, promptsDir = originalEvalsDir, originalPromptsDir })
+	evalsDir = t.TempDir()
+	promptsDir = writePromptLibrary(t, map[string]string{"secretary-hand-v3.txt": "Transcribe this Secretary hand letter.\n"})
+	stub := &stubEvalProvider{responses: map[string]string{"page.jpg": "hello world"}}
+	useStubEvalProvider(t, stub)
+
+	if err := runEval(evalCmd, nil); err != nil {
+		t.Fatalf("runEval() error = %v", err)
+	}
+	if got := stub.configs[0].Prompt; got != "Transcribe this Secretary hand letter." {
+		t.Errorf("sent prompt = %q, want the library prompt", got)
+	}
+	summary, err := loadEvalSummary(filepath.Join(evalsDir, "stub_stub-model.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.Config.PromptID != "secretary-hand-v3" || summary.Config.Prompt != "Transcribe this Secretary hand letter." {
+		t.Errorf("saved PromptID, Prompt = %q, %q; want the id and its text", summary.Config.PromptID, summary.Config.Prompt)
+	}
+
+	if err := evalCmd.Flags().Set("prompt-id", "secretary-hand-v2"); err != nil {
+		t.Fatal(err)
+	}
+	if err := runEval(evalCmd, nil); err == nil || !strings.Contains(err.Error(), `unknown prompt id "secretary-hand-v2"`) {
+		t.Errorf("runEval() with an unknown id error = %v, want unknown prompt id", err)
+	}
+}
@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/lehigh-university-libraries/htr/pkg/providers"
+	"github.com/spf13/cobra"
+)
+
+var providersCmd = &cobra.Command{
+	Use:     "providers",
+	Aliases: []string{"models"},
+	Short:   "List the available providers, their default models, and required environment variables",
+	Long: `List every provider htr can use with its default model and the environment
+variables it needs, marking each variable as set or missing in the current
+environment. The default model is the one create and ocr use when --model is
+not given; a *_MODEL environment variable overrides it.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		printProviders(os.Stdout, providerRegistry)
+		return nil
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(providersCmd)
+}
+
+// validateProvider returns an error listing the registered providers, with
+// the closest name as a suggestion, when name is not one of them.
+func validateProvider(registry *providers.Registry, name string) error {
+	if registry.HasProvider(name) {
+		return nil
+	}
+	names := registry.List()
+	if suggestion := suggestProvider(names, name); suggestion != "" {
+		return fmt.Errorf("unknown provider %q; did you mean %q? Valid providers: %s", name, suggestion, strings.Join(names, ", "))
+	}
+	return fmt.Errorf("unknown provider %q. Valid providers: %s", name, strings.Join(names, ", "))
+}
+
+// suggestProvider returns the name closest to name by edit distance, or ""
+// when none is close enough to be a likely typo. Two edits are always
+// allowed, so a swapped pair of letters matches, and longer names allow one
+// edit per three characters, up to three.
+func suggestProvider(names []string, name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	best, bestDistance := "", 0
+	for _, candidate := range names {
+		distance := levenshteinDistance(name, candidate)
+		limit := min(3, max(2, max(len(name), len(candidate))/3))
+		if distance > limit {
+			continue
+		}
+		if best == "" || distance < bestDistance {
+			best, bestDistance = candidate, distance
+		}
+	}
+	return best
+}
+
+// providerEnvVars returns the environment variables a provider cannot run
+// without. Optional overrides, such as base URLs, are not listed.
+func providerEnvVars(providerName string) []string {
+	switch providerName {
+	case "openai":
+		return []string{"OPENAI_API_KEY"}
+	case "azure":
+		return []string{"AZURE_OCR_ENDPOINT", "AZURE_OCR_API_KEY"}
+	case "azure-openai":
+		return []string{"AZURE_OPENAI_ENDPOINT", "AZURE_OPENAI_API_KEY", "AZURE_OPENAI_DEPLOYMENT"}
+	case "claude":
+		return []string{"ANTHROPIC_API_KEY"}
+	case "gemini":
+		return []string{"GEMINI_API_KEY"}
+	case "mistral":
+		return []string{"MISTRAL_API_KEY"}
+	case "docai":
+		return []string{"GOOGLE_APPLICATION_CREDENTIALS", "DOCAI_LOCATION", "DOCAI_PROCESSOR_ID"}
+	case "openai-compat":
+		return []string{"OPENAI_COMPAT_BASE_URL", "OPENAI_COMPAT_API_KEY"}
+	case "huggingface":
+		return []string{"HF_API_TOKEN"}
+	case "kraken":
+		return []string{"KRAKEN_URL", "KRAKEN_TOKEN"}
+	case "bedrock":
+		return []string{"AWS_REGION", "AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY"}
+	default:
+		return nil
+	}
+}
+
+// printProviders writes one row per registered provider, sorted by name.
+func printProviders(w io.Writer, registry *providers.Registry) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "PROVIDER\tDEFAULT MODEL\tENVIRONMENT")
+	for _, name := range registry.List() {
+		model := getDefaultModel(name)
+		if model == "" {
+			model = "-"
+		}
+
+		env := "none required"
+		if names := providerEnvVars(name); len(names) > 0 {
+			statuses := make([]string, len(names))
+			for i, name := range names {
+				status := "missing"
+				if strings.TrimSpace(os.Getenv(name)) != "" {
+					status = "set"
+				}
+				statuses[i] = fmt.Sprintf("%s (%s)", name, status)
+			}
+			env = strings.Join(statuses, ", ")
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", name, model, env)
+	}
+	tw.Flush()
+}
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestPrintProvidersListsBuiltIns(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", "sk-test")
+	t.Setenv("GEMINI_API_KEY", "")
+	t.Setenv("OPENAI_MODEL", "")
+
+	var out bytes.Buffer
+	printProviders(&out, providerRegistry)
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	rows := make(map[string]string)
+	var names []string
+	for _, line := range lines[1:] {
+		name, _, _ := strings.Cut(line, " ")
+		names = append(names, name)
+		rows[name] = line
+	}
+
+	for _, name := range []string{"openai", "azure", "azure-openai", "claude", "gemini", "ollama", "mistral", "docai", "openai-compat", "huggingface", "kraken", "bedrock"} {
+		if _, ok := rows[name]; !ok {
+			t.Errorf("output is missing provider %q:\n%s", name, out.String())
+		}
+	}
+	if !slices.IsSorted(names) {
+		t.Errorf("providers are not sorted: %v", names)
+	}
+
+	for name, want := range map[string][]string{
+		"openai": {"gpt-4o", "OPENAI_API_KEY (set)"},
+		"gemini": {"gemini-1.5-flash", "GEMINI_API_KEY (missing)"},
+		"azure":  {"AZURE_OCR_ENDPOINT", "AZURE_OCR_API_KEY"},
+		"ollama": {"none required"},
+	} {
+		for _, text := range want {
+			if !strings.Contains(rows[name], text) {
+				t.Errorf("%s row = %q, want it to contain %q", name, rows[name], text)
+			}
+		}
+	}
+}
+
+func TestSuggestProvider(t *testing.T) {
+	names := providerRegistry.List()
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "opena", want: "openai"},
+		{name: "OpenAI", want: "openai"},
+		{name: "claud", want: "claude"},
+		{name: "gemnii", want: "gemini"},
+		{name: "azur", want: "azure"},
+		{name: "olama", want: "ollama"},
+		{name: "openaicompat", want: "openai-compat"},
+		{name: "docia", want: "docai"},
+		{name: "tesseract", want: ""},
+		{name: "x", want: ""},
+		{name: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := suggestProvider(names, tt.name); got != tt.want {
+				t.Errorf("suggestProvider(%q) = %q, want %q", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateProvider(t *testing.T) {
+	if err := validateProvider(providerRegistry, "Gemini"); err != nil {
+		t.Errorf("validateProvider(Gemini) error = %v", err)
+	}
+
+	err := validateProvider(providerRegistry, "opena")
+	if err == nil || !strings.Contains(err.Error(), `did you mean "openai"?`) || !strings.Contains(err.Error(), "Valid providers: azure, azure-openai, bedrock, claude, docai") {
+		t.Errorf("validateProvider(opena) error = %v, want a suggestion and the valid providers", err)
+	}
+
+	err = validateProvider(providerRegistry, "tesseract")
+	if err == nil || strings.Contains(err.Error(), "did you mean") || !strings.Contains(err.Error(), `unknown provider "tesseract". Valid providers:`) {
+		t.Errorf("validateProvider(tesseract) error = %v, want the valid providers without a suggestion", err)
+	}
+}
+
+func TestRunCreateRejectsUnknownProviderFirst(t *testing.T) {
+	originalProvider, originalImagePath := provider, imagePath
+	t.Cleanup(func() { provider, imagePath = originalProvider, originalImagePath })
+	provider = "claud"
+	imagePath = "does-not-exist.jpg"
+
+	err := runCreate(nil, nil)
+	if err == nil || !strings.Contains(err.Error(), `did you mean "claude"?`) {
+		t.Errorf("runCreate() error = %v, want an unknown provider error before the image is read", err)
+	}
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/lehigh-university-libraries/htr/pkg/imaging"
+	"github.com/lehigh-university-libraries/htr/pkg/providers"
+)
+
+// rasterizeImage converts a TIFF or PDF to a PNG of one page, numbered from 1,
+// since vision providers cannot read either format. Page 0 selects the first
+// page. Other images are returned unchanged whatever the page.
+func rasterizeImage(data []byte, page int) ([]byte, error) {
+	mediaType := providers.DetectMediaType(data)
+	if !imaging.IsPaged(mediaType) {
+		return data, nil
+	}
+	return imaging.Rasterize(data, mediaType, max(page, 1))
+}
+
+// rasterizeImageFile is rasterizeImage for commands that hand ImageMagick a
+// file path. The page is written to a PNG in dir, or the system temp
+// directory when dir is empty, and its path returned; the caller removes it.
+// Other images return imagePath itself and write nothing.
+func rasterizeImageFile(imagePath string, page int, dir string) (string, error) {
+	data, err := os.ReadFile(imagePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read image: %w", err)
+	}
+	if !imaging.IsPaged(providers.DetectMediaType(data)) {
+		return imagePath, nil
+	}
+
+	png, err := rasterizeImage(data, page)
+	if err != nil {
+		return "", err
+	}
+	file, err := os.CreateTemp(dir, "htr-page-*.png")
+	if err != nil {
+		return "", fmt.Errorf("failed to create page image: %w", err)
+	}
+	if _, err := file.Write(png); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return "", fmt.Errorf("failed to write page image: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(file.Name())
+		return "", fmt.Errorf("failed to write page image: %w", err)
+	}
+	return file.Name(), nil
+}
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var redactCmd = &cobra.Command{
+	Use:   "redact [eval-file]",
+	Short: "Remove the transcriptions and paths of non-public rows from an evaluation file",
+	Long: `Write a copy of an evaluation file that can be shared without exposing
+restricted materials.
+
+Every row not marked public in the input keeps its identifier and scores but
+loses its provider response, image path, transcript path, and reference
+paths. The text of each line from --per-line runs, the words recorded with
+--logprobs, and the texts stored with --store-normalized are removed as well.
+Public rows are written unchanged.
+
+The redacted copy is written to --output in the format given by its extension,
+so the original is kept for later backfills and reports.
+
+Examples:
+  htr redact gpt-4o --output shared/gpt-4o.yaml
+  htr redact evals/openai_gpt-4o.json --output shared/gpt-4o.json.gz`,
+	RunE: runRedact,
+	Args: cobra.ExactArgs(1),
+}
+
+var redactOutputPath string
+
+func init() {
+	RootCmd.AddCommand(redactCmd)
+
+	redactCmd.Flags().StringVarP(&redactOutputPath, "output", "o", "", "Path to write the redacted evaluation file (required)")
+
+	if err := redactCmd.MarkFlagRequired("output"); err != nil {
+		panic(err)
+	}
+}
+
+func runRedact(cmd *cobra.Command, args []string) error {
+	evalFile := resolveEvalFile(evalsDir, args[0])
+	summary, err := loadEvalSummary(evalFile)
+	if err != nil {
+		return err
+	}
+
+	redacted := redactResults(summary.Results)
+	if err := saveEvalResults(summary, redactOutputPath); err != nil {
+		return err
+	}
+	fmt.Printf("Redacted %d of %d rows; written to %s\n", redacted, len(summary.Results), redactOutputPath)
+	return nil
+}
+
+// redactResults strips the provider response and file paths from every
+// result not marked public, in place, and returns how many were redacted.
+// Scores are kept so the redacted rows still count in summaries.
+func redactResults(results []EvalResult) int {
+	redacted := 0
+	for i := range results {
+		result := &results[i]
+		if result.Public {
+			continue
+		}
+		result.ProviderResponse = ""
+		result.ImagePath = ""
+		result.TranscriptPath = ""
+		result.References = nil
+		result.WordConfidences = nil
+		result.NormalizedGroundTruth = ""
+		result.NormalizedResponse = ""
+		for j := range result.LineResults {
+			result.LineResults[j].GroundTruth = ""
+			result.LineResults[j].Transcription = ""
+		}
+		redacted++
+	}
+	return redacted
+}
@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	htrmetrics "github.com/lehigh-university-libraries/htr/pkg/metrics"
+)
+
+func TestRedactResults(t *testing.T) {
+	public := EvalResult{
+		Identifier:       "open.jpg",
+		ImagePath:        "images/open.jpg",
+		TranscriptPath:   "transcripts/open.txt",
+		Public:           true,
+		ProviderResponse: "open letter",
+		WordAccuracy:     1,
+	}
+	results := []EvalResult{
+		public,
+		{
+			Identifier:            "restricted.jpg",
+			ImagePath:             "images/restricted.jpg",
+			TranscriptPath:        "transcripts/restricted-a.txt",
+			ProviderResponse:      "private diary",
+			WordAccuracy:          0.5,
+			References:            []string{"transcripts/restricted-a.txt", "transcripts/restricted-b.txt"},
+			Reference:             1,
+			LineResults:           []htrmetrics.LineMetric{{GroundTruthLine: 1, TranscribedLine: 1, GroundTruth: "private diary", Transcription: "private dairy", WordAccuracy: 0.5}},
+			WordConfidences:       []WordConfidence{{Word: "private", Confidence: 0.99}, {Word: "dairy", Confidence: 0.4}},
+			NormalizedGroundTruth: "private diary",
+			NormalizedResponse:    "private dairy",
+		},
+	}
+
+	if redacted := redactResults(results); redacted != 1 {
+		t.Errorf("redactResults() = %d, want 1", redacted)
+	}
+	if !reflect.DeepEqual(results[0], public) {
+		t.Errorf("public row = %+v, want it unchanged", results[0])
+	}
+	want := EvalResult{
+		Identifier:   "restricted.jpg",
+		WordAccuracy: 0.5,
+		Reference:    1,
+		LineResults:  []htrmetrics.LineMetric{{GroundTruthLine: 1, TranscribedLine: 1, WordAccuracy: 0.5}},
+	}
+	if !reflect.DeepEqual(results[1], want) {
+		t.Errorf("restricted row = %+v, want %+v", results[1], want)
+	}
+}
+
+func TestRunRedactWritesCopy(t *testing.T) {
+	dir := t.TempDir()
+	evalFile := filepath.Join(dir, "stub.yaml")
+	summary := EvalSummary{
+		Config: EvalConfig{Provider: "stub", Model: "stub-model"},
+		Results: []EvalResult{
+			{Identifier: "open.jpg", Public: true, ProviderResponse: "open letter"},
+			{Identifier: "restricted.jpg", ImagePath: "images/restricted.jpg", ProviderResponse: "private diary"},
+		},
+	}
+	if err := saveEvalResults(summary, evalFile); err != nil {
+		t.Fatal(err)
+	}
+
+	originalOutput := redactOutputPath
+	t.Cleanup(func() { redactOutputPath = originalOutput })
+	redactOutputPath = filepath.Join(dir, "shared", "stub.json")
+	if err := os.MkdirAll(filepath.Dir(redactOutputPath), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := runRedact(redactCmd, []string{evalFile}); err != nil {
+		t.Fatalf("runRedact() error = %v", err)
+	}
+	data, err := os.ReadFile(redactOutputPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, hidden := range []string{"private diary", "images/restricted.jpg"} {
+		if strings.Contains(string(data), hidden) {
+			t.Errorf("redacted file contains %q:\n%s", hidden, data)
+		}
+	}
+	if !strings.Contains(string(data), "open letter") || !strings.Contains(string(data), "restricted.jpg") {
+		t.Errorf("redacted file is missing the public response or the restricted identifier:\n%s", data)
+	}
+
+	original, err := loadEvalSummary(evalFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if original.Results[1].ProviderResponse != "private diary" {
+		t.Errorf("original eval file was modified: %+v", original.Results[1])
+	}
+}
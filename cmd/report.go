@@ -0,0 +1,225 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	htrmetrics "github.com/lehigh-university-libraries/htr/pkg/metrics"
+	"github.com/spf13/cobra"
+)
+
+var reportCmd = &cobra.Command{
+	Use:   "report [eval-file]",
+	Short: "Generate an HTML report with word-level diffs from an evaluation file",
+	Long: `Generate a standalone HTML report from an evaluation file.
+
+The report starts with summary statistics and then shows, for each row, the ground
+truth next to the provider response with word-level substitutions, deletions, and
+insertions highlighted.
+
+If a row's transcript file can no longer be read, only the stored provider response
+is shown for that row.
+
+With --public-only, rows not marked public in the input are left out of both the
+statistics and the diffs, so the report can be shared.`,
+	RunE: runReport,
+	Args: cobra.ExactArgs(1),
+}
+
+var (
+	reportOutputPath string
+	reportPublicOnly bool
+)
+
+// reportWord is one rendered word in a report diff column.
+type reportWord struct {
+	Text  string
+	Class string
+}
+
+// reportRow is the rendered view of one EvalResult.
+type reportRow struct {
+	Result       EvalResult
+	GroundTruth  []reportWord
+	Response     []reportWord
+	MissingTruth bool
+}
+
+// reportStat is one labeled line of summary statistics.
+type reportStat struct {
+	Label string
+	Stats htrmetrics.Stats
+}
+
+type reportData struct {
+	File   string
+	Config EvalConfig
+	Count  int
+	Stats  []reportStat
+	Rows   []reportRow
+}
+
+func init() {
+	RootCmd.AddCommand(reportCmd)
+
+	reportCmd.Flags().StringVarP(&reportOutputPath, "output", "o", "report.html", "Path to write the HTML report")
+	reportCmd.Flags().BoolVar(&reportPublicOnly, "public-only", false, "Only include rows marked public in the input")
+}
+
+func runReport(cmd *cobra.Command, args []string) error {
+	evalFile := resolveEvalFile(evalsDir, args[0])
+	summary, err := loadEvalSummary(evalFile)
+	if err != nil {
+		return err
+	}
+	if reportPublicOnly {
+		summary.Results = publicResults(summary.Results)
+	}
+
+	file, err := os.Create(reportOutputPath)
+	if err != nil {
+		return fmt.Errorf("failed to create report: %w", err)
+	}
+	defer file.Close()
+
+	if err := writeReport(file, filepath.Base(evalFile), summary); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+
+	fmt.Printf("Report written to %s\n", reportOutputPath)
+	return nil
+}
+
+// writeReport renders summary as a standalone HTML document.
+func writeReport(w io.Writer, name string, summary EvalSummary) error {
+	data := reportData{
+		File:   name,
+		Config: summary.Config,
+		Count:  len(summary.Results),
+	}
+
+	charSims := make([]float64, 0, len(summary.Results))
+	charAccs := make([]float64, 0, len(summary.Results))
+	wordSims := make([]float64, 0, len(summary.Results))
+	wordAccs := make([]float64, 0, len(summary.Results))
+	wers := make([]float64, 0, len(summary.Results))
+	for _, result := range summary.Results {
+		charSims = append(charSims, result.CharacterSimilarity)
+		charAccs = append(charAccs, result.CharacterAccuracy)
+		wordSims = append(wordSims, result.WordSimilarity)
+		wordAccs = append(wordAccs, result.WordAccuracy)
+		wers = append(wers, result.WordErrorRate)
+		data.Rows = append(data.Rows, buildReportRow(result, summary.Config))
+	}
+	data.Stats = []reportStat{
+		{Label: "Character Similarity", Stats: htrmetrics.Summarize(charSims)},
+		{Label: "Character Accuracy", Stats: htrmetrics.Summarize(charAccs)},
+		{Label: "Word Similarity", Stats: htrmetrics.Summarize(wordSims)},
+		{Label: "Word Accuracy", Stats: htrmetrics.Summarize(wordAccs)},
+		{Label: "Word Error Rate", Stats: htrmetrics.Summarize(wers)},
+	}
+
+	return reportTemplate.Execute(w, data)
+}
+
+// buildReportRow aligns the ground truth and provider response word by word.
+// When the transcript cannot be read the response is shown unmarked.
+func buildReportRow(result EvalResult, config EvalConfig) reportRow {
+	row := reportRow{Result: result}
+	responseWords := strings.Fields(result.ProviderResponse)
+
+	groundTruth, err := readTextFile(context.Background(), result.TranscriptPath)
+	if err != nil || result.TranscriptPath == "" {
+		row.MissingTruth = true
+		for _, word := range responseWords {
+			row.Response = append(row.Response, reportWord{Text: word})
+		}
+		return row
+	}
+
+	truthWords := strings.Fields(groundTruth)
+	row.GroundTruth, row.Response = diffWords(truthWords, responseWords, config.IgnoreCase)
+	return row
+}
+
+// diffWords marks each ground-truth and response word with its alignment
+// operation. Words are compared case-insensitively when ignoreCase is set.
+func diffWords(truthWords, responseWords []string, ignoreCase bool) ([]reportWord, []reportWord) {
+	truthKeys, responseKeys := truthWords, responseWords
+	if ignoreCase {
+		truthKeys = lowerAll(truthWords)
+		responseKeys = lowerAll(responseWords)
+	}
+
+	var truth, response []reportWord
+	for _, step := range htrmetrics.AlignWordSequence(truthKeys, responseKeys) {
+		switch step.Op {
+		case htrmetrics.EditEqual:
+			truth = append(truth, reportWord{Text: truthWords[step.Original]})
+			response = append(response, reportWord{Text: responseWords[step.Transcribed]})
+		case htrmetrics.EditSubstitute:
+			truth = append(truth, reportWord{Text: truthWords[step.Original], Class: "sub"})
+			response = append(response, reportWord{Text: responseWords[step.Transcribed], Class: "sub"})
+		case htrmetrics.EditDelete:
+			truth = append(truth, reportWord{Text: truthWords[step.Original], Class: "del"})
+		case htrmetrics.EditInsert:
+			response = append(response, reportWord{Text: responseWords[step.Transcribed], Class: "ins"})
+		}
+	}
+	return truth, response
+}
+
+func lowerAll(words []string) []string {
+	lowered := make([]string, len(words))
+	for index, word := range words {
+		lowered[index] = strings.ToLower(word)
+	}
+	return lowered
+}
+
+var reportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>HTR report: {{.File}}</title>
+<style>
+body { font-family: system-ui, sans-serif; margin: 2rem; color: #222; }
+table.stats { border-collapse: collapse; margin-bottom: 2rem; }
+table.stats th, table.stats td { border: 1px solid #ccc; padding: 0.25rem 0.75rem; text-align: right; }
+table.stats th:first-child, table.stats td:first-child { text-align: left; }
+section.row { border-top: 1px solid #ccc; padding-top: 1rem; margin-top: 1rem; }
+.columns { display: grid; grid-template-columns: 1fr 1fr; gap: 1rem; }
+.text { font-family: ui-monospace, monospace; white-space: normal; line-height: 1.6; }
+.sub { background: #fff3b0; }
+.del { background: #ffc9c9; text-decoration: line-through; }
+.ins { background: #c3f0c8; }
+.note { color: #a33; }
+.legend span { padding: 0 0.4rem; margin-right: 0.5rem; }
+</style>
+</head>
+<body>
+<h1>Evaluation report: {{.File}}</h1>
+<p>Provider: {{.Config.Provider}} &middot; Model: {{.Config.Model}} &middot; Timestamp: {{.Config.Timestamp}} &middot; Rows: {{.Count}}</p>
+<table class="stats">
+<tr><th>Metric</th><th>Mean</th><th>Median</th><th>Std Dev</th><th>Min</th><th>Max</th></tr>
+{{range .Stats}}<tr><td>{{.Label}}</td><td>{{printf "%.3f" .Stats.Mean}}</td><td>{{printf "%.3f" .Stats.Median}}</td><td>{{printf "%.3f" .Stats.StdDev}}</td><td>{{printf "%.3f" .Stats.Min}}</td><td>{{printf "%.3f" .Stats.Max}}</td></tr>
+{{end}}</table>
+<p class="legend"><span class="sub">substitution</span><span class="del">deletion</span><span class="ins">insertion</span></p>
+{{range .Rows}}<section class="row">
+<h2>{{.Result.Identifier}}</h2>
+<p>Character accuracy: {{printf "%.3f" .Result.CharacterAccuracy}} &middot; Word accuracy: {{printf "%.3f" .Result.WordAccuracy}} &middot; Word error rate: {{printf "%.3f" .Result.WordErrorRate}} &middot; Substitutions: {{.Result.Substitutions}} &middot; Deletions: {{.Result.Deletions}} &middot; Insertions: {{.Result.Insertions}}</p>
+{{if .MissingTruth}}<p class="note">Ground truth unavailable{{if .Result.TranscriptPath}} ({{.Result.TranscriptPath}}){{end}}; showing the stored provider response only.</p>
+<div class="text">{{range .Response}}{{.Text}} {{end}}</div>
+{{else}}<div class="columns">
+<div><h3>Ground truth</h3><div class="text">{{range .GroundTruth}}{{if .Class}}<span class="{{.Class}}">{{.Text}}</span>{{else}}{{.Text}}{{end}} {{end}}</div></div>
+<div><h3>Provider response</h3><div class="text">{{range .Response}}{{if .Class}}<span class="{{.Class}}">{{.Text}}</span>{{else}}{{.Text}}{{end}} {{end}}</div></div>
+</div>
+{{end}}</section>
+{{end}}</body>
+</html>
+`))
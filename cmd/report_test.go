@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDiffWords(t *testing.T) {
+	tests := []struct {
+		name         string
+		truth        string
+		response     string
+		ignoreCase   bool
+		wantTruth    []reportWord
+		wantResponse []reportWord
+	}{
+		{
+			name:         "identical",
+			truth:        "the quick fox",
+			response:     "the quick fox",
+			wantTruth:    []reportWord{{Text: "the"}, {Text: "quick"}, {Text: "fox"}},
+			wantResponse: []reportWord{{Text: "the"}, {Text: "quick"}, {Text: "fox"}},
+		},
+		{
+			name:         "substitution",
+			truth:        "the quick fox",
+			response:     "the quack fox",
+			wantTruth:    []reportWord{{Text: "the"}, {Text: "quick", Class: "sub"}, {Text: "fox"}},
+			wantResponse: []reportWord{{Text: "the"}, {Text: "quack", Class: "sub"}, {Text: "fox"}},
+		},
+		{
+			name:         "deletion",
+			truth:        "the brown fox",
+			response:     "the fox",
+			wantTruth:    []reportWord{{Text: "the"}, {Text: "brown", Class: "del"}, {Text: "fox"}},
+			wantResponse: []reportWord{{Text: "the"}, {Text: "fox"}},
+		},
+		{
+			name:         "insertion",
+			truth:        "the fox",
+			response:     "the fox jumps",
+			wantTruth:    []reportWord{{Text: "the"}, {Text: "fox"}},
+			wantResponse: []reportWord{{Text: "the"}, {Text: "fox"}, {Text: "jumps", Class: "ins"}},
+		},
+		{
+			name:         "ignore case keeps original spelling",
+			truth:        "The Fox",
+			response:     "the fox",
+			ignoreCase:   true,
+			wantTruth:    []reportWord{{Text: "The"}, {Text: "Fox"}},
+			wantResponse: []reportWord{{Text: "the"}, {Text: "fox"}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			gotTruth, gotResponse := diffWords(strings.Fields(test.truth), strings.Fields(test.response), test.ignoreCase)
+			if !equalReportWords(gotTruth, test.wantTruth) {
+				t.Errorf("truth = %v, want %v", gotTruth, test.wantTruth)
+			}
+			if !equalReportWords(gotResponse, test.wantResponse) {
+				t.Errorf("response = %v, want %v", gotResponse, test.wantResponse)
+			}
+		})
+	}
+}
+
+func TestWriteReport(t *testing.T) {
+	transcript := filepath.Join(t.TempDir(), "row1.txt")
+	if err := os.WriteFile(transcript, []byte("hello brave new world"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	summary := EvalSummary{
+		Config: EvalConfig{Provider: "stub", Model: "stub-model"},
+		Results: []EvalResult{
+			{Identifier: "row1", TranscriptPath: transcript, ProviderResponse: "hello new world <b>", WordAccuracy: 0.5},
+			{Identifier: "row2", TranscriptPath: filepath.Join(t.TempDir(), "missing.txt"), ProviderResponse: "orphaned response", WordAccuracy: 1},
+		},
+	}
+
+	var out strings.Builder
+	if err := writeReport(&out, "stub.yaml", summary); err != nil {
+		t.Fatalf("writeReport() error = %v", err)
+	}
+	html := out.String()
+
+	for _, want := range []string{
+		"Evaluation report: stub.yaml",
+		"<td>Word Accuracy</td><td>0.750</td>",
+		`<span class="del">brave</span>`,
+		`<span class="ins">&lt;b&gt;</span>`,
+		"Ground truth unavailable",
+		"orphaned response",
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("report missing %q", want)
+		}
+	}
+}
+
+func TestRunReportPublicOnly(t *testing.T) {
+	dir := t.TempDir()
+	evalFile := filepath.Join(dir, "stub.yaml")
+	summary := EvalSummary{
+		Config: EvalConfig{Provider: "stub", Model: "stub-model"},
+		Results: []EvalResult{
+			{Identifier: "open.jpg", Public: true, ProviderResponse: "open letter", WordAccuracy: 1},
+			{Identifier: "restricted.jpg", ProviderResponse: "private diary", WordAccuracy: 0.5},
+		},
+	}
+	if err := saveEvalResults(summary, evalFile); err != nil {
+		t.Fatal(err)
+	}
+
+	originalOutput, originalPublicOnly := reportOutputPath, reportPublicOnly
+	t.Cleanup(func() { reportOutputPath, reportPublicOnly = originalOutput, originalPublicOnly })
+	reportOutputPath, reportPublicOnly = filepath.Join(dir, "report.html"), true
+
+	if err := runReport(reportCmd, []string{evalFile}); err != nil {
+		t.Fatalf("runReport() error = %v", err)
+	}
+	data, err := os.ReadFile(reportOutputPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	html := string(data)
+	if !strings.Contains(html, "open.jpg") || !strings.Contains(html, "<td>Word Accuracy</td><td>1.000</td>") {
+		t.Errorf("report is missing the public row and its statistics:\n%s", html)
+	}
+	for _, hidden := range []string{"restricted.jpg", "private diary"} {
+		if strings.Contains(html, hidden) {
+			t.Errorf("report with --public-only contains %q", hidden)
+		}
+	}
+}
+
+func equalReportWords(a, b []reportWord) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
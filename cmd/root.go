@@ -1,6 +1,8 @@
 package cmd
 
 import (
+	"errors"
+	"fmt"
 	"log/slog"
 	"os"
 	"strings"
@@ -33,6 +35,18 @@ var RootCmd = &cobra.Command{
 		handler := slog.New(slog.NewTextHandler(os.Stdout, opts))
 		slog.SetDefault(handler)
 
+		path, err := findDefaultsFile()
+		if err != nil || path == "" {
+			return err
+		}
+		defaults, err := loadDefaults(path)
+		if err != nil {
+			return err
+		}
+		slog.Debug("Applying flag defaults", "path", path)
+		if err := applyDefaults(cmd, defaults); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
 		return nil
 	},
 }
@@ -40,14 +54,52 @@ var RootCmd = &cobra.Command{
 func Execute() {
 	err := RootCmd.Execute()
 	if err != nil {
-		os.Exit(1)
+		os.Exit(ExitCode(err))
+	}
+}
+
+// ExitCode returns the process exit status for an error returned by a
+// command: 3 when an eval run finished below its accuracy thresholds, so CI
+// can tell a quality regression from a failed run, and 1 for any other error.
+func ExitCode(err error) int {
+	switch {
+	case err == nil:
+		return 0
+	case errors.Is(err, errBelowAccuracyThreshold):
+		return 3
+	default:
+		return 1
 	}
 }
 
+// evalsDir is the directory eval files are written to and looked up in.
+var evalsDir string
+
+// promptsDir is the prompt library --prompt-id names are looked up in.
+var promptsDir string
+
 func init() {
 	ll := os.Getenv("LOG_LEVEL")
 	if ll == "" {
 		ll = "INFO"
 	}
 	RootCmd.PersistentFlags().String("log-level", ll, "The logging level for the command")
+	RootCmd.PersistentFlags().StringVar(&evalsDir, "evals-dir", defaultEvalsDir(), "Directory eval files are written to and read from (env HTR_EVALS_DIR)")
+	RootCmd.PersistentFlags().StringVar(&promptsDir, "prompts-dir", defaultPromptsDir(), "Directory of named prompts for --prompt-id (env HTR_PROMPTS_DIR)")
+}
+
+// defaultEvalsDir returns HTR_EVALS_DIR, or evals when it is unset.
+func defaultEvalsDir() string {
+	if dir := os.Getenv("HTR_EVALS_DIR"); dir != "" {
+		return dir
+	}
+	return "evals"
+}
+
+// defaultPromptsDir returns HTR_PROMPTS_DIR, or prompts when it is unset.
+func defaultPromptsDir() string {
+	if dir := os.Getenv("HTR_PROMPTS_DIR"); dir != "" {
+		return dir
+	}
+	return "prompts"
 }
@@ -0,0 +1,337 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+	yaml "go.yaml.in/yaml/v3"
+)
+
+var validateCmd = &cobra.Command{
+	Use:   "validate [eval-file...]",
+	Short: "Check evaluation files for unknown or missing fields and implausible values",
+	Long: `Check that evaluation files have the structure this version of htr writes.
+
+Loading an eval file silently ignores fields it does not know and leaves
+missing ones at zero, so a hand-edited file or one written by an older version
+can quietly skew summaries. validate reports:
+
+  - fields the eval file format does not define, such as misspelled keys
+  - required fields that are missing
+  - implausible values, such as similarities outside 0 to 1, accuracies
+    above 1, negative error rates, and negative token or word counts
+
+Accuracies can legitimately fall below 0 when a response has many more
+characters or words than the ground truth, so only values above 1 are
+reported. With no arguments, every eval file in the evals directory is
+checked. The command exits with an error when any file has a problem.
+
+Examples:
+  htr validate
+  htr validate gpt-4o evals/openai_gpt-4o.json`,
+	RunE: runValidate,
+}
+
+// evalProblem is one problem found in an eval file. Path locates the field,
+// such as results[2].word_accuracy, and is empty for the file as a whole.
+type evalProblem struct {
+	Path    string
+	Message string
+}
+
+func (p evalProblem) String() string {
+	if p.Path == "" {
+		return p.Message
+	}
+	return p.Path + ": " + p.Message
+}
+
+func init() {
+	RootCmd.AddCommand(validateCmd)
+}
+
+func runValidate(cmd *cobra.Command, args []string) error {
+	files := make([]string, len(args))
+	for i, arg := range args {
+		files[i] = resolveEvalFile(evalsDir, arg)
+	}
+	if len(args) == 0 {
+		var err error
+		if files, err = listEvalFiles(evalsDir); err != nil {
+			return fmt.Errorf("failed to list eval files: %w", err)
+		}
+		if len(files) == 0 {
+			fmt.Printf("No evaluation files found in %s.\n", evalsDir)
+			return nil
+		}
+	}
+
+	failed := 0
+	for _, file := range files {
+		problems, err := validateEvalFile(file)
+		if err != nil {
+			return err
+		}
+		printEvalProblems(os.Stdout, file, problems)
+		if len(problems) > 0 {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d eval files have problems", failed, len(files))
+	}
+	return nil
+}
+
+// printEvalProblems writes OK for a file without problems and otherwise one
+// indented line per problem.
+func printEvalProblems(w io.Writer, file string, problems []evalProblem) {
+	if len(problems) == 0 {
+		fmt.Fprintf(w, "%s: OK\n", file)
+		return
+	}
+	fmt.Fprintf(w, "%s: problems found: %d\n", file, len(problems))
+	for _, problem := range problems {
+		fmt.Fprintf(w, "  %s\n", problem)
+	}
+}
+
+// validateEvalFile checks the fields of an eval file against EvalSummary and
+// then the values of the fields it loads. Only a file that cannot be read is
+// an error; a file that cannot be parsed is reported as a problem.
+func validateEvalFile(path string) ([]evalProblem, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read eval file %s: %w", path, err)
+	}
+	if data, err = decompressEvalFile(data); err != nil {
+		return []evalProblem{{Message: fmt.Sprintf("failed to decompress: %v", err)}}, nil
+	}
+
+	format := evalFileFormat(path)
+	var document any
+	var summary EvalSummary
+	if format == "json" {
+		if err = json.Unmarshal(data, &document); err == nil {
+			err = json.Unmarshal(data, &summary)
+		}
+	} else {
+		if err = yaml.Unmarshal(data, &document); err == nil {
+			err = yaml.Unmarshal(data, &summary)
+		}
+	}
+	if err != nil {
+		return []evalProblem{{Message: fmt.Sprintf("failed to parse: %v", err)}}, nil
+	}
+
+	var problems []evalProblem
+	report := func(path, format string, args ...any) {
+		problems = append(problems, evalProblem{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+	checkEvalFields("", document, reflect.TypeFor[EvalSummary](), format, report)
+	checkEvalValues(summary, format, report)
+	return problems, nil
+}
+
+// evalFieldName returns the key a struct field is written under in format
+// and whether the field is always written. JSON keys come from the json tag;
+// YAML keys come from the yaml tag or are the lowercased field name, as
+// go.yaml.in/yaml/v3 writes them. YAML writes every field without omitempty
+// in its yaml tag, so whether a field is required follows its json tag in
+// both formats, which keeps settings added by later versions optional. ok is
+// false for fields that are never written.
+func evalFieldName(field reflect.StructField, format string) (name string, required, ok bool) {
+	if !field.IsExported() {
+		return "", false, false
+	}
+	jsonName, jsonOptions, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if jsonName == "-" && jsonOptions == "" {
+		return "", false, false
+	}
+	required = !slices.Contains(strings.Split(jsonOptions, ","), "omitempty")
+	if format == "json" {
+		if jsonName == "" {
+			jsonName = field.Name
+		}
+		return jsonName, required, true
+	}
+	yamlName, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+	if yamlName == "-" {
+		return "", false, false
+	}
+	if yamlName == "" {
+		yamlName = strings.ToLower(field.Name)
+	}
+	return yamlName, required, true
+}
+
+// evalFieldKey returns the key of the field goName of typ in format.
+func evalFieldKey(typ reflect.Type, goName, format string) string {
+	field, _ := typ.FieldByName(goName)
+	name, _, _ := evalFieldName(field, format)
+	return name
+}
+
+// checkEvalFields walks value, decoded from an eval file without a target
+// type, alongside typ and reports keys typ does not define and required
+// fields that are missing. JSON keys match case-insensitively, as
+// encoding/json decodes them. Type mismatches are left to the typed decode.
+func checkEvalFields(path string, value any, typ reflect.Type, format string, report func(string, string, ...any)) {
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	switch typ.Kind() {
+	case reflect.Struct:
+		object, ok := value.(map[string]any)
+		if !ok {
+			return
+		}
+		seen := make(map[string]bool, len(object))
+		for i := range typ.NumField() {
+			field := typ.Field(i)
+			name, required, ok := evalFieldName(field, format)
+			if !ok {
+				continue
+			}
+			key, present := matchEvalKey(object, name, format)
+			if !present {
+				if required {
+					report(joinEvalPath(path, name), "missing")
+				}
+				continue
+			}
+			seen[key] = true
+			checkEvalFields(joinEvalPath(path, name), object[key], field.Type, format, report)
+		}
+		keys := slices.Sorted(func(yield func(string) bool) {
+			for key := range object {
+				if !seen[key] && !yield(key) {
+					return
+				}
+			}
+		})
+		for _, key := range keys {
+			report(joinEvalPath(path, key), "unknown field")
+		}
+	case reflect.Slice:
+		items, ok := value.([]any)
+		if !ok {
+			return
+		}
+		for i, item := range items {
+			checkEvalFields(fmt.Sprintf("%s[%d]", path, i), item, typ.Elem(), format, report)
+		}
+	}
+}
+
+// matchEvalKey returns the key of object that holds the field name.
+func matchEvalKey(object map[string]any, name, format string) (string, bool) {
+	if _, ok := object[name]; ok {
+		return name, true
+	}
+	if format != "json" {
+		return "", false
+	}
+	for key := range object {
+		if strings.EqualFold(key, name) {
+			return key, true
+		}
+	}
+	return "", false
+}
+
+func joinEvalPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// checkEvalValues reports values no run can produce: similarities and
+// scores outside 0 to 1, accuracies above 1, and negative rates and counts.
+func checkEvalValues(summary EvalSummary, format string, report func(string, string, ...any)) {
+	configType := reflect.TypeFor[EvalConfig]()
+	config := reflect.ValueOf(summary.Config)
+	for _, name := range []string{"Provider", "Model"} {
+		if strings.TrimSpace(config.FieldByName(name).String()) == "" {
+			report(joinEvalPath("config", evalFieldKey(configType, name, format)), "is empty")
+		}
+	}
+	checkEvalRange("config", config, format, report, evalRange{min: 0, max: math.Inf(1)}, "Temperature", "MaxTokens", "Limit", "Sample", "MaxDimension", "Page", "MaxRetries")
+
+	resultType := reflect.TypeFor[EvalResult]()
+	for i, result := range summary.Results {
+		path := fmt.Sprintf("results[%d]", i)
+		value := reflect.ValueOf(result)
+		if strings.TrimSpace(result.Identifier) == "" {
+			report(joinEvalPath(path, evalFieldKey(resultType, "Identifier", format)), "is empty")
+		}
+		checkEvalRange(path, value, format, report, evalRange{min: 0, max: 1}, "CharacterSimilarity", "WordSimilarity")
+		checkEvalRange(path, value, format, report, evalRange{min: math.Inf(-1), max: 1}, "CharacterAccuracy", "WordAccuracy")
+		checkEvalRange(path, value, format, report, evalRange{min: 0, max: math.Inf(1)},
+			"WordErrorRate", "TotalWordsOriginal", "TotalWordsTranscribed", "CorrectWords", "Substitutions",
+			"Deletions", "Insertions", "IgnoredCharsCount", "InputTokens", "OutputTokens", "PageCount", "LatencyMS")
+		if result.BLEUScore != nil && (*result.BLEUScore < 0 || *result.BLEUScore > 1) {
+			report(joinEvalPath(path, evalFieldKey(resultType, "BLEUScore", format)), "%v is outside 0 to 1", *result.BLEUScore)
+		}
+		if result.BagOfWords != nil {
+			checkEvalRange(joinEvalPath(path, evalFieldKey(resultType, "BagOfWords", format)), reflect.ValueOf(*result.BagOfWords), format, report, evalRange{min: 0, max: 1}, "Precision", "Recall", "F1")
+		}
+		if result.SortedWords != nil {
+			sortedPath := joinEvalPath(path, evalFieldKey(resultType, "SortedWords", format))
+			checkEvalRange(sortedPath, reflect.ValueOf(*result.SortedWords), format, report, evalRange{min: math.Inf(-1), max: 1}, "Accuracy")
+			checkEvalRange(sortedPath, reflect.ValueOf(*result.SortedWords), format, report, evalRange{min: 0, max: 1}, "Similarity")
+		}
+		if result.Reference != 0 && (result.Reference < 1 || result.Reference > len(result.References)) {
+			report(joinEvalPath(path, evalFieldKey(resultType, "Reference", format)), "%d is not the position of one of the %d references", result.Reference, len(result.References))
+		}
+		for j, line := range result.LineResults {
+			linePath := fmt.Sprintf("%s[%d]", joinEvalPath(path, evalFieldKey(resultType, "LineResults", format)), j)
+			checkEvalRange(linePath, reflect.ValueOf(line), format, report, evalRange{min: math.Inf(-1), max: 1}, "CharacterAccuracy", "WordAccuracy")
+		}
+		for j, word := range result.WordConfidences {
+			wordPath := fmt.Sprintf("%s[%d]", joinEvalPath(path, evalFieldKey(resultType, "WordConfidences", format)), j)
+			checkEvalRange(wordPath, reflect.ValueOf(word), format, report, evalRange{min: 0, max: 1}, "Confidence")
+		}
+	}
+}
+
+// evalRange is the inclusive range of plausible values for a field.
+type evalRange struct {
+	min, max float64
+}
+
+// checkEvalRange reports each named numeric field of the struct value that
+// falls outside bounds, or is NaN.
+func checkEvalRange(path string, value reflect.Value, format string, report func(string, string, ...any), bounds evalRange, names ...string) {
+	for _, name := range names {
+		field := value.FieldByName(name)
+		var number float64
+		switch {
+		case field.CanFloat():
+			number = field.Float()
+		case field.CanInt():
+			number = float64(field.Int())
+		default:
+			continue
+		}
+		key := joinEvalPath(path, evalFieldKey(value.Type(), name, format))
+		switch {
+		case math.IsNaN(number):
+			report(key, "is not a number")
+		case number < bounds.min && bounds.min == 0:
+			report(key, "%v is negative", number)
+		case number < bounds.min:
+			report(key, "%v is below %v", number, bounds.min)
+		case number > bounds.max:
+			report(key, "%v is above %v", number, bounds.max)
+		}
+	}
+}
@@ -0,0 +1,199 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	htrmetrics "github.com/lehigh-university-libraries/htr/pkg/metrics"
+)
+
+func validEvalSummary() EvalSummary {
+	bleu := 0.8
+	return EvalSummary{
+		Config: EvalConfig{Provider: "stub", Model: "stub-model", Prompt: "Transcribe", BLEU: true, PerLine: true},
+		Results: []EvalResult{
+			{
+				Identifier:          "a.jpg",
+				ProviderResponse:    "hello world",
+				CharacterSimilarity: 0.9,
+				CharacterAccuracy:   -0.5,
+				WordSimilarity:      0.5,
+				WordAccuracy:        0.5,
+				WordErrorRate:       1.5,
+				TotalWordsOriginal:  2,
+				InputTokens:         10,
+				BLEUScore:           &bleu,
+				BagOfWords:          &htrmetrics.BagOfWordsScore{Precision: 0.5, Recall: 0.5, F1: 0.5},
+				References:          []string{"a-1.txt", "a-2.txt"},
+				Reference:           2,
+				LineResults:         []htrmetrics.LineMetric{{GroundTruthLine: 1, TranscribedLine: 1, WordAccuracy: 0.5}},
+				WordConfidences:     []WordConfidence{{Word: "hello", Confidence: 0.9}},
+			},
+		},
+	}
+}
+
+func TestValidateEvalFileAcceptsSavedFiles(t *testing.T) {
+	for _, name := range []string{"stub.yaml", "stub.json", "stub.yaml.gz"} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), name)
+			if err := saveEvalResults(validEvalSummary(), path); err != nil {
+				t.Fatal(err)
+			}
+			problems, err := validateEvalFile(path)
+			if err != nil {
+				t.Fatalf("validateEvalFile() error = %v", err)
+			}
+			if len(problems) != 0 {
+				t.Errorf("validateEvalFile() = %v, want no problems", problems)
+			}
+		})
+	}
+}
+
+func TestValidateEvalFileReportsProblems(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		data string
+		want []string
+	}{
+		{
+			name: "unknown and missing yaml fields",
+			file: "stub.yaml",
+			data: `config:
+  provider: stub
+  model: stub-model
+  prompt: Transcribe
+  temperature: 0
+  timeout: 0s
+  csvpath: data.csv
+  testrows: []
+  timestamp: "2026-01-01"
+  temprature: 1
+results:
+  - identifier: a.jpg
+    imagepath: a.jpg
+    transcriptpath: a.txt
+    public: false
+    providerresponse: hi
+    charactersimilarity: 1
+    characteraccuracy: 1
+    wordsimilarity: 1
+    wordaccuracy: 1
+    worderrorrate: 0
+    totalwordsoriginal: 1
+    totalwordstranscribed: 1
+    correctwords: 1
+    substitutions: 0
+    deletions: 0
+    insertions: 0
+`,
+			want: []string{
+				"config.temprature: unknown field",
+				"results[0].ignoredcharscount: missing",
+			},
+		},
+		{
+			name: "implausible json values",
+			file: "stub.json",
+			data: `{
+  "config": {"provider": "stub", "model": "", "prompt": "", "temperature": -1, "timeout": 0, "csv_path": "", "rows": null, "timestamp": ""},
+  "results": [{
+    "identifier": "a.jpg", "image_path": "", "transcript_path": "", "public": false, "provider_response": "",
+    "character_similarity": 1.2, "character_accuracy": -3, "word_similarity": -0.1, "word_accuracy": 1.5,
+    "word_error_rate": -1, "total_words_original": 1, "total_words_transcribed": 1, "correct_words": 1,
+    "substitutions": 0, "deletions": 0, "insertions": 0, "ignored_chars_count": 0,
+    "input_tokens": -10, "output_tokens": 5, "reference": 2, "references": ["a.txt"],
+    "word_confidences": [{"word": "a", "confidence": 1.1}]
+  }]
+}`,
+			want: []string{
+				"config.model: is empty",
+				"config.temperature: -1 is negative",
+				"results[0].character_similarity: 1.2 is above 1",
+				"results[0].word_similarity: -0.1 is negative",
+				"results[0].word_accuracy: 1.5 is above 1",
+				"results[0].word_error_rate: -1 is negative",
+				"results[0].input_tokens: -10 is negative",
+				"results[0].reference: 2 is not the position of one of the 1 references",
+				"results[0].word_confidences[0].confidence: 1.1 is above 1",
+			},
+		},
+		{
+			name: "json keys match case-insensitively",
+			file: "stub.json",
+			data: `{"Config": {"Provider": "stub", "Model": "m", "prompt": "", "temperature": 0, "timeout": 0, "csv_path": "", "rows": [], "timestamp": ""}, "results": [], "notes": "x"}`,
+			want: []string{"notes: unknown field"},
+		},
+		{
+			name: "unparseable file",
+			file: "stub.yaml",
+			data: "config: [unclosed",
+			want: []string{"failed to parse"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			if err := os.WriteFile(path, []byte(tt.data), 0644); err != nil {
+				t.Fatal(err)
+			}
+			problems, err := validateEvalFile(path)
+			if err != nil {
+				t.Fatalf("validateEvalFile() error = %v", err)
+			}
+			got := make([]string, len(problems))
+			for i, problem := range problems {
+				got[i] = problem.String()
+			}
+			if tt.name == "unparseable file" {
+				if len(got) != 1 || !strings.HasPrefix(got[0], tt.want[0]) {
+					t.Errorf("validateEvalFile() = %v, want one %q problem", got, tt.want[0])
+				}
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("validateEvalFile() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestRunValidateFailsOnProblems(t *testing.T) {
+	originalEvalsDir := evalsDir
+	t.Cleanup(func() { evalsDir = originalEvalsDir })
+	evalsDir = t.TempDir()
+
+	if err := saveEvalResults(validEvalSummary(), filepath.Join(evalsDir, "stub_good.yaml")); err != nil {
+		t.Fatal(err)
+	}
+	if err := runValidate(validateCmd, nil); err != nil {
+		t.Fatalf("runValidate() error = %v, want nil for valid files", err)
+	}
+
+	bad := validEvalSummary()
+	bad.Results[0].OutputTokens = -1
+	if err := saveEvalResults(bad, filepath.Join(evalsDir, "stub_bad.json")); err != nil {
+		t.Fatal(err)
+	}
+	err := runValidate(validateCmd, nil)
+	if err == nil || err.Error() != "1 of 2 eval files have problems" {
+		t.Errorf("runValidate() error = %v, want 1 of 2 eval files have problems", err)
+	}
+}
+
+func TestPrintEvalProblems(t *testing.T) {
+	var out bytes.Buffer
+	printEvalProblems(&out, "evals/a.yaml", nil)
+	printEvalProblems(&out, "evals/b.yaml", []evalProblem{{Path: "results[0].word_accuracy", Message: "1.5 is above 1"}})
+	want := "evals/a.yaml: OK\nevals/b.yaml: problems found: 1\n  results[0].word_accuracy: 1.5 is above 1\n"
+	if out.String() != want {
+		t.Errorf("printEvalProblems() =\n%s\nwant\n%s", out.String(), want)
+	}
+}
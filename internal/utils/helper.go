@@ -30,6 +30,12 @@ func MaskSensitiveData(s string) string {
 	xApiKeyPattern := regexp.MustCompile(`x-api-key:\s*([^\s]+)`)
 	s = xApiKeyPattern.ReplaceAllString(s, `x-api-key: ***MASKED***`)
 
+	// Mask bare API keys echoed back in provider error bodies, keeping the
+	// prefix so the kind of key is still visible (OpenAI sk-, Anthropic
+	// sk-ant-, Google AIza)
+	bareKeyPattern := regexp.MustCompile(`\b(sk-ant-|sk-proj-|sk-|AIza)[A-Za-z0-9_\-]{10,}`)
+	s = bareKeyPattern.ReplaceAllString(s, `${1}***MASKED***`)
+
 	return s
 }
 
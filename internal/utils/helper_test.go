@@ -66,6 +66,31 @@ func TestMaskSensitiveData(t *testing.T) {
 			input:    "Post \"https://generativelanguage.googleapis.com/v1beta/models/gemini-pro:generateContent?key=AIzaSyTest123\": context deadline exceeded",
 			expected: "Post \"https://generativelanguage.googleapis.com/v1beta/models/gemini-pro:generateContent?key=***MASKED***\": context deadline exceeded",
 		},
+		{
+			name:     "bare OpenAI key in error body",
+			input:    `openAI API error: 401 - {"error": {"message": "Incorrect API key provided: sk-abcdefghijklmnop1234"}}`,
+			expected: `openAI API error: 401 - {"error": {"message": "Incorrect API key provided: sk-***MASKED***"}}`,
+		},
+		{
+			name:     "bare OpenAI project key",
+			input:    "invalid key sk-proj-ABC123_def-456ghi",
+			expected: "invalid key sk-proj-***MASKED***",
+		},
+		{
+			name:     "bare Anthropic key",
+			input:    "claude API error: 401 - key sk-ant-api03-abcdefghij is invalid",
+			expected: "claude API error: 401 - key sk-ant-***MASKED*** is invalid",
+		},
+		{
+			name:     "bare Google key",
+			input:    "API key not valid: AIzaSyABCDEFGHIJKLMNOP.",
+			expected: "API key not valid: AIza***MASKED***.",
+		},
+		{
+			name:     "short sk- words are not keys",
+			input:    "install scikit-learn via sk-learn",
+			expected: "install scikit-learn via sk-learn",
+		},
 	}
 
 	for _, tt := range tests {
@@ -25,7 +25,7 @@ func main() {
 	slog.SetDefault(logger)
 
 	if err := fang.Execute(context.Background(), cmd.RootCmd); err != nil {
-		os.Exit(1)
+		os.Exit(cmd.ExitCode(err))
 	}
 }
 
@@ -0,0 +1,66 @@
+package awssigv4
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+// The credentials, request, and signature are the IAM ListUsers example from
+// the AWS Signature Version 4 documentation.
+func TestSignMatchesAWSExample(t *testing.T) {
+	request, err := http.NewRequest(http.MethodGet, "https://iam.amazonaws.com/?Version=2010-05-08&Action=ListUsers", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+	credentials := Credentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"}
+
+	Sign(request, credentials, "us-east-1", "iam", EmptyPayloadHash, time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC))
+
+	want := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/iam/aws4_request, " +
+		"SignedHeaders=content-type;host;x-amz-date, " +
+		"Signature=5d672d79c15b13162d9279b0855cfba6789a8edb4c82c400e06b5924a6f2b5d7"
+	if got := request.Header.Get("Authorization"); got != want {
+		t.Errorf("Authorization = %q, want %q", got, want)
+	}
+	if got := request.Header.Get("X-Amz-Content-Sha256"); got != "" {
+		t.Errorf("X-Amz-Content-Sha256 = %q, want it only on S3 requests", got)
+	}
+}
+
+func TestCanonicalURI(t *testing.T) {
+	tests := []struct {
+		name    string
+		rawURL  string
+		service string
+		want    string
+	}{
+		{name: "empty path", rawURL: "https://example.com", service: "bedrock", want: "/"},
+		{name: "encoded twice", rawURL: "https://example.com/model/anthropic.claude-v1%3A0/converse", service: "bedrock", want: "/model/anthropic.claude-v1%253A0/converse"},
+		{name: "encoded once for S3", rawURL: "https://example.com/letters/page%20one.txt", service: "s3", want: "/letters/page%20one.txt"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request, err := http.NewRequest(http.MethodGet, tt.rawURL, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := canonicalURI(request.URL, tt.service); got != tt.want {
+				t.Errorf("canonicalURI() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSignAddsSessionToken(t *testing.T) {
+	request, err := http.NewRequest(http.MethodPost, "https://bedrock-runtime.us-east-1.amazonaws.com/model/m/converse", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	Sign(request, Credentials{AccessKeyID: "AKID", SecretAccessKey: "secret", SessionToken: "session"}, "us-east-1", "bedrock", PayloadHash([]byte("{}")), time.Now())
+	if got := request.Header.Get("X-Amz-Security-Token"); got != "session" {
+		t.Errorf("X-Amz-Security-Token = %q, want session", got)
+	}
+}
@@ -0,0 +1,250 @@
+// Package gcpaccesstoken provides cached OAuth access tokens for Google service accounts.
+package gcpaccesstoken
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lehigh-university-libraries/htr/pkg/httpclient"
+)
+
+const (
+	// CloudPlatformScope grants access to Google Cloud APIs.
+	CloudPlatformScope = "https://www.googleapis.com/auth/cloud-platform"
+
+	defaultTokenURI     = "https://oauth2.googleapis.com/token"
+	defaultTimeout      = 10 * time.Second
+	defaultRefreshAhead = 2 * time.Minute
+	assertionLifetime   = time.Hour
+	maxCredentialBytes  = 64 << 10
+	maxTokenBytes       = 64 << 10
+)
+
+// ErrInvalidCredentials indicates an unreadable or unsupported credentials file.
+var ErrInvalidCredentials = errors.New("invalid service account credentials")
+
+// ErrTokenUnavailable indicates a redacted token exchange or parsing failure.
+var ErrTokenUnavailable = errors.New("access token unavailable")
+
+// Options configures an access token Source.
+type Options struct {
+	HTTPClient *http.Client
+	// Credentials is the JSON key of a Google service account.
+	Credentials  []byte
+	Scope        string
+	Timeout      time.Duration
+	RefreshAhead time.Duration
+	Now          func() time.Time
+}
+
+type serviceAccountKey struct {
+	Type         string `json:"type"`
+	ProjectID    string `json:"project_id"`
+	PrivateKeyID string `json:"private_key_id"`
+	PrivateKey   string `json:"private_key"`
+	ClientEmail  string `json:"client_email"`
+	TokenURI     string `json:"token_uri"`
+}
+
+// Source exchanges signed service account assertions for access tokens and
+// caches the result until shortly before it expires.
+type Source struct {
+	client       *http.Client
+	tokenURI     string
+	email        string
+	keyID        string
+	key          *rsa.PrivateKey
+	projectID    string
+	scope        string
+	refreshAhead time.Duration
+	now          func() time.Time
+
+	mu        sync.Mutex
+	token     string
+	expiresAt time.Time
+}
+
+// New parses service account credentials. It does not read environment variables
+// or contact the token endpoint.
+func New(options Options) (*Source, error) {
+	if len(options.Credentials) == 0 || len(options.Credentials) > maxCredentialBytes {
+		return nil, ErrInvalidCredentials
+	}
+	var account serviceAccountKey
+	if err := json.Unmarshal(options.Credentials, &account); err != nil || account.Type != "service_account" || account.ClientEmail == "" {
+		return nil, ErrInvalidCredentials
+	}
+	key, err := parsePrivateKey(account.PrivateKey)
+	if err != nil {
+		return nil, ErrInvalidCredentials
+	}
+	tokenURI := account.TokenURI
+	if tokenURI == "" {
+		tokenURI = defaultTokenURI
+	}
+	parsed, err := httpclient.ParseEndpoint(tokenURI)
+	if err != nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	scope := options.Scope
+	if scope == "" {
+		scope = CloudPlatformScope
+	}
+	timeout := options.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	refreshAhead := options.RefreshAhead
+	if refreshAhead <= 0 {
+		refreshAhead = defaultRefreshAhead
+	}
+	now := options.Now
+	if now == nil {
+		now = time.Now
+	}
+	return &Source{
+		client:       httpclient.Secure(options.HTTPClient, timeout),
+		tokenURI:     parsed.String(),
+		email:        account.ClientEmail,
+		keyID:        account.PrivateKeyID,
+		key:          key,
+		projectID:    account.ProjectID,
+		scope:        scope,
+		refreshAhead: refreshAhead,
+		now:          now,
+	}, nil
+}
+
+// ProjectID returns the project the service account belongs to, if recorded.
+func (s *Source) ProjectID() string { return s.projectID }
+
+// Token returns a cached or freshly exchanged access token. Concurrent callers
+// share a single exchange.
+func (s *Source) Token(ctx context.Context) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token != "" && s.now().Add(s.refreshAhead).Before(s.expiresAt) {
+		return s.token, nil
+	}
+
+	token, expiresAt, err := s.exchange(ctx)
+	if err != nil {
+		return "", err
+	}
+	s.token, s.expiresAt = token, expiresAt
+	return token, nil
+}
+
+func (s *Source) exchange(ctx context.Context) (string, time.Time, error) {
+	now := s.now()
+	assertion, err := s.assertion(now)
+	if err != nil {
+		return "", time.Time{}, ErrTokenUnavailable
+	}
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, s.tokenURI, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", time.Time{}, ErrTokenUnavailable
+	}
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	response, err := s.client.Do(request)
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return "", time.Time{}, ctxErr
+		}
+		return "", time.Time{}, ErrTokenUnavailable
+	}
+	defer response.Body.Close()
+	body, err := httpclient.ReadAll(response.Body, maxTokenBytes)
+	if err != nil {
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return "", time.Time{}, err
+		}
+		return "", time.Time{}, ErrTokenUnavailable
+	}
+	if response.StatusCode != http.StatusOK {
+		return "", time.Time{}, ErrTokenUnavailable
+	}
+
+	var decoded struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}
+	if err := json.Unmarshal(body, &decoded); err != nil || decoded.ExpiresIn <= 0 {
+		return "", time.Time{}, ErrTokenUnavailable
+	}
+	if err := httpclient.SetHeader(make(http.Header), "Authorization", "Bearer "+decoded.AccessToken); err != nil {
+		return "", time.Time{}, ErrTokenUnavailable
+	}
+	return decoded.AccessToken, now.Add(time.Duration(decoded.ExpiresIn) * time.Second), nil
+}
+
+// assertion builds the RS256-signed JWT exchanged for an access token.
+func (s *Source) assertion(now time.Time) (string, error) {
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT", "kid": s.keyID})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]any{
+		"iss":   s.email,
+		"scope": s.scope,
+		"aud":   s.tokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(assertionLifetime).Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	var unsigned bytes.Buffer
+	unsigned.WriteString(base64.RawURLEncoding.EncodeToString(header))
+	unsigned.WriteByte('.')
+	unsigned.WriteString(base64.RawURLEncoding.EncodeToString(claims))
+	digest := sha256.Sum256(unsigned.Bytes())
+	signature, err := rsa.SignPKCS1v15(rand.Reader, s.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+	return unsigned.String() + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+func parsePrivateKey(value string) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(value))
+	if block == nil {
+		return nil, ErrInvalidCredentials
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, ErrInvalidCredentials
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, ErrInvalidCredentials
+	}
+	return key, nil
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -12,9 +13,18 @@ import (
 	"strings"
 	"time"
 
+	"github.com/lehigh-university-libraries/htr/pkg/httpclient"
 	"github.com/lehigh-university-libraries/htr/pkg/providers"
 )
 
+// defaultTimeout bounds a whole extraction, including polling, when the
+// config does not set a timeout.
+const defaultTimeout = 2 * time.Minute
+
+// defaultPollInterval is the delay between polls of the Read operation when
+// the config does not set one.
+var defaultPollInterval = time.Second
+
 // Provider implements the Azure OCR provider
 type Provider struct{}
 
@@ -28,6 +38,12 @@ func (p *Provider) Name() string {
 	return "azure"
 }
 
+// Capabilities reports that Azure OCR is billed per page and ignores the
+// prompt and temperature. Its token counts hold pages and lines, not tokens.
+func (p *Provider) Capabilities() providers.Capabilities {
+	return providers.Capabilities{ReportsPageUsage: true}
+}
+
 // ValidateConfig validates the Azure configuration
 func (p *Provider) ValidateConfig(config providers.Config) error {
 	endpoint := os.Getenv("AZURE_OCR_ENDPOINT")
@@ -48,6 +64,15 @@ func (p *Provider) ExtractText(ctx context.Context, config providers.Config, ima
 		return "", providers.UsageInfo{}, fmt.Errorf("AZURE_OCR_ENDPOINT and AZURE_OCR_API_KEY environment variables must be set")
 	}
 
+	// The timeout covers the analyze request and all polling, so a slow
+	// operation cannot outlast it
+	timeout := config.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
 	// Decode base64 image data
 	imageData, err := base64.StdEncoding.DecodeString(imageBase64)
 	if err != nil {
@@ -67,10 +92,10 @@ func (p *Provider) ExtractText(ctx context.Context, config providers.Config, ima
 	req.Header.Set("Ocp-Apim-Subscription-Key", apiKey)
 	req.Header.Set("Content-Type", "application/octet-stream")
 
-	client := &http.Client{Timeout: config.Timeout}
+	client := &http.Client{Transport: httpclient.SharedTransport(), Timeout: timeout}
 	resp, err := client.Do(req)
 	if err != nil {
-		return "", providers.UsageInfo{}, err
+		return "", providers.UsageInfo{}, providers.ErrorForRequest(ctx, err)
 	}
 	defer resp.Body.Close()
 
@@ -81,7 +106,7 @@ func (p *Provider) ExtractText(ctx context.Context, config providers.Config, ima
 	}
 
 	if resp.StatusCode != http.StatusAccepted {
-		return "", providers.UsageInfo{}, fmt.Errorf("azure OCR API error: %d - %s", resp.StatusCode, string(initialBody))
+		return "", providers.UsageInfo{}, fmt.Errorf("azure OCR API error: %w - body: %s", providers.ErrorForStatus(resp.StatusCode), providers.TruncateBody(initialBody))
 	}
 
 	// Get the operation URL from the Operation-Location header
@@ -90,9 +115,18 @@ func (p *Provider) ExtractText(ctx context.Context, config providers.Config, ima
 		return "", providers.UsageInfo{}, fmt.Errorf("no operation location returned from Azure OCR - body: %s", providers.TruncateBody(initialBody))
 	}
 
-	// Poll for results
-	for attempts := 0; attempts < 30; attempts++ {
-		time.Sleep(1 * time.Second)
+	pollInterval := config.PollInterval
+	if pollInterval <= 0 {
+		pollInterval = defaultPollInterval
+	}
+
+	// Poll for results until the operation finishes or the context ends
+	for {
+		select {
+		case <-ctx.Done():
+			return "", providers.UsageInfo{}, pollingStopped(ctx)
+		case <-time.After(pollInterval):
+		}
 
 		req, err := http.NewRequestWithContext(ctx, "GET", operationURL, nil)
 		if err != nil {
@@ -102,7 +136,10 @@ func (p *Provider) ExtractText(ctx context.Context, config providers.Config, ima
 
 		resp, err := client.Do(req)
 		if err != nil {
-			return "", providers.UsageInfo{}, err
+			if ctx.Err() != nil {
+				return "", providers.UsageInfo{}, pollingStopped(ctx)
+			}
+			return "", providers.UsageInfo{}, providers.ErrorForRequest(ctx, err)
 		}
 
 		// Read polling response body for both parsing and error logging
@@ -112,7 +149,12 @@ func (p *Provider) ExtractText(ctx context.Context, config providers.Config, ima
 			return "", providers.UsageInfo{}, fmt.Errorf("failed to read polling response body: %w", readErr)
 		}
 
+		// Keep polling through throttling and server errors, but stop on
+		// statuses that would repeat, such as a rejected key
 		if resp.StatusCode != http.StatusOK {
+			if statusErr := providers.ErrorForStatus(resp.StatusCode); !errors.Is(statusErr, providers.ErrTransient) {
+				return "", providers.UsageInfo{}, fmt.Errorf("azure OCR polling error: %w - body: %s", statusErr, providers.TruncateBody(pollBody))
+			}
 			continue
 		}
 
@@ -128,28 +170,54 @@ func (p *Provider) ExtractText(ctx context.Context, config providers.Config, ima
 
 		switch status {
 		case "succeeded":
-			// Azure OCR does not provide token usage information
-			return extractText(result), providers.UsageInfo{}, nil
+			return extractText(result), readUsage(result), nil
 		case "failed":
 			return "", providers.UsageInfo{}, fmt.Errorf("azure OCR analysis failed - body: %s", providers.TruncateBody(pollBody))
 		}
 		// Continue polling if status is "running" or "notStarted"
 	}
+}
 
-	return "", providers.UsageInfo{}, fmt.Errorf("azure OCR operation timed out")
+// pollingStopped reports why polling ended early: the timeout passed or the
+// caller canceled the context.
+func pollingStopped(ctx context.Context) error {
+	if errors.Is(ctx.Err(), context.Canceled) {
+		return fmt.Errorf("azure OCR polling canceled: %w", ctx.Err())
+	}
+	return fmt.Errorf("azure OCR operation timed out: %w", ctx.Err())
 }
 
 // extractText extracts text from Azure OCR response (supports both v3.2 and v4.0 formats)
 func extractText(result map[string]interface{}) string {
+	texts, _ := readLines(result)
+	return strings.Join(texts, "\n")
+}
+
+// readUsage reports Azure OCR usage. The Read API is billed per page rather
+// than per token, so InputTokens holds the number of pages analyzed and
+// OutputTokens the number of text lines read.
+func readUsage(result map[string]interface{}) providers.UsageInfo {
+	texts, pages := readLines(result)
+	return providers.UsageInfo{
+		InputTokens:  pages,
+		OutputTokens: len(texts),
+		Pages:        pages,
+	}
+}
+
+// readLines returns the text lines and page count of an Azure OCR response
+// (supports both v3.2 and v4.0 formats)
+func readLines(result map[string]interface{}) ([]string, int) {
 	var texts []string
 
 	analyzeResult, ok := result["analyzeResult"].(map[string]interface{})
 	if !ok {
-		return ""
+		return nil, 0
 	}
 
 	// Try v3.2 format first
 	readResults, ok := analyzeResult["readResults"].([]interface{})
+	pages := len(readResults)
 	if ok {
 		for _, readResult := range readResults {
 			readResultMap, ok := readResult.(map[string]interface{})
@@ -176,9 +244,10 @@ func extractText(result map[string]interface{}) string {
 		}
 	} else {
 		// Try v4.0 format as fallback
-		pages, ok := analyzeResult["pages"].([]interface{})
+		v4Pages, ok := analyzeResult["pages"].([]interface{})
+		pages = len(v4Pages)
 		if ok {
-			for _, page := range pages {
+			for _, page := range v4Pages {
 				pageMap, ok := page.(map[string]interface{})
 				if !ok {
 					continue
@@ -204,5 +273,5 @@ func extractText(result map[string]interface{}) string {
 		}
 	}
 
-	return strings.Join(texts, "\n")
+	return texts, pages
 }
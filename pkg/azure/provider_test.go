@@ -2,10 +2,12 @@ package azure
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -100,8 +102,10 @@ func TestProvider_ExtractText(t *testing.T) {
 		resultStatus      int
 		operationLocation string
 		expectedText      string
+		expectedUsage     providers.UsageInfo
 		expectError       bool
 		errorContains     string
+		wantErr           error
 	}{
 		{
 			name:          "successful extraction",
@@ -128,8 +132,9 @@ func TestProvider_ExtractText(t *testing.T) {
 					]
 				}
 			}`,
-			expectedText: "Line 1 of text\nLine 2 of text",
-			expectError:  false,
+			expectedText:  "Line 1 of text\nLine 2 of text",
+			expectedUsage: providers.UsageInfo{InputTokens: 1, OutputTokens: 2, Pages: 1},
+			expectError:   false,
 		},
 		{
 			name:          "v4.0 format response",
@@ -156,8 +161,9 @@ func TestProvider_ExtractText(t *testing.T) {
 					]
 				}
 			}`,
-			expectedText: "Page 1 line 1\nPage 1 line 2",
-			expectError:  false,
+			expectedText:  "Page 1 line 1\nPage 1 line 2",
+			expectedUsage: providers.UsageInfo{InputTokens: 1, OutputTokens: 2, Pages: 1},
+			expectError:   false,
 		},
 		{
 			name:          "analyze request error",
@@ -170,6 +176,28 @@ func TestProvider_ExtractText(t *testing.T) {
 			}`,
 			expectError:   true,
 			errorContains: "azure OCR API error",
+			wantErr:       providers.ErrBadRequest,
+		},
+		{
+			name:            "analyze request throttled",
+			analyzeStatus:   http.StatusTooManyRequests,
+			analyzeResponse: `{"error": {"code": "429", "message": "Rate limit exceeded"}}`,
+			expectError:     true,
+			errorContains:   "(status 429)",
+			wantErr:         providers.ErrRateLimited,
+		},
+		{
+			name:          "polling rejected",
+			analyzeStatus: http.StatusAccepted,
+			analyzeResponse: `{
+				"status": "running"
+			}`,
+			operationLocation: "/operations/test-id",
+			resultStatus:      http.StatusUnauthorized,
+			resultResponse:    `{"error": {"code": "401", "message": "Access denied"}}`,
+			expectError:       true,
+			errorContains:     "azure OCR polling error",
+			wantErr:           providers.ErrAuth,
 		},
 		{
 			name:          "missing operation location",
@@ -268,7 +296,7 @@ func TestProvider_ExtractText(t *testing.T) {
 			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 			defer cancel()
 
-			result, _, err := p.ExtractText(ctx, config, "test.jpg", "dGVzdCBpbWFnZSBkYXRh") // "test image data" in base64
+			result, usage, err := p.ExtractText(ctx, config, "test.jpg", "dGVzdCBpbWFnZSBkYXRh") // "test image data" in base64
 
 			if tt.expectError {
 				if err == nil {
@@ -277,6 +305,9 @@ func TestProvider_ExtractText(t *testing.T) {
 				if tt.errorContains != "" && !strings.Contains(err.Error(), tt.errorContains) {
 					t.Errorf("Expected error to contain '%s', got: %v", tt.errorContains, err)
 				}
+				if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+					t.Errorf("Expected error to match %q, got: %v", tt.wantErr, err)
+				}
 			} else {
 				if err != nil {
 					t.Errorf("Expected no error but got: %v", err)
@@ -284,6 +315,9 @@ func TestProvider_ExtractText(t *testing.T) {
 				if result != tt.expectedText {
 					t.Errorf("Expected text '%s', got '%s'", tt.expectedText, result)
 				}
+				if usage != tt.expectedUsage {
+					t.Errorf("Expected usage %+v, got %+v", tt.expectedUsage, usage)
+				}
 			}
 
 			// Verify that the analyze endpoint was called
@@ -363,3 +397,146 @@ func TestExtractText(t *testing.T) {
 		})
 	}
 }
+
+func TestProviderCapabilities(t *testing.T) {
+	want := providers.Capabilities{ReportsPageUsage: true}
+	if got := New().Capabilities(); got != want {
+		t.Errorf("Capabilities() = %+v, want %+v", got, want)
+	}
+}
+
+func TestProvider_ExtractTextHonorsTimeout(t *testing.T) {
+	originalInterval := defaultPollInterval
+	t.Cleanup(func() { defaultPollInterval = originalInterval })
+	defaultPollInterval = 10 * time.Millisecond
+
+	tests := []struct {
+		name        string
+		slowAnalyze bool
+	}{
+		{name: "operation never finishes"},
+		{name: "analyze request is slow", slowAnalyze: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var serverURL string
+			release := make(chan struct{})
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if strings.Contains(r.URL.Path, "/analyze") {
+					if tt.slowAnalyze {
+						select {
+						case <-r.Context().Done():
+						case <-release:
+						}
+						return
+					}
+					w.Header().Set("Operation-Location", serverURL+"/operations/test-id")
+					w.WriteHeader(http.StatusAccepted)
+					return
+				}
+				_, _ = w.Write([]byte(`{"status": "running"}`))
+			}))
+			serverURL = server.URL
+			defer server.Close()
+			defer close(release)
+
+			t.Setenv("AZURE_OCR_ENDPOINT", server.URL)
+			t.Setenv("AZURE_OCR_API_KEY", "test-key")
+
+			config := providers.Config{Provider: "azure", Timeout: 200 * time.Millisecond}
+			start := time.Now()
+			_, _, err := New().ExtractText(context.Background(), config, "test.jpg", "dGVzdCBpbWFnZSBkYXRh")
+			if err == nil {
+				t.Fatal("Expected timeout error but got none")
+			}
+			if elapsed := time.Since(start); elapsed > 2*time.Second {
+				t.Errorf("ExtractText took %v, want it bounded by the 200ms timeout", elapsed)
+			}
+		})
+	}
+}
+
+func TestProvider_ExtractTextPollsUntilSucceeded(t *testing.T) {
+	tests := []struct {
+		name         string
+		pollInterval time.Duration
+		running      int
+	}{
+		{name: "finishes after several running polls", pollInterval: 10 * time.Millisecond, running: 4},
+		{name: "finishes on first poll", pollInterval: 10 * time.Millisecond, running: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var serverURL string
+			var polls atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if strings.Contains(r.URL.Path, "/analyze") {
+					w.Header().Set("Operation-Location", serverURL+"/operations/test-id")
+					w.WriteHeader(http.StatusAccepted)
+					return
+				}
+				if int(polls.Add(1)) <= tt.running {
+					_, _ = w.Write([]byte(`{"status": "running"}`))
+					return
+				}
+				_, _ = w.Write([]byte(`{"status": "succeeded", "analyzeResult": {"readResults": [{"lines": [{"text": "Finally done"}]}]}}`))
+			}))
+			serverURL = server.URL
+			defer server.Close()
+
+			t.Setenv("AZURE_OCR_ENDPOINT", server.URL)
+			t.Setenv("AZURE_OCR_API_KEY", "test-key")
+
+			config := providers.Config{Provider: "azure", Timeout: 5 * time.Second, PollInterval: tt.pollInterval}
+			text, _, err := New().ExtractText(context.Background(), config, "test.jpg", "dGVzdCBpbWFnZSBkYXRh")
+			if err != nil {
+				t.Fatalf("Expected no error but got: %v", err)
+			}
+			if text != "Finally done" {
+				t.Errorf("Expected text 'Finally done', got '%s'", text)
+			}
+			if got, want := int(polls.Load()), tt.running+1; got != want {
+				t.Errorf("Expected %d polls, got %d", want, got)
+			}
+		})
+	}
+}
+
+func TestProvider_ExtractTextStopsPollingOnCancel(t *testing.T) {
+	var serverURL string
+	var polls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, "/analyze") {
+			w.Header().Set("Operation-Location", serverURL+"/operations/test-id")
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		polls.Add(1)
+		_, _ = w.Write([]byte(`{"status": "running"}`))
+	}))
+	serverURL = server.URL
+	defer server.Close()
+
+	t.Setenv("AZURE_OCR_ENDPOINT", server.URL)
+	t.Setenv("AZURE_OCR_API_KEY", "test-key")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+
+	config := providers.Config{Provider: "azure", Timeout: time.Minute, PollInterval: 20 * time.Millisecond}
+	start := time.Now()
+	_, _, err := New().ExtractText(ctx, config, "test.jpg", "dGVzdCBpbWFnZSBkYXRh")
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("ExtractText took %v after cancellation, want it to stop promptly", elapsed)
+	}
+	stopped := polls.Load()
+	time.Sleep(60 * time.Millisecond)
+	if polls.Load() != stopped {
+		t.Error("Expected polling to stop after cancellation")
+	}
+}
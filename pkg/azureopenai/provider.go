@@ -0,0 +1,123 @@
+// Package azureopenai provides a transcription provider for vision model
+// deployments on Azure OpenAI, such as GPT-4o. It is separate from pkg/azure,
+// which calls the Azure Computer Vision Read API. Requests are built by
+// pkg/openai and sent to the deployment's chat completions endpoint.
+package azureopenai
+
+import (
+	"context"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/lehigh-university-libraries/htr/pkg/httpclient"
+	"github.com/lehigh-university-libraries/htr/pkg/openai"
+	"github.com/lehigh-university-libraries/htr/pkg/providers"
+)
+
+const (
+	defaultMaxImageBytes = 50 << 20
+	// defaultAPIVersion is the generally available data plane API version
+	// used unless AZURE_OPENAI_API_VERSION is set.
+	defaultAPIVersion = "2024-10-21"
+	apiKeyHeader      = "api-key"
+)
+
+// Provider is the CLI adapter for Azure OpenAI deployments. New integrations
+// should use openai.NewClient with the deployment endpoint, the api-key
+// header, and an api-version query.
+type Provider struct {
+	providers.BaseProvider
+}
+
+// New creates the CLI adapter.
+func New() *Provider { return &Provider{} }
+
+// Name returns the provider name.
+func (p *Provider) Name() string { return "azure-openai" }
+
+// ValidateConfig validates environment-backed CLI configuration: the
+// resource endpoint, the deployment, and the API key.
+func (p *Provider) ValidateConfig(config providers.Config) error {
+	if _, err := deploymentEndpoint(config); err != nil {
+		return providers.NewError(providers.ErrorInvalidRequest, 0, false, nil)
+	}
+	if strings.TrimSpace(os.Getenv("AZURE_OPENAI_API_KEY")) == "" {
+		return providers.NewError(providers.ErrorAuthentication, 0, false, nil)
+	}
+	return nil
+}
+
+// ExtractText adapts historical base64 CLI inputs to an OpenAI client pointed
+// at the configured deployment.
+func (p *Provider) ExtractText(ctx context.Context, config providers.Config, imagePath, imageBase64 string) (string, providers.UsageInfo, error) {
+	result, err := p.extract(ctx, config, imagePath, imageBase64, false)
+	return result.Text, result.Usage, err
+}
+
+// ExtractTextWithLogprobs is ExtractText that also returns the log
+// probability of each response token.
+func (p *Provider) ExtractTextWithLogprobs(ctx context.Context, config providers.Config, imagePath, imageBase64 string) (string, providers.UsageInfo, []providers.TokenLogprob, error) {
+	result, err := p.extract(ctx, config, imagePath, imageBase64, true)
+	return result.Text, result.Usage, result.Logprobs, err
+}
+
+func (p *Provider) extract(ctx context.Context, config providers.Config, imagePath, imageBase64 string, logprobs bool) (providers.Result, error) {
+	if strings.TrimSpace(config.Model) == "" {
+		// Azure routes on the deployment, so it also names the model.
+		config.Model = deployment()
+	}
+	request, err := providers.LegacyRequest(config, imagePath, imageBase64, defaultMaxImageBytes)
+	if err != nil {
+		return providers.Result{}, err
+	}
+	request.Logprobs = logprobs
+	endpoint, err := deploymentEndpoint(config)
+	if err != nil {
+		return providers.Result{}, providers.NewError(providers.ErrorInvalidRequest, 0, false, nil)
+	}
+	client, err := openai.NewClient(openai.Options{
+		Endpoint: endpoint,
+		APIKey: func(context.Context) (string, error) {
+			key := os.Getenv("AZURE_OPENAI_API_KEY")
+			if strings.TrimSpace(key) == "" {
+				return "", providers.NewError(providers.ErrorAuthentication, 0, false, nil)
+			}
+			return key, nil
+		},
+		APIKeyHeader: apiKeyHeader,
+		Query:        url.Values{"api-version": {apiVersion()}},
+		Timeout:      config.Timeout,
+		ImageDetail:  config.ImageDetail,
+	})
+	if err != nil {
+		return providers.Result{}, err
+	}
+	return client.Extract(ctx, request)
+}
+
+// deploymentEndpoint returns the chat completions endpoint of the
+// AZURE_OPENAI_DEPLOYMENT deployment on the resource endpoint from
+// config.BaseURL or AZURE_OPENAI_ENDPOINT, for example
+// https://my-resource.openai.azure.com/openai/deployments/gpt-4o/chat/completions.
+func deploymentEndpoint(config providers.Config) (string, error) {
+	baseURL := strings.TrimSpace(config.BaseURL)
+	if baseURL == "" {
+		baseURL = strings.TrimSpace(os.Getenv("AZURE_OPENAI_ENDPOINT"))
+	}
+	return httpclient.AppendPathSegment(baseURL, "/openai/deployments/", deployment(), "/chat/completions")
+}
+
+// deployment returns the AZURE_OPENAI_DEPLOYMENT deployment name.
+func deployment() string {
+	return strings.TrimSpace(os.Getenv("AZURE_OPENAI_DEPLOYMENT"))
+}
+
+// apiVersion returns AZURE_OPENAI_API_VERSION, or the default version when
+// it is unset.
+func apiVersion() string {
+	if version := strings.TrimSpace(os.Getenv("AZURE_OPENAI_API_VERSION")); version != "" {
+		return version
+	}
+	return defaultAPIVersion
+}
@@ -0,0 +1,159 @@
+package azureopenai
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lehigh-university-libraries/htr/pkg/providers"
+)
+
+var (
+	_ providers.Provider        = (*Provider)(nil)
+	_ providers.LogprobProvider = (*Provider)(nil)
+)
+
+func TestProviderExtractText(t *testing.T) {
+	image := []byte("encoded-image")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, request *http.Request) {
+		if request.Method != http.MethodPost || request.URL.Path != "/openai/deployments/gpt-4o-htr/chat/completions" {
+			t.Errorf("unexpected request target: %s %s", request.Method, request.URL.String())
+		}
+		if got := request.URL.Query().Get("api-version"); got != defaultAPIVersion {
+			t.Errorf("api-version = %q, want %q", got, defaultAPIVersion)
+		}
+		if got := request.Header.Get("api-key"); got != "azure-key" {
+			t.Errorf("api-key = %q", got)
+		}
+		if got := request.Header.Get("Authorization"); got != "" {
+			t.Errorf("Authorization = %q, want none", got)
+		}
+		var body struct {
+			Messages []struct {
+				Content []struct {
+					Type     string `json:"type"`
+					Text     string `json:"text"`
+					ImageURL struct {
+						URL    string `json:"url"`
+						Detail string `json:"detail"`
+					} `json:"image_url"`
+				} `json:"content"`
+			} `json:"messages"`
+		}
+		if err := json.NewDecoder(request.Body).Decode(&body); err != nil {
+			t.Fatal(err)
+		}
+		if len(body.Messages) != 1 || len(body.Messages[0].Content) != 2 {
+			t.Fatalf("unexpected chat request: %#v", body)
+		}
+		if got := body.Messages[0].Content[0].Text; got != "Transcribe" {
+			t.Errorf("prompt = %q", got)
+		}
+		wantURL := "data:image/png;base64," + base64.StdEncoding.EncodeToString(image)
+		if got := body.Messages[0].Content[1].ImageURL.URL; got != wantURL {
+			t.Errorf("image URL = %q, want %q", got, wantURL)
+		}
+		if got := body.Messages[0].Content[1].ImageURL.Detail; got != "low" {
+			t.Errorf("image detail = %q, want low", got)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"model":"gpt-4o-2024-11-20","choices":[{"message":{"content":"café 世界"}}],"usage":{"prompt_tokens":18,"completion_tokens":5}}`))
+	}))
+	defer server.Close()
+
+	t.Setenv("AZURE_OPENAI_ENDPOINT", server.URL+"/")
+	t.Setenv("AZURE_OPENAI_API_KEY", "azure-key")
+	t.Setenv("AZURE_OPENAI_DEPLOYMENT", "gpt-4o-htr")
+	t.Setenv("AZURE_OPENAI_API_VERSION", "")
+	config := providers.Config{Model: "gpt-4o-htr", Prompt: "Transcribe", ImageDetail: "low"}
+	text, usage, err := New().ExtractText(context.Background(), config, "page.png", base64.StdEncoding.EncodeToString(image))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text != "café 世界" || usage.InputTokens != 18 || usage.OutputTokens != 5 {
+		t.Fatalf("unexpected result: %q %#v", text, usage)
+	}
+}
+
+func TestProviderExtractTextUsesConfiguredAPIVersion(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, request *http.Request) {
+		if got := request.URL.RawQuery; got != "api-version=2025-01-01-preview" {
+			t.Errorf("query = %q", got)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"content":"text"}}],"usage":{"prompt_tokens":1,"completion_tokens":1}}`))
+	}))
+	defer server.Close()
+
+	t.Setenv("AZURE_OPENAI_ENDPOINT", "")
+	t.Setenv("AZURE_OPENAI_API_KEY", "azure-key")
+	t.Setenv("AZURE_OPENAI_DEPLOYMENT", "gpt-4o-htr")
+	t.Setenv("AZURE_OPENAI_API_VERSION", "2025-01-01-preview")
+	config := providers.Config{Prompt: "Transcribe", BaseURL: server.URL}
+	if _, _, err := New().ExtractText(context.Background(), config, "page.png", base64.StdEncoding.EncodeToString([]byte("image"))); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestProviderExtractTextRedactsErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte("secret upstream body"))
+	}))
+	defer server.Close()
+
+	t.Setenv("AZURE_OPENAI_ENDPOINT", server.URL)
+	t.Setenv("AZURE_OPENAI_API_KEY", "azure-key")
+	t.Setenv("AZURE_OPENAI_DEPLOYMENT", "gpt-4o-htr")
+	config := providers.Config{Prompt: "Transcribe"}
+	_, _, err := New().ExtractText(context.Background(), config, "page.png", base64.StdEncoding.EncodeToString([]byte("image")))
+	var providerError *providers.Error
+	if !errors.As(err, &providerError) || providerError.Kind != providers.ErrorAuthentication || providerError.Retryable {
+		t.Fatalf("expected authentication error, got %v", err)
+	}
+}
+
+func TestProviderValidateConfig(t *testing.T) {
+	tests := []struct {
+		name       string
+		endpoint   string
+		apiKey     string
+		deployment string
+		config     providers.Config
+		wantErr    bool
+	}{
+		{name: "valid", endpoint: "https://htr.openai.azure.com", apiKey: "key", deployment: "gpt-4o"},
+		{name: "config base URL overrides environment", endpoint: "", apiKey: "key", deployment: "gpt-4o", config: providers.Config{BaseURL: "https://htr.openai.azure.com"}},
+		{name: "missing endpoint", endpoint: "", apiKey: "key", deployment: "gpt-4o", wantErr: true},
+		{name: "invalid endpoint", endpoint: "htr.openai.azure.com", apiKey: "key", deployment: "gpt-4o", wantErr: true},
+		{name: "missing API key", endpoint: "https://htr.openai.azure.com", apiKey: "", deployment: "gpt-4o", wantErr: true},
+		{name: "missing deployment", endpoint: "https://htr.openai.azure.com", apiKey: "key", deployment: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("AZURE_OPENAI_ENDPOINT", tt.endpoint)
+			t.Setenv("AZURE_OPENAI_API_KEY", tt.apiKey)
+			t.Setenv("AZURE_OPENAI_DEPLOYMENT", tt.deployment)
+			err := New().ValidateConfig(tt.config)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestProviderCapabilities(t *testing.T) {
+	provider := New()
+	if provider.Name() != "azure-openai" {
+		t.Fatalf("Name() = %q", provider.Name())
+	}
+	want := providers.Capabilities{ReportsTokenUsage: true, SupportsTemperature: true, SupportsCustomPrompt: true}
+	if got := provider.Capabilities(); got != want {
+		t.Errorf("Capabilities() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,342 @@
+// Package bedrock provides an Amazon Bedrock transcription client for vision
+// models such as Anthropic Claude and Amazon Nova. Requests go through the
+// Bedrock Runtime Converse API and are signed with Signature Version 4.
+package bedrock
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/lehigh-university-libraries/htr/pkg/auth/awssigv4"
+	"github.com/lehigh-university-libraries/htr/pkg/httpclient"
+	"github.com/lehigh-university-libraries/htr/pkg/providers"
+)
+
+const (
+	// signingService is the Signature Version 4 service name of Bedrock
+	// Runtime, which differs from its bedrock-runtime host name.
+	signingService = "bedrock"
+	defaultTimeout = 2 * time.Minute
+	// defaultMaxImageBytes is the Converse API limit of 3.75 MB per image.
+	defaultMaxImageBytes    = 3840 << 10
+	defaultMaxRequestBytes  = 8 << 20
+	defaultMaxResponseBytes = 8 << 20
+)
+
+// CredentialSource returns AWS credentials for one request.
+type CredentialSource func(context.Context) (awssigv4.Credentials, error)
+
+// Options configures a Client. Constructors do not read environment variables.
+type Options struct {
+	HTTPClient *http.Client
+	// Region selects the regional Bedrock Runtime endpoint and signs requests.
+	Region string
+	// Endpoint replaces the regional endpoint, such as for a VPC endpoint.
+	Endpoint         string
+	Credentials      CredentialSource
+	Timeout          time.Duration
+	MaxImageBytes    int64
+	MaxRequestBytes  int64
+	MaxResponseBytes int64
+	Now              func() time.Time
+}
+
+// Client is a byte-oriented Bedrock transcription client.
+type Client struct {
+	httpClient       *http.Client
+	region           string
+	endpoint         *url.URL
+	credentials      CredentialSource
+	maxImageBytes    int64
+	maxRequestBytes  int64
+	maxResponseBytes int64
+	now              func() time.Time
+}
+
+// Provider is the historical CLI adapter. New integrations should use Client.
+type Provider struct {
+	providers.BaseProvider
+}
+
+type converseRequest struct {
+	Messages        []message       `json:"messages"`
+	System          []contentBlock  `json:"system,omitempty"`
+	InferenceConfig inferenceConfig `json:"inferenceConfig"`
+}
+
+type inferenceConfig struct {
+	Temperature float64 `json:"temperature"`
+	MaxTokens   int     `json:"maxTokens,omitempty"`
+}
+
+type message struct {
+	Role    string         `json:"role"`
+	Content []contentBlock `json:"content"`
+}
+
+// contentBlock is a Converse content block. Images are sent as raw bytes,
+// which JSON encodes as base64.
+type contentBlock struct {
+	Text  string      `json:"text,omitempty"`
+	Image *imageBlock `json:"image,omitempty"`
+}
+
+type imageBlock struct {
+	Format string `json:"format"`
+	Source struct {
+		Bytes []byte `json:"bytes"`
+	} `json:"source"`
+}
+
+type converseResponse struct {
+	Output struct {
+		Message struct {
+			Content []struct {
+				Text string `json:"text"`
+			} `json:"content"`
+		} `json:"message"`
+	} `json:"output"`
+	StopReason string `json:"stopReason"`
+	Usage      struct {
+		InputTokens  int `json:"inputTokens"`
+		OutputTokens int `json:"outputTokens"`
+	} `json:"usage"`
+}
+
+// imageFormats maps the media types the Converse API accepts to its format
+// names.
+var imageFormats = map[string]string{
+	"image/png":  "png",
+	"image/jpeg": "jpeg",
+	"image/gif":  "gif",
+	"image/webp": "webp",
+}
+
+// NewClient constructs a secure Bedrock client from explicit dependencies.
+func NewClient(options Options) (*Client, error) {
+	region := strings.TrimSpace(options.Region)
+	endpoint := options.Endpoint
+	if endpoint == "" && region != "" {
+		endpoint = "https://bedrock-runtime." + region + ".amazonaws.com"
+	}
+	parsed, err := httpclient.ParseEndpoint(endpoint)
+	if err != nil || region == "" || options.Credentials == nil {
+		return nil, providers.NewError(providers.ErrorInvalidRequest, 0, false, nil)
+	}
+	now := options.Now
+	if now == nil {
+		now = time.Now
+	}
+	return &Client{
+		httpClient:       httpclient.Secure(options.HTTPClient, durationOr(options.Timeout, defaultTimeout)),
+		region:           region,
+		endpoint:         parsed,
+		credentials:      options.Credentials,
+		maxImageBytes:    positiveOr(options.MaxImageBytes, defaultMaxImageBytes),
+		maxRequestBytes:  positiveOr(options.MaxRequestBytes, defaultMaxRequestBytes),
+		maxResponseBytes: positiveOr(options.MaxResponseBytes, defaultMaxResponseBytes),
+		now:              now,
+	}, nil
+}
+
+// Name returns the provider name.
+func (c *Client) Name() string { return "bedrock" }
+
+// Extract transcribes an encoded image.
+func (c *Client) Extract(ctx context.Context, request providers.Request) (providers.Result, error) {
+	if err := providers.ValidateRequest(request, c.maxImageBytes); err != nil {
+		return providers.Result{}, err
+	}
+	if err := ctx.Err(); err != nil {
+		return providers.Result{}, providers.ErrorForRequest(ctx, err)
+	}
+	mediaType, err := providers.CanonicalMediaType(request.Image.MediaType)
+	if err != nil {
+		return providers.Result{}, err
+	}
+	format, ok := imageFormats[mediaType]
+	if !ok {
+		return providers.Result{}, providers.NewError(providers.ErrorInvalidRequest, 0, false, nil)
+	}
+	credentials, err := c.credentials(ctx)
+	if err != nil || credentials.AccessKeyID == "" || credentials.SecretAccessKey == "" {
+		return providers.Result{}, providers.ErrorForAuthentication(ctx, err)
+	}
+
+	image := &imageBlock{Format: format}
+	image.Source.Bytes = request.Image.Data
+	payload := converseRequest{
+		Messages: []message{{
+			Role:    "user",
+			Content: []contentBlock{{Image: image}, {Text: request.Prompt}},
+		}},
+		InferenceConfig: inferenceConfig{Temperature: request.Temperature, MaxTokens: request.MaxTokens},
+	}
+	if request.SystemPrompt != "" {
+		payload.System = []contentBlock{{Text: request.SystemPrompt}}
+	}
+	body, err := json.Marshal(payload)
+	if err != nil || int64(len(body)) > c.maxRequestBytes {
+		return providers.Result{}, providers.NewError(providers.ErrorInvalidRequest, 0, false, nil)
+	}
+
+	httpRequest, err := http.NewRequestWithContext(ctx, http.MethodPost, c.converseURL(request.Model), bytes.NewReader(body))
+	if err != nil {
+		return providers.Result{}, providers.NewError(providers.ErrorInvalidRequest, 0, false, nil)
+	}
+	httpRequest.Header.Set("Content-Type", "application/json")
+	awssigv4.Sign(httpRequest, credentials, c.region, signingService, awssigv4.PayloadHash(body), c.now())
+
+	response, err := c.httpClient.Do(httpRequest)
+	if err != nil {
+		return providers.Result{}, providers.ErrorForRequest(ctx, err)
+	}
+	defer response.Body.Close()
+	responseBody, err := httpclient.ReadAll(response.Body, c.maxResponseBytes)
+	if err != nil {
+		if errors.Is(err, httpclient.ErrResponseTooLarge) {
+			return providers.Result{}, providers.NewError(providers.ErrorResponseTooLarge, response.StatusCode, false, nil)
+		}
+		return providers.Result{}, providers.ErrorForRequest(ctx, err)
+	}
+	if response.StatusCode < http.StatusOK || response.StatusCode >= http.StatusMultipleChoices {
+		return providers.Result{}, providers.ErrorForStatus(response.StatusCode)
+	}
+
+	var decoded converseResponse
+	if err := json.Unmarshal(responseBody, &decoded); err != nil {
+		return providers.Result{}, providers.NewError(providers.ErrorInvalidResponse, response.StatusCode, false, nil)
+	}
+	var text strings.Builder
+	for _, block := range decoded.Output.Message.Content {
+		text.WriteString(block.Text)
+	}
+	if strings.TrimSpace(text.String()) == "" {
+		return providers.Result{}, providers.NewError(providers.ErrorInvalidResponse, response.StatusCode, false, nil)
+	}
+	return providers.Result{
+		Text: providers.CleanResponse(text.String()),
+		Usage: providers.UsageInfo{
+			InputTokens:  decoded.Usage.InputTokens,
+			OutputTokens: decoded.Usage.OutputTokens,
+			Truncated:    decoded.StopReason == "max_tokens",
+		},
+		EffectiveModel: request.Model,
+	}, nil
+}
+
+// converseURL returns the Converse endpoint for a model ID, inference profile
+// ID, or ARN. The ID is encoded strictly, so the colon in a versioned ID such
+// as anthropic.claude-3-5-sonnet-20240620-v1:0 is sent as %3A.
+func (c *Client) converseURL(model string) string {
+	target := *c.endpoint
+	rawPath := strings.TrimRight(target.EscapedPath(), "/") + "/model/" + awssigv4.Escape(model) + "/converse"
+	target.Path = strings.TrimRight(target.Path, "/") + "/model/" + model + "/converse"
+	target.RawPath = rawPath
+	return target.String()
+}
+
+// New creates the historical CLI adapter.
+func New() *Provider { return &Provider{} }
+
+// Name returns the provider name.
+func (p *Provider) Name() string { return "bedrock" }
+
+// ValidateConfig validates environment-backed CLI configuration: the region,
+// the access keys, and a model from the config or BEDROCK_MODEL, since model
+// access is granted per account.
+func (p *Provider) ValidateConfig(config providers.Config) error {
+	if region() == "" {
+		return providers.NewError(providers.ErrorInvalidRequest, 0, false, nil)
+	}
+	if _, err := environmentCredentials(context.Background()); err != nil {
+		return err
+	}
+	if resolveModel(config) == "" {
+		return providers.NewError(providers.ErrorInvalidRequest, 0, false, nil)
+	}
+	return nil
+}
+
+// ExtractText adapts historical base64 CLI inputs to Client.
+func (p *Provider) ExtractText(ctx context.Context, config providers.Config, imagePath, imageBase64 string) (string, providers.UsageInfo, error) {
+	config.Model = resolveModel(config)
+	request, err := providers.LegacyRequest(config, imagePath, imageBase64, defaultMaxImageBytes)
+	if err != nil {
+		return "", providers.UsageInfo{}, err
+	}
+	endpoint := strings.TrimSpace(config.BaseURL)
+	if endpoint == "" {
+		endpoint = firstEnv("AWS_ENDPOINT_URL_BEDROCK_RUNTIME", "AWS_ENDPOINT_URL")
+	}
+	client, err := NewClient(Options{
+		Region:      region(),
+		Endpoint:    endpoint,
+		Credentials: environmentCredentials,
+		Timeout:     config.Timeout,
+	})
+	if err != nil {
+		return "", providers.UsageInfo{}, err
+	}
+	result, err := client.Extract(ctx, request)
+	return result.Text, result.Usage, err
+}
+
+// environmentCredentials reads AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY, and
+// AWS_SESSION_TOKEN.
+func environmentCredentials(context.Context) (awssigv4.Credentials, error) {
+	credentials := awssigv4.Credentials{
+		AccessKeyID:     firstEnv("AWS_ACCESS_KEY_ID"),
+		SecretAccessKey: firstEnv("AWS_SECRET_ACCESS_KEY"),
+		SessionToken:    firstEnv("AWS_SESSION_TOKEN"),
+	}
+	if credentials.AccessKeyID == "" || credentials.SecretAccessKey == "" {
+		return awssigv4.Credentials{}, providers.NewError(providers.ErrorAuthentication, 0, false, nil)
+	}
+	return credentials, nil
+}
+
+// resolveModel returns the configured model, or BEDROCK_MODEL when the model
+// is unset or still eval's OpenAI default.
+func resolveModel(config providers.Config) string {
+	if model := strings.TrimSpace(config.Model); model != "" && model != "gpt-4o" {
+		return model
+	}
+	return firstEnv("BEDROCK_MODEL")
+}
+
+// region returns AWS_REGION, or AWS_DEFAULT_REGION when it is unset.
+func region() string {
+	return firstEnv("AWS_REGION", "AWS_DEFAULT_REGION")
+}
+
+// firstEnv returns the first non-empty environment variable among names.
+func firstEnv(names ...string) string {
+	for _, name := range names {
+		if value := strings.TrimSpace(os.Getenv(name)); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+func positiveOr(value, fallback int64) int64 {
+	if value > 0 {
+		return value
+	}
+	return fallback
+}
+
+func durationOr(value, fallback time.Duration) time.Duration {
+	if value > 0 {
+		return value
+	}
+	return fallback
+}
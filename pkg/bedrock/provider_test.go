@@ -0,0 +1,305 @@
+package bedrock
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lehigh-university-libraries/htr/pkg/auth/awssigv4"
+	"github.com/lehigh-university-libraries/htr/pkg/providers"
+)
+
+var (
+	_ providers.Client   = (*Client)(nil)
+	_ providers.Provider = (*Provider)(nil)
+)
+
+var testTime = time.Date(2026, 3, 2, 9, 30, 0, 0, time.UTC)
+
+func TestClientExtract(t *testing.T) {
+	t.Parallel()
+	image := []byte("encoded-image")
+	credentials := awssigv4.Credentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "secret", SessionToken: "session"}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, request *http.Request) {
+		if request.Method != http.MethodPost || request.URL.EscapedPath() != "/model/anthropic.claude-3-5-sonnet-20240620-v1%3A0/converse" {
+			t.Errorf("unexpected request target: %s %s", request.Method, request.URL.EscapedPath())
+		}
+		var raw json.RawMessage
+		if err := json.NewDecoder(request.Body).Decode(&raw); err != nil {
+			t.Fatal(err)
+		}
+		// Sign the request as received to check the signature covers the
+		// path, headers, and body the service sees.
+		resigned, err := http.NewRequest(request.Method, "http://"+request.Host+request.URL.RequestURI(), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resigned.Header.Set("Content-Type", request.Header.Get("Content-Type"))
+		awssigv4.Sign(resigned, credentials, "us-west-2", "bedrock", awssigv4.PayloadHash(raw), testTime)
+		if got, want := request.Header.Get("Authorization"), resigned.Header.Get("Authorization"); got != want {
+			t.Errorf("Authorization = %q, want %q", got, want)
+		}
+		if got := request.Header.Get("X-Amz-Security-Token"); got != "session" {
+			t.Errorf("X-Amz-Security-Token = %q", got)
+		}
+
+		var body converseRequest
+		if err := json.Unmarshal(raw, &body); err != nil {
+			t.Fatal(err)
+		}
+		if len(body.Messages) != 1 || body.Messages[0].Role != "user" || len(body.Messages[0].Content) != 2 {
+			t.Fatalf("unexpected converse request: %s", raw)
+		}
+		if got := body.Messages[0].Content[0].Image; got == nil || got.Format != "png" || string(got.Source.Bytes) != string(image) {
+			t.Errorf("image block = %#v", got)
+		}
+		if got := body.Messages[0].Content[1].Text; got != "Transcribe café" {
+			t.Errorf("prompt = %q", got)
+		}
+		if len(body.System) != 1 || body.System[0].Text != "You are a paleographer." {
+			t.Errorf("system = %#v", body.System)
+		}
+		if body.InferenceConfig.Temperature != 0.2 || body.InferenceConfig.MaxTokens != 2048 {
+			t.Errorf("inference config = %#v", body.InferenceConfig)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"output":{"message":{"role":"assistant","content":[{"text":"The text in the image reads: café 世界"}]}},"stopReason":"end_turn","usage":{"inputTokens":1612,"outputTokens":7,"totalTokens":1619}}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(Options{
+		Region:      "us-west-2",
+		Endpoint:    server.URL,
+		Credentials: staticCredentials(credentials),
+		Now:         func() time.Time { return testTime },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	request := testRequest(image)
+	request.SystemPrompt = "You are a paleographer."
+	request.MaxTokens = 2048
+	result, err := client.Extract(context.Background(), request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Text != "café 世界" || result.Usage.InputTokens != 1612 || result.Usage.OutputTokens != 7 || result.Usage.Truncated || result.EffectiveModel != request.Model {
+		t.Fatalf("unexpected result: %#v", result)
+	}
+}
+
+func TestClientExtractReportsTruncation(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"output":{"message":{"content":[{"text":"Dear "},{"text":"Sir"}]}},"stopReason":"max_tokens","usage":{"inputTokens":10,"outputTokens":2}}`))
+	}))
+	defer server.Close()
+	client, err := NewClient(Options{Region: "us-east-1", Endpoint: server.URL, Credentials: staticCredentials(testCredentials())})
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := client.Extract(context.Background(), testRequest([]byte("image")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Text != "Dear Sir" || !result.Usage.Truncated {
+		t.Fatalf("unexpected result: %#v", result)
+	}
+}
+
+func TestClientErrorsAreTypedAndRedacted(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		status    int
+		kind      providers.ErrorKind
+		retryable bool
+	}{
+		{status: http.StatusForbidden, kind: providers.ErrorAuthentication},
+		{status: http.StatusTooManyRequests, kind: providers.ErrorRateLimited, retryable: true},
+		{status: http.StatusBadRequest, kind: providers.ErrorInvalidRequest},
+		{status: http.StatusServiceUnavailable, kind: providers.ErrorUpstream, retryable: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			t.Parallel()
+			secretBody := `{"message":"credential=upstream-secret"}`
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(secretBody))
+			}))
+			defer server.Close()
+			client, err := NewClient(Options{Region: "us-east-1", Endpoint: server.URL, Credentials: staticCredentials(testCredentials())})
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = client.Extract(context.Background(), testRequest([]byte("image")))
+			var providerError *providers.Error
+			if !errors.As(err, &providerError) || providerError.Kind != tt.kind || providerError.Retryable != tt.retryable {
+				t.Fatalf("unexpected error: %#v", err)
+			}
+			if strings.Contains(err.Error(), "upstream-secret") || strings.Contains(err.Error(), "private-secret") {
+				t.Fatalf("error leaked sensitive data: %q", err)
+			}
+		})
+	}
+}
+
+func TestClientRejectsInvalidInputBeforeNetwork(t *testing.T) {
+	t.Parallel()
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) { calls.Add(1) }))
+	defer server.Close()
+	client, err := NewClient(Options{Region: "us-east-1", Endpoint: server.URL, Credentials: staticCredentials(testCredentials())})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tiff := testRequest([]byte("image"))
+	tiff.Image.MediaType = "image/tiff"
+	unsigned, err := NewClient(Options{Region: "us-east-1", Endpoint: server.URL, Credentials: staticCredentials(awssigv4.Credentials{})})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = client.Extract(context.Background(), tiff)
+	var providerError *providers.Error
+	if !errors.As(err, &providerError) || providerError.Kind != providers.ErrorInvalidRequest {
+		t.Fatalf("expected invalid request for a TIFF image, got %v", err)
+	}
+	_, err = unsigned.Extract(context.Background(), testRequest([]byte("image")))
+	if !errors.As(err, &providerError) || providerError.Kind != providers.ErrorAuthentication {
+		t.Fatalf("expected authentication error without credentials, got %v", err)
+	}
+	if calls.Load() != 0 {
+		t.Fatal("network called for invalid input")
+	}
+}
+
+func TestNewClientRequiresRegionAndCredentials(t *testing.T) {
+	t.Parallel()
+	if _, err := NewClient(Options{Credentials: staticCredentials(testCredentials())}); err == nil {
+		t.Error("NewClient() without a region error = nil, want error")
+	}
+	if _, err := NewClient(Options{Region: "us-east-1"}); err == nil {
+		t.Error("NewClient() without credentials error = nil, want error")
+	}
+}
+
+func TestLegacyProviderExtractText(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, request *http.Request) {
+		if got := request.Header.Get("Authorization"); !strings.Contains(got, "Credential=AKIDEXAMPLE/") || !strings.Contains(got, "/eu-central-1/bedrock/aws4_request") {
+			t.Errorf("Authorization = %q", got)
+		}
+		if got := request.URL.EscapedPath(); got != "/model/amazon.nova-pro-v1%3A0/converse" {
+			t.Errorf("path = %q", got)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"output":{"message":{"content":[{"text":"café 世界"}]}},"stopReason":"end_turn","usage":{"inputTokens":21,"outputTokens":6}}`))
+	}))
+	defer server.Close()
+
+	setEnvironment(t, "eu-central-1", "AKIDEXAMPLE", "secret")
+	t.Setenv("AWS_ENDPOINT_URL_BEDROCK_RUNTIME", server.URL)
+	t.Setenv("BEDROCK_MODEL", "amazon.nova-pro-v1:0")
+	// eval's OpenAI default falls back to BEDROCK_MODEL
+	config := providers.Config{Model: "gpt-4o", Prompt: "Transcribe"}
+	text, usage, err := New().ExtractText(context.Background(), config, "page.png", base64.StdEncoding.EncodeToString([]byte("image")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text != "café 世界" || usage.InputTokens != 21 || usage.OutputTokens != 6 {
+		t.Fatalf("unexpected result: %q %#v", text, usage)
+	}
+}
+
+func TestLegacyProviderValidation(t *testing.T) {
+	tests := []struct {
+		name      string
+		region    string
+		accessKey string
+		secretKey string
+		model     string
+		envModel  string
+		wantKind  providers.ErrorKind
+	}{
+		{name: "valid", region: "us-east-1", accessKey: "AKID", secretKey: "secret", model: "amazon.nova-pro-v1:0"},
+		{name: "missing region", accessKey: "AKID", secretKey: "secret", model: "amazon.nova-pro-v1:0", wantKind: providers.ErrorInvalidRequest},
+		{name: "missing access key", region: "us-east-1", secretKey: "secret", model: "amazon.nova-pro-v1:0", wantKind: providers.ErrorAuthentication},
+		{name: "missing secret key", region: "us-east-1", accessKey: "AKID", model: "amazon.nova-pro-v1:0", wantKind: providers.ErrorAuthentication},
+		{name: "missing model", region: "us-east-1", accessKey: "AKID", secretKey: "secret", wantKind: providers.ErrorInvalidRequest},
+		{name: "eval default model", region: "us-east-1", accessKey: "AKID", secretKey: "secret", model: "gpt-4o", wantKind: providers.ErrorInvalidRequest},
+		{name: "model from environment", region: "us-east-1", accessKey: "AKID", secretKey: "secret", model: "gpt-4o", envModel: "amazon.nova-pro-v1:0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setEnvironment(t, tt.region, tt.accessKey, tt.secretKey)
+			t.Setenv("BEDROCK_MODEL", tt.envModel)
+			err := New().ValidateConfig(providers.Config{Model: tt.model})
+			if tt.wantKind == "" {
+				if err != nil {
+					t.Fatalf("ValidateConfig() error = %v", err)
+				}
+				return
+			}
+			var providerError *providers.Error
+			if !errors.As(err, &providerError) || providerError.Kind != tt.wantKind {
+				t.Fatalf("ValidateConfig() error = %v, want %s", err, tt.wantKind)
+			}
+		})
+	}
+}
+
+func TestProviderCapabilities(t *testing.T) {
+	provider := New()
+	if provider.Name() != "bedrock" {
+		t.Fatalf("Name() = %q", provider.Name())
+	}
+	want := providers.Capabilities{ReportsTokenUsage: true, SupportsTemperature: true, SupportsCustomPrompt: true}
+	if got := provider.Capabilities(); got != want {
+		t.Errorf("Capabilities() = %+v, want %+v", got, want)
+	}
+}
+
+// setEnvironment sets the AWS variables the CLI adapter reads and clears the
+// fallbacks, so the host environment cannot leak into a test.
+func setEnvironment(t *testing.T, region, accessKey, secretKey string) {
+	t.Helper()
+	t.Setenv("AWS_REGION", region)
+	t.Setenv("AWS_DEFAULT_REGION", "")
+	t.Setenv("AWS_ACCESS_KEY_ID", accessKey)
+	t.Setenv("AWS_SECRET_ACCESS_KEY", secretKey)
+	t.Setenv("AWS_SESSION_TOKEN", "")
+	t.Setenv("AWS_ENDPOINT_URL_BEDROCK_RUNTIME", "")
+	t.Setenv("AWS_ENDPOINT_URL", "")
+	t.Setenv("BEDROCK_MODEL", "")
+}
+
+func testRequest(image []byte) providers.Request {
+	return providers.Request{
+		Model:       "anthropic.claude-3-5-sonnet-20240620-v1:0",
+		Prompt:      "Transcribe café",
+		Temperature: 0.2,
+		Image: providers.Image{
+			Data:      image,
+			MediaType: "image/png",
+			Filename:  "page.png",
+		},
+	}
+}
+
+func testCredentials() awssigv4.Credentials {
+	return awssigv4.Credentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "private-secret"}
+}
+
+func staticCredentials(credentials awssigv4.Credentials) CredentialSource {
+	return func(context.Context) (awssigv4.Credentials, error) { return credentials, nil }
+}
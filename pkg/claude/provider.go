@@ -3,19 +3,34 @@ package claude
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
-	"mime"
+	"log/slog"
 	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
+	"github.com/lehigh-university-libraries/htr/pkg/httpclient"
 	"github.com/lehigh-university-libraries/htr/pkg/providers"
 )
 
+const (
+	defaultBaseURL = "https://api.anthropic.com"
+	messagesPath   = "/v1/messages"
+	// defaultTimeout applies when the config does not set a timeout.
+	defaultTimeout = 2 * time.Minute
+	// defaultMaxTokens applies when the config does not set MaxTokens.
+	defaultMaxTokens = 4096
+)
+
 // Provider implements the Anthropic Claude vision provider
-type Provider struct{}
+type Provider struct {
+	providers.BaseProvider
+}
 
 // Response represents an Anthropic API response
 type Response struct {
@@ -46,9 +61,25 @@ func (p *Provider) ValidateConfig(config providers.Config) error {
 	if apiKey == "" {
 		return fmt.Errorf("ANTHROPIC_API_KEY environment variable not set")
 	}
+	if _, err := messagesEndpoint(config); err != nil {
+		return fmt.Errorf("invalid ANTHROPIC_BASE_URL: %w", err)
+	}
 	return nil
 }
 
+// messagesEndpoint appends the Messages API path to config.BaseURL, then
+// ANTHROPIC_BASE_URL, and otherwise the public API root.
+func messagesEndpoint(config providers.Config) (string, error) {
+	baseURL := strings.TrimSpace(config.BaseURL)
+	if baseURL == "" {
+		baseURL = strings.TrimSpace(os.Getenv("ANTHROPIC_BASE_URL"))
+	}
+	if baseURL == "" {
+		baseURL = defaultBaseURL
+	}
+	return httpclient.AppendPath(baseURL, messagesPath)
+}
+
 // ExtractText extracts text from an image using Claude's vision API
 func (p *Provider) ExtractText(ctx context.Context, config providers.Config, imagePath, imageBase64 string) (string, providers.UsageInfo, error) {
 	apiKey := os.Getenv("ANTHROPIC_API_KEY")
@@ -56,16 +87,20 @@ func (p *Provider) ExtractText(ctx context.Context, config providers.Config, ima
 		return "", providers.UsageInfo{}, fmt.Errorf("ANTHROPIC_API_KEY environment variable not set")
 	}
 
-	// Determine media type (Claude uses "media_type" instead of "mime_type")
-	mediaType := mime.TypeByExtension(filepath.Ext(imagePath))
-	if mediaType == "" {
-		mediaType = "image/jpeg"
+	// Determine media type (Claude uses "media_type" instead of "mime_type").
+	// The first 512 bytes are enough to sniff it.
+	head, _ := base64.StdEncoding.DecodeString(imageBase64[:min(len(imageBase64), 684)])
+	mediaType := providers.ImageMediaType(imagePath, head)
+
+	maxTokens := config.MaxTokens
+	if maxTokens <= 0 {
+		maxTokens = defaultMaxTokens
 	}
 
 	// Prepare request body for Claude API
 	requestBody := map[string]interface{}{
 		"model":      config.Model,
-		"max_tokens": 4096,
+		"max_tokens": maxTokens,
 		"messages": []map[string]interface{}{
 			{
 				"role": "user",
@@ -87,6 +122,11 @@ func (p *Provider) ExtractText(ctx context.Context, config providers.Config, ima
 		},
 	}
 
+	// Claude takes the system prompt as a top-level field, not a message
+	if config.SystemPrompt != "" {
+		requestBody["system"] = config.SystemPrompt
+	}
+
 	// Add temperature if specified
 	if config.Temperature > 0 {
 		requestBody["temperature"] = config.Temperature
@@ -97,8 +137,13 @@ func (p *Provider) ExtractText(ctx context.Context, config providers.Config, ima
 		return "", providers.UsageInfo{}, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
+	endpoint, err := messagesEndpoint(config)
+	if err != nil {
+		return "", providers.UsageInfo{}, fmt.Errorf("invalid ANTHROPIC_BASE_URL: %w", err)
+	}
+
 	// Make API request
-	req, err := http.NewRequestWithContext(ctx, "POST", "https://api.anthropic.com/v1/messages", bytes.NewBuffer(requestJSON))
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewBuffer(requestJSON))
 	if err != nil {
 		return "", providers.UsageInfo{}, err
 	}
@@ -107,10 +152,14 @@ func (p *Provider) ExtractText(ctx context.Context, config providers.Config, ima
 	req.Header.Set("x-api-key", apiKey)
 	req.Header.Set("anthropic-version", "2023-06-01")
 
-	client := &http.Client{Timeout: config.Timeout}
+	timeout := config.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	client := &http.Client{Transport: httpclient.SharedTransport(), Timeout: timeout}
 	resp, err := client.Do(req)
 	if err != nil {
-		return "", providers.UsageInfo{}, err
+		return "", providers.UsageInfo{}, providers.ErrorForRequest(ctx, err)
 	}
 	defer resp.Body.Close()
 
@@ -121,7 +170,7 @@ func (p *Provider) ExtractText(ctx context.Context, config providers.Config, ima
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return "", providers.UsageInfo{}, fmt.Errorf("claude API error: %d - %s", resp.StatusCode, string(body))
+		return "", providers.UsageInfo{}, fmt.Errorf("claude API error: %w - body: %s", providers.ErrorForStatus(resp.StatusCode), providers.TruncateBody(body))
 	}
 
 	var claudeResp Response
@@ -146,9 +195,15 @@ func (p *Provider) ExtractText(ctx context.Context, config providers.Config, ima
 		return "", providers.UsageInfo{}, fmt.Errorf("no text content in Claude response - body: %s", providers.TruncateBody(body))
 	}
 
+	if claudeResp.StopReason == "max_tokens" {
+		slog.Warn("Claude response was cut off at the max_tokens limit; raise --max-tokens for complete transcriptions",
+			"model", config.Model, "max_tokens", maxTokens, "image", filepath.Base(imagePath))
+	}
+
 	usage := providers.UsageInfo{
 		InputTokens:  claudeResp.Usage.InputTokens,
 		OutputTokens: claudeResp.Usage.OutputTokens,
+		Truncated:    claudeResp.StopReason == "max_tokens",
 	}
 
 	return providers.ProcessResponse(p, extractedText), usage, nil
@@ -1,12 +1,17 @@
 package claude
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/lehigh-university-libraries/htr/pkg/providers"
 )
@@ -74,8 +79,10 @@ func TestProvider_ExtractText(t *testing.T) {
 		serverResponse string
 		statusCode     int
 		expectedText   string
+		expectedUsage  providers.UsageInfo
 		expectError    bool
 		errorContains  string
+		wantErr        error
 	}{
 		{
 			name:       "successful response",
@@ -87,10 +94,12 @@ func TestProvider_ExtractText(t *testing.T) {
 						"text": "This is extracted text from the image"
 					}
 				],
-				"stop_reason": "end_turn"
+				"stop_reason": "end_turn",
+				"usage": {"input_tokens": 1568, "output_tokens": 37}
 			}`,
-			expectedText: "This is extracted text from the image",
-			expectError:  false,
+			expectedText:  "This is extracted text from the image",
+			expectedUsage: providers.UsageInfo{InputTokens: 1568, OutputTokens: 37},
+			expectError:   false,
 		},
 		{
 			name:       "response with cleaning needed",
@@ -117,7 +126,32 @@ func TestProvider_ExtractText(t *testing.T) {
 				}
 			}`,
 			expectError:   true,
-			errorContains: "claude API error",
+			errorContains: "claude API error: provider request failed: invalid_request (status 400)",
+			wantErr:       providers.ErrBadRequest,
+		},
+		{
+			name:           "rate limited",
+			statusCode:     http.StatusTooManyRequests,
+			serverResponse: `{"error": {"type": "rate_limit_error", "message": "Rate limited"}}`,
+			expectError:    true,
+			errorContains:  "(status 429)",
+			wantErr:        providers.ErrRateLimited,
+		},
+		{
+			name:           "rejected key",
+			statusCode:     http.StatusUnauthorized,
+			serverResponse: `{"error": {"type": "authentication_error", "message": "invalid x-api-key"}}`,
+			expectError:    true,
+			errorContains:  "(status 401)",
+			wantErr:        providers.ErrAuth,
+		},
+		{
+			name:           "overloaded",
+			statusCode:     529,
+			serverResponse: `{"error": {"type": "overloaded_error", "message": "Overloaded"}}`,
+			expectError:    true,
+			errorContains:  "(status 529)",
+			wantErr:        providers.ErrTransient,
 		},
 		{
 			name:       "empty content",
@@ -158,14 +192,17 @@ func TestProvider_ExtractText(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			// Create mock server
 			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-				// Verify request method and headers
+				// Verify request method, path, and headers
 				if r.Method != http.MethodPost {
 					t.Errorf("Expected POST request, got %s", r.Method)
 				}
+				if r.URL.Path != "/v1/messages" {
+					t.Errorf("Expected /v1/messages, got %s", r.URL.Path)
+				}
 				if r.Header.Get("Content-Type") != "application/json" {
 					t.Errorf("Expected application/json content type")
 				}
-				if r.Header.Get("x-api-key") == "" {
+				if r.Header.Get("x-api-key") != "sk-ant-test-key" {
 					t.Errorf("Expected x-api-key header")
 				}
 				if r.Header.Get("anthropic-version") == "" {
@@ -193,17 +230,65 @@ func TestProvider_ExtractText(t *testing.T) {
 			}))
 			defer server.Close()
 
-			// Set environment variable
-			original := os.Getenv("ANTHROPIC_API_KEY")
-			defer os.Setenv("ANTHROPIC_API_KEY", original)
-			os.Setenv("ANTHROPIC_API_KEY", "sk-ant-test-key")
+			t.Setenv("ANTHROPIC_API_KEY", "sk-ant-test-key")
+			t.Setenv("ANTHROPIC_BASE_URL", server.URL)
 
-			if tt.statusCode == http.StatusOK && !tt.expectError {
-				p := New()
-				cleaned := providers.ProcessResponse(p, tt.expectedText)
-				if cleaned != tt.expectedText {
-					t.Logf("Response cleaning changed text from '%s' to '%s'", tt.expectedText, cleaned)
+			config := providers.Config{
+				Provider: "claude",
+				Model:    "claude-3-5-sonnet-20241022",
+				Prompt:   "Extract text",
+				Timeout:  5 * time.Second,
+			}
+			text, usage, err := New().ExtractText(context.Background(), config, "page.png", "aW1hZ2U=")
+
+			if tt.expectError {
+				if err == nil {
+					t.Fatal("Expected error but got none")
+				}
+				if tt.errorContains != "" && !strings.Contains(err.Error(), tt.errorContains) {
+					t.Errorf("Expected error to contain '%s', got: %v", tt.errorContains, err)
+				}
+				if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+					t.Errorf("Expected error to match %q, got: %v", tt.wantErr, err)
 				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error but got: %v", err)
+			}
+			if text != tt.expectedText {
+				t.Errorf("Expected text '%s', got '%s'", tt.expectedText, text)
+			}
+			if usage != tt.expectedUsage {
+				t.Errorf("Expected usage %+v, got %+v", tt.expectedUsage, usage)
+			}
+		})
+	}
+}
+
+func TestMessagesEndpoint(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     string
+		config  providers.Config
+		want    string
+		wantErr bool
+	}{
+		{name: "public API by default", want: "https://api.anthropic.com/v1/messages"},
+		{name: "environment base URL", env: "https://proxy.example.org/anthropic/", want: "https://proxy.example.org/anthropic/v1/messages"},
+		{name: "config base URL wins", env: "https://proxy.example.org", config: providers.Config{BaseURL: "http://localhost:8080"}, want: "http://localhost:8080/v1/messages"},
+		{name: "invalid base URL", env: "proxy.example.org", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("ANTHROPIC_BASE_URL", tt.env)
+			got, err := messagesEndpoint(tt.config)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("messagesEndpoint() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("messagesEndpoint() = %q, want %q", got, tt.want)
 			}
 		})
 	}
@@ -262,3 +347,123 @@ func TestCleanResponse(t *testing.T) {
 		})
 	}
 }
+
+func TestProviderCapabilities(t *testing.T) {
+	want := providers.Capabilities{ReportsTokenUsage: true, SupportsTemperature: true, SupportsCustomPrompt: true}
+	if got := New().Capabilities(); got != want {
+		t.Errorf("Capabilities() = %+v, want %+v", got, want)
+	}
+}
+
+func TestProvider_ExtractTextHonorsTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	t.Setenv("ANTHROPIC_API_KEY", "sk-ant-test-key")
+	t.Setenv("ANTHROPIC_BASE_URL", server.URL)
+
+	config := providers.Config{Model: "claude-3-5-sonnet-20241022", Prompt: "Extract text", Timeout: 100 * time.Millisecond}
+	start := time.Now()
+	_, _, err := New().ExtractText(context.Background(), config, "page.png", "aW1hZ2U=")
+	if err == nil {
+		t.Fatal("Expected timeout error but got none")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("ExtractText took %v, want it bounded by the 100ms timeout", elapsed)
+	}
+}
+
+func TestProvider_ExtractTextSystemPrompt(t *testing.T) {
+	tests := []struct {
+		name         string
+		systemPrompt string
+	}{
+		{name: "no system prompt"},
+		{name: "system prompt", systemPrompt: "You are a paleographer."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var reqBody map[string]interface{}
+				if err := json.NewDecoder(r.Body).Decode(&reqBody); err != nil {
+					t.Fatal(err)
+				}
+				system, ok := reqBody["system"]
+				if ok != (tt.systemPrompt != "") || (ok && system != tt.systemPrompt) {
+					t.Errorf("Expected system %q, got %v (present %v)", tt.systemPrompt, system, ok)
+				}
+				_, _ = w.Write([]byte(`{"content": [{"type": "text", "text": "Transcription"}], "stop_reason": "end_turn"}`))
+			}))
+			defer server.Close()
+
+			t.Setenv("ANTHROPIC_API_KEY", "sk-ant-test-key")
+			t.Setenv("ANTHROPIC_BASE_URL", server.URL)
+
+			config := providers.Config{Model: "claude-3-5-sonnet-20241022", Prompt: "Extract text", SystemPrompt: tt.systemPrompt}
+			if _, _, err := New().ExtractText(context.Background(), config, "page.png", "aW1hZ2U="); err != nil {
+				t.Fatalf("Expected no error but got: %v", err)
+			}
+		})
+	}
+}
+
+func TestProvider_ExtractTextMaxTokens(t *testing.T) {
+	tests := []struct {
+		name          string
+		maxTokens     int
+		stopReason    string
+		wantMaxTokens float64
+		wantWarning   bool
+	}{
+		{name: "default limit", stopReason: "end_turn", wantMaxTokens: 4096},
+		{name: "configured limit", maxTokens: 16000, stopReason: "end_turn", wantMaxTokens: 16000},
+		{name: "truncated response warns", maxTokens: 10, stopReason: "max_tokens", wantMaxTokens: 10, wantWarning: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var reqBody map[string]interface{}
+				if err := json.NewDecoder(r.Body).Decode(&reqBody); err != nil {
+					t.Fatal(err)
+				}
+				if got := reqBody["max_tokens"]; got != tt.wantMaxTokens {
+					t.Errorf("Expected max_tokens %v, got %v", tt.wantMaxTokens, got)
+				}
+				_, _ = w.Write([]byte(`{"content": [{"type": "text", "text": "Partial transcription"}], "stop_reason": "` + tt.stopReason + `"}`))
+			}))
+			defer server.Close()
+
+			t.Setenv("ANTHROPIC_API_KEY", "sk-ant-test-key")
+			t.Setenv("ANTHROPIC_BASE_URL", server.URL)
+
+			var logs bytes.Buffer
+			original := slog.Default()
+			t.Cleanup(func() { slog.SetDefault(original) })
+			slog.SetDefault(slog.New(slog.NewTextHandler(&logs, nil)))
+
+			config := providers.Config{Model: "claude-3-5-sonnet-20241022", Prompt: "Extract text", MaxTokens: tt.maxTokens}
+			text, usage, err := New().ExtractText(context.Background(), config, "page.png", "aW1hZ2U=")
+			if err != nil {
+				t.Fatalf("Expected no error but got: %v", err)
+			}
+			if usage.Truncated != tt.wantWarning {
+				t.Errorf("Expected Truncated %v, got %v", tt.wantWarning, usage.Truncated)
+			}
+			if text != "Partial transcription" {
+				t.Errorf("Expected the truncated text to be returned, got '%s'", text)
+			}
+			if warned := strings.Contains(logs.String(), "max_tokens limit"); warned != tt.wantWarning {
+				t.Errorf("Expected warning %v, got logs: %s", tt.wantWarning, logs.String())
+			}
+		})
+	}
+}
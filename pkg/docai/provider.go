@@ -0,0 +1,284 @@
+// Package docai provides a Google Document AI transcription client.
+package docai
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lehigh-university-libraries/htr/pkg/auth/gcpaccesstoken"
+	"github.com/lehigh-university-libraries/htr/pkg/httpclient"
+	"github.com/lehigh-university-libraries/htr/pkg/providers"
+)
+
+const (
+	defaultTimeout          = 2 * time.Minute
+	defaultMaxImageBytes    = 20 << 20
+	defaultMaxRequestBytes  = 40 << 20
+	defaultMaxResponseBytes = 64 << 20
+	maxCredentialFileBytes  = 64 << 10
+)
+
+var resourceIDPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,127}$`)
+
+// CredentialSource returns an OAuth access token for one request.
+type CredentialSource func(context.Context) (string, error)
+
+// Options configures a Client. Constructors do not read environment variables.
+type Options struct {
+	HTTPClient *http.Client
+	// Endpoint is the service base URL. It defaults to the regional
+	// https://<location>-documentai.googleapis.com endpoint.
+	Endpoint         string
+	ProjectID        string
+	Location         string
+	ProcessorID      string
+	AccessToken      CredentialSource
+	Timeout          time.Duration
+	MaxImageBytes    int64
+	MaxRequestBytes  int64
+	MaxResponseBytes int64
+}
+
+// Client sends images to a Document AI processor.
+type Client struct {
+	httpClient       *http.Client
+	endpoint         string
+	processorID      string
+	accessToken      CredentialSource
+	maxImageBytes    int64
+	maxRequestBytes  int64
+	maxResponseBytes int64
+}
+
+// Provider is the historical CLI adapter. New integrations should use Client.
+type Provider struct {
+	mu     sync.Mutex
+	tokens *gcpaccesstoken.Source
+	path   string
+}
+
+type processRequest struct {
+	RawDocument rawDocument `json:"rawDocument"`
+}
+
+type rawDocument struct {
+	Content  string `json:"content"`
+	MimeType string `json:"mimeType"`
+}
+
+type processResponse struct {
+	Document struct {
+		Text  string            `json:"text"`
+		Pages []json.RawMessage `json:"pages"`
+	} `json:"document"`
+}
+
+// NewClient constructs a secure Document AI client from explicit dependencies.
+func NewClient(options Options) (*Client, error) {
+	if !resourceIDPattern.MatchString(options.ProjectID) || !resourceIDPattern.MatchString(options.Location) ||
+		!resourceIDPattern.MatchString(options.ProcessorID) || options.AccessToken == nil {
+		return nil, providers.NewError(providers.ErrorInvalidRequest, 0, false, nil)
+	}
+	base := options.Endpoint
+	if base == "" {
+		base = "https://" + options.Location + "-documentai.googleapis.com"
+	}
+	endpoint, err := httpclient.AppendPath(base, "/v1/projects/"+options.ProjectID+"/locations/"+options.Location+"/processors/"+options.ProcessorID+":process")
+	if err != nil {
+		return nil, providers.NewError(providers.ErrorInvalidRequest, 0, false, nil)
+	}
+	return &Client{
+		httpClient:       httpclient.Secure(options.HTTPClient, durationOr(options.Timeout, defaultTimeout)),
+		endpoint:         endpoint,
+		processorID:      options.ProcessorID,
+		accessToken:      options.AccessToken,
+		maxImageBytes:    positiveOr(options.MaxImageBytes, defaultMaxImageBytes),
+		maxRequestBytes:  positiveOr(options.MaxRequestBytes, defaultMaxRequestBytes),
+		maxResponseBytes: positiveOr(options.MaxResponseBytes, defaultMaxResponseBytes),
+	}, nil
+}
+
+// Name returns the provider name.
+func (c *Client) Name() string { return "docai" }
+
+// Extract transcribes an encoded image. Document AI processors do not take a
+// prompt or model, so only the image is validated and sent.
+func (c *Client) Extract(ctx context.Context, request providers.Request) (providers.Result, error) {
+	if err := providers.ValidateImage(request.Image, c.maxImageBytes); err != nil {
+		return providers.Result{}, err
+	}
+	if err := ctx.Err(); err != nil {
+		return providers.Result{}, providers.ErrorForRequest(ctx, err)
+	}
+	mediaType, err := providers.CanonicalMediaType(request.Image.MediaType)
+	if err != nil {
+		return providers.Result{}, err
+	}
+	credential, err := c.accessToken(ctx)
+	if err != nil {
+		return providers.Result{}, providers.ErrorForAuthentication(ctx, err)
+	}
+	body, err := json.Marshal(processRequest{RawDocument: rawDocument{
+		Content:  base64.StdEncoding.EncodeToString(request.Image.Data),
+		MimeType: mediaType,
+	}})
+	if err != nil || int64(len(body)) > c.maxRequestBytes {
+		return providers.Result{}, providers.NewError(providers.ErrorInvalidRequest, 0, false, nil)
+	}
+
+	httpRequest, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return providers.Result{}, providers.NewError(providers.ErrorInvalidRequest, 0, false, nil)
+	}
+	httpRequest.Header.Set("Content-Type", "application/json")
+	if err := httpclient.StaticBearer(credential).Authorize(ctx, httpRequest); err != nil {
+		return providers.Result{}, providers.ErrorForAuthentication(ctx, err)
+	}
+
+	response, err := c.httpClient.Do(httpRequest)
+	if err != nil {
+		return providers.Result{}, providers.ErrorForRequest(ctx, err)
+	}
+	defer response.Body.Close()
+	responseBody, err := httpclient.ReadAll(response.Body, c.maxResponseBytes)
+	if err != nil {
+		if errors.Is(err, httpclient.ErrResponseTooLarge) {
+			return providers.Result{}, providers.NewError(providers.ErrorResponseTooLarge, response.StatusCode, false, nil)
+		}
+		return providers.Result{}, providers.ErrorForRequest(ctx, err)
+	}
+	if response.StatusCode < http.StatusOK || response.StatusCode >= http.StatusMultipleChoices {
+		return providers.Result{}, providers.ErrorForStatus(response.StatusCode)
+	}
+
+	var decoded processResponse
+	if err := json.Unmarshal(responseBody, &decoded); err != nil || strings.TrimSpace(decoded.Document.Text) == "" {
+		return providers.Result{}, providers.NewError(providers.ErrorInvalidResponse, response.StatusCode, false, nil)
+	}
+	effectiveModel := request.Model
+	if effectiveModel == "" {
+		effectiveModel = c.processorID
+	}
+	return providers.Result{
+		Text:           strings.TrimSpace(decoded.Document.Text),
+		Usage:          providers.UsageInfo{Pages: len(decoded.Document.Pages)},
+		EffectiveModel: effectiveModel,
+	}, nil
+}
+
+// New creates the historical CLI adapter.
+func New() *Provider { return &Provider{} }
+
+// Name returns the provider name.
+func (p *Provider) Name() string { return "docai" }
+
+// Capabilities reports that Document AI is billed per page and sends neither
+// the prompt nor the temperature to the processor.
+func (p *Provider) Capabilities() providers.Capabilities {
+	return providers.Capabilities{ReportsPageUsage: true}
+}
+
+// MediaTypes returns the image formats Document AI processors accept, which
+// include TIFF and BMP.
+func (p *Provider) MediaTypes() []string {
+	return []string{"image/jpeg", "image/png", "image/gif", "image/tiff", "image/bmp", "image/webp"}
+}
+
+// ValidateConfig validates environment-backed CLI configuration: the service
+// account credentials, processor location, and processor ID.
+func (p *Provider) ValidateConfig(providers.Config) error {
+	tokens, err := p.tokenSource()
+	if err != nil {
+		return err
+	}
+	if !resourceIDPattern.MatchString(projectID(tokens)) ||
+		!resourceIDPattern.MatchString(strings.TrimSpace(os.Getenv("DOCAI_LOCATION"))) ||
+		!resourceIDPattern.MatchString(strings.TrimSpace(os.Getenv("DOCAI_PROCESSOR_ID"))) {
+		return providers.NewError(providers.ErrorInvalidRequest, 0, false, nil)
+	}
+	return nil
+}
+
+// ExtractText adapts historical base64 CLI inputs to Client.
+func (p *Provider) ExtractText(ctx context.Context, config providers.Config, imagePath, imageBase64 string) (string, providers.UsageInfo, error) {
+	request, err := providers.LegacyRequest(config, imagePath, imageBase64, defaultMaxImageBytes)
+	if err != nil {
+		return "", providers.UsageInfo{}, err
+	}
+	tokens, err := p.tokenSource()
+	if err != nil {
+		return "", providers.UsageInfo{}, err
+	}
+	client, err := NewClient(Options{
+		ProjectID:   projectID(tokens),
+		Location:    strings.TrimSpace(os.Getenv("DOCAI_LOCATION")),
+		ProcessorID: strings.TrimSpace(os.Getenv("DOCAI_PROCESSOR_ID")),
+		AccessToken: tokens.Token,
+		Timeout:     config.Timeout,
+	})
+	if err != nil {
+		return "", providers.UsageInfo{}, err
+	}
+	result, err := client.Extract(ctx, request)
+	return result.Text, result.Usage, err
+}
+
+// tokenSource loads the service account named by GOOGLE_APPLICATION_CREDENTIALS,
+// reusing the cached source while the path is unchanged.
+func (p *Provider) tokenSource() (*gcpaccesstoken.Source, error) {
+	path := strings.TrimSpace(os.Getenv("GOOGLE_APPLICATION_CREDENTIALS"))
+	if path == "" {
+		return nil, providers.NewError(providers.ErrorAuthentication, 0, false, nil)
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.tokens != nil && p.path == path {
+		return p.tokens, nil
+	}
+
+	info, err := os.Stat(path)
+	if err != nil || info.Size() > maxCredentialFileBytes {
+		return nil, providers.NewError(providers.ErrorAuthentication, 0, false, nil)
+	}
+	credentials, err := os.ReadFile(path)
+	if err != nil {
+		return nil, providers.NewError(providers.ErrorAuthentication, 0, false, nil)
+	}
+	tokens, err := gcpaccesstoken.New(gcpaccesstoken.Options{Credentials: credentials})
+	if err != nil {
+		return nil, providers.NewError(providers.ErrorAuthentication, 0, false, nil)
+	}
+	p.tokens, p.path = tokens, path
+	return tokens, nil
+}
+
+// projectID prefers DOCAI_PROJECT_ID and falls back to the service account's project.
+func projectID(tokens *gcpaccesstoken.Source) string {
+	if project := strings.TrimSpace(os.Getenv("DOCAI_PROJECT_ID")); project != "" {
+		return project
+	}
+	return tokens.ProjectID()
+}
+
+func positiveOr(value, fallback int64) int64 {
+	if value > 0 {
+		return value
+	}
+	return fallback
+}
+
+func durationOr(value, fallback time.Duration) time.Duration {
+	if value > 0 {
+		return value
+	}
+	return fallback
+}
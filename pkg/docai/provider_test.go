@@ -0,0 +1,195 @@
+package docai
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lehigh-university-libraries/htr/pkg/providers"
+)
+
+var _ providers.Client = (*Client)(nil)
+var _ providers.Provider = (*Provider)(nil)
+
+func TestClientExtract(t *testing.T) {
+	t.Parallel()
+	image := []byte("encoded-image")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, request *http.Request) {
+		if request.Method != http.MethodPost || request.URL.Path != "/v1/projects/htr-project/locations/us/processors/abc123:process" {
+			t.Errorf("unexpected request target: %s %s", request.Method, request.URL.Path)
+		}
+		if got := request.Header.Get("Authorization"); got != "Bearer access-token" {
+			t.Errorf("Authorization = %q", got)
+		}
+		var body processRequest
+		if err := json.NewDecoder(request.Body).Decode(&body); err != nil {
+			t.Fatal(err)
+		}
+		if body.RawDocument.MimeType != "image/png" || body.RawDocument.Content != base64.StdEncoding.EncodeToString(image) {
+			t.Fatalf("unexpected process request: %#v", body)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"document":{"text":"Dear sir,\nI remain\n","pages":[{"pageNumber":1}]}}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(testOptions(server.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := client.Extract(context.Background(), testRequest(image))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Text != "Dear sir,\nI remain" || result.Usage.Pages != 1 || result.EffectiveModel != "abc123" {
+		t.Fatalf("unexpected result: %#v", result)
+	}
+}
+
+func TestClientErrorsAreTypedAndRedacted(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		want   providers.ErrorKind
+	}{
+		{"rate limited", http.StatusTooManyRequests, "credential=upstream-secret", providers.ErrorRateLimited},
+		{"permission denied", http.StatusForbidden, "credential=upstream-secret", providers.ErrorAuthentication},
+		{"empty document", http.StatusOK, `{"document":{"text":"  "}}`, providers.ErrorInvalidResponse},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(test.status)
+				_, _ = w.Write([]byte(test.body))
+			}))
+			defer server.Close()
+			client, err := NewClient(testOptions(server.URL))
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = client.Extract(context.Background(), testRequest([]byte("image")))
+			var providerError *providers.Error
+			if !errors.As(err, &providerError) || providerError.Kind != test.want {
+				t.Fatalf("err = %v, want %s", err, test.want)
+			}
+			if strings.Contains(err.Error(), "upstream-secret") || strings.Contains(err.Error(), server.URL) {
+				t.Fatalf("error leaked sensitive data: %q", err)
+			}
+		})
+	}
+}
+
+func TestNewClientRejectsInvalidOptions(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		mutate func(*Options)
+	}{
+		{"missing processor", func(o *Options) { o.ProcessorID = "" }},
+		{"path in location", func(o *Options) { o.Location = "us/../eu" }},
+		{"missing credentials", func(o *Options) { o.AccessToken = nil }},
+		{"bad endpoint", func(o *Options) { o.Endpoint = "ftp://example.com" }},
+	}
+	for _, test := range tests {
+		options := testOptions("https://us-documentai.googleapis.com")
+		test.mutate(&options)
+		if _, err := NewClient(options); err == nil {
+			t.Errorf("NewClient(%s) succeeded", test.name)
+		}
+	}
+}
+
+func TestLegacyProviderValidation(t *testing.T) {
+	provider := New()
+	if provider.Name() != "docai" {
+		t.Fatalf("Name() = %q", provider.Name())
+	}
+	t.Setenv("GOOGLE_APPLICATION_CREDENTIALS", "")
+	t.Setenv("DOCAI_PROJECT_ID", "")
+	t.Setenv("DOCAI_LOCATION", "us")
+	t.Setenv("DOCAI_PROCESSOR_ID", "abc123")
+	if err := provider.ValidateConfig(providers.Config{}); err == nil {
+		t.Fatal("expected missing credentials error")
+	}
+
+	t.Setenv("GOOGLE_APPLICATION_CREDENTIALS", writeCredentials(t))
+	if err := provider.ValidateConfig(providers.Config{}); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("DOCAI_PROCESSOR_ID", "")
+	if err := provider.ValidateConfig(providers.Config{}); err == nil {
+		t.Fatal("expected missing processor error")
+	}
+	t.Setenv("DOCAI_PROCESSOR_ID", "abc123")
+	t.Setenv("DOCAI_LOCATION", "")
+	if err := provider.ValidateConfig(providers.Config{}); err == nil {
+		t.Fatal("expected missing location error")
+	}
+}
+
+func testOptions(endpoint string) Options {
+	return Options{
+		Endpoint:    endpoint,
+		ProjectID:   "htr-project",
+		Location:    "us",
+		ProcessorID: "abc123",
+		AccessToken: func(context.Context) (string, error) { return "access-token", nil },
+	}
+}
+
+func testRequest(image []byte) providers.Request {
+	return providers.Request{
+		Image: providers.Image{
+			Data:      image,
+			MediaType: "image/png",
+			Filename:  "page.png",
+		},
+	}
+}
+
+func writeCredentials(t *testing.T) string {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(map[string]string{
+		"type":         "service_account",
+		"project_id":   "htr-project",
+		"private_key":  string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		"client_email": "htr@example.iam.gserviceaccount.com",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "credentials.json")
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestProviderCapabilities(t *testing.T) {
+	want := providers.Capabilities{ReportsPageUsage: true}
+	if got := New().Capabilities(); got != want {
+		t.Errorf("Capabilities() = %+v, want %+v", got, want)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"errors"
+	"log/slog"
 	"net/http"
 	"os"
 	"strings"
@@ -55,11 +56,14 @@ type Client struct {
 }
 
 // Provider is the historical CLI adapter. New integrations should use Client.
-type Provider struct{}
+type Provider struct {
+	providers.BaseProvider
+}
 
 type generateRequest struct {
-	Contents         []content        `json:"contents"`
-	GenerationConfig generationConfig `json:"generationConfig"`
+	SystemInstruction *content         `json:"systemInstruction,omitempty"`
+	Contents          []content        `json:"contents"`
+	GenerationConfig  generationConfig `json:"generationConfig"`
 }
 
 type content struct {
@@ -161,6 +165,7 @@ func (c *Client) Extract(ctx context.Context, request providers.Request) (provid
 		if finishReason == "MAX_TOKENS" && c.mediaResolutionFallback {
 			next := nextResolution(resolution)
 			if next != "" {
+				slog.Warn("Gemini response hit MAX_TOKENS, retrying at lower media resolution", "from", resolution, "to", next)
 				resolution = next
 				continue
 			}
@@ -185,6 +190,9 @@ func (c *Client) extractOnce(ctx context.Context, endpoint, credential string, r
 		}}},
 		GenerationConfig: configuration,
 	}
+	if request.SystemPrompt != "" {
+		payload.SystemInstruction = &content{Parts: []part{{Text: request.SystemPrompt}}}
+	}
 	body, err := json.Marshal(payload)
 	if err != nil || int64(len(body)) > c.maxRequestBytes {
 		return providers.Result{}, "", providers.NewError(providers.ErrorInvalidRequest, 0, false, nil)
@@ -234,6 +242,7 @@ func (c *Client) extractOnce(ctx context.Context, endpoint, credential string, r
 		Usage: providers.UsageInfo{
 			InputTokens:  decoded.Usage.PromptTokens,
 			OutputTokens: decoded.Usage.CandidateTokens,
+			Truncated:    decoded.Candidates[0].FinishReason == "MAX_TOKENS",
 		},
 		EffectiveModel: effectiveModel,
 	}, decoded.Candidates[0].FinishReason, nil
@@ -245,11 +254,20 @@ func New() *Provider { return &Provider{} }
 // Name returns the provider name.
 func (p *Provider) Name() string { return "gemini" }
 
+// MediaTypes returns the image formats Gemini accepts, which include HEIC
+// and HEIF but not GIF.
+func (p *Provider) MediaTypes() []string {
+	return []string{"image/jpeg", "image/png", "image/webp", "image/heic", "image/heif"}
+}
+
 // ValidateConfig validates environment-backed CLI configuration.
-func (p *Provider) ValidateConfig(providers.Config) error {
+func (p *Provider) ValidateConfig(config providers.Config) error {
 	if strings.TrimSpace(os.Getenv("GEMINI_API_KEY")) == "" {
 		return providers.NewError(providers.ErrorAuthentication, 0, false, nil)
 	}
+	if _, err := httpclient.ParseEndpoint(resolveBaseURL(config)); err != nil {
+		return providers.NewError(providers.ErrorInvalidRequest, 0, false, nil)
+	}
 	return nil
 }
 
@@ -263,6 +281,7 @@ func (p *Provider) ExtractText(ctx context.Context, config providers.Config, ima
 		return "", providers.UsageInfo{}, err
 	}
 	client, err := NewClient(Options{
+		Endpoint: resolveBaseURL(config),
 		APIKey: func(context.Context) (string, error) {
 			key := os.Getenv("GEMINI_API_KEY")
 			if strings.TrimSpace(key) == "" {
@@ -282,6 +301,19 @@ func (p *Provider) ExtractText(ctx context.Context, config providers.Config, ima
 	return result.Text, result.Usage, err
 }
 
+// resolveBaseURL returns config.BaseURL, then GEMINI_BASE_URL, and otherwise
+// the public API root. The base URL includes the API version, for example
+// https://generativelanguage.googleapis.com/v1beta.
+func resolveBaseURL(config providers.Config) string {
+	if baseURL := strings.TrimSpace(config.BaseURL); baseURL != "" {
+		return baseURL
+	}
+	if environmentURL := strings.TrimSpace(os.Getenv("GEMINI_BASE_URL")); environmentURL != "" {
+		return environmentURL
+	}
+	return defaultEndpoint
+}
+
 func validResolution(value string) bool {
 	switch value {
 	case "", "MEDIA_RESOLUTION_UNSPECIFIED", "MEDIA_RESOLUTION_HIGH", "MEDIA_RESOLUTION_MEDIUM", "MEDIA_RESOLUTION_LOW":
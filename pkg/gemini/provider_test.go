@@ -6,6 +6,7 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"slices"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -48,6 +49,33 @@ func TestClientExtractUsesHeaderCredentialAndIgnoresThoughts(t *testing.T) {
 	}
 }
 
+func TestClientExtractSendsSystemInstruction(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, request *http.Request) {
+		var body generateRequest
+		if err := json.NewDecoder(request.Body).Decode(&body); err != nil {
+			t.Fatal(err)
+		}
+		if body.SystemInstruction == nil || len(body.SystemInstruction.Parts) != 1 || body.SystemInstruction.Parts[0].Text != "You are a paleographer." {
+			t.Fatalf("systemInstruction = %#v", body.SystemInstruction)
+		}
+		if len(body.Contents) != 1 || len(body.Contents[0].Parts) != 2 || body.Contents[0].Parts[0].Text != "Transcribe café" {
+			t.Fatalf("contents = %#v", body.Contents)
+		}
+		_, _ = w.Write([]byte(`{"candidates":[{"finishReason":"STOP","content":{"parts":[{"text":"text"}]}}]}`))
+	}))
+	defer server.Close()
+	client, err := NewClient(Options{Endpoint: server.URL, APIKey: staticKey("key")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	request := testRequest([]byte("image"))
+	request.SystemPrompt = "You are a paleographer."
+	if _, err := client.Extract(context.Background(), request); err != nil {
+		t.Fatal(err)
+	}
+}
+
 func TestClientResolutionFallback(t *testing.T) {
 	t.Parallel()
 	var mu sync.Mutex
@@ -76,7 +104,7 @@ func TestClientResolutionFallback(t *testing.T) {
 		t.Fatal(err)
 	}
 	result, err := client.Extract(context.Background(), testRequest([]byte("image")))
-	if err != nil || result.Text != "complete" {
+	if err != nil || result.Text != "complete" || result.Usage.Truncated {
 		t.Fatalf("result = %#v, error = %v", result, err)
 	}
 	mu.Lock()
@@ -86,6 +114,52 @@ func TestClientResolutionFallback(t *testing.T) {
 	}
 }
 
+func TestClientResolutionFallbackStopsAtLowest(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name            string
+		fallback        bool
+		wantResolutions []string
+	}{
+		{"fallback disabled", false, []string{"MEDIA_RESOLUTION_HIGH"}},
+		{"fallback exhausted", true, []string{"MEDIA_RESOLUTION_HIGH", "MEDIA_RESOLUTION_MEDIUM", "MEDIA_RESOLUTION_LOW"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			var mu sync.Mutex
+			var resolutions []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, request *http.Request) {
+				var body generateRequest
+				if err := json.NewDecoder(request.Body).Decode(&body); err != nil {
+					t.Fatal(err)
+				}
+				mu.Lock()
+				resolutions = append(resolutions, body.GenerationConfig.MediaResolution)
+				mu.Unlock()
+				_, _ = w.Write([]byte(`{"candidates":[{"finishReason":"MAX_TOKENS","content":{"parts":[{"text":"partial"}]}}]}`))
+			}))
+			defer server.Close()
+			client, err := NewClient(Options{
+				Endpoint: server.URL, APIKey: staticKey("key"),
+				MediaResolution: "MEDIA_RESOLUTION_HIGH", MediaResolutionFallback: test.fallback,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			result, err := client.Extract(context.Background(), testRequest([]byte("image")))
+			if err != nil || result.Text != "partial" || !result.Usage.Truncated {
+				t.Fatalf("result = %#v, error = %v", result, err)
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if !slices.Equal(resolutions, test.wantResolutions) {
+				t.Fatalf("resolution attempts = %#v, want %#v", resolutions, test.wantResolutions)
+			}
+		})
+	}
+}
+
 func TestClientErrorsAreRedactedAndRedirectSafe(t *testing.T) {
 	t.Parallel()
 	secret := "sensitive-upstream-response"
@@ -144,6 +218,71 @@ func TestLegacyProviderValidation(t *testing.T) {
 	}
 }
 
+func TestLegacyProviderExtractTextUsesBaseURL(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		body       string
+		wantText   string
+		wantUsage  providers.UsageInfo
+		wantKind   providers.ErrorKind
+		retryable  bool
+	}{
+		{
+			name:       "success",
+			statusCode: http.StatusOK,
+			body:       `{"modelVersion":"gemini-2.5-flash","candidates":[{"finishReason":"STOP","content":{"parts":[{"text":"Dear Sir"}]}}],"usageMetadata":{"promptTokenCount":1290,"candidatesTokenCount":17}}`,
+			wantText:   "Dear Sir",
+			wantUsage:  providers.UsageInfo{InputTokens: 1290, OutputTokens: 17},
+		},
+		{name: "rate limited", statusCode: http.StatusTooManyRequests, body: `{"error":{"code":429}}`, wantKind: providers.ErrorRateLimited, retryable: true},
+		{name: "server error", statusCode: http.StatusServiceUnavailable, body: `{"error":{"code":503}}`, wantKind: providers.ErrorUpstream, retryable: true},
+		{name: "bad request", statusCode: http.StatusBadRequest, body: `{"error":{"code":400}}`, wantKind: providers.ErrorInvalidRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, request *http.Request) {
+				if request.URL.Path != "/v1beta/models/gemini-2.5-flash:generateContent" {
+					t.Errorf("unexpected request URL: %s", request.URL.String())
+				}
+				if got := request.Header.Get("x-goog-api-key"); got != "env-key" {
+					t.Errorf("API key header = %q", got)
+				}
+				w.WriteHeader(tt.statusCode)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			t.Setenv("GEMINI_API_KEY", "env-key")
+			t.Setenv("GEMINI_BASE_URL", server.URL+"/v1beta")
+			config := providers.Config{Model: "gemini-2.5-flash", Prompt: "Transcribe"}
+			text, usage, err := New().ExtractText(context.Background(), config, "page.png", "aW1hZ2U=")
+			if tt.wantKind != "" {
+				var providerError *providers.Error
+				if !errors.As(err, &providerError) || providerError.Kind != tt.wantKind || providerError.Retryable != tt.retryable {
+					t.Fatalf("error = %v, want %s (retryable %v)", err, tt.wantKind, tt.retryable)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if text != tt.wantText || usage != tt.wantUsage {
+				t.Fatalf("ExtractText() = %q, %#v; want %q, %#v", text, usage, tt.wantText, tt.wantUsage)
+			}
+		})
+	}
+}
+
+func TestLegacyProviderRejectsInvalidBaseURL(t *testing.T) {
+	t.Setenv("GEMINI_API_KEY", "key")
+	t.Setenv("GEMINI_BASE_URL", "generativelanguage.googleapis.com")
+	if err := New().ValidateConfig(providers.Config{}); err == nil {
+		t.Fatal("expected invalid base URL error")
+	}
+}
+
 func testRequest(image []byte) providers.Request {
 	return providers.Request{
 		Model:       "gemini-2.5-pro",
@@ -156,3 +295,10 @@ func testRequest(image []byte) providers.Request {
 func staticKey(value string) CredentialSource {
 	return func(context.Context) (string, error) { return value, nil }
 }
+
+func TestProviderCapabilities(t *testing.T) {
+	want := providers.Capabilities{ReportsTokenUsage: true, SupportsTemperature: true, SupportsCustomPrompt: true}
+	if got := New().Capabilities(); got != want {
+		t.Errorf("Capabilities() = %+v, want %+v", got, want)
+	}
+}
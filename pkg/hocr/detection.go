@@ -11,6 +11,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
 	"sort"
 	"strings"
 	"time"
@@ -33,14 +34,32 @@ func DetectWordBoundariesCustom(imagePath string) (OCRResponse, error) {
 	slog.Info("Custom word detection completed", "word_count", len(words), "image_size", fmt.Sprintf("%dx%d", width, height))
 
 	// Step 2: Group words into lines based on coordinates
-	lines := groupWordsIntoLines(words)
-	slog.Info("Grouped words into lines", "line_count", len(lines))
+	lines := orderLines(words, LineOrder)
+	slog.Info("Grouped words into lines", "line_count", len(lines), "reading_order", LineOrder)
 
 	// Step 3: Convert to OCR response format
 	return convertWordsAndLinesToOCRResponse(lines, width, height), nil
 }
 
+// getImageDimensions reads the width and height from the image header. Formats
+// Go cannot decode (such as TIFF) fall back to ImageMagick's identify.
 func getImageDimensions(imagePath string) (int, int, error) {
+	file, err := os.Open(imagePath)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to open image: %w", err)
+	}
+	config, _, decodeErr := image.DecodeConfig(file)
+	file.Close()
+	if decodeErr == nil {
+		return config.Width, config.Height, nil
+	}
+
+	slog.Debug("Falling back to ImageMagick for image dimensions", "path", imagePath, "err", decodeErr)
+	return identifyImageDimensions(imagePath)
+}
+
+// identifyImageDimensions reads image dimensions with ImageMagick.
+func identifyImageDimensions(imagePath string) (int, int, error) {
 	cmd := exec.Command("magick", "identify", "-format", "%w %h", imagePath)
 	output, err := cmd.Output()
 	if err != nil {
@@ -57,12 +76,17 @@ func getImageDimensions(imagePath string) (int, int, error) {
 }
 
 func detectWords(imagePath string, imgWidth, imgHeight int) ([]WordBox, error) {
+	tempDir, err := newRunTempDir("htr-detect-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tempDir)
+
 	// Preprocess the image
-	processedPath, err := preprocessImageForWordDetection(imagePath)
+	processedPath, err := preprocessImageForWordDetection(imagePath, tempDir)
 	if err != nil {
 		return nil, fmt.Errorf("failed to preprocess image: %w", err)
 	}
-	defer os.Remove(processedPath)
 
 	// Load processed image
 	file, err := os.Open(processedPath)
@@ -85,18 +109,83 @@ func detectWords(imagePath string, imgWidth, imgHeight int) ([]WordBox, error) {
 	return wordBoxes, nil
 }
 
-func preprocessImageForWordDetection(imagePath string) (string, error) {
-	tempDir := "/tmp"
-	baseName := strings.TrimSuffix(filepath.Base(imagePath), filepath.Ext(imagePath))
-	processedPath := filepath.Join(tempDir, fmt.Sprintf("processed_words_%s_%d.jpg", baseName, time.Now().Unix()))
+// PreprocessOptions selects the ImageMagick operations applied to an image
+// before word detection. The enabled operations compose into a single magick
+// invocation, always followed by a light sharpen and a morphological close
+// that joins broken strokes.
+type PreprocessOptions struct {
+	// Deskew straightens rotated scans. Word boxes are then detected on the
+	// straightened image, so it suits scans with only slight skew.
+	Deskew bool
+	// Grayscale converts the image to a single gray channel.
+	Grayscale bool
+	// ContrastStretch is the black-point and white-point clip passed to
+	// -contrast-stretch, such as "0.15x0.05%". Empty skips the stretch.
+	ContrastStretch string
+	// Threshold is the -threshold level that binarizes the image, such as
+	// "75%". Empty leaves the image unthresholded.
+	Threshold string
+}
+
+// DefaultPreprocessing is the pipeline tuned for typical handwritten pages.
+var DefaultPreprocessing = PreprocessOptions{
+	Grayscale:       true,
+	ContrastStretch: "0.15x0.05%",
+	Threshold:       "75%",
+}
+
+// Preprocessing is applied by DetectWordBoundariesCustom. It defaults to
+// DefaultPreprocessing.
+var Preprocessing = DefaultPreprocessing
 
-	cmd := exec.Command("magick", imagePath,
-		"-colorspace", "Gray",
-		"-contrast-stretch", "0.15x0.05%",
+// deskewThreshold is the -deskew threshold ImageMagick recommends for most scans.
+const deskewThreshold = "40%"
+
+var (
+	contrastStretchPattern = regexp.MustCompile(`^\d+(\.\d+)?%?(x\d+(\.\d+)?%?)?$`)
+	thresholdPattern       = regexp.MustCompile(`^\d+(\.\d+)?%?$`)
+)
+
+// Validate rejects values ImageMagick would misread, including values that
+// look like command-line options.
+func (o PreprocessOptions) Validate() error {
+	if o.ContrastStretch != "" && !contrastStretchPattern.MatchString(o.ContrastStretch) {
+		return fmt.Errorf("invalid contrast stretch %q: use BLACKxWHITE, such as 0.15x0.05%%", o.ContrastStretch)
+	}
+	if o.Threshold != "" && !thresholdPattern.MatchString(o.Threshold) {
+		return fmt.Errorf("invalid threshold %q: use a level such as 75%%", o.Threshold)
+	}
+	return nil
+}
+
+// magickArgs returns the magick arguments that read inputPath, apply the
+// enabled operations in a fixed order, and write outputPath.
+func (o PreprocessOptions) magickArgs(inputPath, outputPath string) []string {
+	args := []string{inputPath}
+	if o.Deskew {
+		args = append(args, "-deskew", deskewThreshold, "+repage")
+	}
+	if o.Grayscale {
+		args = append(args, "-colorspace", "Gray")
+	}
+	if o.ContrastStretch != "" {
+		args = append(args, "-contrast-stretch", o.ContrastStretch)
+	}
+	args = append(args,
 		"-sharpen", "0x1",
 		"-morphology", "close", "rectangle:2x1",
-		"-threshold", "75%",
-		processedPath)
+	)
+	if o.Threshold != "" {
+		args = append(args, "-threshold", o.Threshold)
+	}
+	return append(args, outputPath)
+}
+
+func preprocessImageForWordDetection(imagePath, tempDir string) (string, error) {
+	baseName := strings.TrimSuffix(filepath.Base(imagePath), filepath.Ext(imagePath))
+	processedPath := filepath.Join(tempDir, fmt.Sprintf("processed_words_%s_%d.jpg", baseName, time.Now().Unix()))
+
+	cmd := exec.Command("magick", Preprocessing.magickArgs(imagePath, processedPath)...)
 
 	if err := cmd.Run(); err != nil {
 		return "", fmt.Errorf("imagemagick preprocessing failed: %w", err)
@@ -304,6 +393,80 @@ func groupWordsIntoLines(words []WordBox) []LineBox {
 	return lines
 }
 
+// ReadingOrder controls the order in which detected lines are emitted.
+type ReadingOrder string
+
+const (
+	// ReadingOrderSingle reads the page as one column, top to bottom.
+	ReadingOrderSingle ReadingOrder = "single"
+	// ReadingOrderColumns splits the page into columns at vertical gutters and
+	// reads each column top to bottom, left column first.
+	ReadingOrderColumns ReadingOrder = "columns"
+)
+
+// LineOrder is applied by DetectWordBoundariesCustom. It defaults to
+// ReadingOrderSingle.
+var LineOrder = ReadingOrderSingle
+
+// ParseReadingOrder returns the reading order named by value.
+func ParseReadingOrder(value string) (ReadingOrder, error) {
+	switch order := ReadingOrder(value); order {
+	case ReadingOrderSingle, ReadingOrderColumns:
+		return order, nil
+	}
+	return "", fmt.Errorf("invalid reading order %q: use single or columns", value)
+}
+
+// orderLines groups words into lines and returns them in reading order. With
+// ReadingOrderColumns, lines are grouped within each column separately, so
+// words side by side in neighboring columns never join the same line.
+func orderLines(words []WordBox, order ReadingOrder) []LineBox {
+	if order != ReadingOrderColumns {
+		return groupWordsIntoLines(words)
+	}
+
+	var lines []LineBox
+	for _, column := range splitIntoColumns(words) {
+		lines = append(lines, groupWordsIntoLines(column)...)
+	}
+	return lines
+}
+
+// splitIntoColumns clusters words by their horizontal extent and returns the
+// columns left to right. Taken in order of their left edge, a word starts a new
+// column when it begins more than one average word height past the right edge
+// of every word before it, so only a gutter that no line crosses splits the
+// page. A heading spanning the gutter keeps the page as a single column.
+func splitIntoColumns(words []WordBox) [][]WordBox {
+	if len(words) == 0 {
+		return nil
+	}
+
+	sorted := append([]WordBox(nil), words...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].X < sorted[j].X
+	})
+
+	gutter := 0
+	for _, word := range sorted {
+		gutter += word.Height
+	}
+	gutter /= len(sorted)
+
+	var columns [][]WordBox
+	column := []WordBox{sorted[0]}
+	right := sorted[0].X + sorted[0].Width
+	for _, word := range sorted[1:] {
+		if word.X-right > gutter {
+			columns = append(columns, column)
+			column = nil
+		}
+		column = append(column, word)
+		right = max(right, word.X+word.Width)
+	}
+	return append(columns, column)
+}
+
 func wordsOnSameLine(currentLineWords []WordBox, newWord WordBox) bool {
 	if len(currentLineWords) == 0 {
 		return true
@@ -1,6 +1,16 @@
 package hocr
 
 import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 )
 
@@ -195,6 +205,106 @@ func TestGroupWordsIntoLines(t *testing.T) {
 	}
 }
 
+// twoColumnWords lays out two columns of three lines, two words per line, with
+// the right column's lines offset a little lower than the left column's.
+func twoColumnWords() []WordBox {
+	var words []WordBox
+	for i, y := range []int{10, 50, 90} {
+		words = append(words,
+			WordBox{X: 10, Y: y, Width: 40, Height: 20, Text: fmt.Sprintf("L%da", i+1)},
+			WordBox{X: 60, Y: y, Width: 40, Height: 20, Text: fmt.Sprintf("L%db", i+1)},
+			WordBox{X: 300, Y: y + 8, Width: 40, Height: 20, Text: fmt.Sprintf("R%da", i+1)},
+			WordBox{X: 350, Y: y + 8, Width: 40, Height: 20, Text: fmt.Sprintf("R%db", i+1)},
+		)
+	}
+	return words
+}
+
+func lineTexts(lines []LineBox) []string {
+	texts := make([]string, len(lines))
+	for i, line := range lines {
+		words := make([]string, len(line.Words))
+		for j, word := range line.Words {
+			words[j] = word.Text
+		}
+		texts[i] = strings.Join(words, " ")
+	}
+	return texts
+}
+
+func TestOrderLines(t *testing.T) {
+	tests := []struct {
+		name  string
+		words []WordBox
+		order ReadingOrder
+		want  []string
+	}{
+		{
+			name:  "two columns read as one",
+			words: twoColumnWords(),
+			order: ReadingOrderSingle,
+			want:  []string{"L1a L1b R1a R1b", "L2a L2b R2a R2b", "L3a L3b R3a R3b"},
+		},
+		{
+			name:  "two columns read left column first",
+			words: twoColumnWords(),
+			order: ReadingOrderColumns,
+			want:  []string{"L1a L1b", "L2a L2b", "L3a L3b", "R1a R1b", "R2a R2b", "R3a R3b"},
+		},
+		{
+			name: "ragged single column",
+			words: []WordBox{
+				{X: 10, Y: 10, Width: 200, Height: 20, Text: "long"},
+				{X: 10, Y: 50, Width: 40, Height: 20, Text: "short"},
+				{X: 120, Y: 90, Width: 40, Height: 20, Text: "indented"},
+			},
+			order: ReadingOrderColumns,
+			want:  []string{"long", "short", "indented"},
+		},
+		{
+			name: "heading spanning the gutter",
+			words: append(twoColumnWords()[:4],
+				WordBox{X: 10, Y: -30, Width: 380, Height: 20, Text: "Heading"},
+			),
+			order: ReadingOrderColumns,
+			want:  []string{"Heading", "L1a L1b R1a R1b"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := lineTexts(orderLines(tt.words, tt.order))
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("orderLines() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConvertWordsAndLinesToOCRResponseKeepsColumnOrder(t *testing.T) {
+	response := convertWordsAndLinesToOCRResponse(orderLines(twoColumnWords(), ReadingOrderColumns), 400, 120)
+
+	var got []Vertex
+	for _, paragraph := range response.Responses[0].FullTextAnnotation.Pages[0].Blocks[0].Paragraphs {
+		got = append(got, paragraph.Words[0].BoundingBox.Vertices[0])
+	}
+	want := []Vertex{{X: 10, Y: 10}, {X: 10, Y: 50}, {X: 10, Y: 90}, {X: 300, Y: 18}, {X: 300, Y: 58}, {X: 300, Y: 98}}
+	if !slices.Equal(got, want) {
+		t.Errorf("line origins = %v, want %v", got, want)
+	}
+}
+
+func TestParseReadingOrder(t *testing.T) {
+	for _, value := range []string{"single", "columns"} {
+		if order, err := ParseReadingOrder(value); err != nil || string(order) != value {
+			t.Errorf("ParseReadingOrder(%q) = %q, %v", value, order, err)
+		}
+	}
+	if _, err := ParseReadingOrder("rows"); err == nil {
+		t.Error("ParseReadingOrder(\"rows\") error = nil, want error")
+	}
+}
+
 func TestWordsOnSameLine(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -383,3 +493,131 @@ type testColor struct {
 func (c testColor) RGBA() (r, g, b, a uint32) {
 	return c.r, c.g, c.b, 65535
 }
+
+func TestGetImageDimensionsDecodesNatively(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, 37, 19))
+	encoders := map[string]func(*bytes.Buffer) error{
+		"page.png": func(buf *bytes.Buffer) error { return png.Encode(buf, img) },
+		"page.jpg": func(buf *bytes.Buffer) error { return jpeg.Encode(buf, img, nil) },
+		"page.gif": func(buf *bytes.Buffer) error { return gif.Encode(buf, img, nil) },
+	}
+
+	for name, encode := range encoders {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := encode(&buf); err != nil {
+				t.Fatalf("failed to encode %s: %v", name, err)
+			}
+			path := filepath.Join(t.TempDir(), name)
+			if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			// Clear PATH so the test fails if ImageMagick is invoked.
+			t.Setenv("PATH", "")
+			width, height, err := getImageDimensions(path)
+			if err != nil {
+				t.Fatalf("getImageDimensions() error = %v", err)
+			}
+			if width != 37 || height != 19 {
+				t.Errorf("getImageDimensions() = %dx%d, want 37x19", width, height)
+			}
+		})
+	}
+}
+
+func TestGetImageDimensionsErrors(t *testing.T) {
+	t.Setenv("PATH", "")
+
+	if _, _, err := getImageDimensions(filepath.Join(t.TempDir(), "missing.png")); err == nil {
+		t.Error("getImageDimensions() on missing file error = nil, want error")
+	}
+
+	path := filepath.Join(t.TempDir(), "notes.txt")
+	if err := os.WriteFile(path, []byte("not an image"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := getImageDimensions(path); err == nil {
+		t.Error("getImageDimensions() on undecodable file without ImageMagick error = nil, want error")
+	}
+}
+
+func TestPreprocessOptionsMagickArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		options PreprocessOptions
+		want    []string
+	}{
+		{
+			name:    "default preset",
+			options: DefaultPreprocessing,
+			want: []string{"in.png",
+				"-colorspace", "Gray",
+				"-contrast-stretch", "0.15x0.05%",
+				"-sharpen", "0x1",
+				"-morphology", "close", "rectangle:2x1",
+				"-threshold", "75%",
+				"out.jpg"},
+		},
+		{
+			name:    "deskew runs first",
+			options: PreprocessOptions{Deskew: true, Grayscale: true, Threshold: "60%"},
+			want: []string{"in.png",
+				"-deskew", "40%", "+repage",
+				"-colorspace", "Gray",
+				"-sharpen", "0x1",
+				"-morphology", "close", "rectangle:2x1",
+				"-threshold", "60%",
+				"out.jpg"},
+		},
+		{
+			name:    "all optional steps disabled",
+			options: PreprocessOptions{},
+			want: []string{"in.png",
+				"-sharpen", "0x1",
+				"-morphology", "close", "rectangle:2x1",
+				"out.jpg"},
+		},
+		{
+			name:    "custom contrast without threshold",
+			options: PreprocessOptions{ContrastStretch: "2x1%"},
+			want: []string{"in.png",
+				"-contrast-stretch", "2x1%",
+				"-sharpen", "0x1",
+				"-morphology", "close", "rectangle:2x1",
+				"out.jpg"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.options.magickArgs("in.png", "out.jpg")
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("magickArgs() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPreprocessOptionsValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		options PreprocessOptions
+		wantErr bool
+	}{
+		{name: "default preset", options: DefaultPreprocessing},
+		{name: "disabled steps", options: PreprocessOptions{}},
+		{name: "plain numbers", options: PreprocessOptions{ContrastStretch: "2", Threshold: "128"}},
+		{name: "contrast stretch option injection", options: PreprocessOptions{ContrastStretch: "-write"}, wantErr: true},
+		{name: "malformed contrast stretch", options: PreprocessOptions{ContrastStretch: "0.15x"}, wantErr: true},
+		{name: "malformed threshold", options: PreprocessOptions{Threshold: "high"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.options.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
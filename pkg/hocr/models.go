@@ -45,6 +45,23 @@ type BoundingPoly struct {
 	Vertices []Vertex `json:"vertices"`
 }
 
+// Bounds returns the axis-aligned box enclosing every vertex. Rotated text can
+// come back as a quad whose first vertex is not the top-left corner, so the
+// box is taken over all vertices rather than from vertices 0 and 2.
+func (p BoundingPoly) Bounds() (minX, minY, maxX, maxY int) {
+	if len(p.Vertices) == 0 {
+		return 0, 0, 0, 0
+	}
+
+	minX, minY = p.Vertices[0].X, p.Vertices[0].Y
+	maxX, maxY = minX, minY
+	for _, v := range p.Vertices[1:] {
+		minX, maxX = min(minX, v.X), max(maxX, v.X)
+		minY, maxY = min(minY, v.Y), max(maxY, v.Y)
+	}
+	return minX, minY, maxX, maxY
+}
+
 type Vertex struct {
 	X int `json:"x"`
 	Y int `json:"y"`
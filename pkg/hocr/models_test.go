@@ -0,0 +1,44 @@
+package hocr
+
+import "testing"
+
+func TestBoundingPolyBounds(t *testing.T) {
+	tests := []struct {
+		name                   string
+		vertices               []Vertex
+		minX, minY, maxX, maxY int
+	}{
+		{
+			name:     "axis aligned",
+			vertices: []Vertex{{X: 10, Y: 20}, {X: 50, Y: 20}, {X: 50, Y: 40}, {X: 10, Y: 40}},
+			minX:     10, minY: 20, maxX: 50, maxY: 40,
+		},
+		{
+			name:     "rotated 90 degrees",
+			vertices: []Vertex{{X: 50, Y: 20}, {X: 50, Y: 40}, {X: 10, Y: 40}, {X: 10, Y: 20}},
+			minX:     10, minY: 20, maxX: 50, maxY: 40,
+		},
+		{
+			name:     "rotated 180 degrees",
+			vertices: []Vertex{{X: 50, Y: 40}, {X: 10, Y: 40}, {X: 10, Y: 20}, {X: 50, Y: 20}},
+			minX:     10, minY: 20, maxX: 50, maxY: 40,
+		},
+		{
+			name:     "tilted quad",
+			vertices: []Vertex{{X: 12, Y: 30}, {X: 60, Y: 18}, {X: 64, Y: 34}, {X: 16, Y: 46}},
+			minX:     12, minY: 18, maxX: 64, maxY: 46,
+		},
+		{
+			name: "no vertices",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			minX, minY, maxX, maxY := BoundingPoly{Vertices: tt.vertices}.Bounds()
+			if minX != tt.minX || minY != tt.minY || maxX != tt.maxX || maxY != tt.maxY {
+				t.Errorf("Bounds() = %d %d %d %d, want %d %d %d %d", minX, minY, maxX, maxY, tt.minX, tt.minY, tt.maxX, tt.maxY)
+			}
+		})
+	}
+}
@@ -10,36 +10,122 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/lehigh-university-libraries/htr/internal/utils"
+	"github.com/lehigh-university-libraries/htr/pkg/imaging"
 	"github.com/lehigh-university-libraries/htr/pkg/providers"
 )
 
+// TempDir is the parent directory for the per-run temporary directories that
+// hold preprocessed and extracted word images. When empty, os.TempDir is used,
+// which honors $TMPDIR.
+var TempDir string
+
+// newRunTempDir creates a per-run temporary directory under TempDir. Callers
+// remove it with os.RemoveAll when done.
+func newRunTempDir(pattern string) (string, error) {
+	dir, err := os.MkdirTemp(TempDir, pattern)
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	return dir, nil
+}
+
 // WordImage represents an extracted word with its image data and metadata
 type WordImage struct {
 	Index       int
 	BoundingBox BoundingPoly
 	ImagePath   string
 	Text        string // Will be filled by transcription
+
+	word *Word // Source word in the OCR response
 }
 
 // TranscribeWordsIndividually extracts individual word images and transcribes each one
 func TranscribeWordsIndividually(imagePath string, response OCRResponse, provider providers.Provider, config providers.Config) (string, error) {
+	wordImages, err := transcribeWords(imagePath, response, provider, config)
+	if err != nil {
+		return "", err
+	}
+
+	// Build hOCR XML from transcribed words
+	return buildHOCRFromWords(wordImages), nil
+}
+
+// TranscribeWordsToResponse transcribes each word like TranscribeWordsIndividually
+// and returns a copy of the response whose words hold the transcribed text.
+// Words that could not be transcribed are left without symbols.
+func TranscribeWordsToResponse(imagePath string, response OCRResponse, provider providers.Provider, config providers.Config) (OCRResponse, error) {
+	response = cloneWithoutSymbols(response)
+	wordImages, err := transcribeWords(imagePath, response, provider, config)
+	if err != nil {
+		return OCRResponse{}, err
+	}
+
+	for _, wordImage := range wordImages {
+		if wordImage.Text == "" {
+			continue
+		}
+		wordImage.word.Symbols = []Symbol{{BoundingBox: wordImage.BoundingBox, Text: wordImage.Text}}
+	}
+	return response, nil
+}
+
+// cloneWithoutSymbols deep copies the page structure of a response, dropping the
+// symbols of every word so they can be replaced by transcriptions
+func cloneWithoutSymbols(response OCRResponse) OCRResponse {
+	clone := OCRResponse{Responses: make([]Response, len(response.Responses))}
+	for r, resp := range response.Responses {
+		if resp.FullTextAnnotation == nil {
+			continue
+		}
+		annotation := *resp.FullTextAnnotation
+		annotation.Pages = make([]Page, len(resp.FullTextAnnotation.Pages))
+		for p, page := range resp.FullTextAnnotation.Pages {
+			page.Blocks = append([]Block(nil), page.Blocks...)
+			for b := range page.Blocks {
+				block := &page.Blocks[b]
+				block.Paragraphs = append([]Paragraph(nil), block.Paragraphs...)
+				for g := range block.Paragraphs {
+					paragraph := &block.Paragraphs[g]
+					paragraph.Words = append([]Word(nil), paragraph.Words...)
+					for w := range paragraph.Words {
+						paragraph.Words[w].Symbols = nil
+					}
+				}
+			}
+			annotation.Pages[p] = page
+		}
+		clone.Responses[r].FullTextAnnotation = &annotation
+	}
+	return clone
+}
+
+// transcribeWords extracts and transcribes every word in the response, returning
+// the word images with their text filled in
+func transcribeWords(imagePath string, response OCRResponse, provider providers.Provider, config providers.Config) ([]WordImage, error) {
 	if len(response.Responses) == 0 || response.Responses[0].FullTextAnnotation == nil {
-		return "", fmt.Errorf("no text annotation in response")
+		return nil, fmt.Errorf("no text annotation in response")
 	}
 
-	tempDir := "/tmp"
+	tempDir, err := newRunTempDir("htr-words-*")
+	if err != nil {
+		return nil, err
+	}
+	// Clean up extracted images when done
+	defer os.RemoveAll(tempDir)
+
 	var wordImages []WordImage
-	var tempPaths []string
 
 	// Extract individual word images grouped by lines
 	wordIndex := 0
 	for _, page := range response.Responses[0].FullTextAnnotation.Pages {
 		for _, block := range page.Blocks {
 			for _, paragraph := range block.Paragraphs {
-				for _, word := range paragraph.Words {
+				for i := range paragraph.Words {
+					word := &paragraph.Words[i]
 					if len(word.BoundingBox.Vertices) < 4 {
 						continue
 					}
@@ -55,58 +141,79 @@ func TranscribeWordsIndividually(imagePath string, response OCRResponse, provide
 						Index:       wordIndex,
 						BoundingBox: word.BoundingBox,
 						ImagePath:   wordImagePath,
+						word:        word,
 					})
-					tempPaths = append(tempPaths, wordImagePath)
 					wordIndex++
 				}
 			}
 		}
 	}
 
-	// Clean up extracted images when done
-	defer func() {
-		for _, path := range tempPaths {
-			os.Remove(path)
-		}
-	}()
-
 	// Group words into lines for better context
 	lineGroups := groupWordsByLines(wordImages)
 
 	// Transcribe line by line for better context
+	transcribeLines(lineGroups, config.Concurrency, func(lineWords []*WordImage) {
+		transcribeLine(imagePath, lineWords, provider, config, tempDir)
+	})
+
+	return wordImages, nil
+}
+
+// transcribeLines calls transcribe for each line group using up to limit
+// concurrent workers. A limit below 1 transcribes the lines one at a time.
+// Each line group holds pointers to distinct words, so workers never write
+// to the same WordImage and word order is unaffected by completion order.
+func transcribeLines(lineGroups [][]*WordImage, limit int, transcribe func([]*WordImage)) {
+	if limit < 1 {
+		limit = 1
+	}
+
+	sem := make(chan struct{}, limit)
+	var wg sync.WaitGroup
 	for _, lineWords := range lineGroups {
-		if len(lineWords) == 1 {
-			// Single word - transcribe individually
-			text, err := transcribeWordImage(lineWords[0].ImagePath, provider, config)
-			if err != nil {
-				slog.Warn("Failed to transcribe word", "wordIndex", lineWords[0].Index, "error", utils.MaskSensitiveError(err))
-				lineWords[0].Text = ""
-			} else {
-				lineWords[0].Text = strings.TrimSpace(text)
-			}
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(lineWords []*WordImage) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			transcribe(lineWords)
+		}(lineWords)
+	}
+	wg.Wait()
+}
+
+// transcribeLine fills in the text of the words on a single line, sending the
+// whole line for context and falling back to individual words on failure.
+func transcribeLine(imagePath string, lineWords []*WordImage, provider providers.Provider, config providers.Config, tempDir string) {
+	if len(lineWords) == 1 {
+		// Single word - transcribe individually
+		text, err := transcribeWordImage(lineWords[0].ImagePath, provider, config)
+		if err != nil {
+			slog.Warn("Failed to transcribe word", "wordIndex", lineWords[0].Index, "error", utils.MaskSensitiveError(err))
+			lineWords[0].Text = ""
 		} else {
-			// Multiple words on same line - transcribe together for context
-			lineText, err := transcribeLineImage(imagePath, lineWords, provider, config, tempDir)
-			if err != nil {
-				slog.Warn("Failed to transcribe line", "wordCount", len(lineWords), "error", utils.MaskSensitiveError(err))
-				// Fall back to individual word transcription
-				for _, word := range lineWords {
-					text, err := transcribeWordImage(word.ImagePath, provider, config)
-					if err != nil {
-						word.Text = ""
-					} else {
-						word.Text = strings.TrimSpace(text)
-					}
+			lineWords[0].Text = strings.TrimSpace(text)
+		}
+	} else {
+		// Multiple words on same line - transcribe together for context
+		lineText, err := transcribeLineImage(imagePath, lineWords, provider, config, tempDir)
+		if err != nil {
+			slog.Warn("Failed to transcribe line", "wordCount", len(lineWords), "error", utils.MaskSensitiveError(err))
+			// Fall back to individual word transcription
+			for _, word := range lineWords {
+				text, err := transcribeWordImage(word.ImagePath, provider, config)
+				if err != nil {
+					word.Text = ""
+				} else {
+					word.Text = strings.TrimSpace(text)
 				}
-			} else {
-				// Distribute the line text across words
-				distributeLineTextToWords(lineText, lineWords)
 			}
+		} else {
+			// Distribute the line text across words
+			distributeLineTextToWords(lineText, lineWords)
 		}
 	}
-
-	// Build hOCR XML from transcribed words
-	return buildHOCRFromWords(wordImages), nil
 }
 
 // transcribeWordImage sends a single word image to the LLM for transcription
@@ -170,13 +277,12 @@ func buildHOCRFromWords(wordImages []WordImage) string {
 		escapedText := CleanProviderResponse(word.Text)
 
 		// Create hOCR line and word markup
+		minX, minY, maxX, maxY := word.BoundingBox.Bounds()
 		line := fmt.Sprintf(`<span class='ocrx_line' id='line_%d' title='bbox %d %d %d %d'><span class='ocrx_word' id='word_%d' title='bbox %d %d %d %d'>%s</span></span>`,
 			word.Index+1,
-			word.BoundingBox.Vertices[0].X, word.BoundingBox.Vertices[0].Y,
-			word.BoundingBox.Vertices[2].X, word.BoundingBox.Vertices[2].Y,
+			minX, minY, maxX, maxY,
 			word.Index+1,
-			word.BoundingBox.Vertices[0].X, word.BoundingBox.Vertices[0].Y,
-			word.BoundingBox.Vertices[2].X, word.BoundingBox.Vertices[2].Y,
+			minX, minY, maxX, maxY,
 			escapedText)
 
 		lines = append(lines, line)
@@ -199,11 +305,12 @@ func groupWordsByLines(words []WordImage) [][]*WordImage {
 
 	// Sort words by Y coordinate first, then X coordinate
 	sort.Slice(wordPtrs, func(i, j int) bool {
-		yDiff := abs(wordPtrs[i].BoundingBox.Vertices[0].Y - wordPtrs[j].BoundingBox.Vertices[0].Y)
-		if yDiff < 20 { // Same line threshold - 20 pixels
-			return wordPtrs[i].BoundingBox.Vertices[0].X < wordPtrs[j].BoundingBox.Vertices[0].X
+		xi, yi, _, _ := wordPtrs[i].BoundingBox.Bounds()
+		xj, yj, _, _ := wordPtrs[j].BoundingBox.Bounds()
+		if abs(yi-yj) < 20 { // Same line threshold - 20 pixels
+			return xi < xj
 		}
-		return wordPtrs[i].BoundingBox.Vertices[0].Y < wordPtrs[j].BoundingBox.Vertices[0].Y
+		return yi < yj
 	})
 
 	var lines [][]*WordImage
@@ -215,7 +322,9 @@ func groupWordsByLines(words []WordImage) [][]*WordImage {
 		} else {
 			// Check if this word is on the same line as the current line
 			lastWord := currentLine[len(currentLine)-1]
-			yDiff := abs(word.BoundingBox.Vertices[0].Y - lastWord.BoundingBox.Vertices[0].Y)
+			_, y, _, _ := word.BoundingBox.Bounds()
+			_, lastY, _, _ := lastWord.BoundingBox.Bounds()
+			yDiff := abs(y - lastY)
 
 			if yDiff < 20 { // Same line
 				currentLine = append(currentLine, word)
@@ -238,22 +347,12 @@ func groupWordsByLines(words []WordImage) [][]*WordImage {
 // transcribeLineImage extracts a line image and transcribes it for better context
 func transcribeLineImage(imagePath string, lineWords []*WordImage, provider providers.Provider, config providers.Config, tempDir string) (string, error) {
 	// Calculate line bounding box
-	minX, minY := lineWords[0].BoundingBox.Vertices[0].X, lineWords[0].BoundingBox.Vertices[0].Y
-	maxX, maxY := lineWords[0].BoundingBox.Vertices[2].X, lineWords[0].BoundingBox.Vertices[2].Y
+	minX, minY, maxX, maxY := lineWords[0].BoundingBox.Bounds()
 
 	for _, word := range lineWords[1:] {
-		if word.BoundingBox.Vertices[0].X < minX {
-			minX = word.BoundingBox.Vertices[0].X
-		}
-		if word.BoundingBox.Vertices[0].Y < minY {
-			minY = word.BoundingBox.Vertices[0].Y
-		}
-		if word.BoundingBox.Vertices[2].X > maxX {
-			maxX = word.BoundingBox.Vertices[2].X
-		}
-		if word.BoundingBox.Vertices[2].Y > maxY {
-			maxY = word.BoundingBox.Vertices[2].Y
-		}
+		wordMinX, wordMinY, wordMaxX, wordMaxY := word.BoundingBox.Bounds()
+		minX, minY = min(minX, wordMinX), min(minY, wordMinY)
+		maxX, maxY = max(maxX, wordMaxX), max(maxY, wordMaxY)
 	}
 
 	// Create line bounding box with padding
@@ -266,19 +365,29 @@ func transcribeLineImage(imagePath string, lineWords []*WordImage, provider prov
 		},
 	}
 
-	// Extract line image
-	lineImagePath, err := ExtractWordImage(imagePath, lineBbox, tempDir, 9999) // Use high index to avoid conflicts
+	// Extract line image into its own directory so lines transcribed
+	// concurrently never share a file name
+	lineDir, err := os.MkdirTemp(tempDir, "line-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create line directory: %w", err)
+	}
+	defer os.RemoveAll(lineDir)
+
+	lineImagePath, err := ExtractWordImage(imagePath, lineBbox, lineDir, 0)
 	if err != nil {
 		return "", err
 	}
-	defer os.Remove(lineImagePath)
 
 	// Read and encode line image
 	imageData, err := os.ReadFile(lineImagePath)
 	if err != nil {
 		return "", fmt.Errorf("failed to read line image: %w", err)
 	}
-	imageBase64 := base64.StdEncoding.EncodeToString(imageData)
+	lineImage, err := imaging.Downscale(imageData, config.MaxDimension)
+	if err != nil {
+		return "", fmt.Errorf("failed to downscale line image: %w", err)
+	}
+	imageBase64 := base64.StdEncoding.EncodeToString(lineImage.Data)
 
 	// Create line transcription prompt
 	lineConfig := config
@@ -378,16 +487,14 @@ func ExtractWordImage(imagePath string, bbox BoundingPoly, tempDir string, wordI
 		return "", fmt.Errorf("invalid bounding box")
 	}
 
-	minX := bbox.Vertices[0].X
-	minY := bbox.Vertices[0].Y
-	maxX := bbox.Vertices[2].X
-	maxY := bbox.Vertices[2].Y
-
+	minX, minY, maxX, maxY := bbox.Bounds()
 	width := maxX - minX
 	height := maxY - minY
 
+	// Degenerate boxes would make magick write an empty crop; callers log the
+	// error and skip the word
 	if width <= 0 || height <= 0 {
-		return "", fmt.Errorf("invalid dimensions")
+		return "", fmt.Errorf("bounding box at %d,%d has no area (%dx%d)", minX, minY, width, height)
 	}
 
 	// Add larger padding for better visibility
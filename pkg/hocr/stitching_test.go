@@ -1,7 +1,16 @@
 package hocr
 
 import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
+
+	"github.com/lehigh-university-libraries/htr/pkg/providers"
 )
 
 func TestGroupWordsByLines(t *testing.T) {
@@ -170,3 +179,173 @@ func TestBuildHOCRFromWords(t *testing.T) {
 		})
 	}
 }
+
+func TestBuildHOCRFromWordsRotatedBox(t *testing.T) {
+	result := buildHOCRFromWords([]WordImage{
+		{
+			BoundingBox: BoundingPoly{
+				Vertices: []Vertex{{X: 40, Y: 30}, {X: 10, Y: 30}, {X: 10, Y: 10}, {X: 40, Y: 10}},
+			},
+			Text: "hello",
+		},
+	})
+	if !strings.Contains(result, "title='bbox 10 10 40 30'") {
+		t.Errorf("buildHOCRFromWords() = %s, want bbox 10 10 40 30", result)
+	}
+}
+
+func TestExtractWordImageRejectsEmptyBox(t *testing.T) {
+	bbox := BoundingPoly{Vertices: []Vertex{{X: 10, Y: 10}, {X: 40, Y: 10}, {X: 40, Y: 10}, {X: 10, Y: 10}}}
+	_, err := ExtractWordImage("page.png", bbox, t.TempDir(), 0)
+	if err == nil || !strings.Contains(err.Error(), "no area (30x0)") {
+		t.Errorf("ExtractWordImage() error = %v, want no area error", err)
+	}
+}
+
+func TestCloneWithoutSymbols(t *testing.T) {
+	original := OCRResponse{
+		Responses: []Response{
+			{
+				FullTextAnnotation: &FullTextAnnotation{
+					Pages: []Page{{Blocks: []Block{{Paragraphs: []Paragraph{{Words: []Word{{Symbols: []Symbol{{Text: "line_1"}}}}}}}}}},
+				},
+			},
+		},
+	}
+
+	clone := cloneWithoutSymbols(original)
+	cloneWord := &clone.Responses[0].FullTextAnnotation.Pages[0].Blocks[0].Paragraphs[0].Words[0]
+	if len(cloneWord.Symbols) != 0 {
+		t.Fatalf("clone kept symbols %v", cloneWord.Symbols)
+	}
+	cloneWord.Symbols = []Symbol{{Text: "changed"}}
+	if got := original.Responses[0].FullTextAnnotation.Pages[0].Blocks[0].Paragraphs[0].Words[0].Symbols[0].Text; got != "line_1" {
+		t.Errorf("original symbol = %q, want %q", got, "line_1")
+	}
+}
+
+func TestNewRunTempDirUsesConfiguredParent(t *testing.T) {
+	parent := t.TempDir()
+	original := TempDir
+	t.Cleanup(func() { TempDir = original })
+	TempDir = parent
+
+	dir, err := newRunTempDir("htr-words-*")
+	if err != nil {
+		t.Fatalf("newRunTempDir() error = %v", err)
+	}
+	if filepath.Dir(dir) != parent {
+		t.Fatalf("newRunTempDir() = %q, want a directory under %q", dir, parent)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "word_img_0.png"), []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.RemoveAll(dir); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := os.ReadDir(parent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Fatalf("parent still contains %v after cleanup", entries)
+	}
+}
+
+func TestNewRunTempDirHonorsTMPDIR(t *testing.T) {
+	original := TempDir
+	t.Cleanup(func() { TempDir = original })
+	TempDir = ""
+
+	tmp := t.TempDir()
+	t.Setenv("TMPDIR", tmp)
+	dir, err := newRunTempDir("htr-detect-*")
+	if err != nil {
+		t.Fatalf("newRunTempDir() error = %v", err)
+	}
+	defer os.RemoveAll(dir)
+	if filepath.Dir(dir) != tmp {
+		t.Fatalf("newRunTempDir() = %q, want a directory under $TMPDIR %q", dir, tmp)
+	}
+}
+
+func TestTranscribeWordsRemovesTempDir(t *testing.T) {
+	parent := t.TempDir()
+	original := TempDir
+	t.Cleanup(func() { TempDir = original })
+	TempDir = parent
+
+	// Words without a full bounding box are skipped, so no ImageMagick call is made.
+	response := OCRResponse{Responses: []Response{{FullTextAnnotation: &FullTextAnnotation{
+		Pages: []Page{{Blocks: []Block{{Paragraphs: []Paragraph{{Words: []Word{{}}}}}}}},
+	}}}}
+	if _, err := transcribeWords("page.jpg", response, nil, providers.Config{}); err != nil {
+		t.Fatalf("transcribeWords() error = %v", err)
+	}
+
+	entries, err := os.ReadDir(parent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Fatalf("temp parent contains %v after transcribeWords, want it cleaned up", entries)
+	}
+}
+
+func TestTranscribeLinesPreservesOrder(t *testing.T) {
+	tests := []struct {
+		name      string
+		limit     int
+		maxActive int32
+	}{
+		{name: "sequential", limit: 1, maxActive: 1},
+		{name: "bounded", limit: 3, maxActive: 3},
+		{name: "limit below one is sequential", limit: 0, maxActive: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			const lineCount = 6
+			wordImages := make([]WordImage, lineCount)
+			lineGroups := make([][]*WordImage, lineCount)
+			for i := range wordImages {
+				wordImages[i].Index = i
+				lineGroups[i] = []*WordImage{&wordImages[i]}
+			}
+
+			var active, peak atomic.Int32
+			var mu sync.Mutex
+			var completed []int
+			transcribeLines(lineGroups, tt.limit, func(lineWords []*WordImage) {
+				current := active.Add(1)
+				for {
+					previous := peak.Load()
+					if current <= previous || peak.CompareAndSwap(previous, current) {
+						break
+					}
+				}
+				// Earlier lines take longer so they finish after later ones.
+				time.Sleep(time.Duration(lineCount-lineWords[0].Index) * 5 * time.Millisecond)
+				lineWords[0].Text = fmt.Sprintf("word%d", lineWords[0].Index)
+				active.Add(-1)
+
+				mu.Lock()
+				completed = append(completed, lineWords[0].Index)
+				mu.Unlock()
+			})
+
+			if len(completed) != lineCount {
+				t.Fatalf("transcribed %d lines, want %d", len(completed), lineCount)
+			}
+			if got := peak.Load(); got > tt.maxActive {
+				t.Errorf("peak concurrency = %d, want at most %d", got, tt.maxActive)
+			}
+			for i, word := range wordImages {
+				if want := fmt.Sprintf("word%d", i); word.Text != want {
+					t.Errorf("wordImages[%d].Text = %q, want %q (completion order %v)", i, word.Text, want, completed)
+				}
+			}
+		})
+	}
+}
@@ -19,15 +19,13 @@ func ConvertToBasicHOCR(response OCRResponse) string {
 			for _, paragraph := range block.Paragraphs {
 				for _, word := range paragraph.Words {
 					if len(word.BoundingBox.Vertices) >= 4 && len(word.Symbols) > 0 {
-						bbox := word.BoundingBox
+						minX, minY, maxX, maxY := word.BoundingBox.Bounds()
 						text := word.Symbols[0].Text
 						line := fmt.Sprintf(`<span class='ocrx_line' id='line_%d' title='bbox %d %d %d %d'><span class='ocrx_word' id='word_%d' title='bbox %d %d %d %d'>%s</span></span>`,
 							wordIndex+1,
-							bbox.Vertices[0].X, bbox.Vertices[0].Y,
-							bbox.Vertices[2].X, bbox.Vertices[2].Y,
+							minX, minY, maxX, maxY,
 							wordIndex+1,
-							bbox.Vertices[0].X, bbox.Vertices[0].Y,
-							bbox.Vertices[2].X, bbox.Vertices[2].Y,
+							minX, minY, maxX, maxY,
 							text)
 						lines = append(lines, line)
 						wordIndex++
@@ -40,6 +38,50 @@ func ConvertToBasicHOCR(response OCRResponse) string {
 	return WrapInHOCRDocument(strings.Join(lines, "\n"))
 }
 
+// ExtractPlainText returns the reading-order text of an OCR response without markup.
+// Words on the same line are joined with spaces, using the same line grouping as
+// stitching, and each line and paragraph is separated by a newline.
+func ExtractPlainText(response OCRResponse) string {
+	if len(response.Responses) == 0 || response.Responses[0].FullTextAnnotation == nil {
+		return ""
+	}
+
+	var lines []string
+	for _, page := range response.Responses[0].FullTextAnnotation.Pages {
+		for _, block := range page.Blocks {
+			for _, paragraph := range block.Paragraphs {
+				var words []WordImage
+				for _, word := range paragraph.Words {
+					text := wordText(word)
+					if len(word.BoundingBox.Vertices) < 4 || text == "" {
+						continue
+					}
+					words = append(words, WordImage{Index: len(words), BoundingBox: word.BoundingBox, Text: text})
+				}
+
+				for _, lineWords := range groupWordsByLines(words) {
+					texts := make([]string, len(lineWords))
+					for i, word := range lineWords {
+						texts[i] = word.Text
+					}
+					lines = append(lines, strings.Join(texts, " "))
+				}
+			}
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// wordText joins the symbols of a word into its text
+func wordText(word Word) string {
+	var text strings.Builder
+	for _, symbol := range word.Symbols {
+		text.WriteString(symbol.Text)
+	}
+	return strings.TrimSpace(text.String())
+}
+
 // WrapInHOCRDocument wraps content in a complete hOCR HTML document
 func WrapInHOCRDocument(content string) string {
 	return fmt.Sprintf(`<!DOCTYPE html PUBLIC "-//W3C//DTD XHTML 1.0 Transitional//EN" "http://www.w3.org/TR/xhtml1/DTD/xhtml1-transitional.dtd">
@@ -237,3 +237,79 @@ func TestEscapeTextInSpans(t *testing.T) {
 		})
 	}
 }
+
+func TestExtractPlainText(t *testing.T) {
+	word := func(text string, x, y int) Word {
+		return Word{
+			BoundingBox: BoundingPoly{
+				Vertices: []Vertex{{X: x, Y: y}, {X: x + 30, Y: y}, {X: x + 30, Y: y + 20}, {X: x, Y: y + 20}},
+			},
+			Symbols: []Symbol{{Text: text}},
+		}
+	}
+	response := func(paragraphs ...Paragraph) OCRResponse {
+		return OCRResponse{
+			Responses: []Response{
+				{
+					FullTextAnnotation: &FullTextAnnotation{
+						Pages: []Page{{Blocks: []Block{{Paragraphs: paragraphs}}}},
+					},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		response OCRResponse
+		want     string
+	}{
+		{
+			name:     "empty response",
+			response: OCRResponse{},
+			want:     "",
+		},
+		{
+			name: "single line in reading order",
+			response: response(Paragraph{Words: []Word{
+				word("world", 50, 12),
+				word("hello", 10, 10),
+			}}),
+			want: "hello world",
+		},
+		{
+			name: "multiple lines in one paragraph",
+			response: response(Paragraph{Words: []Word{
+				word("quick", 50, 10),
+				word("the", 10, 10),
+				word("brown", 10, 50),
+				word("fox", 50, 52),
+			}}),
+			want: "the quick\nbrown fox",
+		},
+		{
+			name: "multiple paragraphs",
+			response: response(
+				Paragraph{Words: []Word{word("first", 10, 10), word("paragraph", 50, 10)}},
+				Paragraph{Words: []Word{word("second", 10, 100), word("one", 50, 100)}},
+			),
+			want: "first paragraph\nsecond one",
+		},
+		{
+			name: "skips words without text",
+			response: response(Paragraph{Words: []Word{
+				word("kept", 10, 10),
+				{BoundingBox: word("", 50, 10).BoundingBox},
+			}}),
+			want: "kept",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExtractPlainText(tt.response); got != tt.want {
+				t.Errorf("ExtractPlainText() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package httpclient
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"io"
 	"net/http"
@@ -108,18 +109,46 @@ func SetHeader(header http.Header, name, value string) error {
 	return nil
 }
 
+// sharedTransport pools connections for every client Secure builds without
+// an injected transport.
+var sharedTransport = newTransport()
+
+// newTransport tunes a clone of http.DefaultTransport for many requests to a
+// few provider hosts: more idle connections are kept per host than the
+// default two, so concurrent requests do not close and redial them, and TLS
+// sessions are cached so the connections that are dialed resume quickly.
+func newTransport() *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConns = 64
+	transport.MaxIdleConnsPerHost = 16
+	transport.IdleConnTimeout = 90 * time.Second
+	transport.TLSClientConfig = &tls.Config{ClientSessionCache: tls.NewLRUClientSessionCache(0)}
+	return transport
+}
+
+// SharedTransport returns the connection pool shared across clients, so a
+// client built per request still reuses connections from earlier requests.
+// It is safe for concurrent use.
+func SharedTransport() http.RoundTripper {
+	return sharedTransport
+}
+
 // New returns an HTTP client that refuses redirects and applies a total timeout.
 func New(timeout time.Duration) *http.Client {
 	return Secure(nil, timeout)
 }
 
 // Secure clones an injected client and enforces redirect and timeout policy.
-// The input is never mutated.
+// A client without a transport uses SharedTransport. The input is never
+// mutated.
 func Secure(client *http.Client, timeout time.Duration) *http.Client {
 	var secured http.Client
 	if client != nil {
 		secured = *client
 	}
+	if secured.Transport == nil {
+		secured.Transport = sharedTransport
+	}
 	secured.CheckRedirect = func(_ *http.Request, _ []*http.Request) error {
 		return ErrRedirectBlocked
 	}
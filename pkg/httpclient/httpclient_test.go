@@ -3,9 +3,13 @@ package httpclient
 import (
 	"context"
 	"errors"
+	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -120,3 +124,93 @@ func TestBearerAuthenticationPreservesOnlyContextErrors(t *testing.T) {
 		t.Fatalf("expected redacted credential error, got %v", err)
 	}
 }
+
+func TestSecureSharesTransportAcrossClients(t *testing.T) {
+	t.Parallel()
+	server, dials := countingServer(t)
+	for range 5 {
+		fetch(t, Secure(nil, time.Second), server.URL)
+	}
+	if got := dials.Load(); got != 1 {
+		t.Errorf("dialed %d connections for 5 sequential requests through separate clients, want 1", got)
+	}
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Go(func() {
+			for range 4 {
+				fetch(t, Secure(nil, time.Second), server.URL)
+			}
+		})
+	}
+	wg.Wait()
+	if got := dials.Load(); got > 9 {
+		t.Errorf("dialed %d connections for 8 concurrent workers, want at most one each plus the first", got)
+	}
+
+	injected := &http.Transport{}
+	if got := Secure(&http.Client{Transport: injected}, time.Second).Transport; got != injected {
+		t.Errorf("Secure() replaced the injected transport with %T", got)
+	}
+	if got := Secure(nil, time.Second).Transport; got != SharedTransport() {
+		t.Errorf("Secure() transport = %T, want the shared transport", got)
+	}
+}
+
+// BenchmarkClientPerRequest builds a client for every request, as the CLI
+// provider adapters do for every row, and reports the connections dialed per
+// request: the shared transport reuses one, while a transport per client
+// dials every time.
+func BenchmarkClientPerRequest(b *testing.B) {
+	benchmarks := []struct {
+		name      string
+		transport func() http.RoundTripper
+	}{
+		{name: "shared transport", transport: func() http.RoundTripper { return nil }},
+		{name: "transport per client", transport: func() http.RoundTripper { return newTransport() }},
+	}
+
+	for _, bench := range benchmarks {
+		b.Run(bench.name, func(b *testing.B) {
+			server, dials := countingServer(b)
+			b.ReportAllocs()
+			for range b.N {
+				transport := bench.transport()
+				fetch(b, Secure(&http.Client{Transport: transport}, time.Second), server.URL)
+				if perClient, ok := transport.(*http.Transport); ok {
+					perClient.CloseIdleConnections()
+				}
+			}
+			b.ReportMetric(float64(dials.Load())/float64(b.N), "dials/op")
+		})
+	}
+}
+
+// countingServer returns a server that counts the connections dialed to it.
+func countingServer(tb testing.TB) (*httptest.Server, *atomic.Int64) {
+	tb.Helper()
+	var dials atomic.Int64
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}))
+	server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			dials.Add(1)
+		}
+	}
+	server.Start()
+	tb.Cleanup(server.Close)
+	return server, &dials
+}
+
+// fetch sends a GET and drains the response so its connection can be reused.
+func fetch(tb testing.TB, client *http.Client, url string) {
+	tb.Helper()
+	response, err := client.Get(url)
+	if err != nil {
+		tb.Error(err)
+		return
+	}
+	_, _ = io.Copy(io.Discard, response.Body)
+	_ = response.Body.Close()
+}
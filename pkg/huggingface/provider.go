@@ -0,0 +1,241 @@
+// Package huggingface provides a transcription client for image-to-text
+// models, such as fine-tuned TrOCR checkpoints, served by the HuggingFace
+// Inference API.
+package huggingface
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/lehigh-university-libraries/htr/pkg/httpclient"
+	"github.com/lehigh-university-libraries/htr/pkg/providers"
+)
+
+const (
+	defaultEndpoint         = "https://router.huggingface.co/hf-inference"
+	defaultTimeout          = 2 * time.Minute
+	defaultMaxImageBytes    = 20 << 20
+	defaultMaxResponseBytes = 8 << 20
+)
+
+// modelPattern matches a HuggingFace model ID, optionally namespaced by its
+// owner, such as microsoft/trocr-base-handwritten.
+var modelPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,95}(/[A-Za-z0-9][A-Za-z0-9._-]{0,95})?$`)
+
+// CredentialSource returns an API credential for one request.
+type CredentialSource func(context.Context) (string, error)
+
+// Options configures a Client. Constructors do not read environment variables.
+type Options struct {
+	HTTPClient *http.Client
+	// Endpoint is the Inference API root; /models/<model> is appended to it.
+	Endpoint         string
+	APIKey           CredentialSource
+	Timeout          time.Duration
+	MaxImageBytes    int64
+	MaxResponseBytes int64
+}
+
+// Client is a byte-oriented HuggingFace Inference API transcription client.
+type Client struct {
+	httpClient       *http.Client
+	endpoint         string
+	apiKey           CredentialSource
+	maxImageBytes    int64
+	maxResponseBytes int64
+}
+
+// Provider is the historical CLI adapter. New integrations should use Client.
+type Provider struct{}
+
+// generation is one output of an image-to-text pipeline.
+type generation struct {
+	GeneratedText string `json:"generated_text"`
+}
+
+// NewClient constructs a secure HuggingFace client from explicit dependencies.
+func NewClient(options Options) (*Client, error) {
+	endpoint := options.Endpoint
+	if endpoint == "" {
+		endpoint = defaultEndpoint
+	}
+	if _, err := httpclient.ParseEndpoint(endpoint); err != nil || options.APIKey == nil {
+		return nil, providers.NewError(providers.ErrorInvalidRequest, 0, false, nil)
+	}
+	return &Client{
+		httpClient:       httpclient.Secure(options.HTTPClient, durationOr(options.Timeout, defaultTimeout)),
+		endpoint:         endpoint,
+		apiKey:           options.APIKey,
+		maxImageBytes:    positiveOr(options.MaxImageBytes, defaultMaxImageBytes),
+		maxResponseBytes: positiveOr(options.MaxResponseBytes, defaultMaxResponseBytes),
+	}, nil
+}
+
+// Name returns the provider name.
+func (c *Client) Name() string { return "huggingface" }
+
+// Extract transcribes an encoded image. Image-to-text pipelines take neither
+// a prompt nor a temperature, so only the model and image are sent, with the
+// image as the raw request body.
+func (c *Client) Extract(ctx context.Context, request providers.Request) (providers.Result, error) {
+	if !modelPattern.MatchString(request.Model) {
+		return providers.Result{}, providers.NewError(providers.ErrorInvalidRequest, 0, false, nil)
+	}
+	if err := providers.ValidateImage(request.Image, c.maxImageBytes); err != nil {
+		return providers.Result{}, err
+	}
+	if err := ctx.Err(); err != nil {
+		return providers.Result{}, providers.ErrorForRequest(ctx, err)
+	}
+	mediaType, err := providers.CanonicalMediaType(request.Image.MediaType)
+	if err != nil {
+		return providers.Result{}, err
+	}
+	endpoint, err := httpclient.AppendPath(c.endpoint, "/models/"+request.Model)
+	if err != nil {
+		return providers.Result{}, providers.NewError(providers.ErrorInvalidRequest, 0, false, nil)
+	}
+	credential, err := c.apiKey(ctx)
+	if err != nil {
+		return providers.Result{}, providers.ErrorForAuthentication(ctx, err)
+	}
+
+	httpRequest, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(request.Image.Data))
+	if err != nil {
+		return providers.Result{}, providers.NewError(providers.ErrorInvalidRequest, 0, false, nil)
+	}
+	httpRequest.Header.Set("Content-Type", mediaType)
+	if err := httpclient.StaticBearer(credential).Authorize(ctx, httpRequest); err != nil {
+		return providers.Result{}, providers.ErrorForAuthentication(ctx, err)
+	}
+
+	response, err := c.httpClient.Do(httpRequest)
+	if err != nil {
+		return providers.Result{}, providers.ErrorForRequest(ctx, err)
+	}
+	defer response.Body.Close()
+	responseBody, err := httpclient.ReadAll(response.Body, c.maxResponseBytes)
+	if err != nil {
+		if errors.Is(err, httpclient.ErrResponseTooLarge) {
+			return providers.Result{}, providers.NewError(providers.ErrorResponseTooLarge, response.StatusCode, false, nil)
+		}
+		return providers.Result{}, providers.ErrorForRequest(ctx, err)
+	}
+	// A model that is still loading answers 503, which is retryable.
+	if response.StatusCode < http.StatusOK || response.StatusCode >= http.StatusMultipleChoices {
+		return providers.Result{}, providers.ErrorForStatus(response.StatusCode)
+	}
+
+	text, ok := generatedText(responseBody)
+	if !ok {
+		return providers.Result{}, providers.NewError(providers.ErrorInvalidResponse, response.StatusCode, false, nil)
+	}
+	return providers.Result{
+		Text:           text,
+		EffectiveModel: request.Model,
+	}, nil
+}
+
+// generatedText reads the text from an image-to-text response, which is a
+// list of generations, keeping the first. A single generation object, as some
+// dedicated endpoints return, is accepted too.
+func generatedText(body []byte) (string, bool) {
+	var generations []generation
+	if err := json.Unmarshal(body, &generations); err != nil {
+		var single generation
+		if err := json.Unmarshal(body, &single); err != nil {
+			return "", false
+		}
+		generations = []generation{single}
+	}
+	if len(generations) == 0 {
+		return "", false
+	}
+	text := strings.TrimSpace(generations[0].GeneratedText)
+	return text, text != ""
+}
+
+// New creates the historical CLI adapter.
+func New() *Provider { return &Provider{} }
+
+// Name returns the provider name.
+func (p *Provider) Name() string { return "huggingface" }
+
+// Capabilities reports that the Inference API returns no token usage and
+// sends neither the prompt nor the temperature to the model.
+func (p *Provider) Capabilities() providers.Capabilities {
+	return providers.Capabilities{}
+}
+
+// ValidateConfig validates environment-backed CLI configuration: the API
+// token, the base URL, and the model, which names the hosted model to run.
+func (p *Provider) ValidateConfig(config providers.Config) error {
+	if strings.TrimSpace(os.Getenv("HF_API_TOKEN")) == "" {
+		return providers.NewError(providers.ErrorAuthentication, 0, false, nil)
+	}
+	if _, err := httpclient.ParseEndpoint(resolveBaseURL(config)); err != nil {
+		return providers.NewError(providers.ErrorInvalidRequest, 0, false, nil)
+	}
+	if !modelPattern.MatchString(config.Model) {
+		return providers.NewError(providers.ErrorInvalidRequest, 0, false, nil)
+	}
+	return nil
+}
+
+// ExtractText adapts historical base64 CLI inputs to Client. The usage is
+// always zero, since the Inference API does not report it.
+func (p *Provider) ExtractText(ctx context.Context, config providers.Config, imagePath, imageBase64 string) (string, providers.UsageInfo, error) {
+	request, err := providers.LegacyRequest(config, imagePath, imageBase64, defaultMaxImageBytes)
+	if err != nil {
+		return "", providers.UsageInfo{}, err
+	}
+	client, err := NewClient(Options{
+		Endpoint: resolveBaseURL(config),
+		APIKey: func(context.Context) (string, error) {
+			key := os.Getenv("HF_API_TOKEN")
+			if strings.TrimSpace(key) == "" {
+				return "", providers.NewError(providers.ErrorAuthentication, 0, false, nil)
+			}
+			return key, nil
+		},
+		Timeout: config.Timeout,
+	})
+	if err != nil {
+		return "", providers.UsageInfo{}, err
+	}
+	result, err := client.Extract(ctx, request)
+	return result.Text, providers.UsageInfo{}, err
+}
+
+// resolveBaseURL returns config.BaseURL, then HF_BASE_URL, then the public
+// Inference API.
+func resolveBaseURL(config providers.Config) string {
+	if baseURL := strings.TrimSpace(config.BaseURL); baseURL != "" {
+		return baseURL
+	}
+	if environmentURL := strings.TrimSpace(os.Getenv("HF_BASE_URL")); environmentURL != "" {
+		return environmentURL
+	}
+	return defaultEndpoint
+}
+
+func positiveOr(value, fallback int64) int64 {
+	if value > 0 {
+		return value
+	}
+	return fallback
+}
+
+func durationOr(value, fallback time.Duration) time.Duration {
+	if value > 0 {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,189 @@
+package huggingface
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/lehigh-university-libraries/htr/pkg/providers"
+)
+
+var _ providers.Client = (*Client)(nil)
+var _ providers.Provider = (*Provider)(nil)
+
+func TestClientExtract(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		response string
+	}{
+		{"pipeline list", `[{"generated_text":" Dear sir, I remain "}]`},
+		{"single object", `{"generated_text":"Dear sir, I remain"}`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			image := []byte("encoded-image")
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, request *http.Request) {
+				if request.Method != http.MethodPost || request.URL.Path != "/base/models/lehigh/trocr-letters" {
+					t.Errorf("unexpected request target: %s %s", request.Method, request.URL.Path)
+				}
+				if got := request.Header.Get("Authorization"); got != "Bearer hf-token" {
+					t.Errorf("Authorization = %q", got)
+				}
+				if got := request.Header.Get("Content-Type"); got != "image/png" {
+					t.Errorf("Content-Type = %q", got)
+				}
+				body, err := io.ReadAll(request.Body)
+				if err != nil || string(body) != string(image) {
+					t.Errorf("body = %q, %v; want the raw image bytes", body, err)
+				}
+				_, _ = w.Write([]byte(test.response))
+			}))
+			defer server.Close()
+
+			client, err := NewClient(Options{Endpoint: server.URL + "/base", APIKey: staticKey("hf-token")})
+			if err != nil {
+				t.Fatal(err)
+			}
+			result, err := client.Extract(context.Background(), testRequest(image))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Text != "Dear sir, I remain" || result.Usage != (providers.UsageInfo{}) || result.EffectiveModel != "lehigh/trocr-letters" {
+				t.Fatalf("unexpected result: %#v", result)
+			}
+		})
+	}
+}
+
+func TestClientErrorsAreTypedAndRedacted(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name      string
+		status    int
+		body      string
+		wantKind  providers.ErrorKind
+		retryable bool
+	}{
+		{"model loading", http.StatusServiceUnavailable, `{"error":"Model is currently loading","estimated_time":20}`, providers.ErrorUpstream, true},
+		{"rejected token", http.StatusUnauthorized, `{"error":"Invalid credentials in Authorization header"}`, providers.ErrorAuthentication, false},
+		{"empty generation", http.StatusOK, `[{"generated_text":"  "}]`, providers.ErrorInvalidResponse, false},
+		{"no generations", http.StatusOK, `[]`, providers.ErrorInvalidResponse, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(test.status)
+				_, _ = w.Write([]byte(test.body))
+			}))
+			defer server.Close()
+			client, err := NewClient(Options{Endpoint: server.URL, APIKey: staticKey("private-key")})
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = client.Extract(context.Background(), testRequest([]byte("image")))
+			var providerError *providers.Error
+			if !errors.As(err, &providerError) || providerError.Kind != test.wantKind || providerError.Retryable != test.retryable {
+				t.Fatalf("unexpected error: %#v", err)
+			}
+			if strings.Contains(err.Error(), "private-key") || strings.Contains(err.Error(), server.URL) {
+				t.Fatalf("error leaked sensitive data: %q", err)
+			}
+		})
+	}
+}
+
+func TestClientRejectsInvalidInputBeforeNetwork(t *testing.T) {
+	t.Parallel()
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) { calls.Add(1) }))
+	defer server.Close()
+	client, err := NewClient(Options{Endpoint: server.URL, APIKey: staticKey("key"), MaxImageBytes: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	oversized := testRequest([]byte("12345"))
+	badModel := testRequest([]byte("1234"))
+	badModel.Model = "../admin?x=1"
+	for _, request := range []providers.Request{oversized, badModel} {
+		_, err = client.Extract(context.Background(), request)
+		var providerError *providers.Error
+		if !errors.As(err, &providerError) || providerError.Kind != providers.ErrorInvalidRequest {
+			t.Fatalf("expected invalid request, got %v", err)
+		}
+	}
+	if calls.Load() != 0 {
+		t.Fatal("network called for invalid input")
+	}
+}
+
+func TestLegacyProviderValidation(t *testing.T) {
+	provider := New()
+	if provider.Name() != "huggingface" {
+		t.Fatalf("Name() = %q", provider.Name())
+	}
+	config := providers.Config{Model: "microsoft/trocr-base-handwritten"}
+	t.Setenv("HF_BASE_URL", "")
+	t.Setenv("HF_API_TOKEN", "")
+	if err := provider.ValidateConfig(config); err == nil {
+		t.Fatal("expected missing token error")
+	}
+	t.Setenv("HF_API_TOKEN", "token")
+	if err := provider.ValidateConfig(config); err != nil {
+		t.Fatal(err)
+	}
+	if err := provider.ValidateConfig(providers.Config{}); err == nil {
+		t.Fatal("expected missing model error")
+	}
+}
+
+func TestLegacyProviderExtractText(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, request *http.Request) {
+		if request.URL.Path != "/models/microsoft/trocr-base-handwritten" || request.Header.Get("Authorization") != "Bearer env-token" {
+			t.Errorf("unexpected request: %s %q", request.URL.Path, request.Header.Get("Authorization"))
+		}
+		_, _ = w.Write([]byte(`[{"generated_text":"hello world"}]`))
+	}))
+	defer server.Close()
+	t.Setenv("HF_API_TOKEN", "env-token")
+	t.Setenv("HF_BASE_URL", server.URL)
+
+	config := providers.Config{Model: "microsoft/trocr-base-handwritten", Prompt: "Transcribe"}
+	text, usage, err := New().ExtractText(context.Background(), config, "line.png", "iVBORw0KGgo=")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text != "hello world" || usage != (providers.UsageInfo{}) {
+		t.Fatalf("ExtractText() = %q, %+v", text, usage)
+	}
+}
+
+func TestProviderCapabilities(t *testing.T) {
+	if got := New().Capabilities(); got != (providers.Capabilities{}) {
+		t.Errorf("Capabilities() = %+v, want no token usage, prompt, or temperature", got)
+	}
+}
+
+func testRequest(image []byte) providers.Request {
+	return providers.Request{
+		Model:  "lehigh/trocr-letters",
+		Prompt: "Transcribe café",
+		Image: providers.Image{
+			Data:      image,
+			MediaType: "image/png",
+			Filename:  "page.png",
+		},
+	}
+}
+
+func staticKey(value string) CredentialSource {
+	return func(context.Context) (string, error) { return value, nil }
+}
@@ -0,0 +1,187 @@
+// Package imaging downscales images before they are sent to a provider, so
+// high-resolution scans do not inflate input token counts.
+package imaging
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"log/slog"
+	"os/exec"
+	"strings"
+)
+
+// jpegQuality is used when re-encoding downscaled JPEGs.
+const jpegQuality = 90
+
+// Dimensions is the size of an image in pixels.
+type Dimensions struct {
+	Width  int
+	Height int
+}
+
+// String formats the dimensions as WIDTHxHEIGHT.
+func (d Dimensions) String() string {
+	return fmt.Sprintf("%dx%d", d.Width, d.Height)
+}
+
+// Result is an image prepared for upload.
+type Result struct {
+	// Data is the encoded image, in the same format as the input.
+	Data []byte
+	// Original is the size of the input image.
+	Original Dimensions
+	// Sent is the size of Data, which equals Original when no resize was needed.
+	Sent Dimensions
+}
+
+// Downscale shrinks data so its longest side is at most maxDimension pixels,
+// preserving the aspect ratio and the encoded format. Images already within
+// the limit are returned unchanged. A maxDimension of zero or less disables
+// resizing and leaves the dimensions unset. PNG, JPEG, and GIF are resized in
+// Go; other formats, such as TIFF, fall back to ImageMagick.
+func Downscale(data []byte, maxDimension int) (Result, error) {
+	if maxDimension <= 0 {
+		return Result{Data: data}, nil
+	}
+
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		slog.Debug("Falling back to ImageMagick to downscale image", "err", err)
+		return downscaleWithMagick(data, maxDimension)
+	}
+
+	original := Dimensions{Width: config.Width, Height: config.Height}
+	sent := original.Fit(maxDimension)
+	if sent == original {
+		return Result{Data: data, Original: original, Sent: original}, nil
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to decode image: %w", err)
+	}
+
+	var out bytes.Buffer
+	resized := resize(src, sent)
+	switch format {
+	case "jpeg":
+		err = jpeg.Encode(&out, resized, &jpeg.Options{Quality: jpegQuality})
+	case "gif":
+		err = gif.Encode(&out, resized, nil)
+	default:
+		err = png.Encode(&out, resized)
+	}
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to encode resized image: %w", err)
+	}
+
+	return Result{Data: out.Bytes(), Original: original, Sent: sent}, nil
+}
+
+// Fit scales d down, preserving the aspect ratio, so its longest side is at
+// most maxDimension. Dimensions already within the limit are returned as-is.
+func (d Dimensions) Fit(maxDimension int) Dimensions {
+	longest := max(d.Width, d.Height)
+	if longest <= maxDimension {
+		return d
+	}
+	scale := float64(maxDimension) / float64(longest)
+	return Dimensions{
+		Width:  max(1, int(float64(d.Width)*scale+0.5)),
+		Height: max(1, int(float64(d.Height)*scale+0.5)),
+	}
+}
+
+// Size returns the dimensions of an encoded image, using ImageMagick for
+// formats Go cannot decode.
+func Size(data []byte) (Dimensions, error) {
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return identify(data)
+	}
+	return Dimensions{Width: config.Width, Height: config.Height}, nil
+}
+
+// resize downsamples src to size by averaging the source pixels that fall
+// under each destination pixel, which keeps thin pen strokes legible better
+// than nearest-neighbor sampling.
+func resize(src image.Image, size Dimensions) *image.RGBA {
+	bounds := src.Bounds()
+	rgba := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(rgba, rgba.Bounds(), src, bounds.Min, draw.Src)
+
+	srcWidth, srcHeight := bounds.Dx(), bounds.Dy()
+	dst := image.NewRGBA(image.Rect(0, 0, size.Width, size.Height))
+	for y := 0; y < size.Height; y++ {
+		y0 := y * srcHeight / size.Height
+		y1 := max(y0+1, (y+1)*srcHeight/size.Height)
+		for x := 0; x < size.Width; x++ {
+			x0 := x * srcWidth / size.Width
+			x1 := max(x0+1, (x+1)*srcWidth/size.Width)
+
+			var sum [4]int
+			for sy := y0; sy < y1; sy++ {
+				row := rgba.Pix[sy*rgba.Stride+x0*4 : sy*rgba.Stride+x1*4]
+				for i := 0; i < len(row); i += 4 {
+					sum[0] += int(row[i])
+					sum[1] += int(row[i+1])
+					sum[2] += int(row[i+2])
+					sum[3] += int(row[i+3])
+				}
+			}
+
+			count := (y1 - y0) * (x1 - x0)
+			offset := y*dst.Stride + x*4
+			for c := range sum {
+				dst.Pix[offset+c] = uint8(sum[c] / count)
+			}
+		}
+	}
+	return dst
+}
+
+// downscaleWithMagick resizes formats Go cannot decode. The ">" geometry flag
+// only shrinks images, and writing to stdout keeps the input format.
+func downscaleWithMagick(data []byte, maxDimension int) (Result, error) {
+	original, err := identify(data)
+	if err != nil {
+		return Result{}, err
+	}
+	if original.Fit(maxDimension) == original {
+		return Result{Data: data, Original: original, Sent: original}, nil
+	}
+
+	cmd := exec.Command("magick", "-", "-resize", fmt.Sprintf("%dx%d>", maxDimension, maxDimension), "-")
+	cmd.Stdin = bytes.NewReader(data)
+	resized, err := cmd.Output()
+	if err != nil {
+		return Result{}, fmt.Errorf("imagemagick resize failed: %w", err)
+	}
+
+	sent, err := identify(resized)
+	if err != nil {
+		return Result{}, err
+	}
+	return Result{Data: resized, Original: original, Sent: sent}, nil
+}
+
+// identify reads image dimensions with ImageMagick.
+func identify(data []byte) (Dimensions, error) {
+	cmd := exec.Command("magick", "identify", "-format", "%w %h", "-")
+	cmd.Stdin = bytes.NewReader(data)
+	output, err := cmd.Output()
+	if err != nil {
+		return Dimensions{}, fmt.Errorf("failed to get image dimensions: %w", err)
+	}
+
+	var size Dimensions
+	if _, err := fmt.Sscanf(strings.TrimSpace(string(output)), "%d %d", &size.Width, &size.Height); err != nil {
+		return Dimensions{}, fmt.Errorf("failed to parse dimensions: %w", err)
+	}
+	return size, nil
+}
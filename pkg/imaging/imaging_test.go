@@ -0,0 +1,111 @@
+package imaging
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+func encodePNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	img := image.NewGray(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.SetGray(x, y, color.Gray{Y: uint8((x + y) % 256)})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestDownscale(t *testing.T) {
+	tests := []struct {
+		name         string
+		width        int
+		height       int
+		maxDimension int
+		wantSent     Dimensions
+		wantResized  bool
+	}{
+		{name: "landscape over limit", width: 400, height: 200, maxDimension: 100, wantSent: Dimensions{100, 50}, wantResized: true},
+		{name: "portrait over limit", width: 150, height: 300, maxDimension: 100, wantSent: Dimensions{50, 100}, wantResized: true},
+		{name: "within limit", width: 80, height: 60, maxDimension: 100, wantSent: Dimensions{80, 60}},
+		{name: "exactly at limit", width: 100, height: 40, maxDimension: 100, wantSent: Dimensions{100, 40}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := encodePNG(t, tt.width, tt.height)
+			result, err := Downscale(data, tt.maxDimension)
+			if err != nil {
+				t.Fatalf("Downscale() error = %v", err)
+			}
+			if want := (Dimensions{tt.width, tt.height}); result.Original != want {
+				t.Errorf("Original = %v, want %v", result.Original, want)
+			}
+			if result.Sent != tt.wantSent {
+				t.Errorf("Sent = %v, want %v", result.Sent, tt.wantSent)
+			}
+			if resized := !bytes.Equal(result.Data, data); resized != tt.wantResized {
+				t.Errorf("data changed = %v, want %v", resized, tt.wantResized)
+			}
+
+			config, format, err := image.DecodeConfig(bytes.NewReader(result.Data))
+			if err != nil {
+				t.Fatalf("DecodeConfig() error = %v", err)
+			}
+			if format != "png" || config.Width != tt.wantSent.Width || config.Height != tt.wantSent.Height {
+				t.Errorf("encoded image = %s %dx%d, want png %v", format, config.Width, config.Height, tt.wantSent)
+			}
+		})
+	}
+}
+
+func TestDownscaleKeepsJPEGFormat(t *testing.T) {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 300, 200)), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := Downscale(buf.Bytes(), 150)
+	if err != nil {
+		t.Fatalf("Downscale() error = %v", err)
+	}
+	config, format, err := image.DecodeConfig(bytes.NewReader(result.Data))
+	if err != nil {
+		t.Fatalf("DecodeConfig() error = %v", err)
+	}
+	if format != "jpeg" || config.Width != 150 || config.Height != 100 {
+		t.Errorf("encoded image = %s %dx%d, want jpeg 150x100", format, config.Width, config.Height)
+	}
+}
+
+func TestDownscaleDisabled(t *testing.T) {
+	data := []byte("not decoded when disabled")
+	result, err := Downscale(data, 0)
+	if err != nil {
+		t.Fatalf("Downscale() error = %v", err)
+	}
+	if !bytes.Equal(result.Data, data) || result.Original != (Dimensions{}) || result.Sent != (Dimensions{}) {
+		t.Errorf("Downscale() = %+v, want input unchanged", result)
+	}
+}
+
+func TestResizeAveragesPixels(t *testing.T) {
+	src := image.NewGray(image.Rect(0, 0, 2, 2))
+	src.SetGray(0, 0, color.Gray{Y: 0})
+	src.SetGray(1, 0, color.Gray{Y: 100})
+	src.SetGray(0, 1, color.Gray{Y: 200})
+	src.SetGray(1, 1, color.Gray{Y: 255})
+
+	got := resize(src, Dimensions{1, 1}).RGBAAt(0, 0)
+	if want := (color.RGBA{R: 138, G: 138, B: 138, A: 255}); got != want {
+		t.Errorf("resize() pixel = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,60 @@
+package imaging
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// pdfDensity is the resolution, in dots per inch, PDF pages are rendered at.
+// ImageMagick's default of 72 blurs handwriting.
+const pdfDensity = "300"
+
+// IsPaged reports whether mediaType is a multi-page document format, TIFF or
+// PDF, that must be rasterized before a vision provider can read it.
+func IsPaged(mediaType string) bool {
+	return mediaType == "image/tiff" || mediaType == "application/pdf"
+}
+
+// Rasterize renders one page of a TIFF or PDF, numbered from 1, as a PNG
+// using ImageMagick.
+func Rasterize(data []byte, mediaType string, page int) ([]byte, error) {
+	args, err := rasterizeArgs(mediaType, page)
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := exec.Command("magick", args...)
+	cmd.Stdin = bytes.NewReader(data)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	png, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("imagemagick could not rasterize page %d: %w: %s", page, err, strings.TrimSpace(stderr.String()))
+	}
+	return png, nil
+}
+
+// rasterizeArgs returns the magick arguments that read page of a document in
+// mediaType from stdin and write it as a PNG to stdout. The format is named
+// explicitly because stdin has no extension to infer it from.
+func rasterizeArgs(mediaType string, page int) ([]string, error) {
+	if page < 1 {
+		return nil, fmt.Errorf("invalid page %d: pages are numbered from 1", page)
+	}
+
+	var args []string
+	var format string
+	switch mediaType {
+	case "image/tiff":
+		format = "tiff"
+	case "application/pdf":
+		format = "pdf"
+		args = append(args, "-density", pdfDensity)
+	default:
+		return nil, fmt.Errorf("cannot rasterize %s: only TIFF and PDF have pages", mediaType)
+	}
+	// ImageMagick frame indexes start at 0
+	return append(args, fmt.Sprintf("%s:-[%d]", format, page-1), "-flatten", "png:-"), nil
+}
@@ -0,0 +1,87 @@
+package imaging
+
+import (
+	"bytes"
+	"image"
+	"os/exec"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestRasterizeArgs(t *testing.T) {
+	tests := []struct {
+		name      string
+		mediaType string
+		page      int
+		want      []string
+		wantErr   string
+	}{
+		{name: "first tiff page", mediaType: "image/tiff", page: 1, want: []string{"tiff:-[0]", "-flatten", "png:-"}},
+		{name: "later tiff page", mediaType: "image/tiff", page: 4, want: []string{"tiff:-[3]", "-flatten", "png:-"}},
+		{name: "pdf page", mediaType: "application/pdf", page: 2, want: []string{"-density", "300", "pdf:-[1]", "-flatten", "png:-"}},
+		{name: "page zero", mediaType: "image/tiff", page: 0, wantErr: "invalid page 0"},
+		{name: "negative page", mediaType: "application/pdf", page: -1, wantErr: "invalid page -1"},
+		{name: "single-page format", mediaType: "image/jpeg", page: 1, wantErr: "cannot rasterize image/jpeg"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := rasterizeArgs(tt.mediaType, tt.page)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("rasterizeArgs() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("rasterizeArgs() error = %v", err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("rasterizeArgs() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsPaged(t *testing.T) {
+	for mediaType, want := range map[string]bool{
+		"image/tiff":      true,
+		"application/pdf": true,
+		"image/png":       false,
+		"image/jpeg":      false,
+	} {
+		if got := IsPaged(mediaType); got != want {
+			t.Errorf("IsPaged(%q) = %v, want %v", mediaType, got, want)
+		}
+	}
+}
+
+func TestRasterizeSelectsPage(t *testing.T) {
+	if _, err := exec.LookPath("magick"); err != nil {
+		t.Skip("ImageMagick is not installed")
+	}
+	// A two-page TIFF whose pages differ in size
+	tiff, err := exec.Command("magick", "-size", "10x10", "xc:red", "-size", "20x30", "xc:blue", "tiff:-").Output()
+	if err != nil {
+		t.Fatalf("failed to build TIFF: %v", err)
+	}
+
+	for page, want := range map[int]image.Point{1: {10, 10}, 2: {20, 30}} {
+		data, err := Rasterize(tiff, "image/tiff", page)
+		if err != nil {
+			t.Fatalf("Rasterize(page %d) error = %v", page, err)
+		}
+		config, format, err := image.DecodeConfig(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("DecodeConfig(page %d) error = %v", page, err)
+		}
+		if format != "png" || config.Width != want.X || config.Height != want.Y {
+			t.Errorf("page %d = %s %dx%d, want png %dx%d", page, format, config.Width, config.Height, want.X, want.Y)
+		}
+	}
+
+	if _, err := Rasterize(tiff, "image/tiff", 3); err == nil {
+		t.Error("Rasterize(page 3 of 2) error = nil, want error")
+	}
+}
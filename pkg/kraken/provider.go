@@ -0,0 +1,263 @@
+// Package kraken provides a transcription client for Kraken HTR models served
+// over HTTP, such as by an eScriptorium instance or a recognition service in
+// front of kraken.
+package kraken
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/lehigh-university-libraries/htr/pkg/httpclient"
+	"github.com/lehigh-university-libraries/htr/pkg/providers"
+)
+
+const (
+	defaultTimeout          = 5 * time.Minute
+	defaultMaxImageBytes    = 50 << 20
+	defaultMaxResponseBytes = 8 << 20
+	// defaultModel asks the service for its default recognition model.
+	defaultModel = "default"
+)
+
+// CredentialSource returns an API credential for one request.
+type CredentialSource func(context.Context) (string, error)
+
+// Options configures a Client. Constructors do not read environment variables.
+type Options struct {
+	HTTPClient *http.Client
+	// Endpoint is the recognition URL the image is posted to.
+	Endpoint         string
+	Token            CredentialSource
+	Timeout          time.Duration
+	MaxImageBytes    int64
+	MaxResponseBytes int64
+}
+
+// Client is a byte-oriented Kraken transcription client.
+type Client struct {
+	httpClient       *http.Client
+	endpoint         string
+	token            CredentialSource
+	maxImageBytes    int64
+	maxResponseBytes int64
+}
+
+// Provider is the historical CLI adapter. New integrations should use Client.
+type Provider struct{}
+
+// recognitionResponse holds the recognized lines in reading order.
+type recognitionResponse struct {
+	Model string `json:"model"`
+	Lines []struct {
+		Text string `json:"text"`
+	} `json:"lines"`
+}
+
+// NewClient constructs a secure Kraken client from explicit dependencies.
+func NewClient(options Options) (*Client, error) {
+	parsed, err := httpclient.ParseEndpoint(options.Endpoint)
+	if err != nil || options.Token == nil {
+		return nil, providers.NewError(providers.ErrorInvalidRequest, 0, false, nil)
+	}
+	return &Client{
+		httpClient:       httpclient.Secure(options.HTTPClient, durationOr(options.Timeout, defaultTimeout)),
+		endpoint:         parsed.String(),
+		token:            options.Token,
+		maxImageBytes:    positiveOr(options.MaxImageBytes, defaultMaxImageBytes),
+		maxResponseBytes: positiveOr(options.MaxResponseBytes, defaultMaxResponseBytes),
+	}, nil
+}
+
+// Name returns the provider name.
+func (c *Client) Name() string { return "kraken" }
+
+// Extract transcribes an encoded image. The image and the recognition model
+// are posted as a multipart form, and the recognized lines are joined with
+// newlines. Kraken takes neither a prompt nor a temperature.
+func (c *Client) Extract(ctx context.Context, request providers.Request) (providers.Result, error) {
+	if strings.TrimSpace(request.Model) == "" || strings.ContainsAny(request.Model, "\r\n\x00") {
+		return providers.Result{}, providers.NewError(providers.ErrorInvalidRequest, 0, false, nil)
+	}
+	if err := providers.ValidateImage(request.Image, c.maxImageBytes); err != nil {
+		return providers.Result{}, err
+	}
+	if err := ctx.Err(); err != nil {
+		return providers.Result{}, providers.ErrorForRequest(ctx, err)
+	}
+	mediaType, err := providers.CanonicalMediaType(request.Image.MediaType)
+	if err != nil {
+		return providers.Result{}, err
+	}
+	credential, err := c.token(ctx)
+	if err != nil {
+		return providers.Result{}, providers.ErrorForAuthentication(ctx, err)
+	}
+	body, contentType, err := recognitionForm(request.Model, request.Image, mediaType)
+	if err != nil {
+		return providers.Result{}, providers.NewError(providers.ErrorInvalidRequest, 0, false, nil)
+	}
+
+	httpRequest, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint, body)
+	if err != nil {
+		return providers.Result{}, providers.NewError(providers.ErrorInvalidRequest, 0, false, nil)
+	}
+	httpRequest.Header.Set("Content-Type", contentType)
+	httpRequest.Header.Set("Accept", "application/json")
+	// eScriptorium authenticates API tokens with the Token scheme.
+	if err := httpclient.StaticHeader("Authorization", "Token "+credential).Authorize(ctx, httpRequest); err != nil {
+		return providers.Result{}, providers.ErrorForAuthentication(ctx, err)
+	}
+
+	response, err := c.httpClient.Do(httpRequest)
+	if err != nil {
+		return providers.Result{}, providers.ErrorForRequest(ctx, err)
+	}
+	defer response.Body.Close()
+	responseBody, err := httpclient.ReadAll(response.Body, c.maxResponseBytes)
+	if err != nil {
+		if errors.Is(err, httpclient.ErrResponseTooLarge) {
+			return providers.Result{}, providers.NewError(providers.ErrorResponseTooLarge, response.StatusCode, false, nil)
+		}
+		return providers.Result{}, providers.ErrorForRequest(ctx, err)
+	}
+	if response.StatusCode < http.StatusOK || response.StatusCode >= http.StatusMultipleChoices {
+		return providers.Result{}, providers.ErrorForStatus(response.StatusCode)
+	}
+
+	var decoded recognitionResponse
+	if err := json.Unmarshal(responseBody, &decoded); err != nil {
+		return providers.Result{}, providers.NewError(providers.ErrorInvalidResponse, response.StatusCode, false, nil)
+	}
+	lines := make([]string, 0, len(decoded.Lines))
+	for _, line := range decoded.Lines {
+		if text := strings.TrimSpace(line.Text); text != "" {
+			lines = append(lines, text)
+		}
+	}
+	if len(lines) == 0 {
+		return providers.Result{}, providers.NewError(providers.ErrorInvalidResponse, response.StatusCode, false, nil)
+	}
+	effectiveModel := strings.TrimSpace(decoded.Model)
+	if effectiveModel == "" {
+		effectiveModel = request.Model
+	}
+	return providers.Result{
+		Text:           strings.Join(lines, "\n"),
+		EffectiveModel: effectiveModel,
+	}, nil
+}
+
+// recognitionForm builds the multipart body holding the model name and the
+// image file.
+func recognitionForm(model string, image providers.Image, mediaType string) (*bytes.Buffer, string, error) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	if err := writer.WriteField("model", model); err != nil {
+		return nil, "", err
+	}
+	filename := image.Filename
+	if filename == "" || strings.ContainsAny(filename, "\"\\\r\n") {
+		filename = "image"
+	}
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", `form-data; name="image"; filename="`+filename+`"`)
+	header.Set("Content-Type", mediaType)
+	part, err := writer.CreatePart(header)
+	if err != nil {
+		return nil, "", err
+	}
+	if _, err := part.Write(image.Data); err != nil {
+		return nil, "", err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, "", err
+	}
+	return &body, writer.FormDataContentType(), nil
+}
+
+// New creates the historical CLI adapter.
+func New() *Provider { return &Provider{} }
+
+// Name returns the provider name.
+func (p *Provider) Name() string { return "kraken" }
+
+// Capabilities reports that Kraken returns no token usage and takes neither
+// the prompt nor the temperature.
+func (p *Provider) Capabilities() providers.Capabilities {
+	return providers.Capabilities{}
+}
+
+// MediaTypes returns the image formats Kraken reads, which include TIFF.
+func (p *Provider) MediaTypes() []string {
+	return []string{"image/jpeg", "image/png", "image/tiff"}
+}
+
+// ValidateConfig validates environment-backed CLI configuration: the
+// recognition endpoint and the API token.
+func (p *Provider) ValidateConfig(config providers.Config) error {
+	if _, err := httpclient.ParseEndpoint(resolveEndpoint(config)); err != nil {
+		return providers.NewError(providers.ErrorInvalidRequest, 0, false, nil)
+	}
+	if strings.TrimSpace(os.Getenv("KRAKEN_TOKEN")) == "" {
+		return providers.NewError(providers.ErrorAuthentication, 0, false, nil)
+	}
+	return nil
+}
+
+// ExtractText adapts historical base64 CLI inputs to Client. Without a model,
+// or with eval's OpenAI default, the service's default model is used.
+func (p *Provider) ExtractText(ctx context.Context, config providers.Config, imagePath, imageBase64 string) (string, providers.UsageInfo, error) {
+	if config.Model == "" || config.Model == "gpt-4o" {
+		config.Model = defaultModel
+	}
+	request, err := providers.LegacyRequest(config, imagePath, imageBase64, defaultMaxImageBytes)
+	if err != nil {
+		return "", providers.UsageInfo{}, err
+	}
+	client, err := NewClient(Options{
+		Endpoint: resolveEndpoint(config),
+		Token: func(context.Context) (string, error) {
+			token := os.Getenv("KRAKEN_TOKEN")
+			if strings.TrimSpace(token) == "" {
+				return "", providers.NewError(providers.ErrorAuthentication, 0, false, nil)
+			}
+			return token, nil
+		},
+		Timeout: config.Timeout,
+	})
+	if err != nil {
+		return "", providers.UsageInfo{}, err
+	}
+	result, err := client.Extract(ctx, request)
+	return result.Text, providers.UsageInfo{}, err
+}
+
+// resolveEndpoint returns config.BaseURL, falling back to KRAKEN_URL.
+func resolveEndpoint(config providers.Config) string {
+	if baseURL := strings.TrimSpace(config.BaseURL); baseURL != "" {
+		return baseURL
+	}
+	return strings.TrimSpace(os.Getenv("KRAKEN_URL"))
+}
+
+func positiveOr(value, fallback int64) int64 {
+	if value > 0 {
+		return value
+	}
+	return fallback
+}
+
+func durationOr(value, fallback time.Duration) time.Duration {
+	if value > 0 {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,181 @@
+package kraken
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/lehigh-university-libraries/htr/pkg/providers"
+)
+
+var _ providers.Client = (*Client)(nil)
+var _ providers.Provider = (*Provider)(nil)
+
+func TestClientExtract(t *testing.T) {
+	t.Parallel()
+	image := []byte("encoded-image")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, request *http.Request) {
+		if request.Method != http.MethodPost || request.URL.Path != "/api/recognize" {
+			t.Errorf("unexpected request target: %s %s", request.Method, request.URL.Path)
+		}
+		if got := request.Header.Get("Authorization"); got != "Token escriptorium-token" {
+			t.Errorf("Authorization = %q", got)
+		}
+		if err := request.ParseMultipartForm(1 << 20); err != nil {
+			t.Fatal(err)
+		}
+		if got := request.FormValue("model"); got != "catmus-medieval" {
+			t.Errorf("model = %q", got)
+		}
+		file, header, err := request.FormFile("image")
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, _ := io.ReadAll(file)
+		if string(data) != string(image) || header.Filename != "page.png" || header.Header.Get("Content-Type") != "image/png" {
+			t.Errorf("image part = %q (%s, %s)", data, header.Filename, header.Header.Get("Content-Type"))
+		}
+		_, _ = w.Write([]byte(`{"lines":[{"text":" Dear sir, "},{"text":""},{"text":"I remain"}]}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(Options{Endpoint: server.URL + "/api/recognize", Token: staticToken("escriptorium-token")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := client.Extract(context.Background(), testRequest(image))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Text != "Dear sir,\nI remain" || result.Usage != (providers.UsageInfo{}) || result.EffectiveModel != "catmus-medieval" {
+		t.Fatalf("unexpected result: %#v", result)
+	}
+}
+
+func TestClientErrorsAreTypedAndRedacted(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		status   int
+		body     string
+		wantKind providers.ErrorKind
+	}{
+		{"rejected token", http.StatusUnauthorized, `{"detail":"Invalid token."}`, providers.ErrorAuthentication},
+		{"unknown model", http.StatusNotFound, `{"detail":"Not found."}`, providers.ErrorInvalidRequest},
+		{"no lines", http.StatusOK, `{"lines":[]}`, providers.ErrorInvalidResponse},
+		{"not json", http.StatusOK, `<html>login</html>`, providers.ErrorInvalidResponse},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(test.status)
+				_, _ = w.Write([]byte(test.body))
+			}))
+			defer server.Close()
+			client, err := NewClient(Options{Endpoint: server.URL, Token: staticToken("private-token")})
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = client.Extract(context.Background(), testRequest([]byte("image")))
+			var providerError *providers.Error
+			if !errors.As(err, &providerError) || providerError.Kind != test.wantKind {
+				t.Fatalf("unexpected error: %#v", err)
+			}
+			if strings.Contains(err.Error(), "private-token") || strings.Contains(err.Error(), server.URL) {
+				t.Fatalf("error leaked sensitive data: %q", err)
+			}
+		})
+	}
+}
+
+func TestClientRejectsInvalidInputBeforeNetwork(t *testing.T) {
+	t.Parallel()
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) { calls.Add(1) }))
+	defer server.Close()
+	client, err := NewClient(Options{Endpoint: server.URL, Token: staticToken("token"), MaxImageBytes: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = client.Extract(context.Background(), testRequest([]byte("12345")))
+	var providerError *providers.Error
+	if !errors.As(err, &providerError) || providerError.Kind != providers.ErrorInvalidRequest {
+		t.Fatalf("expected invalid request, got %v", err)
+	}
+	if calls.Load() != 0 {
+		t.Fatal("network called for invalid input")
+	}
+	if _, err := NewClient(Options{Token: staticToken("token")}); err == nil {
+		t.Fatal("NewClient() without an endpoint error = nil")
+	}
+}
+
+func TestLegacyProviderValidation(t *testing.T) {
+	provider := New()
+	if provider.Name() != "kraken" {
+		t.Fatalf("Name() = %q", provider.Name())
+	}
+	t.Setenv("KRAKEN_URL", "")
+	t.Setenv("KRAKEN_TOKEN", "token")
+	if err := provider.ValidateConfig(providers.Config{}); err == nil {
+		t.Fatal("expected missing URL error")
+	}
+	t.Setenv("KRAKEN_URL", "https://escriptorium.example.edu/api/recognize")
+	t.Setenv("KRAKEN_TOKEN", "")
+	if err := provider.ValidateConfig(providers.Config{}); err == nil {
+		t.Fatal("expected missing token error")
+	}
+	t.Setenv("KRAKEN_TOKEN", "token")
+	if err := provider.ValidateConfig(providers.Config{}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLegacyProviderUsesServiceDefaultModel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, request *http.Request) {
+		if got := request.FormValue("model"); got != defaultModel {
+			t.Errorf("model = %q, want %q", got, defaultModel)
+		}
+		_, _ = w.Write([]byte(`{"model":"catmus-medieval","lines":[{"text":"hello"},{"text":"world"}]}`))
+	}))
+	defer server.Close()
+	t.Setenv("KRAKEN_URL", server.URL)
+	t.Setenv("KRAKEN_TOKEN", "token")
+
+	config := providers.Config{Model: "gpt-4o", Prompt: "Transcribe"}
+	text, usage, err := New().ExtractText(context.Background(), config, "line.png", "iVBORw0KGgo=")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text != "hello\nworld" || usage != (providers.UsageInfo{}) {
+		t.Fatalf("ExtractText() = %q, %+v", text, usage)
+	}
+}
+
+func TestProviderCapabilities(t *testing.T) {
+	if got := New().Capabilities(); got != (providers.Capabilities{}) {
+		t.Errorf("Capabilities() = %+v, want no token usage, prompt, or temperature", got)
+	}
+}
+
+func testRequest(image []byte) providers.Request {
+	return providers.Request{
+		Model:  "catmus-medieval",
+		Prompt: "Transcribe café",
+		Image: providers.Image{
+			Data:      image,
+			MediaType: "image/png",
+			Filename:  "page.png",
+		},
+	}
+}
+
+func staticToken(value string) CredentialSource {
+	return func(context.Context) (string, error) { return value, nil }
+}
@@ -4,6 +4,9 @@
 package metrics
 
 import (
+	"math"
+	"regexp"
+	"slices"
 	"strings"
 	"unicode"
 )
@@ -14,9 +17,35 @@ type Options struct {
 	// surrounded by whitespace skips one hypothesis word; an inline pattern
 	// skips one hypothesis rune.
 	IgnorePatterns []string
+	// IgnoreRegex marks ground-truth words to ignore by expression, such as
+	// bracketed illegible spans. An expression must match a whole
+	// whitespace-separated word, which is removed along with one hypothesis
+	// word. Build them with CompileIgnoreRegex. They apply together with
+	// IgnorePatterns, and a word an expression matches is removed whole even
+	// when it also contains a literal pattern.
+	IgnoreRegex []*regexp.Regexp
 	// SingleLine maps CR, LF, and tab characters to spaces and collapses runs
 	// of ASCII spaces before calculating character metrics.
 	SingleLine bool
+	// LineBreakTolerance, when set, scores line breaks as layout. Each line
+	// break becomes a word token, so a break the transcription misses or adds
+	// is a word edit, and a break written as a space is a character edit.
+	// Either costs 1 minus the tolerance, so 0 is strict and 1 ignores layout
+	// like SingleLine. The rates and similarities use these costs while the
+	// edit counts stay unweighted. It has no effect with SingleLine.
+	LineBreakTolerance *float64
+	// IgnoreCase lowercases both strings (and any ignore patterns) after
+	// single-line normalization so capitalization never counts as an edit.
+	IgnoreCase bool
+	// Alignment also returns the word alignment behind the word metrics in
+	// Result.Alignment, along with the normalized words it indexes.
+	Alignment bool
+	// BLEU also computes Result.BLEU over the normalized words.
+	BLEU bool
+	// BagOfWords also computes Result.BagOfWords over the normalized words.
+	BagOfWords bool
+	// SortWords also computes Result.SortedWords over the normalized words.
+	SortWords bool
 }
 
 // Result contains character- and word-level edit metrics.
@@ -34,7 +63,25 @@ type Result struct {
 	Substitutions         int
 	Deletions             int
 	Insertions            int
-	IgnoredCharsCount     int
+	// IgnoredCharsCount is the number of ignore markers removed from the
+	// ground truth. Each occurrence of a pattern, and each word an expression
+	// matches, counts once however many characters it spans.
+	IgnoredCharsCount int
+	// TotalCharsOriginal is the length of the compared ground truth in
+	// characters, which CharacterAccuracy is measured against.
+	TotalCharsOriginal int
+	// BLEU is only set when Options.BLEU is true.
+	BLEU float64
+	// BagOfWords is only set when Options.BagOfWords is true.
+	BagOfWords BagOfWordsScore
+	// SortedWords is only set when Options.SortWords is true.
+	SortedWords SortedWordScore
+
+	// OriginalWords, TranscribedWords, and Alignment are only set when
+	// Options.Alignment is true. Alignment steps index into the word slices.
+	OriginalWords    []string
+	TranscribedWords []string
+	Alignment        []AlignmentStep
 }
 
 // WordEdits contains the edit alignment for two token sequences.
@@ -48,11 +95,7 @@ type WordEdits struct {
 
 // Evaluate compares a ground-truth string with a transcription.
 func Evaluate(original, transcribed string, options Options) Result {
-	if options.SingleLine {
-		original = NormalizeSingleLine(original)
-		transcribed = NormalizeSingleLine(transcribed)
-	}
-	original, transcribed, ignored := ApplyIgnorePatterns(original, transcribed, options.IgnorePatterns)
+	original, transcribed, ignored := Normalize(original, transcribed, options)
 
 	characterDistance := LevenshteinDistance(original, transcribed)
 	originalRunes := len([]rune(original))
@@ -69,7 +112,7 @@ func Evaluate(original, transcribed string, options Options) Result {
 		wordErrorRate = float64(wordEdits.Distance) / float64(len(originalWords))
 	}
 
-	return Result{
+	result := Result{
 		CharacterDistance:     characterDistance,
 		CharacterSimilarity:   Similarity(original, transcribed),
 		CharacterAccuracy:     characterAccuracy,
@@ -79,12 +122,146 @@ func Evaluate(original, transcribed string, options Options) Result {
 		WordErrorRate:         wordErrorRate,
 		TotalWordsOriginal:    len(originalWords),
 		TotalWordsTranscribed: len(transcribedWords),
+		TotalCharsOriginal:    originalRunes,
 		CorrectWords:          wordEdits.Correct,
 		Substitutions:         wordEdits.Substitutions,
 		Deletions:             wordEdits.Deletions,
 		Insertions:            wordEdits.Insertions,
 		IgnoredCharsCount:     ignored,
 	}
+	if options.LineBreakTolerance != nil && !options.SingleLine {
+		applyLineBreakTolerance(&result, original, transcribed, *options.LineBreakTolerance)
+	}
+	if options.BLEU {
+		result.BLEU = BLEU(originalWords, transcribedWords)
+	}
+	if options.BagOfWords {
+		result.BagOfWords = BagOfWords(originalWords, transcribedWords)
+	}
+	if options.SortWords {
+		result.SortedWords = SortedWords(originalWords, transcribedWords)
+	}
+	if options.Alignment {
+		result.OriginalWords = originalWords
+		result.TranscribedWords = transcribedWords
+		result.Alignment = AlignWordSequence(originalWords, transcribedWords)
+	}
+	return result
+}
+
+// lineBreak is the word token a line break becomes under
+// Options.LineBreakTolerance.
+const lineBreak = "\n"
+
+// applyLineBreakTolerance recomputes the character and word rates of result
+// with line-break edits costing 1 minus tolerance.
+func applyLineBreakTolerance(result *Result, original, transcribed string, tolerance float64) {
+	penalty := 1 - tolerance
+	lineBreaks := strings.NewReplacer("\r\n", "\n", "\r", "\n")
+	original = lineBreaks.Replace(original)
+	transcribed = lineBreaks.Replace(transcribed)
+
+	originalRunes, transcribedRunes := []rune(original), []rune(transcribed)
+	characterDistance := weightedDistance(originalRunes, transcribedRunes,
+		func(rune) float64 { return 1 },
+		func(left, right rune) float64 {
+			switch {
+			case left == right:
+				return 0
+			case left == '\n' && unicode.IsSpace(right), right == '\n' && unicode.IsSpace(left):
+				return penalty
+			default:
+				return 1
+			}
+		})
+	result.TotalCharsOriginal = len(originalRunes)
+	if len(originalRunes) > 0 {
+		result.CharacterAccuracy = 1 - characterDistance/float64(len(originalRunes))
+	}
+	if maximum := max(len(originalRunes), len(transcribedRunes)); maximum > 0 {
+		result.CharacterSimilarity = 1 - characterDistance/float64(maximum)
+	}
+
+	originalTokens, transcribedTokens := lineBreakTokens(original), lineBreakTokens(transcribed)
+	wordDistance := weightedDistance(originalTokens, transcribedTokens,
+		func(token string) float64 {
+			if token == lineBreak {
+				return penalty
+			}
+			return 1
+		},
+		func(left, right string) float64 {
+			switch {
+			case left == right:
+				return 0
+			case left == lineBreak || right == lineBreak:
+				// Never cheaper than dropping the break and adding the word
+				return 1 + penalty
+			default:
+				return 1
+			}
+		})
+	if result.TotalWordsOriginal > 0 {
+		result.WordErrorRate = wordDistance / float64(result.TotalWordsOriginal)
+		result.WordAccuracy = 1 - result.WordErrorRate
+	}
+	if maximum := max(len(originalTokens), len(transcribedTokens)); maximum > 0 {
+		result.WordSimilarity = 1 - wordDistance/float64(maximum)
+	}
+}
+
+// lineBreakTokens splits text into words with a lineBreak token between
+// lines. Leading and trailing line breaks are dropped.
+func lineBreakTokens(text string) []string {
+	var tokens []string
+	for index, line := range strings.Split(strings.TrimSpace(text), "\n") {
+		if index > 0 {
+			tokens = append(tokens, lineBreak)
+		}
+		tokens = append(tokens, strings.Fields(line)...)
+	}
+	return tokens
+}
+
+// Normalize applies the single-line, ignore-case, and ignore-pattern options
+// in the order Evaluate uses them. It returns the strings that are compared
+// and the number of ignore markers removed.
+func Normalize(original, transcribed string, options Options) (string, string, int) {
+	if options.SingleLine {
+		original = NormalizeSingleLine(original)
+		transcribed = NormalizeSingleLine(transcribed)
+	}
+	ignorePatterns := options.IgnorePatterns
+	ignoreRegex := options.IgnoreRegex
+	if options.IgnoreCase {
+		original = strings.ToLower(original)
+		transcribed = strings.ToLower(transcribed)
+		ignorePatterns = make([]string, len(options.IgnorePatterns))
+		for index, pattern := range options.IgnorePatterns {
+			ignorePatterns[index] = strings.ToLower(pattern)
+		}
+		ignoreRegex = make([]*regexp.Regexp, len(options.IgnoreRegex))
+		for index, expression := range options.IgnoreRegex {
+			ignoreRegex[index] = regexp.MustCompile("(?i)" + expression.String())
+		}
+	}
+	return applyIgnore(original, transcribed, ignorePatterns, ignoreRegex)
+}
+
+// CompileIgnoreRegex compiles expressions for Options.IgnoreRegex, anchoring
+// each so it has to match a whole word.
+func CompileIgnoreRegex(expressions []string) ([]*regexp.Regexp, error) {
+	compiled := make([]*regexp.Regexp, 0, len(expressions))
+	for _, expression := range expressions {
+		if expression == "" {
+			continue
+		}
+		if _, err := regexp.Compile(expression); err != nil {
+			return nil, err
+		}
+		compiled = append(compiled, regexp.MustCompile("^(?:"+expression+")$"))
+	}
+	return compiled, nil
 }
 
 // LevenshteinDistance returns the Unicode code-point edit distance between two
@@ -121,6 +298,59 @@ func WordSimilarity(left, right []string) float64 {
 // AlignWords calculates word-level substitutions, deletions, insertions, and
 // exact matches using a deterministic minimum-edit alignment.
 func AlignWords(original, transcribed []string) WordEdits {
+	edits := WordEdits{}
+	for _, step := range AlignWordSequence(original, transcribed) {
+		switch step.Op {
+		case EditEqual:
+			edits.Correct++
+		case EditSubstitute:
+			edits.Substitutions++
+		case EditDelete:
+			edits.Deletions++
+		case EditInsert:
+			edits.Insertions++
+		}
+	}
+	edits.Distance = edits.Substitutions + edits.Deletions + edits.Insertions
+	return edits
+}
+
+// EditOp is one step in a word or character alignment.
+type EditOp int
+
+const (
+	// EditEqual pairs identical words.
+	EditEqual EditOp = iota
+	// EditSubstitute pairs a ground-truth word with a different transcribed word.
+	EditSubstitute
+	// EditDelete marks a ground-truth word missing from the transcription.
+	EditDelete
+	// EditInsert marks a transcribed word absent from the ground truth.
+	EditInsert
+)
+
+// AlignmentStep pairs word (or rune) indexes for one edit operation. Original
+// is -1 for insertions and Transcribed is -1 for deletions.
+type AlignmentStep struct {
+	Op          EditOp
+	Original    int
+	Transcribed int
+}
+
+// AlignWordSequence returns the minimum-edit alignment of two token sequences
+// in reading order. It is the backtrace used by AlignWords.
+func AlignWordSequence(original, transcribed []string) []AlignmentStep {
+	return alignSequence(original, transcribed)
+}
+
+// AlignCharacters returns the minimum-edit alignment of two strings by Unicode
+// code point. Step indexes refer to positions in []rune(original) and
+// []rune(transcribed).
+func AlignCharacters(original, transcribed string) []AlignmentStep {
+	return alignSequence([]rune(original), []rune(transcribed))
+}
+
+func alignSequence[T comparable](original, transcribed []T) []AlignmentStep {
 	rows, columns := len(original), len(transcribed)
 	matrix := make([][]int, rows+1)
 	for row := range matrix {
@@ -143,43 +373,292 @@ func AlignWords(original, transcribed []string) WordEdits {
 		}
 	}
 
-	edits := WordEdits{Distance: matrix[rows][columns]}
+	steps := make([]AlignmentStep, 0, max(rows, columns))
 	for row, column := rows, columns; row > 0 || column > 0; {
 		switch {
 		case row > 0 && column > 0 && original[row-1] == transcribed[column-1]:
-			edits.Correct++
+			steps = append(steps, AlignmentStep{Op: EditEqual, Original: row - 1, Transcribed: column - 1})
 			row--
 			column--
 		case row > 0 && column > 0 && matrix[row][column] == matrix[row-1][column-1]+1:
-			edits.Substitutions++
+			steps = append(steps, AlignmentStep{Op: EditSubstitute, Original: row - 1, Transcribed: column - 1})
 			row--
 			column--
 		case row > 0 && matrix[row][column] == matrix[row-1][column]+1:
-			edits.Deletions++
+			steps = append(steps, AlignmentStep{Op: EditDelete, Original: row - 1, Transcribed: -1})
 			row--
 		default:
-			edits.Insertions++
+			steps = append(steps, AlignmentStep{Op: EditInsert, Original: -1, Transcribed: column - 1})
 			column--
 		}
 	}
-	return edits
+	slices.Reverse(steps)
+	return steps
+}
+
+// BagOfWordsScore is an order-insensitive comparison of two word multisets.
+type BagOfWordsScore struct {
+	Precision float64
+	Recall    float64
+	F1        float64
+}
+
+// BagOfWords compares the word multisets of original and transcribed,
+// ignoring word order. A word matches at most as many times as it occurs in
+// both. Precision is measured against the transcription and recall against
+// the ground truth. Two empty inputs match perfectly.
+func BagOfWords(original, transcribed []string) BagOfWordsScore {
+	if len(original) == 0 && len(transcribed) == 0 {
+		return BagOfWordsScore{Precision: 1, Recall: 1, F1: 1}
+	}
+
+	originalCounts := make(map[string]int, len(original))
+	for _, word := range original {
+		originalCounts[word]++
+	}
+	matches := 0
+	for _, word := range transcribed {
+		if originalCounts[word] > 0 {
+			originalCounts[word]--
+			matches++
+		}
+	}
+
+	var score BagOfWordsScore
+	if len(transcribed) > 0 {
+		score.Precision = float64(matches) / float64(len(transcribed))
+	}
+	if len(original) > 0 {
+		score.Recall = float64(matches) / float64(len(original))
+	}
+	if score.Precision+score.Recall > 0 {
+		score.F1 = 2 * score.Precision * score.Recall / (score.Precision + score.Recall)
+	}
+	return score
+}
+
+// SortedWordScore is the word accuracy and similarity of two word sequences
+// after both are sorted.
+type SortedWordScore struct {
+	Accuracy   float64
+	Similarity float64
+}
+
+// SortedWords sorts copies of original and transcribed and compares them with
+// the ordered word metrics, so words emitted in a different order (such as
+// table cells read row by row instead of column by column) are not counted
+// as errors. A misread word can sort into a different position and count as
+// two edits, so it is a diagnostic alongside the ordered metrics, not a
+// replacement for them. Two empty inputs match perfectly.
+func SortedWords(original, transcribed []string) SortedWordScore {
+	original = slices.Sorted(slices.Values(original))
+	transcribed = slices.Sorted(slices.Values(transcribed))
+
+	accuracy := 1.0
+	if len(original) > 0 {
+		accuracy = 1.0 - float64(WordLevenshteinDistance(original, transcribed))/float64(len(original))
+	}
+	return SortedWordScore{
+		Accuracy:   accuracy,
+		Similarity: WordSimilarity(original, transcribed),
+	}
+}
+
+// bleuMaxOrder is the longest n-gram used by BLEU.
+const bleuMaxOrder = 4
+
+// BLEU returns the sentence-level BLEU score of hypothesis against a single
+// reference: the geometric mean of clipped 1- to 4-gram precisions, scaled by
+// a brevity penalty when the hypothesis is shorter than the reference. No
+// smoothing is applied, so any n-gram order without a match (including a
+// hypothesis shorter than four words) scores 0.
+func BLEU(reference, hypothesis []string) float64 {
+	if len(reference) == 0 || len(hypothesis) == 0 {
+		return 0
+	}
+
+	logPrecision := 0.0
+	for order := 1; order <= bleuMaxOrder; order++ {
+		hypothesisCounts := ngramCounts(hypothesis, order)
+		referenceCounts := ngramCounts(reference, order)
+		matches, total := 0, 0
+		for ngram, count := range hypothesisCounts {
+			matches += min(count, referenceCounts[ngram])
+			total += count
+		}
+		if matches == 0 {
+			return 0
+		}
+		logPrecision += math.Log(float64(matches)/float64(total)) / bleuMaxOrder
+	}
+
+	brevityPenalty := 1.0
+	if len(hypothesis) < len(reference) {
+		brevityPenalty = math.Exp(1 - float64(len(reference))/float64(len(hypothesis)))
+	}
+	return brevityPenalty * math.Exp(logPrecision)
+}
+
+// ngramCounts counts the n-grams of words, keyed by the words joined with a
+// space, which never appears inside a whitespace-split token.
+func ngramCounts(words []string, order int) map[string]int {
+	counts := make(map[string]int)
+	for start := 0; start+order <= len(words); start++ {
+		counts[strings.Join(words[start:start+order], " ")]++
+	}
+	return counts
+}
+
+// LineMetric scores one aligned pair of ground-truth and transcribed lines.
+// Line numbers are 1-based positions among non-blank lines; GroundTruthLine is 0 for an extra transcribed line
+// and TranscribedLine is 0 for a ground-truth line missing from the
+// transcription. Unpaired lines score 0.
+type LineMetric struct {
+	GroundTruthLine   int     `json:"ground_truth_line"`
+	TranscribedLine   int     `json:"transcribed_line"`
+	GroundTruth       string  `json:"ground_truth"`
+	Transcription     string  `json:"transcription"`
+	CharacterAccuracy float64 `json:"character_accuracy"`
+	WordAccuracy      float64 `json:"word_accuracy"`
+}
+
+// EvaluateLines pairs the non-blank lines of original and transcribed with
+// AlignLines and scores each pair with Evaluate. SingleLine is ignored.
+func EvaluateLines(original, transcribed string, options Options) []LineMetric {
+	options = Options{IgnorePatterns: options.IgnorePatterns, IgnoreRegex: options.IgnoreRegex, IgnoreCase: options.IgnoreCase}
+	originalLines, transcribedLines := splitLines(original), splitLines(transcribed)
+
+	compareOriginal, compareTranscribed := originalLines, transcribedLines
+	if options.IgnoreCase {
+		compareOriginal = lowerLines(originalLines)
+		compareTranscribed = lowerLines(transcribedLines)
+	}
+
+	steps := AlignLines(compareOriginal, compareTranscribed)
+	lines := make([]LineMetric, 0, len(steps))
+	for _, step := range steps {
+		line := LineMetric{GroundTruthLine: step.Original + 1, TranscribedLine: step.Transcribed + 1}
+		if step.Original >= 0 {
+			line.GroundTruth = originalLines[step.Original]
+		}
+		if step.Transcribed >= 0 {
+			line.Transcription = transcribedLines[step.Transcribed]
+		}
+		if step.Op == EditEqual || step.Op == EditSubstitute {
+			result := Evaluate(line.GroundTruth, line.Transcription, options)
+			line.CharacterAccuracy = result.CharacterAccuracy
+			line.WordAccuracy = result.WordAccuracy
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// AlignLines aligns two sequences of lines. Unlike AlignWordSequence, pairing
+// two different lines costs one minus their character similarity, so a
+// missing or extra line shifts the alignment instead of mispairing every
+// line after it.
+func AlignLines(original, transcribed []string) []AlignmentStep {
+	rows, columns := len(original), len(transcribed)
+	substitution := func(row, column int) float64 {
+		return 1 - Similarity(original[row], transcribed[column])
+	}
+	matrix := make([][]float64, rows+1)
+	for row := range matrix {
+		matrix[row] = make([]float64, columns+1)
+		matrix[row][0] = float64(row)
+	}
+	for column := 0; column <= columns; column++ {
+		matrix[0][column] = float64(column)
+	}
+	for row := 1; row <= rows; row++ {
+		for column := 1; column <= columns; column++ {
+			matrix[row][column] = min(
+				matrix[row-1][column-1]+substitution(row-1, column-1),
+				matrix[row-1][column]+1,
+				matrix[row][column-1]+1,
+			)
+		}
+	}
+
+	const epsilon = 1e-9
+	steps := make([]AlignmentStep, 0, max(rows, columns))
+	for row, column := rows, columns; row > 0 || column > 0; {
+		switch {
+		case row > 0 && column > 0 && math.Abs(matrix[row][column]-(matrix[row-1][column-1]+substitution(row-1, column-1))) < epsilon:
+			op := EditSubstitute
+			if original[row-1] == transcribed[column-1] {
+				op = EditEqual
+			}
+			steps = append(steps, AlignmentStep{Op: op, Original: row - 1, Transcribed: column - 1})
+			row--
+			column--
+		case row > 0 && math.Abs(matrix[row][column]-(matrix[row-1][column]+1)) < epsilon:
+			steps = append(steps, AlignmentStep{Op: EditDelete, Original: row - 1, Transcribed: -1})
+			row--
+		default:
+			steps = append(steps, AlignmentStep{Op: EditInsert, Original: -1, Transcribed: column - 1})
+			column--
+		}
+	}
+	slices.Reverse(steps)
+	return steps
+}
+
+// splitLines returns the non-blank lines of text without trailing carriage returns.
+func splitLines(text string) []string {
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if strings.TrimSpace(line) != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+func lowerLines(lines []string) []string {
+	lowered := make([]string, len(lines))
+	for index, line := range lines {
+		lowered[index] = strings.ToLower(line)
+	}
+	return lowered
 }
 
 // NormalizeSingleLine maps line-breaking whitespace to spaces and collapses
 // repeated ASCII spaces. Leading and trailing single spaces are preserved so
 // this remains compatible with historical HTR evaluation output.
 func NormalizeSingleLine(text string) string {
-	text = strings.NewReplacer("\n", " ", "\r", " ", "\t", " ").Replace(text)
-	for strings.Contains(text, "  ") {
-		text = strings.ReplaceAll(text, "  ", " ")
+	var builder strings.Builder
+	builder.Grow(len(text))
+	previousSpace := false
+	for index := 0; index < len(text); index++ {
+		char := text[index]
+		switch char {
+		case ' ', '\n', '\r', '\t':
+			if !previousSpace {
+				builder.WriteByte(' ')
+			}
+			previousSpace = true
+		default:
+			builder.WriteByte(char)
+			previousSpace = false
+		}
 	}
-	return text
+	return builder.String()
 }
 
 // ApplyIgnorePatterns removes unknown markers from the ground truth and skips
-// the corresponding rune or word in the transcription.
+// the corresponding rune or word in the transcription. It also returns the
+// number of markers removed.
 func ApplyIgnorePatterns(groundTruth, transcription string, patterns []string) (string, string, int) {
-	if len(patterns) == 0 {
+	return applyIgnore(groundTruth, transcription, patterns, nil)
+}
+
+// applyIgnore removes ground-truth words matching an expression, then literal
+// patterns, skipping the corresponding transcription word or rune for each.
+func applyIgnore(groundTruth, transcription string, patterns []string, expressions []*regexp.Regexp) (string, string, int) {
+	if len(patterns) == 0 && len(expressions) == 0 {
 		return groundTruth, transcription, 0
 	}
 
@@ -191,42 +670,111 @@ func ApplyIgnorePatterns(groundTruth, transcription string, patterns []string) (
 			continue
 		}
 		patternRunes = append(patternRunes, runes)
-		ignoredCount += strings.Count(groundTruth, pattern) * len(runes)
 	}
 
 	groundTruthRunes := []rune(groundTruth)
 	transcriptionRunes := []rune(transcription)
-	var processedGroundTruth strings.Builder
-	var processedTranscription strings.Builder
+	processedGroundTruth := make([]rune, 0, len(groundTruthRunes))
+	processedTranscription := make([]rune, 0, len(transcriptionRunes))
 	groundTruthIndex, transcriptionIndex := 0, 0
+
+	// removeWord drops the standalone ground-truth word ending at
+	// groundTruthIndex and the transcription word in its place. Each side
+	// also drops the space after the word when its text so far already ends
+	// in one, or the space before it at the end of the ground truth, so the
+	// words either side are not left two spaces apart, which would count
+	// against the character metrics.
+	removeWord := func() {
+		transcriptionIndex = skipWord(transcriptionRunes, transcriptionIndex)
+		if groundTruthIndex < len(groundTruthRunes) {
+			if endsAtBoundary(processedGroundTruth) {
+				groundTruthIndex++
+			}
+			if endsAtBoundary(processedTranscription) && transcriptionIndex < len(transcriptionRunes) && unicode.IsSpace(transcriptionRunes[transcriptionIndex]) {
+				transcriptionIndex++
+			}
+			return
+		}
+		processedGroundTruth = trimTrailingSpace(processedGroundTruth)
+		processedTranscription = trimTrailingSpace(processedTranscription)
+	}
+
 	for groundTruthIndex < len(groundTruthRunes) {
+		if word := matchingWord(groundTruthRunes, groundTruthIndex, expressions); len(word) > 0 {
+			ignoredCount++
+			groundTruthIndex += len(word)
+			removeWord()
+			continue
+		}
+
 		matched := matchingPattern(groundTruthRunes[groundTruthIndex:], patternRunes)
 		if len(matched) == 0 {
-			processedGroundTruth.WriteRune(groundTruthRunes[groundTruthIndex])
+			processedGroundTruth = append(processedGroundTruth, groundTruthRunes[groundTruthIndex])
 			if transcriptionIndex < len(transcriptionRunes) {
-				processedTranscription.WriteRune(transcriptionRunes[transcriptionIndex])
+				processedTranscription = append(processedTranscription, transcriptionRunes[transcriptionIndex])
 				transcriptionIndex++
 			}
 			groundTruthIndex++
 			continue
 		}
 
+		ignoredCount++
 		beforeSpace := groundTruthIndex == 0 || unicode.IsSpace(groundTruthRunes[groundTruthIndex-1])
 		afterIndex := groundTruthIndex + len(matched)
 		afterSpace := afterIndex >= len(groundTruthRunes) || unicode.IsSpace(groundTruthRunes[afterIndex])
 		groundTruthIndex = afterIndex
 		if beforeSpace && afterSpace {
-			for transcriptionIndex < len(transcriptionRunes) && unicode.IsSpace(transcriptionRunes[transcriptionIndex]) {
-				transcriptionIndex++
-			}
-			for transcriptionIndex < len(transcriptionRunes) && !unicode.IsSpace(transcriptionRunes[transcriptionIndex]) {
-				transcriptionIndex++
-			}
+			removeWord()
 		} else if transcriptionIndex < len(transcriptionRunes) {
 			transcriptionIndex++
 		}
 	}
-	return processedGroundTruth.String(), processedTranscription.String(), ignoredCount
+	return string(processedGroundTruth), string(processedTranscription), ignoredCount
+}
+
+// endsAtBoundary reports whether runes is empty or ends in whitespace, so a
+// space appended to it would be redundant.
+func endsAtBoundary(runes []rune) bool {
+	return len(runes) == 0 || unicode.IsSpace(runes[len(runes)-1])
+}
+
+// trimTrailingSpace drops the last rune of runes when it is whitespace.
+func trimTrailingSpace(runes []rune) []rune {
+	if len(runes) > 0 && unicode.IsSpace(runes[len(runes)-1]) {
+		return runes[:len(runes)-1]
+	}
+	return runes
+}
+
+// skipWord returns the index just past the word at or after index, skipping
+// any whitespace before it.
+func skipWord(runes []rune, index int) int {
+	for index < len(runes) && unicode.IsSpace(runes[index]) {
+		index++
+	}
+	for index < len(runes) && !unicode.IsSpace(runes[index]) {
+		index++
+	}
+	return index
+}
+
+// matchingWord returns the word starting at index when an expression matches
+// it whole, or nil when index is not the start of a word or none matches.
+func matchingWord(runes []rune, index int, expressions []*regexp.Regexp) []rune {
+	if len(expressions) == 0 || unicode.IsSpace(runes[index]) || (index > 0 && !unicode.IsSpace(runes[index-1])) {
+		return nil
+	}
+	end := index
+	for end < len(runes) && !unicode.IsSpace(runes[end]) {
+		end++
+	}
+	word := runes[index:end]
+	for _, expression := range expressions {
+		if expression.MatchString(string(word)) {
+			return word
+		}
+	}
+	return nil
 }
 
 func matchingPattern(value []rune, patterns [][]rune) []rune {
@@ -276,3 +824,71 @@ func sequenceDistance[T comparable](left, right []T) int {
 	}
 	return previous[len(right)]
 }
+
+// weightedDistance returns the edit distance between two sequences with the
+// cost of inserting or deleting each item given by indel and of replacing
+// one item with another by substitution.
+func weightedDistance[T comparable](left, right []T, indel func(T) float64, substitution func(T, T) float64) float64 {
+	previous := make([]float64, len(right)+1)
+	current := make([]float64, len(right)+1)
+	for column := 1; column <= len(right); column++ {
+		previous[column] = previous[column-1] + indel(right[column-1])
+	}
+	for row := 1; row <= len(left); row++ {
+		current[0] = previous[0] + indel(left[row-1])
+		for column := 1; column <= len(right); column++ {
+			current[column] = min(
+				previous[column]+indel(left[row-1]),
+				current[column-1]+indel(right[column-1]),
+				previous[column-1]+substitution(left[row-1], right[column-1]),
+			)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(right)]
+}
+
+// Stats describes the distribution of a per-document metric.
+type Stats struct {
+	Mean   float64
+	Median float64
+	StdDev float64
+	Min    float64
+	Max    float64
+}
+
+// Summarize returns the mean, median, population standard deviation, minimum,
+// and maximum of values. An empty slice yields zero-valued Stats.
+func Summarize(values []float64) Stats {
+	if len(values) == 0 {
+		return Stats{}
+	}
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+
+	total := 0.0
+	for _, value := range sorted {
+		total += value
+	}
+	count := float64(len(sorted))
+	mean := total / count
+
+	variance := 0.0
+	for _, value := range sorted {
+		variance += (value - mean) * (value - mean)
+	}
+
+	middle := len(sorted) / 2
+	median := sorted[middle]
+	if len(sorted)%2 == 0 {
+		median = (sorted[middle-1] + sorted[middle]) / 2
+	}
+
+	return Stats{
+		Mean:   mean,
+		Median: median,
+		StdDev: math.Sqrt(variance / count),
+		Min:    sorted[0],
+		Max:    sorted[len(sorted)-1],
+	}
+}
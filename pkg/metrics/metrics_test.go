@@ -1,6 +1,10 @@
 package metrics_test
 
 import (
+	"math"
+	"regexp"
+	"slices"
+	"strings"
 	"testing"
 
 	"github.com/lehigh-university-libraries/htr/pkg/metrics"
@@ -35,6 +39,126 @@ func TestEvaluateWordEditsAndIgnorePatterns(t *testing.T) {
 	}
 }
 
+func TestEvaluateIgnoredWordsLeaveNoExtraSpace(t *testing.T) {
+	tests := []struct {
+		name        string
+		original    string
+		transcribed string
+		options     metrics.Options
+	}{
+		{"middle", "hello | world", "hello foo world", metrics.Options{IgnorePatterns: []string{"|"}}},
+		{"start", "| hello world", "foo hello world", metrics.Options{IgnorePatterns: []string{"|"}}},
+		{"end", "hello world |", "hello world foo", metrics.Options{IgnorePatterns: []string{"|"}}},
+		{"adjacent", "hello | | world", "hello foo bar world", metrics.Options{IgnorePatterns: []string{"|"}}},
+		{"single line", "hello |\nworld", "hello foo\nworld", metrics.Options{IgnorePatterns: []string{"|"}, SingleLine: true}},
+		{"single line collapses runs", "hello  |\t\tworld", "hello foo   world", metrics.Options{IgnorePatterns: []string{"|"}, SingleLine: true}},
+		{"expression", "hello [illegible] world", "hello foo world", metrics.Options{IgnoreRegex: mustCompileIgnoreRegex(t, `\[.*?\]`)}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			original, transcribed, _ := metrics.Normalize(test.original, test.transcribed, test.options)
+			if original != "hello world" || transcribed != "hello world" {
+				t.Errorf("Normalize() = %q, %q; want both %q", original, transcribed, "hello world")
+			}
+			result := metrics.Evaluate(test.original, test.transcribed, test.options)
+			if result.CharacterSimilarity != 1.0 || result.CharacterAccuracy != 1.0 {
+				t.Errorf("character similarity = %v, accuracy = %v; want 1.0", result.CharacterSimilarity, result.CharacterAccuracy)
+			}
+		})
+	}
+}
+
+func TestEvaluateIgnoreRegex(t *testing.T) {
+	bracketed, err := metrics.CompileIgnoreRegex([]string{`\[.*?\]`})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name          string
+		original      string
+		transcribed   string
+		options       metrics.Options
+		wantOriginal  []string
+		wantCorrect   int
+		wantIgnored   int
+		wantWordError float64
+	}{
+		{
+			name:         "bracketed words skip one transcription word each",
+			original:     "the [illegible] cat sat on [?]",
+			transcribed:  "the quick cat sat on mat",
+			options:      metrics.Options{IgnoreRegex: bracketed},
+			wantOriginal: []string{"the", "cat", "sat", "on"},
+			wantCorrect:  4,
+			wantIgnored:  2,
+		},
+		{
+			name:         "expression must match the whole word",
+			original:     "see note[1] here",
+			transcribed:  "see note here",
+			options:      metrics.Options{IgnoreRegex: bracketed},
+			wantOriginal: []string{"see", "note[1]", "here"},
+			wantCorrect:  2,
+			// note[1] is substituted by note
+			wantWordError: 1.0 / 3,
+		},
+		{
+			name:         "composes with literal patterns",
+			original:     "the [gap] cat | d|te",
+			transcribed:  "the big cat sat date",
+			options:      metrics.Options{IgnoreRegex: bracketed, IgnorePatterns: []string{"|"}},
+			wantOriginal: []string{"the", "cat", "dte"},
+			wantCorrect:  3,
+			wantIgnored:  3,
+		},
+		{
+			name:         "ignore case applies to expressions",
+			original:     "The ILLEGIBLE cat",
+			transcribed:  "the dog cat",
+			options:      metrics.Options{IgnoreRegex: mustCompileIgnoreRegex(t, "illegible"), IgnoreCase: true},
+			wantOriginal: []string{"the", "cat"},
+			wantCorrect:  2,
+			wantIgnored:  1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.options.Alignment = true
+			result := metrics.Evaluate(test.original, test.transcribed, test.options)
+			if !slices.Equal(result.OriginalWords, test.wantOriginal) {
+				t.Errorf("original words = %q, want %q", result.OriginalWords, test.wantOriginal)
+			}
+			if result.IgnoredCharsCount != test.wantIgnored {
+				t.Errorf("ignored chars = %d, want %d", result.IgnoredCharsCount, test.wantIgnored)
+			}
+			if result.CorrectWords != test.wantCorrect {
+				t.Errorf("correct words = %d, want %d", result.CorrectWords, test.wantCorrect)
+			}
+			if math.Abs(result.WordErrorRate-test.wantWordError) > 1e-9 {
+				t.Errorf("word error rate = %v, want %v", result.WordErrorRate, test.wantWordError)
+			}
+		})
+	}
+}
+
+func TestCompileIgnoreRegexRejectsInvalidExpressions(t *testing.T) {
+	if _, err := metrics.CompileIgnoreRegex([]string{`[unclosed`}); err == nil {
+		t.Fatal("CompileIgnoreRegex() error = nil, want error for an invalid expression")
+	}
+}
+
+func mustCompileIgnoreRegex(t *testing.T, expressions ...string) []*regexp.Regexp {
+	t.Helper()
+	compiled, err := metrics.CompileIgnoreRegex(expressions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return compiled
+}
+
 func TestEvaluateSingleLineAndUnicodeDenominator(t *testing.T) {
 	result := metrics.Evaluate("école\n世界", "ecole 世界", metrics.Options{SingleLine: true})
 	if result.CharacterDistance != 1 {
@@ -45,6 +169,88 @@ func TestEvaluateSingleLineAndUnicodeDenominator(t *testing.T) {
 	}
 }
 
+func TestEvaluateLineBreakTolerance(t *testing.T) {
+	tolerance := func(value float64) *float64 { return &value }
+	original := "the quick\nbrown fox"
+
+	tests := []struct {
+		name              string
+		transcribed       string
+		options           metrics.Options
+		wantCharAccuracy  float64
+		wantWordAccuracy  float64
+		wantWordErrorRate float64
+	}{
+		{
+			name:             "unset counts the break as a character only",
+			transcribed:      "the quick brown fox",
+			wantCharAccuracy: 1 - 1.0/19,
+			wantWordAccuracy: 1,
+		},
+		{
+			name:              "strict",
+			transcribed:       "the quick brown fox",
+			options:           metrics.Options{LineBreakTolerance: tolerance(0)},
+			wantCharAccuracy:  1 - 1.0/19,
+			wantWordAccuracy:  0.75,
+			wantWordErrorRate: 0.25,
+		},
+		{
+			name:              "half",
+			transcribed:       "the quick brown fox",
+			options:           metrics.Options{LineBreakTolerance: tolerance(0.5)},
+			wantCharAccuracy:  1 - 0.5/19,
+			wantWordAccuracy:  0.875,
+			wantWordErrorRate: 0.125,
+		},
+		{
+			name:             "full",
+			transcribed:      "the quick brown fox",
+			options:          metrics.Options{LineBreakTolerance: tolerance(1)},
+			wantCharAccuracy: 1,
+			wantWordAccuracy: 1,
+		},
+		{
+			name:             "single line is full tolerance",
+			transcribed:      "the quick brown fox",
+			options:          metrics.Options{SingleLine: true, LineBreakTolerance: tolerance(0)},
+			wantCharAccuracy: 1,
+			wantWordAccuracy: 1,
+		},
+		{
+			name:              "strict extra break",
+			transcribed:       "the\nquick\nbrown fox",
+			options:           metrics.Options{LineBreakTolerance: tolerance(0)},
+			wantCharAccuracy:  1 - 1.0/19,
+			wantWordAccuracy:  0.75,
+			wantWordErrorRate: 0.25,
+		},
+		{
+			name:              "matching layout with a word error",
+			transcribed:       "the quack\r\nbrown fox",
+			options:           metrics.Options{LineBreakTolerance: tolerance(0)},
+			wantCharAccuracy:  1 - 1.0/19,
+			wantWordAccuracy:  0.75,
+			wantWordErrorRate: 0.25,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := metrics.Evaluate(original, test.transcribed, test.options)
+			if math.Abs(result.CharacterAccuracy-test.wantCharAccuracy) > 1e-9 {
+				t.Errorf("character accuracy = %v, want %v", result.CharacterAccuracy, test.wantCharAccuracy)
+			}
+			if math.Abs(result.WordAccuracy-test.wantWordAccuracy) > 1e-9 {
+				t.Errorf("word accuracy = %v, want %v", result.WordAccuracy, test.wantWordAccuracy)
+			}
+			if math.Abs(result.WordErrorRate-test.wantWordErrorRate) > 1e-9 {
+				t.Errorf("word error rate = %v, want %v", result.WordErrorRate, test.wantWordErrorRate)
+			}
+		})
+	}
+}
+
 func TestAlignWords(t *testing.T) {
 	edits := metrics.AlignWords(
 		[]string{"one", "two", "three"},
@@ -54,3 +260,327 @@ func TestAlignWords(t *testing.T) {
 		t.Fatalf("AlignWords() = %+v", edits)
 	}
 }
+
+func TestEvaluateIgnoreCase(t *testing.T) {
+	result := metrics.Evaluate("Hello WORLD", "hello world", metrics.Options{IgnoreCase: true})
+	if result.CharacterDistance != 0 || result.WordErrorRate != 0 {
+		t.Fatalf("Evaluate() = %+v, want an exact case-insensitive match", result)
+	}
+	result = metrics.Evaluate("Hello WORLD", "hello world", metrics.Options{})
+	if result.CharacterDistance != 6 || result.CorrectWords != 0 {
+		t.Fatalf("Evaluate() = %+v, want case differences counted by default", result)
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		want   metrics.Stats
+	}{
+		{"empty", nil, metrics.Stats{}},
+		{"single value", []float64{0.5}, metrics.Stats{Mean: 0.5, Median: 0.5, StdDev: 0, Min: 0.5, Max: 0.5}},
+		{"odd count unsorted", []float64{9, 1, 5}, metrics.Stats{Mean: 5, Median: 5, StdDev: math.Sqrt(32.0 / 3), Min: 1, Max: 9}},
+		{"even count", []float64{2, 4, 4, 4, 5, 5, 7, 9}, metrics.Stats{Mean: 5, Median: 4.5, StdDev: 2, Min: 2, Max: 9}},
+		{"bimodal clean and messy pages", []float64{1, 1, 0, 0}, metrics.Stats{Mean: 0.5, Median: 0.5, StdDev: 0.5, Min: 0, Max: 1}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := metrics.Summarize(test.values)
+			if math.Abs(got.Mean-test.want.Mean) > 1e-9 || math.Abs(got.Median-test.want.Median) > 1e-9 ||
+				math.Abs(got.StdDev-test.want.StdDev) > 1e-9 || got.Min != test.want.Min || got.Max != test.want.Max {
+				t.Fatalf("Summarize(%v) = %+v, want %+v", test.values, got, test.want)
+			}
+		})
+	}
+}
+
+func TestAlignWordSequence(t *testing.T) {
+	steps := metrics.AlignWordSequence(
+		[]string{"the", "quick", "fox", "jumps"},
+		[]string{"the", "quack", "fox", "jumps", "high"},
+	)
+	want := []metrics.AlignmentStep{
+		{Op: metrics.EditEqual, Original: 0, Transcribed: 0},
+		{Op: metrics.EditSubstitute, Original: 1, Transcribed: 1},
+		{Op: metrics.EditEqual, Original: 2, Transcribed: 2},
+		{Op: metrics.EditEqual, Original: 3, Transcribed: 3},
+		{Op: metrics.EditInsert, Original: -1, Transcribed: 4},
+	}
+	if len(steps) != len(want) {
+		t.Fatalf("AlignWordSequence() = %+v, want %+v", steps, want)
+	}
+	for index := range want {
+		if steps[index] != want[index] {
+			t.Fatalf("step %d = %+v, want %+v", index, steps[index], want[index])
+		}
+	}
+
+	deletions := metrics.AlignWordSequence([]string{"a", "b"}, nil)
+	if len(deletions) != 2 || deletions[0].Op != metrics.EditDelete || deletions[1].Transcribed != -1 {
+		t.Fatalf("AlignWordSequence() with empty transcription = %+v", deletions)
+	}
+}
+
+func TestEvaluateAlignment(t *testing.T) {
+	result := metrics.Evaluate("The quick fox", "the quack fox", metrics.Options{IgnoreCase: true})
+	if result.Alignment != nil || result.OriginalWords != nil {
+		t.Fatalf("alignment returned without Options.Alignment: %+v", result)
+	}
+
+	result = metrics.Evaluate("The quick fox", "the quack fox", metrics.Options{IgnoreCase: true, Alignment: true})
+	if len(result.Alignment) != 3 || result.Alignment[1].Op != metrics.EditSubstitute {
+		t.Fatalf("Alignment = %+v, want substitution at the second word", result.Alignment)
+	}
+	if result.OriginalWords[0] != "the" || result.TranscribedWords[1] != "quack" {
+		t.Fatalf("words = %q / %q, want normalized words", result.OriginalWords, result.TranscribedWords)
+	}
+}
+
+func TestAlignCharacters(t *testing.T) {
+	steps := metrics.AlignCharacters("ſun", "fun!")
+	want := []metrics.AlignmentStep{
+		{Op: metrics.EditSubstitute, Original: 0, Transcribed: 0},
+		{Op: metrics.EditEqual, Original: 1, Transcribed: 1},
+		{Op: metrics.EditEqual, Original: 2, Transcribed: 2},
+		{Op: metrics.EditInsert, Original: -1, Transcribed: 3},
+	}
+	if len(steps) != len(want) {
+		t.Fatalf("AlignCharacters() = %+v, want %+v", steps, want)
+	}
+	for index := range want {
+		if steps[index] != want[index] {
+			t.Fatalf("step %d = %+v, want %+v", index, steps[index], want[index])
+		}
+	}
+}
+
+func TestBLEU(t *testing.T) {
+	reference := strings.Fields("the cat is on the mat")
+	tests := []struct {
+		name       string
+		hypothesis string
+		want       float64
+	}{
+		{name: "exact match", hypothesis: "the cat is on the mat", want: 1},
+		// Precisions 5/6, 3/5, 2/4, 1/3 with no brevity penalty.
+		{name: "one substitution", hypothesis: "the cat is on a mat", want: math.Pow(5.0/6*3.0/5*2.0/4*1.0/3, 0.25)},
+		// Every n-gram matches, but 5 of 6 words gives a penalty of exp(1-6/5).
+		{name: "brevity penalty", hypothesis: "the cat is on the", want: math.Exp(1 - 6.0/5)},
+		// Clipping limits "the" to the two occurrences in the reference.
+		{name: "no four-gram match", hypothesis: "the the the the the the", want: 0},
+		{name: "empty hypothesis", hypothesis: "", want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := metrics.BLEU(reference, strings.Fields(tt.hypothesis))
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("BLEU() = %.6f, want %.6f", got, tt.want)
+			}
+		})
+	}
+
+	result := metrics.Evaluate("The cat is on the mat", "the cat is on the mat", metrics.Options{IgnoreCase: true, BLEU: true})
+	if result.BLEU != 1 {
+		t.Errorf("Evaluate() BLEU = %f, want 1 after case normalization", result.BLEU)
+	}
+}
+
+func TestBagOfWords(t *testing.T) {
+	tests := []struct {
+		name        string
+		original    string
+		transcribed string
+		want        metrics.BagOfWordsScore
+	}{
+		{name: "reordered columns", original: "left column text right column text", transcribed: "right column text left column text", want: metrics.BagOfWordsScore{Precision: 1, Recall: 1, F1: 1}},
+		{name: "missing word", original: "a b c d", transcribed: "a b c", want: metrics.BagOfWordsScore{Precision: 1, Recall: 0.75, F1: 6.0 / 7}},
+		{name: "repeated words are clipped", original: "the cat", transcribed: "the the the cat", want: metrics.BagOfWordsScore{Precision: 0.5, Recall: 1, F1: 2.0 / 3}},
+		{name: "empty transcription", original: "a b", transcribed: "", want: metrics.BagOfWordsScore{}},
+		{name: "both empty", original: "", transcribed: "", want: metrics.BagOfWordsScore{Precision: 1, Recall: 1, F1: 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := metrics.BagOfWords(strings.Fields(tt.original), strings.Fields(tt.transcribed))
+			if math.Abs(got.Precision-tt.want.Precision) > 1e-9 || math.Abs(got.Recall-tt.want.Recall) > 1e-9 || math.Abs(got.F1-tt.want.F1) > 1e-9 {
+				t.Errorf("BagOfWords() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	result := metrics.Evaluate("one two three four", "four three two one", metrics.Options{BagOfWords: true})
+	if result.BagOfWords.F1 != 1 || result.WordErrorRate < 0.75 {
+		t.Errorf("Evaluate() = %+v, want perfect bag-of-words F1 with high word error rate", result)
+	}
+}
+
+func TestSortedWords(t *testing.T) {
+	tests := []struct {
+		name        string
+		original    string
+		transcribed string
+		want        metrics.SortedWordScore
+	}{
+		{name: "reordered table cells", original: "name date\nSmith 1850\nJones 1851", transcribed: "name Smith Jones date 1850 1851", want: metrics.SortedWordScore{Accuracy: 1, Similarity: 1}},
+		{name: "misread word", original: "a b c d", transcribed: "d c bb a", want: metrics.SortedWordScore{Accuracy: 0.75, Similarity: 0.75}},
+		{name: "misread word that sorts elsewhere", original: "a b c d", transcribed: "d c b x", want: metrics.SortedWordScore{Accuracy: 0.5, Similarity: 0.5}},
+		{name: "extra word", original: "a b", transcribed: "b a c", want: metrics.SortedWordScore{Accuracy: 0.5, Similarity: 2.0 / 3}},
+		{name: "empty transcription", original: "a b", transcribed: "", want: metrics.SortedWordScore{}},
+		{name: "both empty", original: "", transcribed: "", want: metrics.SortedWordScore{Accuracy: 1, Similarity: 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original, transcribed := strings.Fields(tt.original), strings.Fields(tt.transcribed)
+			got := metrics.SortedWords(original, transcribed)
+			if math.Abs(got.Accuracy-tt.want.Accuracy) > 1e-9 || math.Abs(got.Similarity-tt.want.Similarity) > 1e-9 {
+				t.Errorf("SortedWords() = %+v, want %+v", got, tt.want)
+			}
+			if !slices.Equal(original, strings.Fields(tt.original)) || !slices.Equal(transcribed, strings.Fields(tt.transcribed)) {
+				t.Errorf("SortedWords() modified its inputs")
+			}
+		})
+	}
+
+	result := metrics.Evaluate("one two three four", "four three two one", metrics.Options{SortWords: true})
+	if result.SortedWords.Accuracy != 1 || result.WordAccuracy > 0.25 {
+		t.Errorf("Evaluate() = %+v, want perfect sorted accuracy with low ordered accuracy", result)
+	}
+	if result := metrics.Evaluate("one two", "two one", metrics.Options{}); result.SortedWords != (metrics.SortedWordScore{}) {
+		t.Errorf("Evaluate() without SortWords = %+v, want SortedWords unset", result.SortedWords)
+	}
+}
+
+func TestNormalizeSingleLine(t *testing.T) {
+	tests := map[string]string{
+		"a\n\nb":     "a b",
+		"a \r\n\t b": "a b",
+		"\n lead":    " lead",
+		"trail \t":   "trail ",
+		"ſome text":  "ſome text",
+		"":           "",
+	}
+	for input, want := range tests {
+		if got := metrics.NormalizeSingleLine(input); got != want {
+			t.Errorf("NormalizeSingleLine(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func BenchmarkNormalizeSingleLine(b *testing.B) {
+	line := "word  \t  another\n\n" + strings.Repeat(" ", 40)
+	text := strings.Repeat(line, 5000)
+	b.SetBytes(int64(len(text)))
+	b.ResetTimer()
+	for range b.N {
+		metrics.NormalizeSingleLine(text)
+	}
+}
+
+func TestWordLevenshteinDistanceMatchesAlignment(t *testing.T) {
+	pairs := [][2]string{
+		{"the quick brown fox", "the quack fox jumps"},
+		{"", "one two"},
+		{"a b c d e f", "f e d c b a"},
+		{"same words here", "same words here"},
+	}
+	for _, pair := range pairs {
+		original, transcribed := strings.Fields(pair[0]), strings.Fields(pair[1])
+		rolling := metrics.WordLevenshteinDistance(original, transcribed)
+		if full := metrics.AlignWords(original, transcribed).Distance; rolling != full {
+			t.Errorf("WordLevenshteinDistance(%q, %q) = %d, full-matrix alignment = %d", pair[0], pair[1], rolling, full)
+		}
+	}
+}
+
+// benchmarkWords returns two long, mostly similar word sequences.
+func benchmarkWords() ([]string, []string) {
+	original := strings.Fields(strings.Repeat("the quick brown fox jumps over the lazy dog ", 300))
+	transcribed := strings.Fields(strings.Repeat("the quack brown fox jumped over a lazy dog ", 300))
+	return original, transcribed
+}
+
+// BenchmarkWordLevenshteinDistance uses two rolling rows; compare its
+// allocations with BenchmarkAlignWordSequence, which keeps the full matrix
+// for backtracking.
+func BenchmarkWordLevenshteinDistance(b *testing.B) {
+	original, transcribed := benchmarkWords()
+	b.ReportAllocs()
+	for range b.N {
+		metrics.WordLevenshteinDistance(original, transcribed)
+	}
+}
+
+func BenchmarkAlignWordSequence(b *testing.B) {
+	original, transcribed := benchmarkWords()
+	b.ReportAllocs()
+	for range b.N {
+		metrics.AlignWordSequence(original, transcribed)
+	}
+}
+
+func BenchmarkLevenshteinDistance(b *testing.B) {
+	original := strings.Repeat("the quick brown fox jumps over the lazy dog ", 100)
+	transcribed := strings.Repeat("the quack brown fox jumped over a lazy dog ", 100)
+	b.ReportAllocs()
+	for range b.N {
+		metrics.LevenshteinDistance(original, transcribed)
+	}
+}
+
+func TestEvaluateLines(t *testing.T) {
+	t.Run("same line count", func(t *testing.T) {
+		lines := metrics.EvaluateLines("first line\nsecond line\n\nthird line", "first line\nsecond lime\nthird line\n", metrics.Options{})
+		if len(lines) != 3 {
+			t.Fatalf("EvaluateLines() = %+v, want 3 lines", lines)
+		}
+		if lines[0].CharacterAccuracy != 1 || lines[2].WordAccuracy != 1 {
+			t.Errorf("matching lines = %+v, %+v, want perfect scores", lines[0], lines[2])
+		}
+		if lines[1].GroundTruthLine != 2 || lines[1].TranscribedLine != 2 || lines[1].WordAccuracy != 0.5 {
+			t.Errorf("lines[1] = %+v, want line 2 paired with one wrong word", lines[1])
+		}
+	})
+
+	t.Run("missing line", func(t *testing.T) {
+		lines := metrics.EvaluateLines("alpha beta\ngamma delta\nepsilon zeta", "alpha beta\nepsilon zeta", metrics.Options{})
+		want := [][2]int{{1, 1}, {2, 0}, {3, 2}}
+		if len(lines) != len(want) {
+			t.Fatalf("EvaluateLines() = %+v, want %d lines", lines, len(want))
+		}
+		for index, pair := range want {
+			if lines[index].GroundTruthLine != pair[0] || lines[index].TranscribedLine != pair[1] {
+				t.Errorf("lines[%d] = %+v, want ground truth %d paired with %d", index, lines[index], pair[0], pair[1])
+			}
+		}
+		if lines[1].CharacterAccuracy != 0 || lines[2].CharacterAccuracy != 1 {
+			t.Errorf("scores = %+v, want the missing line at 0 and the following line unaffected", lines)
+		}
+	})
+
+	t.Run("extra line", func(t *testing.T) {
+		lines := metrics.EvaluateLines("Alpha beta\nGamma delta", "alpha beta\nstray marginal note\ngamma delta", metrics.Options{IgnoreCase: true})
+		if len(lines) != 3 || lines[1].GroundTruthLine != 0 || lines[1].Transcription != "stray marginal note" {
+			t.Fatalf("EvaluateLines() = %+v, want the extra transcribed line unpaired", lines)
+		}
+		if lines[0].CharacterAccuracy != 1 || lines[2].CharacterAccuracy != 1 {
+			t.Errorf("scores = %+v, want case-insensitive matches", lines)
+		}
+	})
+	t.Run("ignore regex", func(t *testing.T) {
+		options := metrics.Options{IgnoreRegex: mustCompileIgnoreRegex(t, `\[illegible\]`)}
+		page := metrics.Evaluate("hello [illegible] world", "hello foo world", options)
+		lines := metrics.EvaluateLines("hello [illegible] world", "hello foo world", options)
+		if len(lines) != 1 {
+			t.Fatalf("EvaluateLines() = %+v, want 1 line", lines)
+		}
+		if lines[0].WordAccuracy != page.WordAccuracy || lines[0].CharacterAccuracy != page.CharacterAccuracy {
+			t.Errorf("lines[0] = %+v, want the page scores %v and %v", lines[0], page.WordAccuracy, page.CharacterAccuracy)
+		}
+		if lines[0].WordAccuracy != 1 {
+			t.Errorf("WordAccuracy = %v, want the ignored token skipped", lines[0].WordAccuracy)
+		}
+	})
+}
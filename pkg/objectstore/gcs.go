@@ -0,0 +1,99 @@
+package objectstore
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/lehigh-university-libraries/htr/pkg/auth/gcpaccesstoken"
+	"github.com/lehigh-university-libraries/htr/pkg/httpclient"
+)
+
+const (
+	defaultGCSEndpoint = "https://storage.googleapis.com"
+	// readOnlyScope limits the access token to reading objects.
+	readOnlyScope = "https://www.googleapis.com/auth/devstorage.read_only"
+)
+
+// GCSOptions configures a Cloud Storage client.
+type GCSOptions struct {
+	HTTPClient *http.Client
+	// Endpoint replaces the Cloud Storage endpoint, such as for an emulator.
+	Endpoint string
+	// AccessToken returns an OAuth access token for one request. Without it
+	// requests are sent unauthenticated, which reads public objects.
+	AccessToken func(ctx context.Context) (string, error)
+	Timeout     time.Duration
+}
+
+// GCS reads objects from Google Cloud Storage through its JSON API.
+type GCS struct {
+	client      *http.Client
+	endpoint    string
+	accessToken func(ctx context.Context) (string, error)
+}
+
+// NewGCS returns a Cloud Storage client. It does not read environment variables.
+func NewGCS(options GCSOptions) (*GCS, error) {
+	endpoint := options.Endpoint
+	if endpoint == "" {
+		endpoint = defaultGCSEndpoint
+	}
+	if _, err := httpclient.ParseEndpoint(endpoint); err != nil {
+		return nil, fmt.Errorf("invalid Cloud Storage endpoint: %w", err)
+	}
+	timeout := options.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	return &GCS{
+		client:      httpclient.Secure(options.HTTPClient, timeout),
+		endpoint:    endpoint,
+		accessToken: options.AccessToken,
+	}, nil
+}
+
+// GCSFromEnv configures a Cloud Storage client that authenticates as the
+// service account named by GOOGLE_APPLICATION_CREDENTIALS, or anonymously when
+// it is unset.
+func GCSFromEnv() (*GCS, error) {
+	var options GCSOptions
+	if path := firstEnv("GOOGLE_APPLICATION_CREDENTIALS"); path != "" {
+		credentials, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read GOOGLE_APPLICATION_CREDENTIALS: %w", err)
+		}
+		tokens, err := gcpaccesstoken.New(gcpaccesstoken.Options{Credentials: credentials, Scope: readOnlyScope})
+		if err != nil {
+			return nil, err
+		}
+		options.AccessToken = tokens.Token
+	}
+	return NewGCS(options)
+}
+
+// Fetch implements Fetcher.
+func (g *GCS) Fetch(ctx context.Context, bucket, key string) ([]byte, error) {
+	objectURL, err := httpclient.AppendPathSegment(g.endpoint, "/storage/v1/b/"+bucket+"/o/", key, "")
+	if err != nil {
+		return nil, fmt.Errorf("invalid Cloud Storage object %s/%s: %w", bucket, key, err)
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, objectURL+"?"+url.Values{"alt": {"media"}}.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Cloud Storage object %s/%s: %w", bucket, key, err)
+	}
+	if g.accessToken != nil {
+		token, err := g.accessToken(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if err := httpclient.SetHeader(request.Header, "Authorization", "Bearer "+strings.TrimSpace(token)); err != nil {
+			return nil, err
+		}
+	}
+	return get(g.client, request, "gs://"+bucket+"/"+key)
+}
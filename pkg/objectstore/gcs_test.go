@@ -0,0 +1,55 @@
+package objectstore
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGCSFetch(t *testing.T) {
+	var gotPath, gotQuery, gotAuth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath, gotQuery, gotAuth = r.URL.EscapedPath(), r.URL.RawQuery, r.Header.Get("Authorization")
+		_, _ = w.Write([]byte("hello world"))
+	}))
+	defer server.Close()
+
+	client, err := NewGCS(GCSOptions{
+		Endpoint:    server.URL,
+		AccessToken: func(context.Context) (string, error) { return "token", nil },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := client.Fetch(context.Background(), "letters", "1850/page1.txt")
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if string(data) != "hello world" {
+		t.Errorf("Fetch() = %q, want %q", data, "hello world")
+	}
+	if gotPath != "/storage/v1/b/letters/o/1850%2Fpage1.txt" || gotQuery != "alt=media" {
+		t.Errorf("request = %s?%s", gotPath, gotQuery)
+	}
+	if gotAuth != "Bearer token" {
+		t.Errorf("Authorization = %q, want Bearer token", gotAuth)
+	}
+}
+
+func TestGCSFetchReportsStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error": {"message": "No such object"}}`, http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	client, err := NewGCS(GCSOptions{Endpoint: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = client.Fetch(context.Background(), "letters", "missing.txt")
+	if err == nil || err.Error() != `failed to fetch gs://letters/missing.txt: HTTP 404 {"error": {"message": "No such object"}}` {
+		t.Errorf("Fetch() error = %v", err)
+	}
+}
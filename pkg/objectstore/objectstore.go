@@ -0,0 +1,131 @@
+// Package objectstore reads objects from Amazon S3 and Google Cloud Storage
+// by s3:// and gs:// URI, with credentials from the standard environment.
+package objectstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lehigh-university-libraries/htr/pkg/httpclient"
+)
+
+const (
+	defaultTimeout = 2 * time.Minute
+	maxObjectBytes = 256 << 20
+	maxErrorBytes  = 4 << 10
+)
+
+// bucketPattern matches the bucket names S3 and GCS both allow, which also
+// keeps a bucket from altering the request host.
+var bucketPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]{1,220}[a-z0-9]$`)
+
+// Fetcher reads one object from a bucket.
+type Fetcher interface {
+	Fetch(ctx context.Context, bucket, key string) ([]byte, error)
+}
+
+// IsURI reports whether uri names an s3:// or gs:// object.
+func IsURI(uri string) bool {
+	return strings.HasPrefix(uri, "s3://") || strings.HasPrefix(uri, "gs://")
+}
+
+// ParseURI splits an s3:// or gs:// URI into its scheme, bucket, and key.
+func ParseURI(uri string) (scheme, bucket, key string, err error) {
+	scheme, rest, ok := strings.Cut(uri, "://")
+	if !ok || (scheme != "s3" && scheme != "gs") {
+		return "", "", "", fmt.Errorf("unsupported object URI %q: use s3://bucket/key or gs://bucket/key", uri)
+	}
+	bucket, key, _ = strings.Cut(rest, "/")
+	if !bucketPattern.MatchString(bucket) || key == "" || strings.ContainsAny(key, "\r\n\x00") {
+		return "", "", "", fmt.Errorf("invalid object URI %q: use %s://bucket/key", uri, scheme)
+	}
+	return scheme, bucket, key, nil
+}
+
+// FromEnv creates the client for scheme from the standard environment
+// variables of its cloud. See S3FromEnv and GCSFromEnv.
+func FromEnv(scheme string) (Fetcher, error) {
+	switch scheme {
+	case "s3":
+		return S3FromEnv()
+	case "gs":
+		return GCSFromEnv()
+	}
+	return nil, fmt.Errorf("unsupported object URI scheme %q", scheme)
+}
+
+// Store reads s3:// and gs:// URIs. Each scheme's client is created on first
+// use and reused, so runs without cloud inputs need no credentials and cached
+// access tokens are shared across reads.
+type Store struct {
+	// NewFetcher creates the client for a scheme. Nil uses FromEnv.
+	NewFetcher func(scheme string) (Fetcher, error)
+
+	mu       sync.Mutex
+	fetchers map[string]Fetcher
+}
+
+// Fetch reads the object named by uri.
+func (s *Store) Fetch(ctx context.Context, uri string) ([]byte, error) {
+	scheme, bucket, key, err := ParseURI(uri)
+	if err != nil {
+		return nil, err
+	}
+	fetcher, err := s.fetcher(scheme)
+	if err != nil {
+		return nil, err
+	}
+	return fetcher.Fetch(ctx, bucket, key)
+}
+
+func (s *Store) fetcher(scheme string) (Fetcher, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if fetcher, ok := s.fetchers[scheme]; ok {
+		return fetcher, nil
+	}
+
+	newFetcher := s.NewFetcher
+	if newFetcher == nil {
+		newFetcher = FromEnv
+	}
+	fetcher, err := newFetcher(scheme)
+	if err != nil {
+		return nil, err
+	}
+	if s.fetchers == nil {
+		s.fetchers = make(map[string]Fetcher)
+	}
+	s.fetchers[scheme] = fetcher
+	return fetcher, nil
+}
+
+// get sends request and returns the body of a 200 response. Other statuses
+// become an error naming uri, with the start of the response body, which
+// carries the service's error code.
+func get(client *http.Client, request *http.Request, uri string) ([]byte, error) {
+	response, err := client.Do(request)
+	if err != nil {
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to fetch %s: %w", uri, err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		body, _ := httpclient.ReadAll(response.Body, maxErrorBytes)
+		return nil, fmt.Errorf("failed to fetch %s: HTTP %d %s", uri, response.StatusCode, strings.TrimSpace(string(body)))
+	}
+	data, err := httpclient.ReadAll(response.Body, maxObjectBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", uri, err)
+	}
+	return data, nil
+}
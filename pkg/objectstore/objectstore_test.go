@@ -0,0 +1,71 @@
+package objectstore
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestParseURI(t *testing.T) {
+	tests := []struct {
+		uri     string
+		scheme  string
+		bucket  string
+		key     string
+		wantErr bool
+	}{
+		{uri: "s3://letters/1850/page1.jpg", scheme: "s3", bucket: "letters", key: "1850/page1.jpg"},
+		{uri: "gs://my.bucket/page1.txt", scheme: "gs", bucket: "my.bucket", key: "page1.txt"},
+		{uri: "s3://letters", wantErr: true},
+		{uri: "s3://letters/", wantErr: true},
+		{uri: "gs://Letters/page1.jpg", wantErr: true},
+		{uri: "s3://evil.com@letters/page1.jpg", wantErr: true},
+		{uri: "azure://letters/page1.jpg", wantErr: true},
+		{uri: "/local/page1.jpg", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.uri, func(t *testing.T) {
+			scheme, bucket, key, err := ParseURI(tt.uri)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseURI() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if scheme != tt.scheme || bucket != tt.bucket || key != tt.key {
+				t.Errorf("ParseURI() = %q, %q, %q, want %q, %q, %q", scheme, bucket, key, tt.scheme, tt.bucket, tt.key)
+			}
+		})
+	}
+}
+
+type fakeFetcher struct {
+	objects map[string]string
+}
+
+func (f fakeFetcher) Fetch(ctx context.Context, bucket, key string) ([]byte, error) {
+	data, ok := f.objects[bucket+"/"+key]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return []byte(data), nil
+}
+
+func TestStoreCreatesEachFetcherOnce(t *testing.T) {
+	created := map[string]int{}
+	store := &Store{NewFetcher: func(scheme string) (Fetcher, error) {
+		created[scheme]++
+		return fakeFetcher{objects: map[string]string{"letters/page1.txt": scheme + " text"}}, nil
+	}}
+
+	for _, uri := range []string{"s3://letters/page1.txt", "s3://letters/page1.txt", "gs://letters/page1.txt"} {
+		data, err := store.Fetch(context.Background(), uri)
+		if err != nil {
+			t.Fatalf("Fetch(%q) error = %v", uri, err)
+		}
+		if want := uri[:2] + " text"; string(data) != want {
+			t.Errorf("Fetch(%q) = %q, want %q", uri, data, want)
+		}
+	}
+	if created["s3"] != 1 || created["gs"] != 1 {
+		t.Errorf("fetchers created = %v, want one per scheme", created)
+	}
+}
@@ -0,0 +1,143 @@
+package objectstore
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/lehigh-university-libraries/htr/pkg/auth/awssigv4"
+	"github.com/lehigh-university-libraries/htr/pkg/httpclient"
+)
+
+const defaultS3Region = "us-east-1"
+
+// S3Options configures an S3 client.
+type S3Options struct {
+	HTTPClient *http.Client
+	Region     string
+	// AccessKeyID and SecretAccessKey sign requests with Signature Version 4.
+	// Without them requests are sent unsigned, which reads public objects.
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	// Endpoint replaces the AWS endpoint for S3-compatible services such as
+	// MinIO. Objects are then addressed path-style.
+	Endpoint string
+	Timeout  time.Duration
+	Now      func() time.Time
+}
+
+// S3 reads objects from Amazon S3 or an S3-compatible service.
+type S3 struct {
+	client          *http.Client
+	region          string
+	accessKeyID     string
+	secretAccessKey string
+	sessionToken    string
+	endpoint        string
+	now             func() time.Time
+}
+
+// NewS3 returns an S3 client. It does not read environment variables.
+func NewS3(options S3Options) (*S3, error) {
+	if (options.AccessKeyID == "") != (options.SecretAccessKey == "") {
+		return nil, fmt.Errorf("S3 access key ID and secret access key must be set together")
+	}
+	region := options.Region
+	if region == "" {
+		region = defaultS3Region
+	}
+	endpoint := ""
+	if options.Endpoint != "" {
+		parsed, err := httpclient.ParseEndpoint(options.Endpoint)
+		if err != nil {
+			return nil, fmt.Errorf("invalid S3 endpoint: %w", err)
+		}
+		endpoint = strings.TrimRight(parsed.String(), "/")
+	}
+	timeout := options.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	now := options.Now
+	if now == nil {
+		now = time.Now
+	}
+	return &S3{
+		client:          httpclient.Secure(options.HTTPClient, timeout),
+		region:          region,
+		accessKeyID:     options.AccessKeyID,
+		secretAccessKey: options.SecretAccessKey,
+		sessionToken:    options.SessionToken,
+		endpoint:        endpoint,
+		now:             now,
+	}, nil
+}
+
+// S3FromEnv configures an S3 client from AWS_ACCESS_KEY_ID,
+// AWS_SECRET_ACCESS_KEY, AWS_SESSION_TOKEN, AWS_REGION (or AWS_DEFAULT_REGION),
+// and AWS_ENDPOINT_URL_S3 (or AWS_ENDPOINT_URL).
+func S3FromEnv() (*S3, error) {
+	return NewS3(S3Options{
+		Region:          firstEnv("AWS_REGION", "AWS_DEFAULT_REGION"),
+		AccessKeyID:     firstEnv("AWS_ACCESS_KEY_ID"),
+		SecretAccessKey: firstEnv("AWS_SECRET_ACCESS_KEY"),
+		SessionToken:    firstEnv("AWS_SESSION_TOKEN"),
+		Endpoint:        firstEnv("AWS_ENDPOINT_URL_S3", "AWS_ENDPOINT_URL"),
+	})
+}
+
+// Fetch implements Fetcher.
+func (s *S3) Fetch(ctx context.Context, bucket, key string) ([]byte, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, s.objectURL(bucket, key), nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid S3 object %s/%s: %w", bucket, key, err)
+	}
+	if s.accessKeyID != "" {
+		s.sign(request, s.now())
+	}
+	return get(s.client, request, "s3://"+bucket+"/"+key)
+}
+
+// objectURL addresses the object virtual-hosted-style on AWS and path-style
+// on a custom endpoint.
+func (s *S3) objectURL(bucket, key string) string {
+	if s.endpoint != "" {
+		return s.endpoint + "/" + bucket + "/" + escapeKey(key)
+	}
+	return fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", bucket, s.region, escapeKey(key))
+}
+
+// sign adds Signature Version 4 headers to a bodiless request. Every header
+// already on the request is signed along with the host.
+func (s *S3) sign(request *http.Request, now time.Time) {
+	credentials := awssigv4.Credentials{
+		AccessKeyID:     s.accessKeyID,
+		SecretAccessKey: s.secretAccessKey,
+		SessionToken:    s.sessionToken,
+	}
+	awssigv4.Sign(request, credentials, s.region, "s3", awssigv4.EmptyPayloadHash, now)
+}
+
+// escapeKey percent-encodes each segment of an object key as Signature
+// Version 4 requires, leaving the separating slashes intact.
+func escapeKey(key string) string {
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		segments[i] = awssigv4.Escape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// firstEnv returns the first non-empty environment variable among names.
+func firstEnv(names ...string) string {
+	for _, name := range names {
+		if value := strings.TrimSpace(os.Getenv(name)); value != "" {
+			return value
+		}
+	}
+	return ""
+}
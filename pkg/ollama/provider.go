@@ -7,9 +7,12 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"errors"
+	"log/slog"
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/lehigh-university-libraries/htr/pkg/auth/gcpidtoken"
@@ -23,6 +26,10 @@ const (
 	defaultMaxImageBytes    = 50 << 20
 	defaultMaxRequestBytes  = 70 << 20
 	defaultMaxResponseBytes = 8 << 20
+	defaultModel            = "llava"
+	// validateTimeout bounds the model check in ValidateConfig, so a
+	// reachable server never slows a run down noticeably.
+	validateTimeout = 5 * time.Second
 )
 
 // Options configures a Client. Constructors do not read environment variables.
@@ -34,38 +41,63 @@ type Options struct {
 	MaxImageBytes    int64
 	MaxRequestBytes  int64
 	MaxResponseBytes int64
+	// KeepAlive is sent as keep_alive: a duration such as "5m", or a number
+	// of seconds. Empty leaves it to the server.
+	KeepAlive string
+	// NumCtx is sent as the num_ctx option. Zero leaves it to the model.
+	NumCtx int
 }
 
 // Client is a byte-oriented Ollama transcription client.
 type Client struct {
 	httpClient       *http.Client
 	endpoint         string
+	tagsEndpoint     string
 	authenticator    httpclient.Authenticator
 	maxImageBytes    int64
 	maxRequestBytes  int64
 	maxResponseBytes int64
+	keepAlive        any
+	numCtx           int
 }
 
 // Provider is the historical CLI adapter. New integrations should use Client.
 type Provider struct {
+	providers.BaseProvider
+
 	identityTokens *gcpidtoken.Source
+
+	// checked records the endpoint and model pairs ValidateConfig has
+	// confirmed, since eval validates the configuration before every row.
+	mu      sync.Mutex
+	checked map[string]bool
 }
 
 type generateRequest struct {
-	Model   string   `json:"model"`
-	Prompt  string   `json:"prompt"`
-	Images  []string `json:"images"`
-	Stream  bool     `json:"stream"`
-	Options struct {
+	Model     string   `json:"model"`
+	Prompt    string   `json:"prompt"`
+	System    string   `json:"system,omitempty"`
+	Images    []string `json:"images"`
+	Stream    bool     `json:"stream"`
+	KeepAlive any      `json:"keep_alive,omitempty"`
+	Options   struct {
 		Temperature float64 `json:"temperature"`
+		NumCtx      int     `json:"num_ctx,omitempty"`
 	} `json:"options"`
 }
 
+type tagsResponse struct {
+	Models []struct {
+		Name string `json:"name"`
+	} `json:"models"`
+}
+
 type generateResponse struct {
 	Model           string `json:"model"`
 	Response        string `json:"response"`
 	PromptEvalCount int    `json:"prompt_eval_count"`
 	EvalCount       int    `json:"eval_count"`
+	DoneReason      string `json:"done_reason"`
 }
 
 // NewClient constructs a secure Ollama client from explicit dependencies.
@@ -78,6 +110,10 @@ func NewClient(options Options) (*Client, error) {
 	if err != nil {
 		return nil, providers.NewError(providers.ErrorInvalidRequest, 0, false, nil)
 	}
+	tagsEndpoint, err := httpclient.AppendPath(endpoint, "/api/tags")
+	if err != nil {
+		return nil, providers.NewError(providers.ErrorInvalidRequest, 0, false, nil)
+	}
 	authenticator := options.Authenticator
 	if authenticator == nil {
 		authenticator = httpclient.NoAuth{}
@@ -85,10 +121,13 @@ func NewClient(options Options) (*Client, error) {
 	return &Client{
 		httpClient:       httpclient.Secure(options.HTTPClient, durationOr(options.Timeout, defaultTimeout)),
 		endpoint:         generateEndpoint,
+		tagsEndpoint:     tagsEndpoint,
 		authenticator:    authenticator,
 		maxImageBytes:    positiveOr(options.MaxImageBytes, defaultMaxImageBytes),
 		maxRequestBytes:  positiveOr(options.MaxRequestBytes, defaultMaxRequestBytes),
 		maxResponseBytes: positiveOr(options.MaxResponseBytes, defaultMaxResponseBytes),
+		keepAlive:        keepAliveValue(options.KeepAlive),
+		numCtx:           options.NumCtx,
 	}, nil
 }
 
@@ -104,12 +143,15 @@ func (c *Client) Extract(ctx context.Context, request providers.Request) (provid
 		return providers.Result{}, providers.ErrorForRequest(ctx, err)
 	}
 	payload := generateRequest{
-		Model:  request.Model,
-		Prompt: request.Prompt,
-		Images: []string{base64.StdEncoding.EncodeToString(request.Image.Data)},
-		Stream: false,
+		Model:     request.Model,
+		Prompt:    request.Prompt,
+		System:    request.SystemPrompt,
+		Images:    []string{base64.StdEncoding.EncodeToString(request.Image.Data)},
+		Stream:    false,
+		KeepAlive: c.keepAlive,
 	}
 	payload.Options.Temperature = request.Temperature
+	payload.Options.NumCtx = c.numCtx
 	body, err := json.Marshal(payload)
 	if err != nil || int64(len(body)) > c.maxRequestBytes {
 		return providers.Result{}, providers.NewError(providers.ErrorInvalidRequest, 0, false, nil)
@@ -150,11 +192,48 @@ func (c *Client) Extract(ctx context.Context, request providers.Request) (provid
 		Usage: providers.UsageInfo{
 			InputTokens:  decoded.PromptEvalCount,
 			OutputTokens: decoded.EvalCount,
+			Truncated:    decoded.DoneReason == "length",
 		},
 		EffectiveModel: effectiveModel,
 	}, nil
 }
 
+// Models returns the names of the models installed on the server, such as
+// "llava:latest".
+func (c *Client) Models(ctx context.Context) ([]string, error) {
+	httpRequest, err := http.NewRequestWithContext(ctx, http.MethodGet, c.tagsEndpoint, nil)
+	if err != nil {
+		return nil, providers.NewError(providers.ErrorInvalidRequest, 0, false, nil)
+	}
+	if err := c.authenticator.Authorize(ctx, httpRequest); err != nil {
+		return nil, providers.ErrorForAuthentication(ctx, err)
+	}
+	response, err := c.httpClient.Do(httpRequest)
+	if err != nil {
+		return nil, providers.ErrorForRequest(ctx, err)
+	}
+	defer response.Body.Close()
+	responseBody, err := httpclient.ReadAll(response.Body, c.maxResponseBytes)
+	if err != nil {
+		if errors.Is(err, httpclient.ErrResponseTooLarge) {
+			return nil, providers.NewError(providers.ErrorResponseTooLarge, response.StatusCode, false, nil)
+		}
+		return nil, providers.ErrorForRequest(ctx, err)
+	}
+	if response.StatusCode < http.StatusOK || response.StatusCode >= http.StatusMultipleChoices {
+		return nil, providers.ErrorForStatus(response.StatusCode)
+	}
+	var decoded tagsResponse
+	if err := json.Unmarshal(responseBody, &decoded); err != nil {
+		return nil, providers.NewError(providers.ErrorInvalidResponse, response.StatusCode, false, nil)
+	}
+	names := make([]string, len(decoded.Models))
+	for i, model := range decoded.Models {
+		names[i] = model.Name
+	}
+	return names, nil
+}
+
 // New creates the historical CLI adapter.
 func New() *Provider {
 	source, err := gcpidtoken.New(gcpidtoken.Options{})
@@ -167,42 +246,119 @@ func New() *Provider {
 // Name returns the provider name.
 func (p *Provider) Name() string { return "ollama" }
 
-// ValidateConfig validates the configured CLI endpoint without contacting it.
+// ValidateConfig validates the configured CLI endpoint and confirms the server
+// is reachable by listing its installed models. A model that is not installed
+// is only warned about, suggesting ollama pull, since the server may pull it
+// itself or the name may carry a tag the list spells differently. Each
+// endpoint and model is checked once.
 func (p *Provider) ValidateConfig(config providers.Config) error {
-	_, err := httpclient.ParseEndpoint(resolveBaseURL(config))
+	model := resolveModel(config)
+	key := resolveBaseURL(config) + "\x00" + model
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.checked[key] {
+		return nil
+	}
+
+	client, err := p.client(config, validateTimeout)
 	if err != nil {
-		return providers.NewError(providers.ErrorInvalidRequest, 0, false, nil)
+		return err
 	}
+	ctx, cancel := context.WithTimeout(context.Background(), validateTimeout)
+	defer cancel()
+	installed, err := client.Models(ctx)
+	if err != nil {
+		return err
+	}
+	if !hasModel(installed, model) {
+		slog.Warn("Ollama model is not installed; run ollama pull on the server to install it",
+			"model", model, "installed", installed)
+	}
+	if p.checked == nil {
+		p.checked = make(map[string]bool)
+	}
+	p.checked[key] = true
 	return nil
 }
 
 // ExtractText adapts historical base64 CLI inputs to Client.
 func (p *Provider) ExtractText(ctx context.Context, config providers.Config, imagePath, imageBase64 string) (string, providers.UsageInfo, error) {
-	if config.Model == "" || config.Model == "gpt-4o" {
-		config.Model = "llava"
-	}
+	config.Model = resolveModel(config)
 	request, err := providers.LegacyRequest(config, imagePath, imageBase64, defaultMaxImageBytes)
 	if err != nil {
 		return "", providers.UsageInfo{}, err
 	}
+	client, err := p.client(config, config.Timeout)
+	if err != nil {
+		return "", providers.UsageInfo{}, err
+	}
+	result, err := client.Extract(ctx, request)
+	return result.Text, result.Usage, err
+}
+
+// client builds a Client for the configured endpoint, authenticating with a
+// Google identity token when the endpoint needs an audience.
+func (p *Provider) client(config providers.Config, timeout time.Duration) (*Client, error) {
 	baseURL := resolveBaseURL(config)
 	var authenticator httpclient.Authenticator = httpclient.NoAuth{}
 	if audience := resolveAudience(config, baseURL); audience != "" {
 		tokenSource := p.identityTokens
 		if tokenSource == nil {
+			var err error
 			tokenSource, err = gcpidtoken.New(gcpidtoken.Options{})
 			if err != nil {
-				return "", providers.UsageInfo{}, providers.NewError(providers.ErrorAuthentication, 0, false, nil)
+				return nil, providers.NewError(providers.ErrorAuthentication, 0, false, nil)
 			}
 		}
 		authenticator = httpclient.BearerAuthenticator{Source: tokenSource, Audience: audience}
 	}
-	client, err := NewClient(Options{Endpoint: baseURL, Authenticator: authenticator, Timeout: config.Timeout})
-	if err != nil {
-		return "", providers.UsageInfo{}, err
+	return NewClient(Options{
+		Endpoint:      baseURL,
+		Authenticator: authenticator,
+		Timeout:       timeout,
+		KeepAlive:     config.KeepAlive,
+		NumCtx:        config.NumCtx,
+	})
+}
+
+// keepAliveValue returns keep_alive as Ollama reads it: whole seconds as a
+// JSON number, since Ollama parses strings only as durations, and anything
+// else as a duration string. Empty returns nil, which is omitted.
+func keepAliveValue(keepAlive string) any {
+	keepAlive = strings.TrimSpace(keepAlive)
+	if keepAlive == "" {
+		return nil
 	}
-	result, err := client.Extract(ctx, request)
-	return result.Text, result.Usage, err
+	if seconds, err := strconv.Atoi(keepAlive); err == nil {
+		return seconds
+	}
+	return keepAlive
+}
+
+// resolveModel returns the configured model, or llava when the model is unset
+// or still eval's OpenAI default.
+func resolveModel(config providers.Config) string {
+	if config.Model == "" || config.Model == "gpt-4o" {
+		return defaultModel
+	}
+	return config.Model
+}
+
+// hasModel reports whether model is among the installed names, treating a
+// name without a tag as the "latest" tag.
+func hasModel(installed []string, model string) bool {
+	if !strings.Contains(model, ":") {
+		model += ":latest"
+	}
+	for _, name := range installed {
+		if !strings.Contains(name, ":") {
+			name += ":latest"
+		}
+		if name == model {
+			return true
+		}
+	}
+	return false
 }
 
 func resolveBaseURL(config providers.Config) string {
@@ -1,14 +1,17 @@
 package ollama
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/lehigh-university-libraries/htr/pkg/httpclient"
 	"github.com/lehigh-university-libraries/htr/pkg/providers"
@@ -45,6 +48,118 @@ func TestClientExtract(t *testing.T) {
 	}
 }
 
+func TestClientExtractSendsSystemPrompt(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name         string
+		systemPrompt string
+	}{
+		{"without system prompt", ""},
+		{"with system prompt", "You are a paleographer."},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, request *http.Request) {
+				var body map[string]any
+				if err := json.NewDecoder(request.Body).Decode(&body); err != nil {
+					t.Fatal(err)
+				}
+				system, ok := body["system"]
+				if ok != (test.systemPrompt != "") || (ok && system != test.systemPrompt) {
+					t.Errorf("system = %v (present %v), want %q", system, ok, test.systemPrompt)
+				}
+				if body["prompt"] != "Transcribe café" {
+					t.Errorf("prompt = %v", body["prompt"])
+				}
+				_, _ = w.Write([]byte(`{"model":"llava","response":"text"}`))
+			}))
+			defer server.Close()
+			client, err := NewClient(Options{Endpoint: server.URL})
+			if err != nil {
+				t.Fatal(err)
+			}
+			request := testRequest([]byte("image"))
+			request.SystemPrompt = test.systemPrompt
+			if _, err := client.Extract(context.Background(), request); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestLegacyProviderSendsKeepAliveAndNumCtx(t *testing.T) {
+	tests := []struct {
+		name          string
+		keepAlive     string
+		numCtx        int
+		wantKeepAlive any
+		wantNumCtx    any
+	}{
+		{name: "duration", keepAlive: "5m", numCtx: 8192, wantKeepAlive: "5m", wantNumCtx: float64(8192)},
+		{name: "seconds", keepAlive: "-1", wantKeepAlive: float64(-1)},
+		{name: "server defaults"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, request *http.Request) {
+				var body map[string]any
+				if err := json.NewDecoder(request.Body).Decode(&body); err != nil {
+					t.Fatal(err)
+				}
+				options, _ := body["options"].(map[string]any)
+				if got := body["keep_alive"]; got != test.wantKeepAlive {
+					t.Errorf("keep_alive = %#v, want %#v", got, test.wantKeepAlive)
+				}
+				if got := options["num_ctx"]; got != test.wantNumCtx {
+					t.Errorf("options.num_ctx = %#v, want %#v", got, test.wantNumCtx)
+				}
+				_, _ = w.Write([]byte(`{"model":"llava","response":"text"}`))
+			}))
+			defer server.Close()
+			t.Setenv("OLLAMA_URL", server.URL)
+			t.Setenv("OLLAMA_AUDIENCE", "")
+
+			config := providers.Config{Model: "llava", Prompt: "Transcribe", KeepAlive: test.keepAlive, NumCtx: test.numCtx}
+			if _, _, err := New().ExtractText(context.Background(), config, "page.png", "aW1hZ2U="); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestClientExtractReportsTruncation(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name          string
+		reason        string
+		wantTruncated bool
+	}{
+		{"stopped", "stop", false},
+		{"hit output limit", "length", true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				_, _ = w.Write([]byte(`{"model":"llava","response":"partial","done_reason":"` + test.reason + `"}`))
+			}))
+			defer server.Close()
+			client, err := NewClient(Options{Endpoint: server.URL})
+			if err != nil {
+				t.Fatal(err)
+			}
+			result, err := client.Extract(context.Background(), testRequest([]byte("image")))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Usage.Truncated != test.wantTruncated {
+				t.Fatalf("Truncated = %v, want %v", result.Usage.Truncated, test.wantTruncated)
+			}
+		})
+	}
+}
+
 func TestClientErrorsAreRedactedBoundedAndRedirectSafe(t *testing.T) {
 	t.Parallel()
 	secret := "secret error body"
@@ -105,7 +220,11 @@ func TestNewClientRejectsUnsafeEndpoint(t *testing.T) {
 
 func TestLegacyConfigurationResolution(t *testing.T) {
 	provider := New()
-	t.Setenv("OLLAMA_URL", "http://localhost:11434")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"models":[{"name":"llava:latest"}]}`))
+	}))
+	defer server.Close()
+	t.Setenv("OLLAMA_URL", server.URL)
 	t.Setenv("OLLAMA_AUDIENCE", "")
 	if err := provider.ValidateConfig(providers.Config{}); err != nil {
 		t.Fatal(err)
@@ -118,6 +237,76 @@ func TestLegacyConfigurationResolution(t *testing.T) {
 	}
 }
 
+func TestLegacyValidateConfigChecksInstalledModels(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, request *http.Request) {
+		calls.Add(1)
+		if request.Method != http.MethodGet || request.URL.Path != "/api/tags" {
+			t.Errorf("unexpected request target: %s %s", request.Method, request.URL.Path)
+		}
+		_, _ = w.Write([]byte(`{"models":[{"name":"llava:latest"},{"name":"mistral-small3.2:24b"}]}`))
+	}))
+	defer server.Close()
+	t.Setenv("OLLAMA_URL", server.URL)
+	t.Setenv("OLLAMA_AUDIENCE", "")
+
+	tests := []struct {
+		model       string
+		wantWarning bool
+	}{
+		{model: "llava", wantWarning: false},
+		{model: "llava:latest", wantWarning: false},
+		{model: "mistral-small3.2:24b", wantWarning: false},
+		{model: "gpt-4o", wantWarning: false},
+		{model: "lava", wantWarning: true},
+		{model: "mistral-small3.2", wantWarning: true},
+	}
+	for _, test := range tests {
+		t.Run(test.model, func(t *testing.T) {
+			var logs bytes.Buffer
+			original := slog.Default()
+			t.Cleanup(func() { slog.SetDefault(original) })
+			slog.SetDefault(slog.New(slog.NewTextHandler(&logs, nil)))
+
+			if err := New().ValidateConfig(providers.Config{Model: test.model}); err != nil {
+				t.Fatalf("ValidateConfig() error = %v, want only a warning", err)
+			}
+			if warned := strings.Contains(logs.String(), "ollama pull"); warned != test.wantWarning {
+				t.Errorf("warned = %v, want %v; logs:\n%s", warned, test.wantWarning, logs.String())
+			}
+		})
+	}
+
+	provider := New()
+	calls.Store(0)
+	for range 3 {
+		if err := provider.ValidateConfig(providers.Config{Model: "llava"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("ValidateConfig() listed models %d times for one model, want 1", got)
+	}
+}
+
+func TestLegacyValidateConfigRejectsUnreachableServer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	unreachable := server.URL
+	server.Close()
+	t.Setenv("OLLAMA_URL", unreachable)
+	t.Setenv("OLLAMA_AUDIENCE", "")
+
+	start := time.Now()
+	err := New().ValidateConfig(providers.Config{Model: "llava"})
+	var providerError *providers.Error
+	if !errors.As(err, &providerError) || providerError.Kind != providers.ErrorTransport {
+		t.Fatalf("ValidateConfig() error = %v, want a transport error", err)
+	}
+	if elapsed := time.Since(start); elapsed > validateTimeout {
+		t.Errorf("ValidateConfig() took %s, want at most %s", elapsed, validateTimeout)
+	}
+}
+
 func testRequest(image []byte) providers.Request {
 	return providers.Request{
 		Model:       "llava",
@@ -126,3 +315,10 @@ func testRequest(image []byte) providers.Request {
 		Image:       providers.Image{Data: image, MediaType: "image/png", Filename: "page.png"},
 	}
 }
+
+func TestProviderCapabilities(t *testing.T) {
+	want := providers.Capabilities{ReportsTokenUsage: true, SupportsTemperature: true, SupportsCustomPrompt: true}
+	if got := New().Capabilities(); got != want {
+		t.Errorf("Capabilities() = %+v, want %+v", got, want)
+	}
+}
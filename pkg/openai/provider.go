@@ -8,6 +8,7 @@ import (
 	"encoding/json"
 	"errors"
 	"net/http"
+	"net/url"
 	"os"
 	"strings"
 	"time"
@@ -17,7 +18,9 @@ import (
 )
 
 const (
-	defaultEndpoint         = "https://api.openai.com/v1/chat/completions"
+	defaultBaseURL          = "https://api.openai.com/v1"
+	defaultEndpoint         = defaultBaseURL + completionsPath
+	completionsPath         = "/chat/completions"
 	defaultTimeout          = 2 * time.Minute
 	defaultMaxImageBytes    = 50 << 20
 	defaultMaxRequestBytes  = 70 << 20
@@ -36,6 +39,16 @@ type Options struct {
 	MaxImageBytes    int64
 	MaxRequestBytes  int64
 	MaxResponseBytes int64
+	// APIKeyHeader is the header the API key is sent in. Empty sends it as a
+	// bearer token in Authorization; Azure OpenAI reads it from api-key.
+	APIKeyHeader string
+	// Query is added to Endpoint, which cannot carry a query itself, for
+	// parameters such as the api-version Azure OpenAI requires.
+	Query url.Values
+	// ImageDetail is sent as the image's detail: "low", "high", or "auto".
+	// Low detail costs a fixed, small number of tokens per image. Empty
+	// leaves it to the service.
+	ImageDetail string
 }
 
 // Client is a byte-oriented OpenAI transcription client.
@@ -43,18 +56,24 @@ type Client struct {
 	httpClient       *http.Client
 	endpoint         string
 	apiKey           CredentialSource
+	apiKeyHeader     string
+	imageDetail      string
 	maxImageBytes    int64
 	maxRequestBytes  int64
 	maxResponseBytes int64
 }
 
 // Provider is the historical CLI adapter. New integrations should use Client.
-type Provider struct{}
+type Provider struct {
+	providers.BaseProvider
+}
 
 type chatRequest struct {
 	Model       string        `json:"model"`
 	Messages    []chatMessage `json:"messages"`
 	Temperature float64       `json:"temperature"`
+	MaxTokens   int           `json:"max_tokens,omitempty"`
+	Logprobs    bool          `json:"logprobs,omitempty"`
 }
 
 type chatMessage struct {
@@ -69,7 +88,8 @@ type contentPart struct {
 }
 
 type imageURL struct {
-	URL string `json:"url"`
+	URL    string `json:"url"`
+	Detail string `json:"detail,omitempty"`
 }
 
 type chatResponse struct {
@@ -78,6 +98,13 @@ type chatResponse struct {
 		Message struct {
 			Content string `json:"content"`
 		} `json:"message"`
+		FinishReason string `json:"finish_reason"`
+		Logprobs     *struct {
+			Content []struct {
+				Token   string  `json:"token"`
+				Logprob float64 `json:"logprob"`
+			} `json:"content"`
+		} `json:"logprobs"`
 	} `json:"choices"`
 	Usage struct {
 		PromptTokens     int `json:"prompt_tokens"`
@@ -98,10 +125,13 @@ func NewClient(options Options) (*Client, error) {
 	maxImageBytes := positiveOr(options.MaxImageBytes, defaultMaxImageBytes)
 	maxRequestBytes := positiveOr(options.MaxRequestBytes, defaultMaxRequestBytes)
 	maxResponseBytes := positiveOr(options.MaxResponseBytes, defaultMaxResponseBytes)
+	parsed.RawQuery = options.Query.Encode()
 	return &Client{
 		httpClient:       httpclient.Secure(options.HTTPClient, durationOr(options.Timeout, defaultTimeout)),
 		endpoint:         parsed.String(),
 		apiKey:           options.APIKey,
+		apiKeyHeader:     options.APIKeyHeader,
+		imageDetail:      options.ImageDetail,
 		maxImageBytes:    maxImageBytes,
 		maxRequestBytes:  maxRequestBytes,
 		maxResponseBytes: maxResponseBytes,
@@ -133,14 +163,20 @@ func (c *Client) Extract(ctx context.Context, request providers.Request) (provid
 	payload := chatRequest{
 		Model:       request.Model,
 		Temperature: request.Temperature,
+		MaxTokens:   request.MaxTokens,
+		Logprobs:    request.Logprobs,
 		Messages: []chatMessage{{
 			Role: "user",
 			Content: []contentPart{
 				{Type: "text", Text: request.Prompt},
-				{Type: "image_url", ImageURL: &imageURL{URL: "data:" + mediaType + ";base64," + base64.StdEncoding.EncodeToString(request.Image.Data)}},
+				{Type: "image_url", ImageURL: &imageURL{URL: "data:" + mediaType + ";base64," + base64.StdEncoding.EncodeToString(request.Image.Data), Detail: c.imageDetail}},
 			},
 		}},
 	}
+	if request.SystemPrompt != "" {
+		system := chatMessage{Role: "system", Content: []contentPart{{Type: "text", Text: request.SystemPrompt}}}
+		payload.Messages = append([]chatMessage{system}, payload.Messages...)
+	}
 	body, err := json.Marshal(payload)
 	if err != nil || int64(len(body)) > c.maxRequestBytes {
 		return providers.Result{}, providers.NewError(providers.ErrorInvalidRequest, 0, false, nil)
@@ -151,7 +187,11 @@ func (c *Client) Extract(ctx context.Context, request providers.Request) (provid
 		return providers.Result{}, providers.NewError(providers.ErrorInvalidRequest, 0, false, nil)
 	}
 	httpRequest.Header.Set("Content-Type", "application/json")
-	if err := httpclient.StaticBearer(credential).Authorize(ctx, httpRequest); err != nil {
+	authenticator := httpclient.StaticBearer(credential)
+	if c.apiKeyHeader != "" {
+		authenticator = httpclient.StaticHeader(c.apiKeyHeader, credential)
+	}
+	if err := authenticator.Authorize(ctx, httpRequest); err != nil {
 		return providers.Result{}, providers.ErrorForAuthentication(ctx, err)
 	}
 
@@ -179,14 +219,21 @@ func (c *Client) Extract(ctx context.Context, request providers.Request) (provid
 	if effectiveModel == "" {
 		effectiveModel = request.Model
 	}
-	return providers.Result{
+	result := providers.Result{
 		Text: providers.CleanResponse(decoded.Choices[0].Message.Content),
 		Usage: providers.UsageInfo{
 			InputTokens:  decoded.Usage.PromptTokens,
 			OutputTokens: decoded.Usage.CompletionTokens,
+			Truncated:    decoded.Choices[0].FinishReason == "length",
 		},
 		EffectiveModel: effectiveModel,
-	}, nil
+	}
+	if logprobs := decoded.Choices[0].Logprobs; logprobs != nil {
+		for _, token := range logprobs.Content {
+			result.Logprobs = append(result.Logprobs, providers.TokenLogprob{Token: token.Token, Logprob: token.Logprob})
+		}
+	}
+	return result, nil
 }
 
 // New creates the historical CLI adapter.
@@ -196,20 +243,41 @@ func New() *Provider { return &Provider{} }
 func (p *Provider) Name() string { return "openai" }
 
 // ValidateConfig validates environment-backed CLI configuration.
-func (p *Provider) ValidateConfig(providers.Config) error {
+func (p *Provider) ValidateConfig(config providers.Config) error {
 	if strings.TrimSpace(os.Getenv("OPENAI_API_KEY")) == "" {
 		return providers.NewError(providers.ErrorAuthentication, 0, false, nil)
 	}
+	if _, err := resolveEndpoint(config); err != nil {
+		return providers.NewError(providers.ErrorInvalidRequest, 0, false, nil)
+	}
 	return nil
 }
 
 // ExtractText adapts historical base64 CLI inputs to Client.
 func (p *Provider) ExtractText(ctx context.Context, config providers.Config, imagePath, imageBase64 string) (string, providers.UsageInfo, error) {
+	result, err := p.extract(ctx, config, imagePath, imageBase64, false)
+	return result.Text, result.Usage, err
+}
+
+// ExtractTextWithLogprobs is ExtractText that also returns the log
+// probability of each response token.
+func (p *Provider) ExtractTextWithLogprobs(ctx context.Context, config providers.Config, imagePath, imageBase64 string) (string, providers.UsageInfo, []providers.TokenLogprob, error) {
+	result, err := p.extract(ctx, config, imagePath, imageBase64, true)
+	return result.Text, result.Usage, result.Logprobs, err
+}
+
+func (p *Provider) extract(ctx context.Context, config providers.Config, imagePath, imageBase64 string, logprobs bool) (providers.Result, error) {
 	request, err := providers.LegacyRequest(config, imagePath, imageBase64, defaultMaxImageBytes)
 	if err != nil {
-		return "", providers.UsageInfo{}, err
+		return providers.Result{}, err
+	}
+	request.Logprobs = logprobs
+	endpoint, err := resolveEndpoint(config)
+	if err != nil {
+		return providers.Result{}, providers.NewError(providers.ErrorInvalidRequest, 0, false, nil)
 	}
 	client, err := NewClient(Options{
+		Endpoint: endpoint,
 		APIKey: func(context.Context) (string, error) {
 			key := os.Getenv("OPENAI_API_KEY")
 			if strings.TrimSpace(key) == "" {
@@ -217,13 +285,27 @@ func (p *Provider) ExtractText(ctx context.Context, config providers.Config, ima
 			}
 			return key, nil
 		},
-		Timeout: config.Timeout,
+		ImageDetail: config.ImageDetail,
+		Timeout:     config.Timeout,
 	})
 	if err != nil {
-		return "", providers.UsageInfo{}, err
+		return providers.Result{}, err
 	}
-	result, err := client.Extract(ctx, request)
-	return result.Text, result.Usage, err
+	return client.Extract(ctx, request)
+}
+
+// resolveEndpoint appends the chat completions path to config.BaseURL, then
+// OPENAI_BASE_URL, and otherwise uses the public API. Proxies and gateways
+// are configured with the API root, for example https://proxy.example.org/v1.
+func resolveEndpoint(config providers.Config) (string, error) {
+	baseURL := strings.TrimSpace(config.BaseURL)
+	if baseURL == "" {
+		baseURL = strings.TrimSpace(os.Getenv("OPENAI_BASE_URL"))
+	}
+	if baseURL == "" {
+		return defaultEndpoint, nil
+	}
+	return httpclient.AppendPath(baseURL, completionsPath)
 }
 
 func positiveOr(value, fallback int64) int64 {
@@ -0,0 +1,87 @@
+// Package openaicompat provides a transcription provider for self-hosted and
+// third-party services that speak the OpenAI chat completions protocol, such
+// as vLLM, LM Studio, and OpenRouter. Requests are built by pkg/openai.
+package openaicompat
+
+import (
+	"context"
+	"os"
+	"strings"
+
+	"github.com/lehigh-university-libraries/htr/pkg/httpclient"
+	"github.com/lehigh-university-libraries/htr/pkg/openai"
+	"github.com/lehigh-university-libraries/htr/pkg/providers"
+)
+
+const (
+	defaultMaxImageBytes = 50 << 20
+	completionsPath      = "/chat/completions"
+)
+
+// Provider is the CLI adapter for OpenAI-compatible services. New
+// integrations should use openai.NewClient with an explicit Endpoint.
+type Provider struct {
+	providers.BaseProvider
+}
+
+// New creates the CLI adapter.
+func New() *Provider { return &Provider{} }
+
+// Name returns the provider name.
+func (p *Provider) Name() string { return "openai-compat" }
+
+// ValidateConfig validates environment-backed CLI configuration: the base URL,
+// the API key, and an explicit model, since compatible services have no
+// common default.
+func (p *Provider) ValidateConfig(config providers.Config) error {
+	if _, err := completionsEndpoint(config); err != nil {
+		return providers.NewError(providers.ErrorInvalidRequest, 0, false, nil)
+	}
+	if strings.TrimSpace(os.Getenv("OPENAI_COMPAT_API_KEY")) == "" {
+		return providers.NewError(providers.ErrorAuthentication, 0, false, nil)
+	}
+	if strings.TrimSpace(config.Model) == "" {
+		return providers.NewError(providers.ErrorInvalidRequest, 0, false, nil)
+	}
+	return nil
+}
+
+// ExtractText adapts historical base64 CLI inputs to an OpenAI client pointed
+// at the configured base URL.
+func (p *Provider) ExtractText(ctx context.Context, config providers.Config, imagePath, imageBase64 string) (string, providers.UsageInfo, error) {
+	request, err := providers.LegacyRequest(config, imagePath, imageBase64, defaultMaxImageBytes)
+	if err != nil {
+		return "", providers.UsageInfo{}, err
+	}
+	endpoint, err := completionsEndpoint(config)
+	if err != nil {
+		return "", providers.UsageInfo{}, providers.NewError(providers.ErrorInvalidRequest, 0, false, nil)
+	}
+	client, err := openai.NewClient(openai.Options{
+		Endpoint: endpoint,
+		APIKey: func(context.Context) (string, error) {
+			key := os.Getenv("OPENAI_COMPAT_API_KEY")
+			if strings.TrimSpace(key) == "" {
+				return "", providers.NewError(providers.ErrorAuthentication, 0, false, nil)
+			}
+			return key, nil
+		},
+		Timeout:     config.Timeout,
+		ImageDetail: config.ImageDetail,
+	})
+	if err != nil {
+		return "", providers.UsageInfo{}, err
+	}
+	result, err := client.Extract(ctx, request)
+	return result.Text, result.Usage, err
+}
+
+// completionsEndpoint appends the chat completions path to the base URL from
+// config.BaseURL or OPENAI_COMPAT_BASE_URL, for example http://localhost:8000/v1.
+func completionsEndpoint(config providers.Config) (string, error) {
+	baseURL := strings.TrimSpace(config.BaseURL)
+	if baseURL == "" {
+		baseURL = strings.TrimSpace(os.Getenv("OPENAI_COMPAT_BASE_URL"))
+	}
+	return httpclient.AppendPath(baseURL, completionsPath)
+}
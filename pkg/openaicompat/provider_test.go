@@ -0,0 +1,127 @@
+package openaicompat
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lehigh-university-libraries/htr/pkg/providers"
+)
+
+var _ providers.Provider = (*Provider)(nil)
+
+func TestProviderExtractText(t *testing.T) {
+	image := []byte("encoded-image")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, request *http.Request) {
+		if request.Method != http.MethodPost || request.URL.Path != "/v1/chat/completions" {
+			t.Errorf("unexpected request target: %s %s", request.Method, request.URL.String())
+		}
+		if got := request.Header.Get("Authorization"); got != "Bearer local-key" {
+			t.Errorf("Authorization = %q", got)
+		}
+		var body struct {
+			Model       string  `json:"model"`
+			Temperature float64 `json:"temperature"`
+			Messages    []struct {
+				Content []struct {
+					Type     string `json:"type"`
+					Text     string `json:"text"`
+					ImageURL struct {
+						URL    string `json:"url"`
+						Detail string `json:"detail"`
+					} `json:"image_url"`
+				} `json:"content"`
+			} `json:"messages"`
+		}
+		if err := json.NewDecoder(request.Body).Decode(&body); err != nil {
+			t.Fatal(err)
+		}
+		if body.Model != "qwen2.5-vl" || body.Temperature != 0.2 || len(body.Messages) != 1 || len(body.Messages[0].Content) != 2 {
+			t.Fatalf("unexpected chat request: %#v", body)
+		}
+		if got := body.Messages[0].Content[0].Text; got != "Transcribe" {
+			t.Errorf("prompt = %q", got)
+		}
+		wantURL := "data:image/png;base64," + base64.StdEncoding.EncodeToString(image)
+		if got := body.Messages[0].Content[1].ImageURL.URL; got != wantURL {
+			t.Errorf("image URL = %q, want %q", got, wantURL)
+		}
+		if got := body.Messages[0].Content[1].ImageURL.Detail; got != "low" {
+			t.Errorf("image detail = %q, want low", got)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"model":"qwen2.5-vl","choices":[{"message":{"content":"café 世界"}}],"usage":{"prompt_tokens":21,"completion_tokens":6}}`))
+	}))
+	defer server.Close()
+
+	t.Setenv("OPENAI_COMPAT_BASE_URL", server.URL+"/v1/")
+	t.Setenv("OPENAI_COMPAT_API_KEY", "local-key")
+	config := providers.Config{Model: "qwen2.5-vl", Prompt: "Transcribe", Temperature: 0.2, ImageDetail: "low"}
+	text, usage, err := New().ExtractText(context.Background(), config, "page.png", base64.StdEncoding.EncodeToString(image))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text != "café 世界" || usage.InputTokens != 21 || usage.OutputTokens != 6 {
+		t.Fatalf("unexpected result: %q %#v", text, usage)
+	}
+}
+
+func TestProviderExtractTextRedactsErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = w.Write([]byte("secret upstream body"))
+	}))
+	defer server.Close()
+
+	t.Setenv("OPENAI_COMPAT_BASE_URL", server.URL)
+	t.Setenv("OPENAI_COMPAT_API_KEY", "local-key")
+	config := providers.Config{Model: "model", Prompt: "Transcribe"}
+	_, _, err := New().ExtractText(context.Background(), config, "page.png", base64.StdEncoding.EncodeToString([]byte("image")))
+	var providerError *providers.Error
+	if !errors.As(err, &providerError) || providerError.Kind != providers.ErrorRateLimited || !providerError.Retryable {
+		t.Fatalf("expected retryable rate limit error, got %v", err)
+	}
+}
+
+func TestProviderValidateConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		baseURL string
+		apiKey  string
+		config  providers.Config
+		wantErr bool
+	}{
+		{name: "valid", baseURL: "http://localhost:8000/v1", apiKey: "key", config: providers.Config{Model: "model"}},
+		{name: "config base URL overrides environment", baseURL: "", apiKey: "key", config: providers.Config{Model: "model", BaseURL: "https://openrouter.ai/api/v1"}},
+		{name: "missing base URL", baseURL: "", apiKey: "key", config: providers.Config{Model: "model"}, wantErr: true},
+		{name: "invalid base URL", baseURL: "localhost:8000", apiKey: "key", config: providers.Config{Model: "model"}, wantErr: true},
+		{name: "missing API key", baseURL: "http://localhost:8000/v1", apiKey: "", config: providers.Config{Model: "model"}, wantErr: true},
+		{name: "missing model", baseURL: "http://localhost:8000/v1", apiKey: "key", config: providers.Config{}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("OPENAI_COMPAT_BASE_URL", tt.baseURL)
+			t.Setenv("OPENAI_COMPAT_API_KEY", tt.apiKey)
+			err := New().ValidateConfig(tt.config)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestProviderCapabilities(t *testing.T) {
+	provider := New()
+	if provider.Name() != "openai-compat" {
+		t.Fatalf("Name() = %q", provider.Name())
+	}
+	want := providers.Capabilities{ReportsTokenUsage: true, SupportsTemperature: true, SupportsCustomPrompt: true}
+	if got := provider.Capabilities(); got != want {
+		t.Errorf("Capabilities() = %+v, want %+v", got, want)
+	}
+}
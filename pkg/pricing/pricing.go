@@ -0,0 +1,108 @@
+// Package pricing provides per-model token prices for cost estimates.
+package pricing
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	yaml "go.yaml.in/yaml/v3"
+)
+
+// Price is the cost of a model in US dollars per million tokens.
+type Price struct {
+	Input  float64 `yaml:"input"`
+	Output float64 `yaml:"output"`
+}
+
+// Table maps model names to prices.
+type Table map[string]Price
+
+// defaultPrices are list prices for standard (non-batch) API usage.
+var defaultPrices = Table{
+	// OpenAI
+	"gpt-4o":       {Input: 2.50, Output: 10.00},
+	"gpt-4o-mini":  {Input: 0.15, Output: 0.60},
+	"gpt-4.1":      {Input: 2.00, Output: 8.00},
+	"gpt-4.1-mini": {Input: 0.40, Output: 1.60},
+	"gpt-4.1-nano": {Input: 0.10, Output: 0.40},
+	"gpt-5":        {Input: 1.25, Output: 10.00},
+	"gpt-5-mini":   {Input: 0.25, Output: 2.00},
+	"gpt-5-nano":   {Input: 0.05, Output: 0.40},
+
+	// Anthropic
+	"claude-opus-4-1":   {Input: 15.00, Output: 75.00},
+	"claude-sonnet-4-5": {Input: 3.00, Output: 15.00},
+	"claude-sonnet-4":   {Input: 3.00, Output: 15.00},
+	"claude-haiku-4-5":  {Input: 1.00, Output: 5.00},
+
+	// Google
+	"gemini-2.5-pro":        {Input: 1.25, Output: 10.00},
+	"gemini-2.5-flash":      {Input: 0.30, Output: 2.50},
+	"gemini-2.5-flash-lite": {Input: 0.10, Output: 0.40},
+	"gemini-2.0-flash":      {Input: 0.10, Output: 0.40},
+	"gemini-1.5-flash":      {Input: 0.075, Output: 0.30},
+
+	// Mistral
+	"pixtral-large-latest": {Input: 2.00, Output: 6.00},
+	"pixtral-12b":          {Input: 0.15, Output: 0.15},
+}
+
+// Default returns a copy of the built-in price table.
+func Default() Table {
+	return maps.Clone(defaultPrices)
+}
+
+// Lookup returns the price for model. An exact name wins; otherwise the longest
+// known name followed by "-" matches, so dated snapshots such as
+// "claude-sonnet-4-5-20250929" resolve to their base model.
+func (t Table) Lookup(model string) (Price, bool) {
+	if price, ok := t[model]; ok {
+		return price, true
+	}
+	best := ""
+	for name := range t {
+		if strings.HasPrefix(model, name+"-") && len(name) > len(best) {
+			best = name
+		}
+	}
+	if best == "" {
+		return Price{}, false
+	}
+	return t[best], true
+}
+
+// Models returns the known model names in sorted order.
+func (t Table) Models() []string {
+	return slices.Sorted(maps.Keys(t))
+}
+
+// LoadFile reads a YAML mapping of model name to input and output prices and
+// returns base with those entries added or replaced:
+//
+//	gpt-4o:
+//	  input: 2.50
+//	  output: 10.00
+func LoadFile(path string, base Table) (Table, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read price file %s: %w", path, err)
+	}
+	var overrides Table
+	if err := yaml.Unmarshal(data, &overrides); err != nil {
+		return nil, fmt.Errorf("failed to parse price file %s: %w", path, err)
+	}
+	table := maps.Clone(base)
+	if table == nil {
+		table = Table{}
+	}
+	for model, price := range overrides {
+		if price.Input < 0 || price.Output < 0 {
+			return nil, fmt.Errorf("invalid price for model %s in %s: prices must not be negative", model, path)
+		}
+		table[model] = price
+	}
+	return table, nil
+}
@@ -0,0 +1,80 @@
+package pricing
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestLookup(t *testing.T) {
+	table := Default()
+	tests := []struct {
+		model  string
+		want   Price
+		wantOK bool
+	}{
+		{"gpt-4o", Price{Input: 2.50, Output: 10.00}, true},
+		{"gpt-4o-mini", Price{Input: 0.15, Output: 0.60}, true},
+		{"gpt-4o-2024-08-06", Price{Input: 2.50, Output: 10.00}, true},
+		{"gpt-4o-mini-2024-07-18", Price{Input: 0.15, Output: 0.60}, true},
+		{"claude-sonnet-4-5-20250929", Price{Input: 3.00, Output: 15.00}, true},
+		{"gemini-2.5-flash-lite", Price{Input: 0.10, Output: 0.40}, true},
+		{"gpt-4", Price{}, false},
+		{"llava", Price{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := table.Lookup(tt.model)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("Lookup(%q) = %+v, %v; want %+v, %v", tt.model, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestDefaultIsACopy(t *testing.T) {
+	table := Default()
+	table["gpt-4o"] = Price{}
+	if Default()["gpt-4o"] == (Price{}) {
+		t.Fatal("modifying Default() changed the built-in table")
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	table, err := LoadFile(write("prices.yaml", "gpt-4o:\n  input: 1.0\n  output: 4.0\nllava:\n  input: 0\n  output: 0.01\n"), Default())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := table["gpt-4o"]; got != (Price{Input: 1.0, Output: 4.0}) {
+		t.Errorf("override gpt-4o = %+v", got)
+	}
+	if _, ok := table.Lookup("llava"); !ok {
+		t.Error("added model llava not found")
+	}
+	if _, ok := table.Lookup("gemini-2.5-pro"); !ok {
+		t.Error("built-in model dropped by override")
+	}
+	if !slices.IsSorted(table.Models()) {
+		t.Error("Models() is not sorted")
+	}
+
+	for name, content := range map[string]string{
+		"invalid.yaml":  "gpt-4o: [1, 2",
+		"negative.yaml": "gpt-4o:\n  input: -1\n",
+	} {
+		if _, err := LoadFile(write(name, content), Default()); err == nil {
+			t.Errorf("LoadFile(%s) succeeded", name)
+		}
+	}
+	if _, err := LoadFile(filepath.Join(dir, "missing.yaml"), Default()); err == nil {
+		t.Error("LoadFile(missing) succeeded")
+	}
+}
@@ -1,6 +1,7 @@
 package providers
 
 import (
+	"bytes"
 	"context"
 	"encoding/base64"
 	"errors"
@@ -13,6 +14,7 @@ import (
 	"regexp"
 	"strings"
 	"time"
+	"unicode"
 )
 
 const defaultLegacyImageLimit int64 = 50 << 20
@@ -30,13 +32,18 @@ var responsePrefixPatterns = []*regexp.Regexp{
 	regexp.MustCompile(`(?i)^i\s+can\s+see\s+text\s+reading:\s*`),
 	regexp.MustCompile(`(?i)^certainly!\s+here'?s?\s+(the\s+)?text\s+(extracted\s+)?from\s+(the\s+)?image:?\s*`),
 	regexp.MustCompile(`(?i)^here'?s?\s+the\s+extracted\s+text\s+from\s+(the\s+)?image:?\s*`),
+	regexp.MustCompile(`(?i)^here(\s+is|'s)\s+the\s+transcription(\s+of\s+(the\s+)?(image|text|page))?:\s*`),
+	regexp.MustCompile(`(?i)^transcription:\s*`),
 }
 
 // Config represents the configuration for a provider
 type Config struct {
-	Provider              string
-	Model                 string
-	Prompt                string
+	Provider string
+	Model    string
+	Prompt   string
+	// SystemPrompt is sent in the provider's system role, separate from the
+	// user prompt that accompanies the image. Empty sends no system prompt.
+	SystemPrompt          string
 	Temperature           float64
 	Timeout               time.Duration
 	Debug                 bool
@@ -44,12 +51,38 @@ type Config struct {
 	MaxResolutionFallback bool
 	BaseURL               string
 	Audience              string
+	// MaxTokens caps the length of the model's response. Zero uses the
+	// provider default.
+	MaxTokens int
+	// PollInterval is the delay between result polls for providers with
+	// asynchronous operations, such as Azure OCR. Zero uses the provider default.
+	PollInterval time.Duration
+	// KeepAlive is how long Ollama keeps the model loaded after a request,
+	// as a duration such as "5m" or a number of seconds, where "-1" keeps it
+	// loaded indefinitely. Empty uses the server default.
+	KeepAlive string
+	// NumCtx is Ollama's context window in tokens. Zero uses the model default.
+	NumCtx int
+	// ImageDetail is OpenAI's image detail level: "low", "high", or "auto".
+	// Empty uses the service default.
+	ImageDetail string
+	// Concurrency is the maximum number of lines transcribed at once when
+	// building hOCR. Values below 1 transcribe one line at a time.
+	Concurrency int
+	// MaxDimension downscales line images whose longest side exceeds it
+	// before they are sent when building hOCR. Zero sends them as cropped.
+	MaxDimension int
 }
 
 // UsageInfo represents token usage information from a provider
 type UsageInfo struct {
 	InputTokens  int
 	OutputTokens int
+	// Pages is the number of pages processed by page-billed providers.
+	Pages int
+	// Truncated is true when the model stopped at its output token limit,
+	// so the text is incomplete.
+	Truncated bool
 }
 
 // Image is an encoded image supplied to a transcription client.
@@ -62,10 +95,16 @@ type Image struct {
 
 // Request is the provider-neutral input to a transcription client.
 type Request struct {
-	Model       string
-	Prompt      string
-	Temperature float64
-	Image       Image
+	Model  string
+	Prompt string
+	// SystemPrompt is optional instruction text for the system role.
+	SystemPrompt string
+	Temperature  float64
+	// MaxTokens caps the response length; zero leaves it to the service.
+	MaxTokens int
+	// Logprobs asks clients that support it for per-token log probabilities.
+	Logprobs bool
+	Image    Image
 }
 
 // Result is the provider-neutral result of a transcription request.
@@ -73,6 +112,16 @@ type Result struct {
 	Text           string
 	Usage          UsageInfo
 	EffectiveModel string
+	// Logprobs is only set when the request asked for them and the service
+	// returned them.
+	Logprobs []TokenLogprob
+}
+
+// TokenLogprob is the natural log of the probability the model gave one
+// response token. Token is raw response text, before any cleaning.
+type TokenLogprob struct {
+	Token   string
+	Logprob float64
 }
 
 // Client transcribes encoded image bytes without depending on a filesystem.
@@ -105,6 +154,21 @@ const (
 	ErrorInvalidResponse ErrorKind = "invalid_response"
 )
 
+// Sentinel errors match a provider Error by category with errors.Is, so
+// callers can branch on a failure without inspecting its Kind.
+var (
+	// ErrRateLimited matches upstream throttling.
+	ErrRateLimited = errors.New("provider rate limited the request")
+	// ErrAuth matches missing or rejected credentials.
+	ErrAuth = errors.New("provider authentication failed")
+	// ErrBadRequest matches locally invalid input and requests the provider
+	// rejected, which fail the same way when repeated.
+	ErrBadRequest = errors.New("provider rejected the request")
+	// ErrTransient matches failures a retry may fix: throttling, timeouts,
+	// transport failures, and 5xx responses.
+	ErrTransient = errors.New("transient provider failure")
+)
+
 // Error is a deliberately redacted provider error suitable for logs and APIs.
 // It never contains request URLs, credentials, response bodies, or model output.
 type Error struct {
@@ -146,6 +210,25 @@ func (e *Error) Unwrap() error {
 	return e.cause
 }
 
+// Is reports whether e falls in the category of a sentinel error such as
+// ErrRateLimited.
+func (e *Error) Is(target error) bool {
+	if e == nil {
+		return false
+	}
+	switch target {
+	case ErrRateLimited:
+		return e.Kind == ErrorRateLimited
+	case ErrAuth:
+		return e.Kind == ErrorAuthentication
+	case ErrBadRequest:
+		return e.Kind == ErrorInvalidRequest
+	case ErrTransient:
+		return e.Retryable && e.Kind != ErrorCanceled
+	}
+	return false
+}
+
 // ErrorForStatus maps an HTTP response status to a redacted provider error.
 func ErrorForStatus(statusCode int) *Error {
 	switch statusCode {
@@ -189,7 +272,8 @@ func ErrorForAuthentication(ctx context.Context, err error) *Error {
 func ValidateRequest(request Request, maxImageBytes int64) error {
 	if strings.TrimSpace(request.Model) == "" || strings.TrimSpace(request.Model) != request.Model ||
 		len(request.Model) > maxModelBytes || strings.ContainsAny(request.Model, "\r\n\x00") ||
-		strings.TrimSpace(request.Prompt) == "" || len(request.Prompt) > maxPromptBytes || strings.ContainsRune(request.Prompt, '\x00') {
+		strings.TrimSpace(request.Prompt) == "" || len(request.Prompt) > maxPromptBytes || strings.ContainsRune(request.Prompt, '\x00') ||
+		len(request.SystemPrompt) > maxPromptBytes || strings.ContainsRune(request.SystemPrompt, '\x00') {
 		return NewError(ErrorInvalidRequest, 0, false, nil)
 	}
 	if request.Temperature < 0 || math.IsNaN(request.Temperature) || math.IsInf(request.Temperature, 0) {
@@ -219,6 +303,43 @@ func CanonicalMediaType(raw string) (string, error) {
 	return mediaType, nil
 }
 
+// DetectMediaType returns the media type of data from its leading bytes, such
+// as "image/png" or "application/pdf". It recognizes TIFF and HEIF images,
+// which http.DetectContentType does not, and returns
+// "application/octet-stream" when nothing matches.
+func DetectMediaType(data []byte) string {
+	switch {
+	case bytes.HasPrefix(data, []byte("II*\x00")), bytes.HasPrefix(data, []byte("MM\x00*")):
+		return "image/tiff"
+	case len(data) >= 12 && string(data[4:8]) == "ftyp":
+		switch string(data[8:12]) {
+		case "heic", "heix", "heim", "heis":
+			return "image/heic"
+		case "mif1", "msf1":
+			return "image/heif"
+		}
+	}
+	mediaType, _, err := mime.ParseMediaType(http.DetectContentType(data))
+	if err != nil {
+		return "application/octet-stream"
+	}
+	return mediaType
+}
+
+// ImageMediaType returns the media type to send for an image: the sniffed type
+// when data is a recognized image, otherwise the type implied by the file
+// extension, so a PNG saved as .jpg is still labeled image/png.
+func ImageMediaType(imagePath string, data []byte) string {
+	sniffed := DetectMediaType(data)
+	if strings.HasPrefix(sniffed, "image/") {
+		return sniffed
+	}
+	if byExtension, _, err := mime.ParseMediaType(mime.TypeByExtension(filepath.Ext(imagePath))); err == nil {
+		return byExtension
+	}
+	return sniffed
+}
+
 // LegacyRequest converts the historical base64/file-name inputs into a bounded
 // byte-oriented request. It exists only for CLI compatibility.
 func LegacyRequest(config Config, imagePath, imageBase64 string, maxImageBytes int64) (Request, error) {
@@ -234,18 +355,14 @@ func LegacyRequest(config Config, imagePath, imageBase64 string, maxImageBytes i
 		return Request{}, NewError(ErrorInvalidRequest, 0, false, nil)
 	}
 
-	mediaType := mime.TypeByExtension(filepath.Ext(imagePath))
-	if mediaType == "" {
-		mediaType = http.DetectContentType(data)
-	}
-	if parsed, _, parseErr := mime.ParseMediaType(mediaType); parseErr == nil {
-		mediaType = parsed
-	}
+	mediaType := ImageMediaType(imagePath, data)
 
 	request := Request{
-		Model:       config.Model,
-		Prompt:      config.Prompt,
-		Temperature: config.Temperature,
+		Model:        config.Model,
+		Prompt:       config.Prompt,
+		SystemPrompt: config.SystemPrompt,
+		Temperature:  config.Temperature,
+		MaxTokens:    config.MaxTokens,
 		Image: Image{
 			Data:      data,
 			MediaType: mediaType,
@@ -267,6 +384,54 @@ type Provider interface {
 	Name() string
 	// ValidateConfig validates the provider-specific configuration
 	ValidateConfig(config Config) error
+	// Capabilities reports which optional features the provider supports
+	Capabilities() Capabilities
+}
+
+// Capabilities describes what a provider honors and reports, so callers can
+// adapt to a provider without special-casing its name.
+type Capabilities struct {
+	// ReportsTokenUsage is true when UsageInfo holds real input and output token counts.
+	ReportsTokenUsage bool
+	// ReportsPageUsage is true when UsageInfo.Pages holds the number of pages billed.
+	ReportsPageUsage bool
+	// SupportsTemperature is true when Config.Temperature is sent to the model.
+	SupportsTemperature bool
+	// SupportsCustomPrompt is true when Config.Prompt is sent to the model.
+	SupportsCustomPrompt bool
+}
+
+// BaseProvider supplies the default Capabilities of a vision language model
+// provider: token usage is reported, and the prompt and temperature are sent
+// with each request. Providers embed it and override Capabilities as needed.
+type BaseProvider struct{}
+
+// Capabilities returns the vision language model defaults.
+func (BaseProvider) Capabilities() Capabilities {
+	return Capabilities{
+		ReportsTokenUsage:    true,
+		SupportsTemperature:  true,
+		SupportsCustomPrompt: true,
+	}
+}
+
+// MediaTypes returns the image formats vision language model APIs commonly
+// accept: JPEG, PNG, GIF, and WebP.
+func (BaseProvider) MediaTypes() []string {
+	return []string{"image/jpeg", "image/png", "image/gif", "image/webp"}
+}
+
+// MediaTypeProvider is an optional interface for providers that accept only
+// some image formats, so unsupported files can be rejected before a request
+// is sent. Providers without it accept any format.
+type MediaTypeProvider interface {
+	MediaTypes() []string
+}
+
+// LogprobProvider is an optional interface for providers that can return the
+// log probability of each response token alongside the text.
+type LogprobProvider interface {
+	ExtractTextWithLogprobs(ctx context.Context, config Config, imagePath, imageBase64 string) (string, UsageInfo, []TokenLogprob, error)
 }
 
 // CleanResponseProvider is an optional interface that providers can implement
@@ -284,8 +449,9 @@ func CleanResponse(response string) string {
 		response = strings.TrimSpace(response)
 	}
 
-	// Remove surrounding quotes
-	response = strings.Trim(response, `"'`)
+	// Remove quotes wrapping the whole response, keeping apostrophes and
+	// quotations that are part of the text
+	response = unwrapQuotes(response)
 
 	// Remove markdown code blocks if present
 	if strings.HasPrefix(response, "```") && strings.HasSuffix(response, "```") {
@@ -297,6 +463,37 @@ func CleanResponse(response string) string {
 	return response
 }
 
+// quotePairs maps each opening quote a model may wrap a response in to its
+// closing quote.
+var quotePairs = map[rune]rune{'"': '"', '\'': '\'', '“': '”', '‘': '’'}
+
+// unwrapQuotes removes one pair of quotes when it wraps the entire response.
+// The pair must match, and the quoted text may not hold a closing quote of
+// its own, so 'tis the season and "Yes," she said, "go" keep their quotes.
+// An apostrophe between letters, as in 'It's late', is not a closing quote.
+func unwrapQuotes(response string) string {
+	runes := []rune(response)
+	if len(runes) < 2 {
+		return response
+	}
+	closing, ok := quotePairs[runes[0]]
+	if !ok || runes[len(runes)-1] != closing {
+		return response
+	}
+	inner := runes[1 : len(runes)-1]
+	for i, r := range inner {
+		if r != closing {
+			continue
+		}
+		if (closing == '\'' || closing == '’') && i > 0 && i < len(inner)-1 &&
+			unicode.IsLetter(inner[i-1]) && unicode.IsLetter(inner[i+1]) {
+			continue
+		}
+		return response
+	}
+	return strings.TrimSpace(string(inner))
+}
+
 // ProcessResponse cleans a response using the provider's custom cleaner if available,
 // otherwise uses the general CleanResponse function
 func ProcessResponse(provider Provider, response string) string {
@@ -306,6 +503,43 @@ func ProcessResponse(provider Provider, response string) string {
 	return CleanResponse(response)
 }
 
+// CompileStripPatterns compiles regular expressions matching text a model
+// adds around a transcription, such as a label or a closing remark, for
+// StripResponse.
+func CompileStripPatterns(expressions []string) ([]*regexp.Regexp, error) {
+	patterns := make([]*regexp.Regexp, 0, 2*len(expressions))
+	for _, expression := range expressions {
+		prefix, err := regexp.Compile(`^\s*(?:` + expression + `)\s*`)
+		if err != nil {
+			return nil, fmt.Errorf("invalid strip pattern %q: %w", expression, err)
+		}
+		suffix := regexp.MustCompile(`\s*(?:` + expression + `)\s*$`)
+		patterns = append(patterns, prefix, suffix)
+	}
+	return patterns, nil
+}
+
+// StripResponse removes the patterns from CompileStripPatterns wherever they
+// match at the start or end of response, repeating until none match, so a
+// label and a trailing remark are both removed. Text between them is kept
+// as it is.
+func StripResponse(response string, patterns []*regexp.Regexp) string {
+	if len(patterns) == 0 {
+		return response
+	}
+	for {
+		stripped := response
+		for _, pattern := range patterns {
+			stripped = pattern.ReplaceAllString(stripped, "")
+		}
+		stripped = strings.TrimSpace(stripped)
+		if stripped == response {
+			return stripped
+		}
+		response = stripped
+	}
+}
+
 // TruncateBody truncates a response body to a maximum length for error messages.
 // This helps keep error logs readable while still providing context.
 // Default maxLen is 500 if not specified.
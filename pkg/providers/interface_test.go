@@ -4,6 +4,8 @@ import (
 	"context"
 	"encoding/base64"
 	"errors"
+	"fmt"
+	"slices"
 	"strings"
 	"testing"
 )
@@ -24,11 +26,12 @@ func TestValidateRequestAndCanonicalMediaType(t *testing.T) {
 		t.Fatalf("CanonicalMediaType() = %q, %v", mediaType, err)
 	}
 
-	invalid := []Request{request, request, request, request}
+	invalid := []Request{request, request, request, request, request}
 	invalid[0].Model = " model"
 	invalid[1].Model = "model\nheader"
 	invalid[2].Prompt = strings.Repeat("x", maxPromptBytes+1)
 	invalid[3].Image.MediaType = "text/plain"
+	invalid[4].SystemPrompt = "system\x00prompt"
 	for _, candidate := range invalid {
 		if err := ValidateRequest(candidate, 10); errorKind(err) != ErrorInvalidRequest {
 			t.Errorf("expected invalid request, got %v", err)
@@ -38,13 +41,13 @@ func TestValidateRequestAndCanonicalMediaType(t *testing.T) {
 
 func TestLegacyRequestDecodesBoundedBytes(t *testing.T) {
 	t.Parallel()
-	config := Config{Model: "model", Prompt: "prompt"}
+	config := Config{Model: "model", Prompt: "prompt", SystemPrompt: "system"}
 	encoded := base64.StdEncoding.EncodeToString([]byte("image"))
 	request, err := LegacyRequest(config, "/tmp/private-name.png", encoded, 5)
 	if err != nil {
 		t.Fatal(err)
 	}
-	if string(request.Image.Data) != "image" || request.Image.MediaType != "image/png" || request.Image.Filename != "private-name.png" {
+	if request.SystemPrompt != "system" || string(request.Image.Data) != "image" || request.Image.MediaType != "image/png" || request.Image.Filename != "private-name.png" {
 		t.Fatalf("unexpected request: %#v", request)
 	}
 	if _, err := LegacyRequest(config, "page.png", encoded, 4); errorKind(err) != ErrorInvalidRequest {
@@ -55,6 +58,43 @@ func TestLegacyRequestDecodesBoundedBytes(t *testing.T) {
 	}
 }
 
+func TestDetectMediaType(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{name: "jpeg", data: "\xff\xd8\xff\xe0\x00\x10JFIF", want: "image/jpeg"},
+		{name: "png", data: "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR", want: "image/png"},
+		{name: "little-endian tiff", data: "II*\x00\x08\x00\x00\x00", want: "image/tiff"},
+		{name: "big-endian tiff", data: "MM\x00*\x00\x00\x00\x08", want: "image/tiff"},
+		{name: "heic", data: "\x00\x00\x00\x18ftypheic\x00\x00\x00\x00", want: "image/heic"},
+		{name: "pdf", data: "%PDF-1.7\n", want: "application/pdf"},
+		{name: "text", data: "not an image", want: "text/plain"},
+		{name: "empty", data: "", want: "text/plain"},
+	}
+	for _, tt := range tests {
+		if got := DetectMediaType([]byte(tt.data)); got != tt.want {
+			t.Errorf("DetectMediaType(%s) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestImageMediaTypePrefersContentOverExtension(t *testing.T) {
+	t.Parallel()
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+	if got := ImageMediaType("scan.jpg", png); got != "image/png" {
+		t.Errorf("ImageMediaType(PNG named .jpg) = %q, want image/png", got)
+	}
+	if got := ImageMediaType("scan.webp", []byte("opaque")); got != "image/webp" {
+		t.Errorf("ImageMediaType(unrecognized .webp) = %q, want image/webp", got)
+	}
+	if got := ImageMediaType("scan", []byte("opaque")); got != "text/plain" {
+		t.Errorf("ImageMediaType(unrecognized, no extension) = %q, want text/plain", got)
+	}
+}
+
 func TestErrorIsCategoricalRedactedAndContextAware(t *testing.T) {
 	t.Parallel()
 	errorValue := NewError(ErrorUpstream, 502, true, nil)
@@ -86,6 +126,129 @@ func TestErrorForStatus(t *testing.T) {
 	}
 }
 
+func TestErrorForStatusMatchesSentinels(t *testing.T) {
+	t.Parallel()
+	sentinels := []error{ErrRateLimited, ErrAuth, ErrBadRequest, ErrTransient}
+	tests := []struct {
+		status int
+		want   []error
+	}{
+		{status: 400, want: []error{ErrBadRequest}},
+		{status: 401, want: []error{ErrAuth}},
+		{status: 403, want: []error{ErrAuth}},
+		{status: 404, want: []error{ErrBadRequest}},
+		{status: 408, want: []error{ErrTransient}},
+		{status: 413, want: []error{ErrBadRequest}},
+		{status: 418},
+		{status: 422, want: []error{ErrBadRequest}},
+		{status: 429, want: []error{ErrRateLimited, ErrTransient}},
+		{status: 500, want: []error{ErrTransient}},
+		{status: 503, want: []error{ErrTransient}},
+		{status: 504, want: []error{ErrTransient}},
+	}
+
+	for _, tt := range tests {
+		// Callers see provider errors wrapped with row context.
+		err := fmt.Errorf("row 3: %w", ErrorForStatus(tt.status))
+		for _, sentinel := range sentinels {
+			if got, want := errors.Is(err, sentinel), slices.Contains(tt.want, sentinel); got != want {
+				t.Errorf("status %d: errors.Is(%q) = %v, want %v", tt.status, sentinel, got, want)
+			}
+		}
+	}
+
+	canceled := ErrorForRequest(context.Background(), context.Canceled)
+	if errors.Is(canceled, ErrTransient) {
+		t.Error("canceled request matches ErrTransient")
+	}
+	if timeout := ErrorForRequest(context.Background(), context.DeadlineExceeded); !errors.Is(timeout, ErrTransient) || !errors.Is(timeout, context.DeadlineExceeded) {
+		t.Errorf("timeout = %v, want it to match ErrTransient and context.DeadlineExceeded", timeout)
+	}
+}
+
+func TestCleanResponseStripsTranscriptionLabels(t *testing.T) {
+	t.Parallel()
+	tests := map[string]string{
+		"Transcription: Dear sir,\nI remain":                        "Dear sir,\nI remain",
+		"Here is the transcription:\n\nDear sir,":                   "Dear sir,",
+		"Here's the transcription of the page: Dear sir,":           "Dear sir,",
+		"Transcription of a letter from 1850":                       "Transcription of a letter from 1850",
+		"The transcription: in the margin is illegible":             "The transcription: in the margin is illegible",
+		"Certainly! Here's the text extracted from the image: Dear": "Dear",
+	}
+	for input, want := range tests {
+		if got := CleanResponse(input); got != want {
+			t.Errorf("CleanResponse(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestCleanResponseUnwrapsOnlyWholeQuotedResponses(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"whole response double quoted", `"whole thing quoted"`, "whole thing quoted"},
+		{"whole response single quoted", `'whole thing quoted'`, "whole thing quoted"},
+		{"whole response in curly quotes", "“whole thing quoted”", "whole thing quoted"},
+		{"leading apostrophe", "'tis the season", "'tis the season"},
+		{"trailing apostrophe", "the Jones' farm", "the Jones' farm"},
+		{"quoted word", "he wrote 'yes'", "he wrote 'yes'"},
+		{"quotations at both ends", `"Yes," she said, "go"`, `"Yes," she said, "go"`},
+		{"mismatched quotes", `"text'`, `"text'`},
+		{"apostrophe inside single quotes", "'It's late'", "It's late"},
+		{"multiline", "\"Dear sir,\nI remain\"", "Dear sir,\nI remain"},
+	}
+	for _, tt := range tests {
+		if got := CleanResponse(tt.input); got != tt.expected {
+			t.Errorf("%s: CleanResponse(%q) = %q, want %q", tt.name, tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestStripResponse(t *testing.T) {
+	t.Parallel()
+	patterns, err := CompileStripPatterns([]string{`(?i)\*\*transcribed text:?\*\*`, `(?i)let me know if you need anything else\.?`, `-{3,}`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"label", "**Transcribed text:**\nDear sir,", "Dear sir,"},
+		{"label and closing remark", "**Transcribed text:** Dear sir,\n\nLet me know if you need anything else.", "Dear sir,"},
+		{"nested wrappers", "---\n**Transcribed text:**\nDear sir,\n---", "Dear sir,"},
+		{"pattern inside the text is kept", "Dear sir,\n---\nI remain", "Dear sir,\n---\nI remain"},
+		{"no match", "Dear sir,", "Dear sir,"},
+	}
+	for _, tt := range tests {
+		if got := StripResponse(ProcessResponse(cleanerProvider{}, tt.input), patterns); got != tt.expected {
+			t.Errorf("%s: StripResponse(%q) = %q, want %q", tt.name, tt.input, got, tt.expected)
+		}
+	}
+
+	if got := StripResponse(ProcessResponse(cleanerProvider{}, "  Dear sir,  "), nil); got != "Dear sir," {
+		t.Errorf("StripResponse() without patterns = %q", got)
+	}
+	if _, err := CompileStripPatterns([]string{"(unclosed"}); err == nil || !strings.Contains(err.Error(), "(unclosed") {
+		t.Errorf("CompileStripPatterns() error = %v, want the invalid pattern named", err)
+	}
+}
+
+// cleanerProvider is a Provider without a custom cleaner, so ProcessResponse
+// uses CleanResponse.
+type cleanerProvider struct{ BaseProvider }
+
+func (cleanerProvider) Name() string                { return "cleaner" }
+func (cleanerProvider) ValidateConfig(Config) error { return nil }
+func (cleanerProvider) ExtractText(context.Context, Config, string, string) (string, UsageInfo, error) {
+	return "", UsageInfo{}, nil
+}
+
 func errorKind(err error) ErrorKind {
 	var providerError *Error
 	if errors.As(err, &providerError) {
@@ -0,0 +1,64 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// RateLimiter paces requests to at most a fixed number per minute. It is a
+// token bucket holding a single token, so requests are spread evenly across
+// the minute instead of bursting at its start. It is safe for concurrent use,
+// and a nil RateLimiter never waits.
+type RateLimiter struct {
+	interval time.Duration
+
+	mu   sync.Mutex
+	next time.Time
+}
+
+// NewRateLimiter returns a limiter allowing requestsPerMinute requests a
+// minute, or nil, which does not limit, when requestsPerMinute is not
+// positive.
+func NewRateLimiter(requestsPerMinute int) *RateLimiter {
+	if requestsPerMinute <= 0 {
+		return nil
+	}
+	return &RateLimiter{interval: time.Minute / time.Duration(requestsPerMinute)}
+}
+
+// Wait blocks until a request may be sent. It returns ctx's error if ctx is
+// done first, and returns an error at once, without taking a turn, when the
+// wait would outlive ctx's deadline.
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	turn := l.next
+	if turn.Before(now) {
+		turn = now
+	}
+	delay := turn.Sub(now)
+	if deadline, ok := ctx.Deadline(); ok && deadline.Before(turn) {
+		l.mu.Unlock()
+		return fmt.Errorf("rate limit wait of %s would exceed the request deadline", delay.Round(time.Millisecond))
+	}
+	l.next = turn.Add(l.interval)
+	l.mu.Unlock()
+
+	if delay <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
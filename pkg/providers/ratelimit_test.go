@@ -0,0 +1,74 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterPacesRequests(t *testing.T) {
+	t.Parallel()
+	// 1200 requests a minute is one every 50ms, so the fourth request of a
+	// burst waits for three intervals
+	limiter := NewRateLimiter(1200)
+	const requests = 4
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	errs := make(chan error, requests)
+	for range requests {
+		wg.Go(func() {
+			errs <- limiter.Wait(context.Background())
+		})
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("Wait() error = %v", err)
+		}
+	}
+
+	if elapsed, want := time.Since(start), 3*50*time.Millisecond; elapsed < want {
+		t.Fatalf("%d requests took %s, want at least %s", requests, elapsed, want)
+	}
+}
+
+func TestRateLimiterHonorsContext(t *testing.T) {
+	t.Parallel()
+	limiter := NewRateLimiter(1)
+	if err := limiter.Wait(context.Background()); err != nil {
+		t.Fatalf("first Wait() error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	start := time.Now()
+	if err := limiter.Wait(ctx); err == nil {
+		t.Fatal("Wait() error = nil, want an error for a turn past the deadline")
+	}
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Fatalf("Wait() blocked for %s, want an immediate error", elapsed)
+	}
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := NewRateLimiter(1).Wait(canceled); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Wait() with a canceled context error = %v, want context.Canceled", err)
+	}
+}
+
+func TestNilRateLimiterDoesNotWait(t *testing.T) {
+	t.Parallel()
+	limiter := NewRateLimiter(0)
+	if limiter != nil {
+		t.Fatalf("NewRateLimiter(0) = %v, want nil", limiter)
+	}
+	for range 100 {
+		if err := limiter.Wait(context.Background()); err != nil {
+			t.Fatalf("Wait() error = %v", err)
+		}
+	}
+}
@@ -2,6 +2,8 @@ package providers
 
 import (
 	"fmt"
+	"maps"
+	"slices"
 	"strings"
 )
 
@@ -31,13 +33,9 @@ func (r *Registry) Get(name string) (Provider, error) {
 	return provider, nil
 }
 
-// List returns all available provider names
+// List returns all available provider names in sorted order
 func (r *Registry) List() []string {
-	var names []string
-	for name := range r.providers {
-		names = append(names, name)
-	}
-	return names
+	return slices.Sorted(maps.Keys(r.providers))
 }
 
 // HasProvider checks if a provider is registered
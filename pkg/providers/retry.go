@@ -0,0 +1,91 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net"
+	"time"
+)
+
+const defaultMaxRetryDelay = 30 * time.Second
+
+// RetryPolicy configures exponential backoff for transient provider failures.
+type RetryPolicy struct {
+	// MaxRetries is the number of additional attempts after the first call.
+	// Zero disables retries.
+	MaxRetries int
+	// BaseDelay is the backoff before the first retry. Each later retry
+	// doubles it, up to MaxDelay.
+	BaseDelay time.Duration
+	// MaxDelay caps a single backoff. It defaults to 30 seconds.
+	MaxDelay time.Duration
+	// OnRetry, when set, is called before sleeping for a retry.
+	OnRetry func(attempt int, delay time.Duration, err error)
+}
+
+// Retry calls operation until it succeeds, returns an error that IsRetryable
+// rejects, exhausts the retry budget, or ctx is done. A backoff that would
+// outlive the context deadline is not started; the last error is returned.
+func Retry(ctx context.Context, policy RetryPolicy, operation func(context.Context) error) error {
+	var err error
+	for attempt := 0; ; attempt++ {
+		err = operation(ctx)
+		if err == nil || attempt >= policy.MaxRetries || !IsRetryable(err) || ctx.Err() != nil {
+			return err
+		}
+
+		delay := backoff(policy, attempt)
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			return err
+		}
+		if policy.OnRetry != nil {
+			policy.OnRetry(attempt+1, delay, err)
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+// IsRetryable reports whether err is a transient failure: a retryable
+// provider error (rate limiting, 5xx, transport, or timeout) or a network
+// timeout. Caller cancellation and invalid requests are never retryable.
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	var providerErr *Error
+	if errors.As(err, &providerErr) {
+		return errors.Is(providerErr, ErrTransient)
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// backoff returns an exponentially growing delay with jitter in [d/2, d].
+func backoff(policy RetryPolicy, attempt int) time.Duration {
+	maxDelay := policy.MaxDelay
+	if maxDelay <= 0 {
+		maxDelay = defaultMaxRetryDelay
+	}
+	delay := policy.BaseDelay
+	if delay <= 0 {
+		return 0
+	}
+	for range attempt {
+		if delay >= maxDelay/2 {
+			delay = maxDelay
+			break
+		}
+		delay *= 2
+	}
+	delay = min(delay, maxDelay)
+	half := delay / 2
+	return half + rand.N(delay-half+1)
+}
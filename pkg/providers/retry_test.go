@@ -0,0 +1,149 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRetryRecoversFromTransientStatuses(t *testing.T) {
+	t.Parallel()
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		switch calls.Add(1) {
+		case 1:
+			w.WriteHeader(http.StatusTooManyRequests)
+		case 2:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer server.Close()
+
+	var retries []int
+	policy := RetryPolicy{
+		MaxRetries: 3,
+		BaseDelay:  time.Millisecond,
+		OnRetry: func(attempt int, _ time.Duration, _ error) {
+			retries = append(retries, attempt)
+		},
+	}
+	err := Retry(context.Background(), policy, func(ctx context.Context) error {
+		return statusCall(ctx, server.URL)
+	})
+	if err != nil {
+		t.Fatalf("Retry() error = %v", err)
+	}
+	if calls.Load() != 3 || len(retries) != 2 || retries[0] != 1 || retries[1] != 2 {
+		t.Fatalf("calls = %d, retries = %v; want 3 calls and retries [1 2]", calls.Load(), retries)
+	}
+}
+
+func TestRetryStopsOnClientErrorsAndBudget(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name       string
+		status     int
+		maxRetries int
+		wantCalls  int32
+		wantKind   ErrorKind
+	}{
+		{"bad request is not retried", http.StatusBadRequest, 3, 1, ErrorInvalidRequest},
+		{"unauthorized is not retried", http.StatusUnauthorized, 3, 1, ErrorAuthentication},
+		{"server error exhausts budget", http.StatusBadGateway, 2, 3, ErrorUpstream},
+		{"zero retries disables retry", http.StatusTooManyRequests, 0, 1, ErrorRateLimited},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			var calls atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				calls.Add(1)
+				w.WriteHeader(test.status)
+			}))
+			defer server.Close()
+
+			err := Retry(context.Background(), RetryPolicy{MaxRetries: test.maxRetries, BaseDelay: time.Millisecond}, func(ctx context.Context) error {
+				return statusCall(ctx, server.URL)
+			})
+			if errorKind(err) != test.wantKind || calls.Load() != test.wantCalls {
+				t.Fatalf("err = %v after %d calls, want %s after %d", err, calls.Load(), test.wantKind, test.wantCalls)
+			}
+		})
+	}
+}
+
+func TestRetryHonorsContextDeadline(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	var calls int
+	start := time.Now()
+	err := Retry(ctx, RetryPolicy{MaxRetries: 5, BaseDelay: time.Second}, func(context.Context) error {
+		calls++
+		return ErrorForStatus(http.StatusServiceUnavailable)
+	})
+	if errorKind(err) != ErrorUpstream || calls != 1 {
+		t.Fatalf("err = %v after %d calls, want one upstream failure", err, calls)
+	}
+	if elapsed := time.Since(start); elapsed > 40*time.Millisecond {
+		t.Fatalf("Retry slept %s past a deadline it could not meet", elapsed)
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"rate limited", ErrorForStatus(http.StatusTooManyRequests), true},
+		{"service unavailable", ErrorForStatus(http.StatusServiceUnavailable), true},
+		{"unprocessable", ErrorForStatus(http.StatusUnprocessableEntity), false},
+		{"canceled", ErrorForRequest(context.Background(), context.Canceled), false},
+		{"transport", ErrorForRequest(context.Background(), errors.New("dial failed")), true},
+		{"plain error", errors.New("boom"), false},
+	}
+	for _, test := range tests {
+		if got := IsRetryable(test.err); got != test.want {
+			t.Errorf("IsRetryable(%s) = %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestBackoffGrowsWithJitterAndCap(t *testing.T) {
+	t.Parallel()
+	policy := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+	for attempt, want := range []time.Duration{100, 200, 400, 800, 1000, 1000} {
+		want *= time.Millisecond
+		for range 20 {
+			got := backoff(policy, attempt)
+			if got < want/2 || got > want {
+				t.Fatalf("backoff(attempt %d) = %s, want within [%s, %s]", attempt, got, want/2, want)
+			}
+		}
+	}
+}
+
+func statusCall(ctx context.Context, url string) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return ErrorForRequest(ctx, err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return ErrorForStatus(response.StatusCode)
+	}
+	return nil
+}